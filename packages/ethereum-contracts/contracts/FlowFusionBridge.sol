@@ -8,6 +8,7 @@ import "@openzeppelin/contracts/token/ERC20/IERC20.sol";
 import "@openzeppelin/contracts/token/ERC20/utils/SafeERC20.sol";
 import "./libraries/TWAPMath.sol";
 import "./interfaces/IFlowFusionBridge.sol";
+import "./interfaces/IPermit2.sol";
 
 /**
  * @title FlowFusion Bridge
@@ -29,12 +30,14 @@ contract FlowFusionBridge is
     //////////////////////////////////////////////////////////////*/
     
     uint256 public constant BASIS_POINTS = 10000;
+    uint256 public constant PRICE_PRECISION = 1e18; // execution prices are 18-decimal fixed point
     uint256 public constant MAX_SLIPPAGE = 1000; // 10%
     uint256 public constant MIN_WINDOW_MINUTES = 5;
     uint256 public constant MAX_WINDOW_MINUTES = 1440; // 24 hours
     uint256 public constant MAX_EXECUTION_INTERVALS = 20;
     uint256 public constant MIN_EXECUTION_AMOUNT = 1000; // Minimum wei to prevent dust attacks
     uint256 public constant VERSION = 1;
+    address public constant PERMIT2 = 0x000000000022D473030F116dDEE9F6B43aC78BA3; // canonical on every chain
 
     /*//////////////////////////////////////////////////////////////
                                  STATE
@@ -91,6 +94,14 @@ contract FlowFusionBridge is
         uint256 cancelledAt
     );
 
+    event OrderAmended(
+        bytes32 indexed orderId,
+        uint256 sourceAmount,
+        uint256 refundAmount,
+        TWAPConfig twapConfig,
+        uint256 amendedAt
+    );
+
     /* event HTLCCreated(
         bytes32 indexed orderId,
         bytes32 indexed htlcHash,
@@ -185,6 +196,64 @@ contract FlowFusionBridge is
         bytes32 htlcHash,
         uint256 timeoutHeight
     ) external payable nonReentrant whenNotPaused returns (bytes32 orderId) {
+        return _createTWAPOrder(
+            msg.sender,
+            sourceToken,
+            sourceAmount,
+            targetChain,
+            targetToken,
+            targetRecipient,
+            twapConfig,
+            htlcHash,
+            timeoutHeight
+        );
+    }
+
+    /**
+     * @notice Create a TWAP order funded by a user who approved the bridge,
+     *         typically through an EIP-2612 or Permit2 signature submitted
+     *         by the executor in the same flow
+     * @param user Owner of the order and of the source tokens
+     * @dev Native ETH orders must be created by the user
+     */
+    function createTWAPOrderFor(
+        address user,
+        address sourceToken,
+        uint256 sourceAmount,
+        string memory targetChain,
+        string memory targetToken,
+        string memory targetRecipient,
+        TWAPConfig memory twapConfig,
+        bytes32 htlcHash,
+        uint256 timeoutHeight
+    ) external onlyAuthorizedExecutor nonReentrant whenNotPaused returns (bytes32 orderId) {
+        require(user != address(0), "FlowFusion: Invalid user");
+        require(sourceToken != address(0), "FlowFusion: Native orders need the user");
+
+        return _createTWAPOrder(
+            user,
+            sourceToken,
+            sourceAmount,
+            targetChain,
+            targetToken,
+            targetRecipient,
+            twapConfig,
+            htlcHash,
+            timeoutHeight
+        );
+    }
+
+    function _createTWAPOrder(
+        address user,
+        address sourceToken,
+        uint256 sourceAmount,
+        string memory targetChain,
+        string memory targetToken,
+        string memory targetRecipient,
+        TWAPConfig memory twapConfig,
+        bytes32 htlcHash,
+        uint256 timeoutHeight
+    ) internal returns (bytes32 orderId) {
         // Validate inputs
         require(sourceAmount >= MIN_EXECUTION_AMOUNT, "FlowFusion: Amount too small");
         require(supportedChains[targetChain], "FlowFusion: Unsupported target chain");
@@ -192,7 +261,7 @@ contract FlowFusionBridge is
         require(bytes(targetRecipient).length > 0, "FlowFusion: Invalid recipient");
         require(htlcHash != bytes32(0), "FlowFusion: Invalid HTLC hash");
         require(timeoutHeight > block.number + 100, "FlowFusion: Timeout too soon"); // At least 100 blocks
-        require(userOrderCount[msg.sender] < maxOrdersPerUser, "FlowFusion: Too many orders");
+        require(userOrderCount[user] < maxOrdersPerUser, "FlowFusion: Too many orders");
         
         // Validate TWAP configuration
         _validateTWAPConfig(twapConfig);
@@ -203,7 +272,7 @@ contract FlowFusionBridge is
         
         // Generate order ID
         orderId = keccak256(abi.encodePacked(
-            msg.sender,
+            user,
             sourceToken,
             sourceAmount,
             targetChain,
@@ -220,7 +289,7 @@ contract FlowFusionBridge is
                 emit ProtocolFeeCollected(orderId, protocolFee, feeCollector);
             }
         } else {
-            IERC20(sourceToken).safeTransferFrom(msg.sender, address(this), sourceAmount);
+            _pullTokens(sourceToken, user, sourceAmount);
             // Send protocol fee to fee collector
             if (protocolFee > 0) {
                 IERC20(sourceToken).safeTransfer(feeCollector, protocolFee);
@@ -231,7 +300,7 @@ contract FlowFusionBridge is
         // Create order
         orders[orderId] = TWAPOrder({
             id: orderId,
-            user: msg.sender,
+            user: user,
             sourceToken: sourceToken,
             sourceAmount: netAmount, // Store net amount after fee
             targetChain: targetChain,
@@ -248,14 +317,14 @@ contract FlowFusionBridge is
         });
         
         // Update mappings
-        userOrders[msg.sender].push(orderId);
-        userOrderCount[msg.sender]++;
+        userOrders[user].push(orderId);
+        userOrderCount[user]++;
         totalOrders++;
         totalVolume += sourceAmount;
         
         emit OrderCreated(
             orderId,
-            msg.sender,
+            user,
             targetChain,
             sourceToken,
             sourceAmount,
@@ -272,13 +341,15 @@ contract FlowFusionBridge is
      * @notice Execute a TWAP interval for an order
      * @param orderId Order to execute
      * @param intervalAmount Amount to execute in this interval
-     * @param executionPrice Price for this execution
+     * @param executionPrice Target token base units per source token base unit, 18-decimal fixed point
+     * @param minOutput Least target output the interval may deliver, in target token base units
      * @param priceProof Proof of price validity (for oracle verification)
      */
     function executeTWAPInterval(
         bytes32 orderId,
         uint256 intervalAmount,
         uint256 executionPrice,
+        uint256 minOutput,
         bytes calldata priceProof
     ) external onlyAuthorizedExecutor orderExists(orderId) orderActive(orderId) nonReentrant {
         TWAPOrder storage order = orders[orderId];
@@ -298,6 +369,12 @@ contract FlowFusionBridge is
         
         // Verify price (implement oracle price verification)
         require(_verifyPrice(executionPrice, priceProof), "FlowFusion: Invalid price");
+
+        // Enforce the interval's share of the order's minimum received
+        require(
+            (intervalAmount * executionPrice) / PRICE_PRECISION >= minOutput,
+            "FlowFusion: Output below minimum"
+        );
         
         // Calculate and validate slippage
         uint256 twapPrice = _calculateTWAP(orderId);
@@ -362,6 +439,56 @@ contract FlowFusionBridge is
         onlyOrderOwner(orderId)
         nonReentrant 
     {
+        _cancelOrder(orderId);
+    }
+
+    /**
+     * @notice Cancel an order on behalf of its owner, refunding the
+     *         unexecuted remainder to the owner
+     * @param orderId Order to cancel
+     */
+    function cancelOrderFor(bytes32 orderId)
+        external
+        orderExists(orderId)
+        onlyAuthorizedExecutor
+        nonReentrant
+    {
+        _cancelOrder(orderId);
+    }
+
+    /**
+     * @notice Amend a live order on behalf of its owner. Reducing the
+     *         source amount refunds the difference to the owner.
+     * @param orderId Order to amend
+     * @param sourceAmount New source amount, no more than the current one
+     * @param twapConfig New TWAP configuration
+     */
+    function amendOrderFor(
+        bytes32 orderId,
+        uint256 sourceAmount,
+        TWAPConfig memory twapConfig
+    ) external orderExists(orderId) orderActive(orderId) onlyAuthorizedExecutor nonReentrant {
+        TWAPOrder storage order = orders[orderId];
+        require(sourceAmount > order.executedAmount, "FlowFusion: Below executed amount");
+        require(sourceAmount <= order.sourceAmount, "FlowFusion: Cannot increase amount");
+        _validateTWAPConfig(twapConfig);
+
+        uint256 refundAmount = order.sourceAmount - sourceAmount;
+        order.sourceAmount = sourceAmount;
+        order.twapConfig = twapConfig;
+
+        if (refundAmount > 0) {
+            if (order.sourceToken == address(0)) {
+                payable(order.user).transfer(refundAmount);
+            } else {
+                IERC20(order.sourceToken).safeTransfer(order.user, refundAmount);
+            }
+        }
+
+        emit OrderAmended(orderId, sourceAmount, refundAmount, twapConfig, block.timestamp);
+    }
+
+    function _cancelOrder(bytes32 orderId) internal {
         TWAPOrder storage order = orders[orderId];
         require(order.status == OrderStatus.Executing, "FlowFusion: Order not cancellable");
         
@@ -372,15 +499,15 @@ contract FlowFusionBridge is
             
             // Refund tokens
             if (order.sourceToken == address(0)) {
-                payable(msg.sender).transfer(refundAmount);
+                payable(order.user).transfer(refundAmount);
             } else {
-                IERC20(order.sourceToken).safeTransfer(msg.sender, refundAmount);
+                IERC20(order.sourceToken).safeTransfer(order.user, refundAmount);
             }
             
             // Decrease user order count
-            userOrderCount[msg.sender]--;
+            userOrderCount[order.user]--;
             
-            emit OrderCancelled(orderId, msg.sender, refundAmount, block.timestamp);
+            emit OrderCancelled(orderId, order.user, refundAmount, block.timestamp);
         }
     }
 
@@ -467,6 +594,19 @@ contract FlowFusionBridge is
         require(config.minFillSize > 0, "FlowFusion: Invalid min fill size");
     }
     
+    /**
+     * @dev Pulls tokens with a direct allowance when one covers the amount,
+     *      otherwise through the user's Permit2 allowance
+     */
+    function _pullTokens(address token, address from, uint256 amount) internal {
+        if (IERC20(token).allowance(from, address(this)) >= amount) {
+            IERC20(token).safeTransferFrom(from, address(this), amount);
+            return;
+        }
+        require(amount <= type(uint160).max, "FlowFusion: Amount too large for Permit2");
+        IPermit2(PERMIT2).transferFrom(from, address(this), uint160(amount), token);
+    }
+    
     function _getIntervalDuration(TWAPConfig memory config) internal pure returns (uint256) {
         return (config.windowMinutes * 60) / config.executionIntervals;
     }
@@ -87,6 +87,13 @@ interface IFlowFusionBridge {
         uint256 refundAmount
     );
 
+    event OrderAmended(
+        bytes32 indexed orderId,
+        uint256 sourceAmount,
+        uint256 refundAmount,
+        TWAPConfig twapConfig
+    );
+
     event HTLCCreated(
         bytes32 indexed orderId,
         bytes32 indexed htlcHash,
@@ -119,12 +126,29 @@ interface IFlowFusionBridge {
     ) external payable returns (bytes32 orderId);
 
     /**
-     * @notice Execute a TWAP interval for an order
+     * @notice Create a TWAP order on behalf of a user who approved the bridge
+     */
+    function createTWAPOrderFor(
+        address user,
+        address sourceToken,
+        uint256 sourceAmount,
+        string memory targetChain,
+        string memory targetToken,
+        string memory targetRecipient,
+        TWAPConfig memory twapConfig,
+        bytes32 htlcHash,
+        uint256 timeoutHeight
+    ) external returns (bytes32 orderId);
+
+    /**
+     * @notice Execute a TWAP interval for an order, reverting if it would
+     *         deliver less than minOutput of the target token
      */
     function executeTWAPInterval(
         bytes32 orderId,
         uint256 intervalAmount,
         uint256 executionPrice,
+        uint256 minOutput,
         bytes calldata priceProof
     ) external;
 
@@ -133,6 +157,21 @@ interface IFlowFusionBridge {
      */
     function cancelOrder(bytes32 orderId) external;
 
+    /**
+     * @notice Cancel an order on behalf of its owner, refunding the owner
+     */
+    function cancelOrderFor(bytes32 orderId) external;
+
+    /**
+     * @notice Amend a live order on behalf of its owner, refunding any
+     *         reduction of the source amount
+     */
+    function amendOrderFor(
+        bytes32 orderId,
+        uint256 sourceAmount,
+        TWAPConfig memory twapConfig
+    ) external;
+
     /**
      * @notice Claim HTLC with secret to complete cross-chain swap
      */
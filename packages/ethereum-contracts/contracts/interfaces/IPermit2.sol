@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.24;
+
+/**
+ * @title IPermit2
+ * @notice The subset of Uniswap's Permit2 AllowanceTransfer used by the bridge
+ */
+interface IPermit2 {
+    /**
+     * @notice Transfer tokens using an allowance granted to msg.sender
+     */
+    function transferFrom(address from, address to, uint160 amount, address token) external;
+}
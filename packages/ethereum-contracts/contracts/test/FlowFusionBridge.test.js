@@ -1,6 +1,7 @@
 const { expect } = require("chai");
 const { ethers } = require("hardhat");
 const { loadFixture } = require("@nomicfoundation/hardhat-network-helpers");
+const { anyValue } = require("@nomicfoundation/hardhat-chai-matchers/withArgs");
 
 describe("FlowFusionBridge", function () {
   // Fixture to deploy the contract and setup initial state
@@ -24,6 +25,36 @@ describe("FlowFusionBridge", function () {
     return { bridge, token, owner, user, executor, feeCollector };
   }
 
+  // Has the executor create an ERC20 order for the user, returning its id
+  async function createOrderFor(bridge, token, user, executor, sourceAmount) {
+    await token.connect(user).approve(bridge.target, sourceAmount);
+
+    const twapConfig = {
+      windowMinutes: 60,
+      executionIntervals: 4,
+      maxSlippage: 100,
+      minFillSize: ethers.parseEther("1"),
+      enableMEVProtection: false
+    };
+    const htlcHash = ethers.keccak256(ethers.toUtf8Bytes("secret-for"));
+    const timeoutHeight = (await ethers.provider.getBlockNumber()) + 1000;
+
+    const tx = await bridge.connect(executor).createTWAPOrderFor(
+      user.address,
+      token.target,
+      sourceAmount,
+      "cosmos",
+      "uatom",
+      "cosmos1recipient",
+      twapConfig,
+      htlcHash,
+      timeoutHeight
+    );
+    const receipt = await tx.wait();
+    const event = receipt.logs.find(log => log.fragment?.name === "OrderCreated");
+    return { orderId: event.args[0], twapConfig };
+  }
+
   describe("Deployment", function () {
     it("Should deploy with correct initial settings", async function () {
       const { bridge, feeCollector } = await loadFixture(deployBridgeFixture);
@@ -144,6 +175,47 @@ describe("FlowFusionBridge", function () {
         )
       ).to.be.revertedWith("Window too small");
     });
+
+    it("Should let an executor create an order funded by an approving user", async function () {
+      const { bridge, token, user, executor } = await loadFixture(deployBridgeFixture);
+
+      const sourceAmount = ethers.parseEther("100");
+      await token.connect(user).approve(bridge.target, sourceAmount);
+
+      const twapConfig = {
+        windowMinutes: 30,
+        executionIntervals: 3,
+        maxSlippage: 50,
+        minFillSize: ethers.parseEther("10"),
+        enableMEVProtection: false
+      };
+      const htlcHash = ethers.keccak256(ethers.toUtf8Bytes("secret789"));
+      const timeoutHeight = (await ethers.provider.getBlockNumber()) + 500;
+      const args = [
+        user.address,
+        token.target,
+        sourceAmount,
+        "stellar",
+        "USDC",
+        "GABC123RECIPIENT",
+        twapConfig,
+        htlcHash,
+        timeoutHeight
+      ];
+
+      await expect(
+        bridge.connect(user).createTWAPOrderFor(...args)
+      ).to.be.revertedWith("FlowFusion: Not authorized executor");
+
+      const balanceBefore = await token.balanceOf(user.address);
+      const tx = await bridge.connect(executor).createTWAPOrderFor(...args);
+      const receipt = await tx.wait();
+      const event = receipt.logs.find(log => log.fragment?.name === "OrderCreated");
+      const order = await bridge.getOrder(event.args[0]);
+
+      expect(order.user).to.equal(user.address);
+      expect(await token.balanceOf(user.address)).to.equal(balanceBefore - sourceAmount);
+    });
   });
 
   describe("TWAP Execution", function () {
@@ -181,6 +253,7 @@ describe("FlowFusionBridge", function () {
       // Execute first interval
       const intervalAmount = ethers.parseEther("0.166"); // ~1/6 of total
       const executionPrice = ethers.parseEther("2000"); // $2000 per ETH
+      const minOutput = ethers.parseEther("332"); // exactly 0.166 * 2000
       const priceProof = "0x1234"; // Mock proof
 
       await expect(
@@ -188,6 +261,7 @@ describe("FlowFusionBridge", function () {
           orderId,
           intervalAmount,
           executionPrice,
+          minOutput,
           priceProof
         )
       ).to.emit(bridge, "TWAPExecution");
@@ -204,6 +278,59 @@ describe("FlowFusionBridge", function () {
       expect(history[0].price).to.equal(executionPrice);
     });
 
+    it("Should revert when the interval output is below its minimum", async function () {
+      const { bridge, user, executor } = await loadFixture(deployBridgeFixture);
+
+      const sourceAmount = ethers.parseEther("1");
+      const twapConfig = {
+        windowMinutes: 60,
+        executionIntervals: 6,
+        maxSlippage: 100,
+        minFillSize: ethers.parseEther("0.1"),
+        enableMEVProtection: true
+      };
+      const htlcHash = ethers.keccak256(ethers.toUtf8Bytes("secret123"));
+      const timeoutHeight = (await ethers.provider.getBlockNumber()) + 1000;
+
+      const tx = await bridge.connect(user).createTWAPOrder(
+        ethers.ZeroAddress,
+        sourceAmount,
+        "cosmos",
+        "uatom",
+        "cosmos1recipient",
+        twapConfig,
+        htlcHash,
+        timeoutHeight,
+        { value: sourceAmount }
+      );
+      const receipt = await tx.wait();
+      const orderId = receipt.logs.find(log => log.fragment?.name === "OrderCreated").args[0];
+
+      // 0.2 at 2000 delivers 400, one wei short of the floor
+      await expect(
+        bridge.connect(executor).executeTWAPInterval(
+          orderId,
+          ethers.parseEther("0.2"),
+          ethers.parseEther("2000"),
+          ethers.parseEther("400") + 1n,
+          "0x1234"
+        )
+      ).to.be.revertedWith("FlowFusion: Output below minimum");
+
+      const order = await bridge.getOrder(orderId);
+      expect(order.executedAmount).to.equal(0);
+
+      await expect(
+        bridge.connect(executor).executeTWAPInterval(
+          orderId,
+          ethers.parseEther("0.2"),
+          ethers.parseEther("2000"),
+          ethers.parseEther("400"),
+          "0x1234"
+        )
+      ).to.emit(bridge, "TWAPExecution");
+    });
+
     it("Should revert if not authorized executor", async function () {
       const { bridge, user } = await loadFixture(deployBridgeFixture);
 
@@ -213,6 +340,7 @@ describe("FlowFusionBridge", function () {
           ethers.keccak256(ethers.toUtf8Bytes("fake")),
           ethers.parseEther("0.1"),
           ethers.parseEther("2000"),
+          0,
           "0x1234"
         )
       ).to.be.revertedWith("Not authorized executor");
@@ -267,6 +395,91 @@ describe("FlowFusionBridge", function () {
       expect(order.status).to.equal(2); // OrderStatus.Cancelled
     });
 
+    it("Should let an executor cancel an order, refunding its owner", async function () {
+      const { bridge, token, user, executor } = await loadFixture(deployBridgeFixture);
+
+      const sourceAmount = ethers.parseEther("100");
+      const { orderId } = await createOrderFor(bridge, token, user, executor, sourceAmount);
+      // The bridge keeps the order net of the 0.25% protocol fee
+      const netAmount = sourceAmount - (sourceAmount * 25n) / 10000n;
+
+      await expect(
+        bridge.connect(user).cancelOrderFor(orderId)
+      ).to.be.revertedWith("FlowFusion: Not authorized executor");
+
+      const userBalance = await token.balanceOf(user.address);
+      const executorBalance = await token.balanceOf(executor.address);
+
+      await expect(bridge.connect(executor).cancelOrderFor(orderId))
+        .to.emit(bridge, "OrderCancelled(bytes32,address,uint256,uint256)")
+        .withArgs(orderId, user.address, netAmount, anyValue);
+
+      // The owner is refunded, not the executor who cancelled
+      expect(await token.balanceOf(user.address)).to.equal(userBalance + netAmount);
+      expect(await token.balanceOf(executor.address)).to.equal(executorBalance);
+      expect((await bridge.getOrder(orderId)).status).to.equal(2); // OrderStatus.Cancelled
+
+      await expect(
+        bridge.connect(executor).cancelOrderFor(orderId)
+      ).to.be.revertedWith("FlowFusion: Order not cancellable");
+    });
+
+    it("Should let an executor reduce an order, refunding its owner", async function () {
+      const { bridge, token, user, executor } = await loadFixture(deployBridgeFixture);
+
+      const sourceAmount = ethers.parseEther("100");
+      const { orderId, twapConfig } = await createOrderFor(bridge, token, user, executor, sourceAmount);
+      const netAmount = sourceAmount - (sourceAmount * 25n) / 10000n;
+      const reduced = ethers.parseEther("50");
+
+      await expect(
+        bridge.connect(user).amendOrderFor(orderId, reduced, twapConfig)
+      ).to.be.revertedWith("FlowFusion: Not authorized executor");
+
+      const userBalance = await token.balanceOf(user.address);
+      const executorBalance = await token.balanceOf(executor.address);
+
+      await expect(bridge.connect(executor).amendOrderFor(orderId, reduced, twapConfig))
+        .to.emit(bridge, "OrderAmended(bytes32,uint256,uint256,(uint256,uint256,uint256,uint256,bool),uint256)")
+        .withArgs(orderId, reduced, netAmount - reduced, anyValue, anyValue);
+
+      expect(await token.balanceOf(user.address)).to.equal(userBalance + netAmount - reduced);
+      expect(await token.balanceOf(executor.address)).to.equal(executorBalance);
+      expect((await bridge.getOrder(orderId)).sourceAmount).to.equal(reduced);
+    });
+
+    it("Should reject amendments that increase an order or leave nothing to execute", async function () {
+      const { bridge, token, user, executor } = await loadFixture(deployBridgeFixture);
+
+      const sourceAmount = ethers.parseEther("100");
+      const { orderId, twapConfig } = await createOrderFor(bridge, token, user, executor, sourceAmount);
+      const netAmount = sourceAmount - (sourceAmount * 25n) / 10000n;
+
+      const executed = ethers.parseEther("10");
+      await bridge.connect(executor).executeTWAPInterval(
+        orderId,
+        executed,
+        ethers.parseEther("2000"),
+        0,
+        "0x1234"
+      );
+
+      await expect(
+        bridge.connect(executor).amendOrderFor(orderId, netAmount + 1n, twapConfig)
+      ).to.be.revertedWith("FlowFusion: Cannot increase amount");
+
+      await expect(
+        bridge.connect(executor).amendOrderFor(orderId, executed, twapConfig)
+      ).to.be.revertedWith("FlowFusion: Below executed amount");
+
+      await expect(
+        bridge.connect(executor).amendOrderFor(orderId, executed - 1n, twapConfig)
+      ).to.be.revertedWith("FlowFusion: Below executed amount");
+
+      // Rejected amendments leave the order as it was
+      expect((await bridge.getOrder(orderId)).sourceAmount).to.equal(netAmount);
+    });
+
     it("Should get user orders correctly", async function () {
       const { bridge, user } = await loadFixture(deployBridgeFixture);
 
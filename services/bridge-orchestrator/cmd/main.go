@@ -18,6 +18,7 @@ import (
 	"flowfusion/bridge-orchestrator/internal/config"
 	"flowfusion/bridge-orchestrator/internal/database"
 	"flowfusion/bridge-orchestrator/pkg/adapters"
+	"flowfusion/bridge-orchestrator/pkg/stats"
 	"flowfusion/bridge-orchestrator/pkg/twap"
 	"flowfusion/bridge-orchestrator/pkg/orchestrator"
 )
@@ -73,7 +74,7 @@ func main() {
 		zap.Int("adapter_count", adapterManager.GetAdapterCount()))
 
 	// Initialize TWAP engine
-	twapEngine, err := twap.NewEngine(config.Config{}, db, adapterManager, logger)
+	twapEngine, err := twap.NewEngine(*cfg, db, adapterManager, logger)
 	if err != nil {
 		logger.Fatal("Failed to initialize TWAP engine", zap.Error(err))
 	}
@@ -88,6 +89,9 @@ func main() {
 
 	logger.Info("Bridge orchestrator initialized")
 
+	// Initialize stats service
+	statsService := stats.NewService(cfg.Stats, db, logger)
+
 	// Start background services
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -108,8 +112,15 @@ func main() {
 		}
 	}()
 
+	// Start stats service
+	go func() {
+		if err := statsService.Start(ctx); err != nil {
+			logger.Error("Stats service failed", zap.Error(err))
+		}
+	}()
+
 	// Initialize HTTP server
-	server := setupHTTPServer(cfg, orch, twapEngine, db, logger)
+	server := setupHTTPServer(cfg, orch, twapEngine, statsService, db, logger)
 
 	// Start HTTP server
 	go func() {
@@ -152,6 +163,7 @@ func setupHTTPServer(
 	cfg *config.Config,
 	orch *orchestrator.Orchestrator,
 	twapEngine *twap.Engine,
+	statsService *stats.Service,
 	db database.DB,
 	logger *zap.Logger,
 ) *http.Server {
@@ -169,7 +181,7 @@ func setupHTTPServer(
 	router.Use(api.CORSMiddleware())
 
 	// Setup routes
-	api.SetupRoutes(router, orch, twapEngine, db, logger)
+	api.SetupRoutes(router, orch, twapEngine, statsService, db, logger)
 
 	// Create server
 	return &http.Server{
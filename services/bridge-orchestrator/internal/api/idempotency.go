@@ -0,0 +1,176 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client's key for a retryable request
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed from an earlier
+	// request with the same key
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// IdempotencyTTL is how long a key's response is kept for replay
+	IdempotencyTTL = 24 * time.Hour
+)
+
+var idempotencyKeyPattern = regexp.MustCompile(`^[\x21-\x7e]{1,255}$`)
+
+// responseRecorder keeps a copy of the response body as it is written
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotency makes a request sent with an Idempotency-Key header safe to
+// retry. The first request with a key runs and its response is stored for
+// IdempotencyTTL; a retry with the same body replays that response, and
+// reusing the key for a different request is a conflict. Server errors are
+// not stored, so a retry after one runs the request again.
+func (h *Handler) idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if !idempotencyKeyPattern.MatchString(key) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:     "Invalid idempotency key, expected 1 to 255 printable characters",
+				Code:      ErrCodeValidation,
+				Timestamp: time.Now(),
+			})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:     "Failed to read request body",
+				Code:      ErrCodeValidation,
+				Timestamp: time.Now(),
+			})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := time.Now()
+		record := &database.IdempotencyKey{
+			Key:         key,
+			UserAddress: h.getUserAddress(c),
+			RequestPath: c.Request.Method + " " + c.Request.URL.Path,
+			Fingerprint: requestFingerprint(c.Request.Method, c.Request.URL.Path, body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(IdempotencyTTL),
+		}
+
+		existing, err := h.db.ReserveIdempotencyKey(record)
+		if err != nil {
+			h.logger.Error("Failed to reserve idempotency key",
+				zap.Error(err),
+				zap.String("request_id", h.getRequestID(c)))
+
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:     "Failed to check idempotency key",
+				Code:      ErrCodeInternalError,
+				Timestamp: time.Now(),
+			})
+			c.Abort()
+			return
+		}
+
+		if existing != nil {
+			h.respondIdempotent(c, record, existing)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		record.StatusCode = recorder.Status()
+		if record.StatusCode >= http.StatusInternalServerError {
+			err = h.db.ReleaseIdempotencyKey(record.UserAddress, record.Key)
+		} else {
+			record.ResponseBody = recorder.body.Bytes()
+			err = h.db.CompleteIdempotencyKey(record)
+		}
+		if err != nil {
+			h.logger.Error("Failed to store idempotent response",
+				zap.Error(err),
+				zap.Int("status", record.StatusCode),
+				zap.String("request_id", h.getRequestID(c)))
+		}
+	}
+}
+
+// respondIdempotent answers a request whose key an earlier request holds:
+// the stored response if it was the same request, otherwise a conflict
+func (h *Handler) respondIdempotent(c *gin.Context, record, existing *database.IdempotencyKey) {
+	switch {
+	case existing.Fingerprint != record.Fingerprint:
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error: "Idempotency key was already used for a different request",
+			Code:  ErrCodeConflict,
+			Details: map[string]interface{}{
+				"idempotency_key": existing.Key,
+				"request_path":    existing.RequestPath,
+				"first_used_at":   existing.CreatedAt.UTC(),
+				"expires_at":      existing.ExpiresAt.UTC(),
+			},
+			Timestamp: time.Now(),
+		})
+	case !existing.Completed():
+		c.Header("Retry-After", "1")
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:     "A request with this idempotency key is still in progress, retry shortly",
+			Code:      ErrCodeConflict,
+			Details:   map[string]interface{}{"idempotency_key": existing.Key},
+			Timestamp: time.Now(),
+		})
+	default:
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Data(existing.StatusCode, "application/json; charset=utf-8", existing.ResponseBody)
+	}
+}
+
+// requestFingerprint hashes what makes two requests the same: the method,
+// the path and the body, ignoring insignificant JSON whitespace
+func requestFingerprint(method, path string, body []byte) string {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, body); err != nil {
+		compact.Reset()
+		compact.Write(body)
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(compact.Bytes())
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -80,8 +80,8 @@ func CORSMiddleware() gin.HandlerFunc {
 		}
 
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, X-User-Address")
-		c.Header("Access-Control-Expose-Headers", "X-Request-ID, X-Rate-Limit-Remaining")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, X-User-Address, Idempotency-Key")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID, X-Rate-Limit-Remaining, Idempotent-Replayed")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		c.Header("Access-Control-Max-Age", "86400") // 24 hours
 
@@ -2,22 +2,33 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 
 	"flowfusion/bridge-orchestrator/internal/database"
+	"flowfusion/bridge-orchestrator/pkg/adapters"
 	"flowfusion/bridge-orchestrator/pkg/orchestrator"
+	"flowfusion/bridge-orchestrator/pkg/recurring"
+	"flowfusion/bridge-orchestrator/pkg/stats"
 	"flowfusion/bridge-orchestrator/pkg/twap"
 )
 
 const (
 	// API timeouts
 	DefaultTimeout = 30 * time.Second
+	// SettleTimeout bounds waiting for an in-flight interval to settle
+	// when cancelling or amending an order
+	SettleTimeout = 2 * time.Minute
 	
 	// Rate limiting
 	// DefaultRateLimit = 100
@@ -29,12 +40,26 @@ const (
 	
 	// Cache TTL
 	CacheTTL = 5 * time.Minute
+
+	// DefaultProgressDrift is how many percentage points a synced basket's
+	// orders may drift apart when the batch sets no limit
+	DefaultProgressDrift = 10
+
+	// Aggregate TCA reports cover the orders created in the lookback,
+	// newest first, up to a limit
+	DefaultTCALookback = 30 * 24 * time.Hour
+	DefaultTCAOrders   = 100
+	MaxTCAOrders       = 500
+
+	// Statistics cover the lookback unless a request sets since
+	DefaultStatsLookback = 30 * 24 * time.Hour
 )
 
 // Handler holds dependencies for API handlers
 type Handler struct {
 	orchestrator *orchestrator.Orchestrator
 	twapEngine   *twap.Engine
+	stats        *stats.Service
 	db           database.DB
 	logger       *zap.Logger
 	
@@ -55,12 +80,14 @@ func SetupRoutes(
 	router *gin.Engine,
 	orch *orchestrator.Orchestrator,
 	twapEngine *twap.Engine,
+	statsService *stats.Service,
 	db database.DB,
 	logger *zap.Logger,
 ) {
 	h := &Handler{
 		orchestrator: orch,
 		twapEngine:   twapEngine,
+		stats:        statsService,
 		db:           db,
 		logger:       logger,
 		cache:        make(map[string]interface{}),
@@ -79,7 +106,10 @@ func SetupRoutes(
 	v1.Use(h.rateLimitMiddleware())
 	{
 		h.setupOrderRoutes(v1)
+		h.setupBasketRoutes(v1)
+		h.setupRecurringRoutes(v1)
 		h.setupTWAPRoutes(v1)
+		h.setupTCARoutes(v1)
 		h.setupChainRoutes(v1)
 		h.setupPriceRoutes(v1)
 		h.setupStatsRoutes(v1)
@@ -131,8 +161,8 @@ func (h *Handler) setupMiddleware(router *gin.Engine) {
 			c.Header("Access-Control-Allow-Origin", origin)
 		}
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
-		c.Header("Access-Control-Expose-Headers", "X-Request-ID, X-Rate-Limit-Remaining")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, Idempotency-Key")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID, X-Rate-Limit-Remaining, Idempotent-Replayed")
 		c.Header("Access-Control-Max-Age", "86400")
 
 		if c.Request.Method == "OPTIONS" {
@@ -167,12 +197,46 @@ func (h *Handler) setupHealthRoutes(router *gin.Engine) {
 func (h *Handler) setupOrderRoutes(v1 *gin.RouterGroup) {
 	orders := v1.Group("/orders")
 	{
-		orders.POST("", h.validateCreateOrder(), h.createOrder)
+		orders.POST("", h.idempotency(), h.validateCreateOrder(), h.createOrder)
+		orders.POST("/permit", h.createPermit)
+		orders.POST("/quote", h.validateCreateOrder(), h.quoteOrder)
+		orders.POST("/batch", h.idempotency(), h.createOrderBatch)
 		orders.GET("/:id", h.validateOrderID(), h.getOrder)
+		orders.PATCH("/:id", h.validateOrderID(), h.amendOrder)
 		orders.PUT("/:id/cancel", h.validateOrderID(), h.cancelOrder)
+		orders.POST("/:id/pause", h.validateOrderID(), h.pauseOrder)
+		orders.POST("/:id/resume", h.validateOrderID(), h.resumeOrder)
 		orders.GET("", h.validateListOrders(), h.listOrders)
 		orders.GET("/:id/history", h.validateOrderID(), h.getOrderHistory)
 		orders.GET("/:id/status", h.validateOrderID(), h.getOrderStatus)
+		orders.GET("/:id/events", h.validateOrderID(), h.getOrderEvents)
+		orders.GET("/:id/amendments", h.validateOrderID(), h.getOrderAmendments)
+		orders.GET("/:id/tca", h.validateOrderID(), h.getOrderTCA)
+	}
+}
+
+// setupBasketRoutes configures basket endpoints; baskets are created by
+// POST /orders/batch
+func (h *Handler) setupBasketRoutes(v1 *gin.RouterGroup) {
+	baskets := v1.Group("/baskets")
+	{
+		baskets.GET("/:id", h.validateOrderID(), h.getBasket)
+		baskets.GET("/:id/orders", h.validateOrderID(), h.listBasketOrders)
+		baskets.PUT("/:id/cancel", h.validateOrderID(), h.cancelBasket)
+	}
+}
+
+// setupRecurringRoutes configures recurring order endpoints
+func (h *Handler) setupRecurringRoutes(v1 *gin.RouterGroup) {
+	recurringOrders := v1.Group("/recurring-orders")
+	{
+		recurringOrders.POST("", h.idempotency(), h.createRecurringOrder)
+		recurringOrders.GET("", h.listRecurringOrders)
+		recurringOrders.GET("/:id", h.validateOrderID(), h.getRecurringOrder)
+		recurringOrders.GET("/:id/orders", h.validateOrderID(), h.getRecurringRuns)
+		recurringOrders.POST("/:id/pause", h.validateOrderID(), h.pauseRecurringOrder)
+		recurringOrders.POST("/:id/resume", h.validateOrderID(), h.resumeRecurringOrder)
+		recurringOrders.POST("/:id/cancel", h.validateOrderID(), h.cancelRecurringOrder)
 	}
 }
 
@@ -184,6 +248,16 @@ func (h *Handler) setupTWAPRoutes(v1 *gin.RouterGroup) {
 		twapRoutes.GET("/current/:pair", h.validateTokenPair(), h.getCurrentPrice)
 		twapRoutes.POST("/execute/:id", h.validateOrderID(), h.executeOrder)
 		twapRoutes.GET("/metrics", h.getTWAPMetrics)
+		twapRoutes.GET("/circuit-breakers", h.getCircuitBreakers)
+	}
+}
+
+// setupTCARoutes configures aggregate transaction cost analysis endpoints
+func (h *Handler) setupTCARoutes(v1 *gin.RouterGroup) {
+	tca := v1.Group("/tca")
+	{
+		tca.GET("/users/:address", h.getUserTCA)
+		tca.GET("/pairs/:source_token/:target_token", h.getPairTCA)
 	}
 }
 
@@ -226,7 +300,9 @@ func (h *Handler) setupAdminRoutes(admin *gin.RouterGroup) {
 	admin.GET("/health", h.adminHealthCheck)
 	admin.POST("/maintenance", h.toggleMaintenanceMode)
 	admin.GET("/metrics/detailed", h.getDetailedMetrics)
+	admin.GET("/orders", h.validateListOrders(), h.searchAllOrders)
 	admin.POST("/cache/clear", h.clearCache)
+	admin.POST("/circuit-breakers/:pair/reset", h.validateTokenPair(), h.resetCircuitBreaker)
 }
 
 // Health Check Handlers
@@ -336,7 +412,7 @@ func (h *Handler) createOrder(c *gin.Context) {
 	defer cancel()
 
 	var req CreateOrderRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
 		h.logger.Error("Invalid create order request", 
 			zap.Error(err),
 			zap.String("request_id", h.getRequestID(c)))
@@ -372,36 +448,30 @@ func (h *Handler) createOrder(c *gin.Context) {
 		return
 	}
 
-	// Convert to database order with proper timestamps
-	now := time.Now()
-	order := &database.Order{
-		ID:                  req.ID,
-		UserAddress:         req.UserAddress,
-		SourceChain:         req.SourceChain,
-		TargetChain:         req.TargetChain,
-		SourceToken:         req.SourceToken,
-		SourceAmount:        req.SourceAmount,
-		TargetToken:         req.TargetToken,
-		TargetRecipient:     req.TargetRecipient,
-		MinReceived:         req.MinReceived,
-		WindowMinutes:       req.TWAPConfig.WindowMinutes,
-		ExecutionIntervals:  req.TWAPConfig.ExecutionIntervals,
-		MaxSlippage:         req.TWAPConfig.MaxSlippage,
-		MinFillSize:         req.TWAPConfig.MinFillSize,
-		EnableMEVProtection: req.TWAPConfig.EnableMEVProtection,
-		HTLCHash:            req.HTLCHash,
-		TimeoutHeight:       req.TimeoutHeight,
-		TimeoutTimestamp:    req.TimeoutTimestamp,
-		CreatedAt:           now,
-		UpdatedAt:           now,
-		Status:              string(database.OrderStatusPending),
-		ExecutedAmount:      decimal.Zero,
-		AveragePrice:        decimal.Zero,
-		Metadata:            database.Metadata(req.Metadata),
+	// Make sure the bridge will be able to pull the source tokens
+	if status, errResp := h.checkSourceApproval(&req); errResp != nil {
+		c.JSON(status, errResp)
+		return
+	}
+
+	order, status, errResp := h.newOrder(&req, time.Now())
+	if errResp != nil {
+		c.JSON(status, errResp)
+		return
 	}
 
 	// Create order in database with context
 	if err := h.db.CreateOrder(order); err != nil {
+		if errors.Is(err, database.ErrDuplicateOrder) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:     "Order ID already exists",
+				Code:      ErrCodeConflict,
+				Details:   map[string]interface{}{"order_id": order.ID},
+				Timestamp: time.Now(),
+			})
+			return
+		}
+
 		h.logger.Error("Failed to create order", 
 			zap.Error(err),
 			zap.String("order_id", order.ID),
@@ -416,6 +486,15 @@ func (h *Handler) createOrder(c *gin.Context) {
 		return
 	}
 
+	if req.Permit != nil {
+		if err := h.orchestrator.SubmitPermitOrder(order, req.Permit); err != nil {
+			h.logger.Error("Failed to submit permit-funded order",
+				zap.Error(err),
+				zap.String("order_id", order.ID),
+				zap.String("request_id", h.getRequestID(c)))
+		}
+	}
+
 	h.logger.Info("Order created successfully", 
 		zap.String("order_id", order.ID),
 		zap.String("user_address", order.UserAddress),
@@ -434,6 +513,110 @@ func (h *Handler) createOrder(c *gin.Context) {
 	})
 }
 
+// createPermit returns EIP-712 typed data approving the bridge to pull an
+// order's source tokens. The signed permit is passed back in createOrder.
+func (h *Handler) createPermit(c *gin.Context) {
+	var req PermitTypedDataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "Invalid request format",
+			Code:      ErrCodeValidation,
+			Details:   map[string]interface{}{"validation_error": err.Error()},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	if err := h.validatePermitTypedDataRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "Validation failed",
+			Code:      ErrCodeValidation,
+			Details:   map[string]interface{}{"validation_error": err.Error()},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	approvals, ok := h.tokenApprovals(req.Chain)
+	if !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "Chain does not support token permits",
+			Code:      ErrCodeInvalidChain,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	typed, err := approvals.BuildPermit(adapters.PermitRequest{
+		Kind:     req.Kind,
+		Token:    req.Token,
+		Owner:    req.Owner,
+		Amount:   req.Amount,
+		Deadline: req.Deadline,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, adapters.ErrPermitNotSupported):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:     "Token does not support EIP-2612 permits",
+				Code:      ErrCodeInvalidToken,
+				Details:   map[string]interface{}{"suggested_kind": adapters.PermitKindPermit2},
+				Timestamp: time.Now(),
+			})
+		case errors.Is(err, adapters.ErrInvalidPermit):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:     "Validation failed",
+				Code:      ErrCodeValidation,
+				Details:   map[string]interface{}{"validation_error": err.Error()},
+				Timestamp: time.Now(),
+			})
+		default:
+			h.logger.Error("Failed to build permit",
+				zap.Error(err),
+				zap.String("chain", req.Chain),
+				zap.String("token", req.Token),
+				zap.String("request_id", h.getRequestID(c)))
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+				Error:     "Failed to read permit state from chain",
+				Code:      ErrCodeChainError,
+				Timestamp: time.Now(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success:   true,
+		Data:      typed,
+		Timestamp: time.Now(),
+	})
+}
+
+// quoteOrder previews what the engine would do with an order without
+// creating it. Problems that would not stop the order being created, like
+// a missing allowance, are returned as warnings.
+func (h *Handler) quoteOrder(c *gin.Context) {
+	req := c.MustGet("validated_request").(CreateOrderRequest)
+
+	now := time.Now()
+	order, status, errResp := h.newOrder(&req, now)
+	if errResp != nil {
+		c.JSON(status, errResp)
+		return
+	}
+
+	quote := h.twapEngine.QuoteOrder(order, req.Permit != nil, now)
+	if _, errResp := h.checkSourceApproval(&req); errResp != nil {
+		quote.Warnings = append(quote.Warnings, strings.ToLower(errResp.Error))
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success:   true,
+		Data:      quote,
+		Timestamp: time.Now(),
+	})
+}
+
 func (h *Handler) getOrder(c *gin.Context) {
 	_, cancel := context.WithTimeout(c.Request.Context(), DefaultTimeout)
 	defer cancel()
@@ -516,6 +699,12 @@ func (h *Handler) getOrder(c *gin.Context) {
 			MaxSlippage:         order.MaxSlippage,
 			MinFillSize:         order.MinFillSize,
 			EnableMEVProtection: order.EnableMEVProtection,
+			StartAt:             order.StartAt,
+			TriggerPrice:        order.TriggerPrice,
+			TriggerCondition:    order.TriggerCondition,
+			TriggeredAt:         order.TriggeredAt,
+			LimitPrice:          order.LimitPrice,
+			SkippedIntervals:    order.SkippedIntervals,
 		},
 		HTLCHash:         order.HTLCHash,
 		TimeoutHeight:    order.TimeoutHeight,
@@ -527,8 +716,15 @@ func (h *Handler) getOrder(c *gin.Context) {
 		Status:           order.Status,
 		AveragePrice:     order.AveragePrice,
 		CompletionRate:   order.CalculateCompletionRate(),
+		GasSpent: GasSpentResponse{
+			NativeToken: adapters.GetNativeTokenSymbol(order.TargetChain),
+			Native:      order.GasSpentNative,
+			Quote:       order.GasSpentQuote,
+		},
 		ExecutionHistory: h.convertExecutionHistory(history),
 		Metadata:         map[string]interface{}(order.Metadata),
+		BasketID:         order.BasketID,
+		ChainOrderID:     order.ChainOrderID,
 	}
 
 	// Cache the response
@@ -537,13 +733,22 @@ func (h *Handler) getOrder(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-func (h *Handler) cancelOrder(c *gin.Context) {
-	_, cancel := context.WithTimeout(c.Request.Context(), DefaultTimeout)
-	defer cancel()
-
+// amendOrder changes a live order's amount, window, intervals or slippage
+func (h *Handler) amendOrder(c *gin.Context) {
 	orderID := c.Param("id")
 	userAddress := h.getUserAddress(c)
 
+	var req AmendOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "Invalid request format",
+			Code:      ErrCodeValidation,
+			Details:   map[string]interface{}{"validation_error": err.Error()},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
 	order, err := h.db.GetOrder(orderID)
 	if err != nil {
 		if err == database.ErrOrderNotFound {
@@ -562,144 +767,165 @@ func (h *Handler) cancelOrder(c *gin.Context) {
 		return
 	}
 
-	// Verify ownership
 	if !h.canModifyOrder(userAddress, order.UserAddress) {
 		c.JSON(http.StatusForbidden, ErrorResponse{
-			Error:     "Unauthorized to cancel this order",
+			Error:     "Unauthorized to amend this order",
 			Code:      ErrCodeForbidden,
 			Timestamp: time.Now(),
 		})
 		return
 	}
 
-	// Check if order can be cancelled
-	if !h.canCancelOrder(order.Status) {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Order cannot be cancelled in current status",
-			Code:    ErrCodeConflict,
-			Details: map[string]interface{}{"current_status": order.Status},
-			Timestamp: time.Now(),
-		})
-		return
-	}
-
-	// Update order status
-	order.Status = string(database.OrderStatusCancelled)
-	order.UpdatedAt = time.Now()
+	// Amending on-chain waits for the transaction to be mined
+	ctx, cancel := context.WithTimeout(c.Request.Context(), SettleTimeout)
+	defer cancel()
 
-	if err := h.db.UpdateOrder(order); err != nil {
-		h.logger.Error("Failed to cancel order", 
-			zap.Error(err),
-			zap.String("order_id", orderID),
-			zap.String("user_address", userAddress))
-		
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:     "Failed to cancel order",
-			Code:      ErrCodeInternalError,
-			Timestamp: time.Now(),
-		})
+	result, err := h.twapEngine.AmendOrder(ctx, orderID, twap.Amendment{
+		SourceAmount:       req.SourceAmount,
+		WindowMinutes:      req.WindowMinutes,
+		ExecutionIntervals: req.ExecutionIntervals,
+		MaxSlippage:        req.MaxSlippage,
+		Actor:              database.ActorUser,
+		Reason:             req.Reason,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, twap.ErrInvalidAmendment):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:     "Validation failed",
+				Code:      ErrCodeValidation,
+				Details:   map[string]interface{}{"validation_error": err.Error()},
+				Timestamp: time.Now(),
+			})
+		case errors.Is(err, twap.ErrOrderNotAmendable):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:     "Order cannot be amended in current status",
+				Code:      ErrCodeConflict,
+				Details:   map[string]interface{}{"current_status": order.Status},
+				Timestamp: time.Now(),
+			})
+		case errors.Is(err, twap.ErrOrderBusy), errors.Is(err, database.ErrStatusConflict):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:     "Order changed while amending, retry",
+				Code:      ErrCodeConflict,
+				Timestamp: time.Now(),
+			})
+		default:
+			h.logger.Error("Failed to amend order",
+				zap.Error(err),
+				zap.String("order_id", orderID),
+				zap.String("user_address", userAddress))
+
+			c.JSON(http.StatusBadGateway, ErrorResponse{
+				Error:     "Failed to amend order on-chain",
+				Code:      ErrCodeChainError,
+				Timestamp: time.Now(),
+			})
+		}
 		return
 	}
 
-	// Clear cache
 	h.clearOrderCache(orderID)
 
-	h.logger.Info("Order cancelled", 
-		zap.String("order_id", orderID),
-		zap.String("user_address", userAddress))
-
 	c.JSON(http.StatusOK, SuccessResponse{
-		Success: true,
-		Data: map[string]interface{}{
-			"order_id":     orderID,
-			"status":       order.Status,
-			"cancelled_at": order.UpdatedAt.UTC(),
-		},
+		Success:   true,
+		Data:      result,
+		Message:   "Order amended",
 		Timestamp: time.Now(),
 	})
 }
 
-func (h *Handler) listOrders(c *gin.Context) {
-	_, cancel := context.WithTimeout(c.Request.Context(), DefaultTimeout)
-	defer cancel()
+// pauseOrder stops a live order's intervals without losing its schedule
+func (h *Handler) pauseOrder(c *gin.Context) {
+	orderID := c.Param("id")
+	userAddress := h.getUserAddress(c)
 
-	// Parse and validate query parameters
-	params := h.parseListOrdersParams(c)
-	if err := h.validateListOrdersParams(params); err != nil {
+	var req PauseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:     "Invalid query parameters",
+			Error:     "Invalid request format",
 			Code:      ErrCodeValidation,
 			Details:   map[string]interface{}{"validation_error": err.Error()},
 			Timestamp: time.Now(),
 		})
 		return
 	}
+	if req.Reason == "" {
+		req.Reason = "paused by user"
+	}
+
+	order, ok := h.getModifiableOrder(c, orderID, userAddress, "pause")
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), SettleTimeout)
+	defer cancel()
+
+	paused, err := h.twapEngine.PauseOrder(ctx, orderID, req.Reason)
+	if err != nil {
+		h.respondPauseError(c, err, order, orderID, "pause")
+		return
+	}
+
+	h.clearOrderCache(orderID)
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success:   true,
+		Data:      paused,
+		Message:   "Order paused",
+		Timestamp: time.Now(),
+	})
+}
 
+// resumeOrder restarts an order the user paused, re-planning its remaining
+// intervals over the remaining window
+func (h *Handler) resumeOrder(c *gin.Context) {
+	orderID := c.Param("id")
 	userAddress := h.getUserAddress(c)
-	if userAddress == "" {
+
+	var req ResumeOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:     "User address is required",
+			Error:     "Invalid request format",
 			Code:      ErrCodeValidation,
+			Details:   map[string]interface{}{"validation_error": err.Error()},
 			Timestamp: time.Now(),
 		})
 		return
 	}
+	mode := database.ResumeExtend
+	if req.Mode != "" {
+		mode = database.ResumeMode(req.Mode)
+	}
 
-	orders, err := h.db.GetOrdersByUser(userAddress, params.Limit, params.Offset)
-	if err != nil {
-		h.logger.Error("Failed to get orders", 
-			zap.Error(err),
-			zap.String("user_address", userAddress))
-		
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:     "Failed to retrieve orders",
-			Code:      ErrCodeInternalError,
-			Timestamp: time.Now(),
-		})
+	order, ok := h.getModifiableOrder(c, orderID, userAddress, "resume")
+	if !ok {
 		return
 	}
 
-	// Convert to response format
-	orderResponses := make([]OrderSummaryResponse, 0, len(orders))
-	for _, order := range orders {
-		// Get execution count
-		history, _ := h.db.GetExecutionHistory(order.ID)
-		
-		orderResponses = append(orderResponses, OrderSummaryResponse{
-			ID:                order.ID,
-			SourceChain:       order.SourceChain,
-			TargetChain:       order.TargetChain,
-			SourceAmount:      order.SourceAmount,
-			ExecutedAmount:    order.ExecutedAmount,
-			Status:            order.Status,
-			CreatedAt:         order.CreatedAt,
-			CompletionRate:    order.CalculateCompletionRate(),
-			AveragePrice:      order.AveragePrice,
-			IntervalsExecuted: len(history),
-			TotalIntervals:    order.ExecutionIntervals,
-		})
+	resumed, schedule, err := h.twapEngine.ResumeOrder(orderID, mode)
+	if err != nil {
+		h.respondPauseError(c, err, order, orderID, "resume")
+		return
 	}
 
-	pagination := NewPaginationResponse(params.Page, params.Limit, int64(len(orderResponses)))
+	h.clearOrderCache(orderID)
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"orders":     orderResponses,
-			"pagination": pagination,
+			"order":    resumed,
+			"schedule": schedule,
 		},
+		Message:   "Order resumed",
 		Timestamp: time.Now(),
 	})
 }
 
-func (h *Handler) getOrderHistory(c *gin.Context) {
-	_, cancel := context.WithTimeout(c.Request.Context(), DefaultTimeout)
-	defer cancel()
-
-	orderID := c.Param("id")
-	userAddress := h.getUserAddress(c)
-
-	// Verify user can access this order
+// getModifiableOrder loads an order the caller may modify, writing the
+// error response and returning false otherwise
+func (h *Handler) getModifiableOrder(c *gin.Context, orderID, userAddress, action string) (*database.Order, bool) {
 	order, err := h.db.GetOrder(orderID)
 	if err != nil {
 		if err == database.ErrOrderNotFound {
@@ -708,50 +934,324 @@ func (h *Handler) getOrderHistory(c *gin.Context) {
 				Code:      ErrCodeNotFound,
 				Timestamp: time.Now(),
 			})
-			return
+			return nil, false
 		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:     "Failed to retrieve order",
 			Code:      ErrCodeInternalError,
 			Timestamp: time.Now(),
 		})
-		return
+		return nil, false
 	}
 
-	if !h.canAccessOrder(userAddress, order.UserAddress) {
+	if !h.canModifyOrder(userAddress, order.UserAddress) {
 		c.JSON(http.StatusForbidden, ErrorResponse{
-			Error:     "Access denied",
+			Error:     fmt.Sprintf("Unauthorized to %s this order", action),
 			Code:      ErrCodeForbidden,
 			Timestamp: time.Now(),
 		})
-		return
+		return nil, false
 	}
+	return order, true
+}
 
-	history, err := h.db.GetExecutionHistory(orderID)
-	if err != nil {
-		h.logger.Error("Failed to get execution history", 
+// respondPauseError maps a pause or resume failure to its response
+func (h *Handler) respondPauseError(c *gin.Context, err error, order *database.Order, orderID, action string) {
+	switch {
+	case errors.Is(err, twap.ErrOrderNotPausable), errors.Is(err, twap.ErrOrderNotResumable):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:     fmt.Sprintf("Order cannot %s in current status", action),
+			Code:      ErrCodeConflict,
+			Details:   map[string]interface{}{"current_status": order.Status, "validation_error": err.Error()},
+			Timestamp: time.Now(),
+		})
+	case errors.Is(err, twap.ErrOrderBusy), errors.Is(err, database.ErrStatusConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:     fmt.Sprintf("Order changed during %s, retry", action),
+			Code:      ErrCodeConflict,
+			Timestamp: time.Now(),
+		})
+	default:
+		h.logger.Error("Failed to "+action+" order",
 			zap.Error(err),
 			zap.String("order_id", orderID))
-		
+
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:     "Failed to retrieve execution history",
+			Error:     fmt.Sprintf("Failed to %s order", action),
 			Code:      ErrCodeInternalError,
 			Timestamp: time.Now(),
 		})
-		return
 	}
+}
 
-	c.JSON(http.StatusOK, SuccessResponse{
-		Success: true,
-		Data: map[string]interface{}{
-			"order_id": orderID,
+func (h *Handler) cancelOrder(c *gin.Context) {
+	_, cancel := context.WithTimeout(c.Request.Context(), DefaultTimeout)
+	defer cancel()
+
+	orderID := c.Param("id")
+	userAddress := h.getUserAddress(c)
+
+	order, err := h.db.GetOrder(orderID)
+	if err != nil {
+		if err == database.ErrOrderNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:     "Order not found",
+				Code:      ErrCodeNotFound,
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve order",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	// Verify ownership
+	if !h.canModifyOrder(userAddress, order.UserAddress) {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:     "Unauthorized to cancel this order",
+			Code:      ErrCodeForbidden,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	// Check if order can be cancelled
+	if !h.canCancelOrder(order.Status) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Order cannot be cancelled in current status",
+			Code:    ErrCodeConflict,
+			Details: map[string]interface{}{"current_status": order.Status},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	// Cancelling on-chain waits for the transaction to be mined
+	ctx, cancelWorkflow := context.WithTimeout(c.Request.Context(), SettleTimeout)
+	defer cancelWorkflow()
+
+	outcome, err := h.orchestrator.CancelOrder(ctx, orderID, "cancelled by owner")
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrInvalidTransition):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:     "Order cannot be cancelled in current status",
+				Code:      ErrCodeConflict,
+				Details:   map[string]interface{}{"current_status": order.Status},
+				Timestamp: time.Now(),
+			})
+		case errors.Is(err, database.ErrStatusConflict):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:     "Order status changed while cancelling, retry",
+				Code:      ErrCodeConflict,
+				Timestamp: time.Now(),
+			})
+		default:
+			h.logger.Error("Failed to cancel order", 
+				zap.Error(err),
+				zap.String("order_id", orderID),
+				zap.String("user_address", userAddress))
+
+			// The order no longer executes; cancelling again resumes
+			c.JSON(http.StatusBadGateway, ErrorResponse{
+				Error:     "Failed to cancel order on-chain, retry to resume",
+				Code:      ErrCodeChainError,
+				Details:   map[string]interface{}{"status": string(database.OrderStatusCancelling)},
+				Timestamp: time.Now(),
+			})
+		}
+		h.clearOrderCache(orderID)
+		return
+	}
+
+	// Clear cache
+	h.clearOrderCache(orderID)
+
+	h.logger.Info("Order cancelled", 
+		zap.String("order_id", orderID),
+		zap.String("user_address", userAddress),
+		zap.String("refund_tx_hash", outcome.RefundTxHash))
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"order_id":         orderID,
+			"status":           outcome.Status,
+			"cancelled_at":     time.Now().UTC(),
+			"executed_amount":  outcome.ExecutedAmount.String(),
+			"refund_amount":    outcome.RefundAmount.String(),
+			"refund_recipient": outcome.RefundRecipient,
+			"refund_tx_hash":   outcome.RefundTxHash,
+			"htlc_refunds":     outcome.HTLCRefunds,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// listOrders searches the caller's orders
+func (h *Handler) listOrders(c *gin.Context) {
+	params := c.MustGet("validated_params").(*ListOrdersParams)
+
+	userAddress := h.getUserAddress(c)
+	if userAddress == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "User address is required",
+			Code:      ErrCodeValidation,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+	if params.UserAddress != "" && !h.canAccessOrder(userAddress, params.UserAddress) {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:     "Access denied",
+			Code:      ErrCodeForbidden,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+	if params.UserAddress == "" {
+		params.UserAddress = userAddress
+	}
+
+	h.respondOrderSearch(c, params)
+}
+
+// searchAllOrders searches the orders of every user
+func (h *Handler) searchAllOrders(c *gin.Context) {
+	h.respondOrderSearch(c, c.MustGet("validated_params").(*ListOrdersParams))
+}
+
+// respondOrderSearch responds with the page of orders matching params
+func (h *Handler) respondOrderSearch(c *gin.Context, params *ListOrdersParams) {
+	_, cancel := context.WithTimeout(c.Request.Context(), DefaultTimeout)
+	defer cancel()
+
+	filter, _ := params.orderFilter()
+	page, err := h.db.SearchOrders(filter)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:     "Invalid query parameters",
+				Code:      ErrCodeValidation,
+				Details:   map[string]interface{}{"validation_error": err.Error()},
+				Timestamp: time.Now(),
+			})
+			return
+		}
+
+		h.logger.Error("Failed to search orders",
+			zap.Error(err),
+			zap.String("user_address", params.UserAddress),
+			zap.String("request_id", h.getRequestID(c)))
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve orders",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	orderResponses := make([]OrderSummaryResponse, 0, len(page.Orders))
+	for _, order := range page.Orders {
+		orderResponses = append(orderResponses, OrderSummaryResponse{
+			ID:                order.ID,
+			UserAddress:       order.UserAddress,
+			SourceChain:       order.SourceChain,
+			TargetChain:       order.TargetChain,
+			SourceToken:       order.SourceToken,
+			TargetToken:       order.TargetToken,
+			SourceAmount:      order.SourceAmount,
+			ExecutedAmount:    order.ExecutedAmount,
+			Status:            order.Status,
+			CreatedAt:         order.CreatedAt,
+			UpdatedAt:         order.UpdatedAt,
+			CompletionRate:    order.CalculateCompletionRate(),
+			AveragePrice:      order.AveragePrice,
+			IntervalsExecuted: page.IntervalsExecuted[order.ID],
+			TotalIntervals:    order.ExecutionIntervals,
+		})
+	}
+
+	pagination := NewPaginationResponse(params.Page, params.Limit, page.Total)
+	pagination.HasNext = page.NextCursor != ""
+	pagination.NextCursor = page.NextCursor
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"orders":     orderResponses,
+			"pagination": pagination,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+func (h *Handler) getOrderHistory(c *gin.Context) {
+	_, cancel := context.WithTimeout(c.Request.Context(), DefaultTimeout)
+	defer cancel()
+
+	orderID := c.Param("id")
+	userAddress := h.getUserAddress(c)
+
+	// Verify user can access this order
+	order, err := h.db.GetOrder(orderID)
+	if err != nil {
+		if err == database.ErrOrderNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:     "Order not found",
+				Code:      ErrCodeNotFound,
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve order",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	if !h.canAccessOrder(userAddress, order.UserAddress) {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:     "Access denied",
+			Code:      ErrCodeForbidden,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	history, err := h.db.GetExecutionHistory(orderID)
+	if err != nil {
+		h.logger.Error("Failed to get execution history", 
+			zap.Error(err),
+			zap.String("order_id", orderID))
+		
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve execution history",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"order_id": orderID,
 			"history":  h.convertExecutionHistory(history),
 		},
 		Timestamp: time.Now(),
 	})
 }
 
-func (h *Handler) getOrderStatus(c *gin.Context) {
+// getOrderEvents returns the order's status transitions, oldest first
+func (h *Handler) getOrderEvents(c *gin.Context) {
 	_, cancel := context.WithTimeout(c.Request.Context(), DefaultTimeout)
 	defer cancel()
 
@@ -785,28 +1285,981 @@ func (h *Handler) getOrderStatus(c *gin.Context) {
 		return
 	}
 
-	// Calculate progress
-	history, _ := h.db.GetExecutionHistory(orderID)
-	
+	events, err := h.db.GetOrderEvents(orderID)
+	if err != nil {
+		h.logger.Error("Failed to get order events",
+			zap.Error(err),
+			zap.String("order_id", orderID))
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve order events",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+	if events == nil {
+		events = []*database.OrderEvent{}
+	}
+
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"order_id":           orderID,
-			"status":             order.Status,
-			"completion_rate":    order.CalculateCompletionRate(),
-			"executed_amount":    order.ExecutedAmount,
-			"remaining_amount":   order.GetRemainingAmount(),
-			"intervals_executed": len(history),
-			"total_intervals":    order.ExecutionIntervals,
-			"average_price":      order.AveragePrice,
-			"last_execution":     order.LastExecution,
-			"can_execute":        order.CanExecuteInterval(),
-			"next_execution":     order.GetNextExecutionTime(),
+			"order_id": orderID,
+			"status":   order.Status,
+			"events":   events,
 		},
 		Timestamp: time.Now(),
 	})
 }
 
+// getOrderAmendments returns the order's amendment audit trail, oldest first
+func (h *Handler) getOrderAmendments(c *gin.Context) {
+	_, cancel := context.WithTimeout(c.Request.Context(), DefaultTimeout)
+	defer cancel()
+
+	orderID := c.Param("id")
+	userAddress := h.getUserAddress(c)
+
+	order, err := h.db.GetOrder(orderID)
+	if err != nil {
+		if err == database.ErrOrderNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:     "Order not found",
+				Code:      ErrCodeNotFound,
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve order",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	if !h.canAccessOrder(userAddress, order.UserAddress) {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:     "Access denied",
+			Code:      ErrCodeForbidden,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	amendments, err := h.db.GetOrderAmendments(orderID)
+	if err != nil {
+		h.logger.Error("Failed to get order amendments",
+			zap.Error(err),
+			zap.String("order_id", orderID))
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve order amendments",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+	if amendments == nil {
+		amendments = []*database.OrderAmendment{}
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"order_id":   orderID,
+			"amendments": amendments,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// getOrderTCA returns the order's transaction cost analysis
+func (h *Handler) getOrderTCA(c *gin.Context) {
+	_, cancel := context.WithTimeout(c.Request.Context(), DefaultTimeout)
+	defer cancel()
+
+	orderID := c.Param("id")
+	userAddress := h.getUserAddress(c)
+
+	order, err := h.db.GetOrder(orderID)
+	if err != nil {
+		if err == database.ErrOrderNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:     "Order not found",
+				Code:      ErrCodeNotFound,
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve order",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	if !h.canAccessOrder(userAddress, order.UserAddress) {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:     "Access denied",
+			Code:      ErrCodeForbidden,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	tca, err := h.twapEngine.OrderTCA(order)
+	if err != nil {
+		h.logger.Error("Failed to analyze order execution",
+			zap.Error(err),
+			zap.String("order_id", orderID),
+			zap.String("request_id", h.getRequestID(c)))
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to analyze order execution",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success:   true,
+		Data:      tca,
+		Timestamp: time.Now(),
+	})
+}
+
+// getUserTCA aggregates the TCA of a user's recent orders
+func (h *Handler) getUserTCA(c *gin.Context) {
+	address := c.Param("address")
+	if !h.canAccessOrder(h.getUserAddress(c), address) {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:     "Access denied",
+			Code:      ErrCodeForbidden,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	h.respondTCASummary(c, database.OrderFilter{UserAddress: &address},
+		map[string]interface{}{"user_address": address})
+}
+
+// getPairTCA aggregates the TCA of recent orders in a token pair across
+// all users
+func (h *Handler) getPairTCA(c *gin.Context) {
+	sourceToken, targetToken := c.Param("source_token"), c.Param("target_token")
+	if len(sourceToken) > 42 || len(targetToken) > 50 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "Invalid token pair format",
+			Code:      ErrCodeValidation,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	h.respondTCASummary(c, database.OrderFilter{SourceToken: &sourceToken, TargetToken: &targetToken},
+		map[string]interface{}{"source_token": sourceToken, "target_token": targetToken})
+}
+
+// respondTCASummary analyzes the orders matching filter that were created
+// since the since query parameter, newest first and up to limit, and
+// responds with their summary
+func (h *Handler) respondTCASummary(c *gin.Context, filter database.OrderFilter, scope map[string]interface{}) {
+	_, cancel := context.WithTimeout(c.Request.Context(), DefaultTimeout)
+	defer cancel()
+
+	since := time.Now().Add(-DefaultTCALookback)
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:     "Invalid query parameters",
+				Code:      ErrCodeValidation,
+				Details:   map[string]interface{}{"validation_error": "since must be an RFC 3339 time"},
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		since = parsed
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(DefaultTCAOrders)))
+	if err != nil || limit <= 0 || limit > MaxTCAOrders {
+		limit = DefaultTCAOrders
+	}
+
+	filter.CreatedAfter = &since
+	filter.Limit = limit
+	page, err := h.db.SearchOrders(filter)
+	if err != nil {
+		h.logger.Error("Failed to get orders for TCA", zap.Error(err), zap.Any("scope", scope))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve orders",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	reports := make([]*twap.OrderTCA, 0, len(page.Orders))
+	for _, order := range page.Orders {
+		tca, err := h.twapEngine.OrderTCA(order)
+		if err != nil {
+			h.logger.Error("Failed to analyze order execution",
+				zap.Error(err),
+				zap.String("order_id", order.ID))
+
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:     "Failed to analyze order execution",
+				Code:      ErrCodeInternalError,
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		reports = append(reports, tca)
+	}
+
+	data := map[string]interface{}{
+		"since":   since.UTC(),
+		"summary": twap.SummarizeTCA(reports),
+	}
+	for key, value := range scope {
+		data[key] = value
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success:   true,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+}
+
+func (h *Handler) getOrderStatus(c *gin.Context) {
+	_, cancel := context.WithTimeout(c.Request.Context(), DefaultTimeout)
+	defer cancel()
+
+	orderID := c.Param("id")
+	userAddress := h.getUserAddress(c)
+
+	order, err := h.db.GetOrder(orderID)
+	if err != nil {
+		if err == database.ErrOrderNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:     "Order not found",
+				Code:      ErrCodeNotFound,
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve order",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	if !h.canAccessOrder(userAddress, order.UserAddress) {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:     "Access denied",
+			Code:      ErrCodeForbidden,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	// Calculate progress
+	history, _ := h.db.GetExecutionHistory(orderID)
+	
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"order_id":           orderID,
+			"status":             order.Status,
+			"completion_rate":    order.CalculateCompletionRate(),
+			"executed_amount":    order.ExecutedAmount,
+			"remaining_amount":   order.GetRemainingAmount(),
+			"intervals_executed": len(history),
+			"intervals_skipped":  order.SkippedIntervals,
+			"total_intervals":    order.ExecutionIntervals,
+			"average_price":      order.AveragePrice,
+			"last_execution":     order.LastExecution,
+			"activated":          order.IsActivated(time.Now()),
+			"can_execute":        order.IsActivated(time.Now()) && order.CanExecuteInterval(),
+			"next_execution":     order.GetNextExecutionTime(),
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// createOrderBatch creates a basket of orders, all or none of them
+func (h *Handler) createOrderBatch(c *gin.Context) {
+	var req BatchCreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "Invalid request format",
+			Code:      ErrCodeValidation,
+			Details:   map[string]interface{}{"validation_error": err.Error()},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	if errs := h.validateBatchCreateOrderRequest(&req); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "Validation failed, no orders were created",
+			Code:      ErrCodeValidation,
+			Details:   map[string]interface{}{"validation_errors": errs},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	userAddress := h.getUserAddress(c)
+	if !h.checkRateLimit(userAddress) {
+		c.JSON(http.StatusTooManyRequests, ErrorResponse{
+			Error:     "Rate limit exceeded",
+			Code:      ErrCodeRateLimit,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	// Make sure the bridge will be able to pull every order's source tokens
+	if status, errResp := h.checkBatchApproval(req.Orders); errResp != nil {
+		c.JSON(status, errResp)
+		return
+	}
+
+	now := time.Now()
+	basket := &database.Basket{
+		ID:               req.BasketID,
+		UserAddress:      req.Orders[0].UserAddress,
+		SyncProgress:     req.SyncProgress,
+		MaxProgressDrift: decimal.NewFromInt(DefaultProgressDrift),
+		Metadata:         database.Metadata(req.Metadata),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if req.MaxProgressDrift != nil {
+		basket.MaxProgressDrift = *req.MaxProgressDrift
+	}
+	if basket.ID == "" {
+		id, err := newID("bsk_")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:     "Failed to generate basket ID",
+				Code:      ErrCodeInternalError,
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		basket.ID = id
+	}
+
+	orders := make([]*database.Order, 0, len(req.Orders))
+	for i := range req.Orders {
+		order, status, errResp := h.newOrder(&req.Orders[i], now)
+		if errResp != nil {
+			c.JSON(status, withBatchOrders(errResp, []int{i}))
+			return
+		}
+		order.BasketID = &basket.ID
+		orders = append(orders, order)
+	}
+
+	if err := h.db.CreateBasket(basket, orders); err != nil {
+		switch {
+		case errors.Is(err, database.ErrDuplicateBasket):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:     "Basket ID already exists",
+				Code:      ErrCodeConflict,
+				Details:   map[string]interface{}{"basket_id": basket.ID},
+				Timestamp: time.Now(),
+			})
+		case errors.Is(err, database.ErrDuplicateOrder):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:     "Order ID already exists, no orders were created",
+				Code:      ErrCodeConflict,
+				Details:   map[string]interface{}{"validation_error": err.Error()},
+				Timestamp: time.Now(),
+			})
+		default:
+			h.logger.Error("Failed to create basket",
+				zap.Error(err),
+				zap.String("basket_id", basket.ID),
+				zap.String("request_id", h.getRequestID(c)))
+
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:     "Failed to create orders",
+				Code:      ErrCodeInternalError,
+				Timestamp: time.Now(),
+			})
+		}
+		return
+	}
+
+	created := make([]map[string]interface{}, 0, len(orders))
+	for i, order := range orders {
+		if permit := req.Orders[i].Permit; permit != nil {
+			if err := h.orchestrator.SubmitPermitOrder(order, permit); err != nil {
+				h.logger.Error("Failed to submit permit-funded order",
+					zap.Error(err),
+					zap.String("order_id", order.ID),
+					zap.String("request_id", h.getRequestID(c)))
+			}
+		}
+		created = append(created, map[string]interface{}{
+			"order_id": order.ID,
+			"status":   order.Status,
+		})
+	}
+
+	h.logger.Info("Basket created",
+		zap.String("basket_id", basket.ID),
+		zap.String("user_address", basket.UserAddress),
+		zap.Int("orders", len(orders)),
+		zap.String("request_id", h.getRequestID(c)))
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"basket_id":  basket.ID,
+			"orders":     created,
+			"created_at": now.UTC(),
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// getBasket returns a basket with its aggregate progress
+func (h *Handler) getBasket(c *gin.Context) {
+	basket, orders, ok := h.getBasketOrders(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"basket":   basket,
+			"progress": orchestrator.NewBasketProgress(orders),
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// listBasketOrders returns the orders in a basket
+func (h *Handler) listBasketOrders(c *gin.Context) {
+	basket, orders, ok := h.getBasketOrders(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"basket_id": basket.ID,
+			"orders":    orders,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// getBasketOrders loads a basket the caller may access and its orders,
+// writing the error response and returning false otherwise
+func (h *Handler) getBasketOrders(c *gin.Context) (*database.Basket, []*database.Order, bool) {
+	id := c.Param("id")
+
+	basket, ok := h.getAccessibleBasket(c, id, h.canAccessOrder)
+	if !ok {
+		return nil, nil, false
+	}
+
+	orders, err := h.db.GetOrdersByBasket(id)
+	if err != nil {
+		h.logger.Error("Failed to get basket orders",
+			zap.Error(err),
+			zap.String("basket_id", id))
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve basket orders",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return nil, nil, false
+	}
+	if orders == nil {
+		orders = []*database.Order{}
+	}
+	return basket, orders, true
+}
+
+// cancelBasket cancels and refunds every live order in a basket
+func (h *Handler) cancelBasket(c *gin.Context) {
+	id := c.Param("id")
+	if _, ok := h.getAccessibleBasket(c, id, h.canModifyOrder); !ok {
+		return
+	}
+
+	// Cancelling orders on-chain waits for their transactions to be mined
+	ctx, cancel := context.WithTimeout(c.Request.Context(), SettleTimeout)
+	defer cancel()
+
+	outcome, err := h.orchestrator.CancelBasket(ctx, id)
+	if err != nil {
+		h.logger.Error("Failed to cancel basket",
+			zap.Error(err),
+			zap.String("basket_id", id))
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to cancel basket",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	for _, order := range outcome.Orders {
+		h.clearOrderCache(order.OrderID)
+	}
+	for orderID := range outcome.OrderErrors {
+		h.clearOrderCache(orderID)
+	}
+
+	message := "Basket cancelled"
+	if len(outcome.OrderErrors) > 0 {
+		message = "Basket partly cancelled, retry to cancel the remaining orders"
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success:   true,
+		Data:      outcome,
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
+
+// getAccessibleBasket loads a basket the caller passes allowed for, writing
+// the error response and returning false otherwise
+func (h *Handler) getAccessibleBasket(c *gin.Context, id string, allowed func(userAddress, ownerAddress string) bool) (*database.Basket, bool) {
+	basket, err := h.db.GetBasket(id)
+	if err != nil {
+		if errors.Is(err, database.ErrBasketNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:     "Basket not found",
+				Code:      ErrCodeNotFound,
+				Timestamp: time.Now(),
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve basket",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return nil, false
+	}
+
+	if !allowed(h.getUserAddress(c), basket.UserAddress) {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:     "Access denied",
+			Code:      ErrCodeForbidden,
+			Timestamp: time.Now(),
+		})
+		return nil, false
+	}
+	return basket, true
+}
+
+// Recurring order endpoints
+
+// createRecurringOrder stores a recurring order; the scheduler spawns its
+// runs as they fall due
+func (h *Handler) createRecurringOrder(c *gin.Context) {
+	var req CreateRecurringOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "Invalid request format",
+			Code:      ErrCodeValidation,
+			Details:   map[string]interface{}{"validation_error": err.Error()},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	if err := h.validateCreateRecurringOrderRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "Validation failed",
+			Code:      ErrCodeValidation,
+			Details:   map[string]interface{}{"validation_error": err.Error()},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	userAddress := h.getUserAddress(c)
+	if !h.checkRateLimit(userAddress) {
+		c.JSON(http.StatusTooManyRequests, ErrorResponse{
+			Error:     "Rate limit exceeded",
+			Code:      ErrCodeRateLimit,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	series := &database.RecurringOrder{
+		ID:                  req.ID,
+		UserAddress:         req.UserAddress,
+		SourceChain:         req.SourceChain,
+		TargetChain:         req.TargetChain,
+		SourceToken:         req.SourceToken,
+		TargetToken:         req.TargetToken,
+		TargetRecipient:     req.TargetRecipient,
+		Schedule:            req.Schedule,
+		AmountPerRun:        req.AmountPerRun,
+		WindowMinutes:       req.TWAPConfig.WindowMinutes,
+		ExecutionIntervals:  req.TWAPConfig.ExecutionIntervals,
+		MaxSlippage:         req.TWAPConfig.MaxSlippage,
+		MinFillSize:         req.TWAPConfig.MinFillSize,
+		EnableMEVProtection: req.TWAPConfig.EnableMEVProtection,
+		HTLCHash:            req.HTLCHash,
+		Budget:              req.Budget,
+		EndAt:               req.EndAt,
+		MaxRuns:             req.MaxRuns,
+		SpentAmount:         decimal.Zero,
+		Metadata:            database.Metadata(req.Metadata),
+	}
+
+	if err := h.orchestrator.CreateRecurringOrder(series); err != nil {
+		if errors.Is(err, database.ErrDuplicateRecurringOrder) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:     "Recurring order ID already exists",
+				Code:      ErrCodeConflict,
+				Details:   map[string]interface{}{"recurring_order_id": series.ID},
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		if errors.Is(err, recurring.ErrInvalidSchedule) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:     "Validation failed",
+				Code:      ErrCodeValidation,
+				Details:   map[string]interface{}{"validation_error": err.Error()},
+				Timestamp: time.Now(),
+			})
+			return
+		}
+
+		h.logger.Error("Failed to create recurring order",
+			zap.Error(err),
+			zap.String("recurring_order_id", series.ID),
+			zap.String("user_address", series.UserAddress),
+			zap.String("request_id", h.getRequestID(c)))
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to create recurring order",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	h.logger.Info("Recurring order created",
+		zap.String("recurring_order_id", series.ID),
+		zap.String("user_address", series.UserAddress),
+		zap.String("schedule", series.Schedule),
+		zap.String("request_id", h.getRequestID(c)))
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success:   true,
+		Data:      series,
+		Timestamp: time.Now(),
+	})
+}
+
+// listRecurringOrders returns the caller's recurring orders, newest first
+func (h *Handler) listRecurringOrders(c *gin.Context) {
+	params := h.parseListOrdersParams(c)
+	err := validatePagination(c)
+	if err == nil {
+		err = h.validateListOrdersParams(params)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "Invalid query parameters",
+			Code:      ErrCodeValidation,
+			Details:   map[string]interface{}{"validation_error": err.Error()},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	userAddress := h.getUserAddress(c)
+	if userAddress == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "User address is required",
+			Code:      ErrCodeValidation,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	series, total, err := h.db.GetRecurringOrdersByUser(userAddress, params.Limit, params.Offset)
+	if err != nil {
+		h.logger.Error("Failed to get recurring orders",
+			zap.Error(err),
+			zap.String("user_address", userAddress))
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve recurring orders",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+	if series == nil {
+		series = []*database.RecurringOrder{}
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"recurring_orders": series,
+			"pagination":       NewPaginationResponse(params.Page, params.Limit, total),
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// getRecurringOrder returns a recurring order with a report on its runs
+func (h *Handler) getRecurringOrder(c *gin.Context) {
+	series, runs, ok := h.getRecurringOrderRuns(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"recurring_order": series,
+			"report":          recurring.Summarize(series, runs),
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// getRecurringRuns returns the orders a recurring order has spawned, oldest
+// first
+func (h *Handler) getRecurringRuns(c *gin.Context) {
+	series, runs, ok := h.getRecurringOrderRuns(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"recurring_order_id": series.ID,
+			"orders":             runs,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// getRecurringOrderRuns loads a recurring order the caller may access and
+// its runs, writing the error response and returning false otherwise
+func (h *Handler) getRecurringOrderRuns(c *gin.Context) (*database.RecurringOrder, []*database.Order, bool) {
+	id := c.Param("id")
+
+	series, ok := h.getAccessibleRecurringOrder(c, id, h.canAccessOrder)
+	if !ok {
+		return nil, nil, false
+	}
+
+	runs, err := h.db.GetOrdersByParent(id)
+	if err != nil {
+		h.logger.Error("Failed to get recurring runs",
+			zap.Error(err),
+			zap.String("recurring_order_id", id))
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve recurring runs",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return nil, nil, false
+	}
+	if runs == nil {
+		runs = []*database.Order{}
+	}
+	return series, runs, true
+}
+
+// pauseRecurringOrder stops a recurring order spawning runs
+func (h *Handler) pauseRecurringOrder(c *gin.Context) {
+	id := c.Param("id")
+	if _, ok := h.getAccessibleRecurringOrder(c, id, h.canModifyOrder); !ok {
+		return
+	}
+
+	series, err := h.orchestrator.PauseRecurringOrder(id)
+	if err != nil {
+		h.respondRecurringError(c, err, id, "pause")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success:   true,
+		Data:      series,
+		Message:   "Recurring order paused",
+		Timestamp: time.Now(),
+	})
+}
+
+// resumeRecurringOrder restarts a paused recurring order at its next
+// scheduled run
+func (h *Handler) resumeRecurringOrder(c *gin.Context) {
+	id := c.Param("id")
+	if _, ok := h.getAccessibleRecurringOrder(c, id, h.canModifyOrder); !ok {
+		return
+	}
+
+	series, err := h.orchestrator.ResumeRecurringOrder(id)
+	if err != nil {
+		h.respondRecurringError(c, err, id, "resume")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success:   true,
+		Data:      series,
+		Message:   "Recurring order resumed",
+		Timestamp: time.Now(),
+	})
+}
+
+// cancelRecurringOrder ends a recurring order, optionally cancelling its
+// live runs
+func (h *Handler) cancelRecurringOrder(c *gin.Context) {
+	id := c.Param("id")
+
+	var req CancelRecurringOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "Invalid request format",
+			Code:      ErrCodeValidation,
+			Details:   map[string]interface{}{"validation_error": err.Error()},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	if _, ok := h.getAccessibleRecurringOrder(c, id, h.canModifyOrder); !ok {
+		return
+	}
+
+	// Cancelling runs on-chain waits for their transactions to be mined
+	ctx, cancel := context.WithTimeout(c.Request.Context(), SettleTimeout)
+	defer cancel()
+
+	outcome, err := h.orchestrator.CancelRecurringOrder(ctx, id, req.CancelRuns)
+	if err != nil {
+		h.respondRecurringError(c, err, id, "cancel")
+		return
+	}
+
+	for _, run := range outcome.Runs {
+		h.clearOrderCache(run.OrderID)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success:   true,
+		Data:      outcome,
+		Message:   "Recurring order cancelled",
+		Timestamp: time.Now(),
+	})
+}
+
+// getAccessibleRecurringOrder loads a recurring order the caller passes
+// allowed for, writing the error response and returning false otherwise
+func (h *Handler) getAccessibleRecurringOrder(c *gin.Context, id string, allowed func(userAddress, ownerAddress string) bool) (*database.RecurringOrder, bool) {
+	series, err := h.db.GetRecurringOrder(id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecurringOrderNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:     "Recurring order not found",
+				Code:      ErrCodeNotFound,
+				Timestamp: time.Now(),
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve recurring order",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return nil, false
+	}
+
+	if !allowed(h.getUserAddress(c), series.UserAddress) {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:     "Access denied",
+			Code:      ErrCodeForbidden,
+			Timestamp: time.Now(),
+		})
+		return nil, false
+	}
+	return series, true
+}
+
+// respondRecurringError maps a recurring order pause, resume or cancel
+// failure to its response
+func (h *Handler) respondRecurringError(c *gin.Context, err error, id, action string) {
+	switch {
+	case errors.Is(err, database.ErrRecurringOrderNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:     "Recurring order not found",
+			Code:      ErrCodeNotFound,
+			Timestamp: time.Now(),
+		})
+	case errors.Is(err, database.ErrInvalidTransition):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:     fmt.Sprintf("Recurring order cannot %s in current status", action),
+			Code:      ErrCodeConflict,
+			Details:   map[string]interface{}{"validation_error": err.Error()},
+			Timestamp: time.Now(),
+		})
+	case errors.Is(err, database.ErrStatusConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:     fmt.Sprintf("Recurring order changed during %s, retry", action),
+			Code:      ErrCodeConflict,
+			Timestamp: time.Now(),
+		})
+	case errors.Is(err, recurring.ErrInvalidSchedule):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "Invalid schedule",
+			Code:      ErrCodeValidation,
+			Details:   map[string]interface{}{"validation_error": err.Error()},
+			Timestamp: time.Now(),
+		})
+	default:
+		h.logger.Error("Failed to "+action+" recurring order",
+			zap.Error(err),
+			zap.String("recurring_order_id", id))
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     fmt.Sprintf("Failed to %s recurring order", action),
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+	}
+}
+
 // TWAP endpoints
 func (h *Handler) getTWAPPrice(c *gin.Context) {
 	_, cancel := context.WithTimeout(c.Request.Context(), DefaultTimeout)
@@ -828,23 +2281,17 @@ func (h *Handler) getTWAPPrice(c *gin.Context) {
 	// Check cache first
 	cacheKey := "twap:" + pair + ":" + windowStr
 	if cached := h.getFromCache(cacheKey); cached != nil {
-		if price, ok := cached.(decimal.Decimal); ok {
+		if data, ok := cached.(*database.TWAPData); ok {
 			c.JSON(http.StatusOK, SuccessResponse{
-				Success: true,
-				Data: map[string]interface{}{
-					"token_pair":     pair,
-					"window_minutes": window,
-					"twap_price":     price,
-					"calculated_at":  time.Now().UTC(),
-					"cached":         true,
-				},
+				Success:   true,
+				Data:      h.twapDataResponse(data, true),
 				Timestamp: time.Now(),
 			})
 			return
 		}
 	}
 
-	price, err := h.twapEngine.GetTWAPPrice(pair, window)
+	data, err := h.twapEngine.GetTWAPData(pair, window)
 	if err != nil {
 		h.logger.Error("Failed to get TWAP price", 
 			zap.Error(err),
@@ -860,17 +2307,11 @@ func (h *Handler) getTWAPPrice(c *gin.Context) {
 	}
 
 	// Cache the result
-	h.setCache(cacheKey, price, CacheTTL)
+	h.setCache(cacheKey, data, CacheTTL)
 
 	c.JSON(http.StatusOK, SuccessResponse{
-		Success: true,
-		Data: map[string]interface{}{
-			"token_pair":     pair,
-			"window_minutes": window,
-			"twap_price":     price,
-			"calculated_at":  time.Now().UTC(),
-			"cached":         false,
-		},
+		Success:   true,
+		Data:      h.twapDataResponse(data, false),
 		Timestamp: time.Now(),
 	})
 }
@@ -942,6 +2383,14 @@ func (h *Handler) executeOrder(c *gin.Context) {
 	}
 
 	response, err := h.twapEngine.ExecuteOrderManually(orderID)
+	if err != nil && errors.Is(err, twap.ErrCircuitOpen) {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:     err.Error(),
+			Code:      ErrCodeServiceUnavailable,
+			Timestamp: time.Now(),
+		})
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to execute order", 
 			zap.Error(err),
@@ -956,6 +2405,15 @@ func (h *Handler) executeOrder(c *gin.Context) {
 		return
 	}
 
+	if !response.Success && errors.Is(response.Error, twap.ErrCircuitOpen) {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:     response.Error.Error(),
+			Code:      ErrCodeServiceUnavailable,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
 	if !response.Success {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:     response.Error.Error(),
@@ -1005,6 +2463,26 @@ func (h *Handler) getTWAPMetrics(c *gin.Context) {
 	})
 }
 
+func (h *Handler) getCircuitBreakers(c *gin.Context) {
+	breakers := h.twapEngine.GetCircuitBreakers()
+
+	halted := 0
+	for _, b := range breakers {
+		if b.State == twap.BreakerOpen {
+			halted++
+		}
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"pairs":        breakers,
+			"halted_pairs": halted,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
 // Chain endpoints
 func (h *Handler) getSupportedChains(c *gin.Context) {
 	chains, err := h.db.GetSupportedChains()
@@ -1056,20 +2534,49 @@ func (h *Handler) getChainStatus(c *gin.Context) {
 	})
 }
 
+// getChainMetrics combines persisted order and execution counts with the
+// latest polled chain status
 func (h *Handler) getChainMetrics(c *gin.Context) {
 	chainID := c.Param("id")
 
-	// In production, get real metrics from the adapter
+	metrics, err := h.db.GetChainMetrics(chainID)
+	if err != nil {
+		if err == database.ErrChainNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:     "Chain not found",
+				Code:      ErrCodeNotFound,
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve chain metrics",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	// The poller's in-memory view is fresher than the last persisted row
+	if health, ok := h.orchestrator.GetChainHealth(chainID); ok {
+		metrics.HealthStatus = string(health.Status)
+		metrics.LastHealthCheck = health.LastChecked
+		if health.BlockHeight > 0 {
+			metrics.CurrentBlockHeight = health.BlockHeight
+			metrics.GasPrice = health.GasPrice
+		}
+		if health.BlockTime > 0 {
+			metrics.AverageBlockTime = health.BlockTime
+		}
+	}
+
+	if total := metrics.SuccessfulExecutions + metrics.FailedExecutions; total > 0 {
+		metrics.SuccessRate = float64(metrics.SuccessfulExecutions) / float64(total) * 100
+	}
+
 	c.JSON(http.StatusOK, SuccessResponse{
-		Success: true,
-		Data: map[string]interface{}{
-			"chain_id":           chainID,
-			"order_count":        100,
-			"total_volume":       "1000000",
-			"success_rate":       99.5,
-			"average_block_time": "12s",
-			"health_status":      "healthy",
-		},
+		Success:   true,
+		Data:      metrics,
 		Timestamp: time.Now(),
 	})
 }
@@ -1086,52 +2593,103 @@ func (h *Handler) websocketHandler(c *gin.Context) {
 
 // Statistics endpoints
 func (h *Handler) getOverviewStats(c *gin.Context) {
-	// In production, get real statistics from the orchestrator
-	stats := h.orchestrator.GetStatistics()
-	
+	overview, err := h.stats.Overview()
+	if err != nil {
+		h.respondStatsError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"total_orders":        stats.TotalOrders,
-			"active_orders":       stats.ActiveOrders,
-			"completed_orders":    stats.CompletedOrders,
-			"failed_orders":       stats.FailedOrders,
-			"total_volume":        stats.TotalVolume,
-			"cross_chain_swaps":   stats.CrossChainSwaps,
-			"successful_swaps":    stats.SuccessfulSwaps,
-			"average_process_time": stats.AverageProcessTime,
-			"uptime_seconds":      stats.UptimeSeconds,
+			"orders":               overview.Orders,
+			"executions":           overview.Executions,
+			"average_slippage_bps": overview.AverageSlippageBps,
+			"volume_by_pair":       overview.VolumeByPair,
+			"refreshed_at":         overview.RefreshedAt,
+			"uptime_seconds":       h.orchestrator.GetStatistics().UptimeSeconds,
 		},
 		Timestamp: time.Now(),
 	})
 }
 
+// getVolumeStats reports executed volume by time bucket, chain and pair
 func (h *Handler) getVolumeStats(c *gin.Context) {
+	bucket := c.DefaultQuery("bucket", string(database.StatsBucketDay))
+	if !database.IsValidStatsBucket(bucket) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "Invalid query parameters",
+			Code:      ErrCodeValidation,
+			Details:   map[string]interface{}{"validation_error": "bucket must be one of hour, day, week or month"},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	since, ok := h.statsSince(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.stats.Volume(database.StatsBucket(bucket), since)
+	if err != nil {
+		h.respondStatsError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, SuccessResponse{
-		Success: true,
-		Data: map[string]interface{}{
-			"daily_volume":   "1000000",
-			"weekly_volume":  "7000000",
-			"monthly_volume": "30000000",
-			"volume_by_chain": map[string]interface{}{
-				"ethereum": "20000000",
-				"cosmos":   "8000000",
-				"stellar":  "2000000",
-			},
-		},
+		Success:   true,
+		Data:      report,
 		Timestamp: time.Now(),
 	})
 }
 
+// getPerformanceStats reports success rates, slippage and latency
 func (h *Handler) getPerformanceStats(c *gin.Context) {
+	since, ok := h.statsSince(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.stats.Performance(since)
+	if err != nil {
+		h.respondStatsError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, SuccessResponse{
-		Success: true,
-		Data: map[string]interface{}{
-			"average_slippage":       "0.15%",
-			"average_execution_time": "42s",
-			"success_rate":           99.2,
-			"mev_protection_rate":    100.0,
-		},
+		Success:   true,
+		Data:      report,
+		Timestamp: time.Now(),
+	})
+}
+
+// statsSince parses the since query parameter, writing a 400 when it is
+// invalid
+func (h *Handler) statsSince(c *gin.Context) (time.Time, bool) {
+	raw := c.Query("since")
+	if raw == "" {
+		return time.Now().Add(-DefaultStatsLookback), true
+	}
+
+	since, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "Invalid query parameters",
+			Code:      ErrCodeValidation,
+			Details:   map[string]interface{}{"validation_error": "since must be an RFC 3339 time"},
+			Timestamp: time.Now(),
+		})
+		return time.Time{}, false
+	}
+	return since, true
+}
+
+func (h *Handler) respondStatsError(c *gin.Context, err error) {
+	h.logger.Error("Failed to compute statistics", zap.Error(err))
+	c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:     "Failed to retrieve statistics",
+		Code:      ErrCodeInternalError,
 		Timestamp: time.Now(),
 	})
 }
@@ -1140,11 +2698,18 @@ func (h *Handler) getPriceHistory(c *gin.Context) {
 	pair := c.Param("pair")
 	windowStr := c.DefaultQuery("window", "1440") // 24 hours default
 
+	resolution := c.DefaultQuery("resolution", "raw")
+
 	window, err := strconv.Atoi(windowStr)
 	if err != nil {
 		window = 1440
 	}
 
+	if resolution != "raw" {
+		h.getPriceCandles(c, pair, resolution, window)
+		return
+	}
+
 	history, err := h.db.GetPriceHistory(pair, window)
 	if err != nil {
 		h.logger.Error("Failed to get price history", 
@@ -1164,6 +2729,7 @@ func (h *Handler) getPriceHistory(c *gin.Context) {
 		Data: map[string]interface{}{
 			"token_pair":     pair,
 			"window_minutes": window,
+			"resolution":     resolution,
 			"data_points":    len(history),
 			"history":        history,
 		},
@@ -1171,6 +2737,47 @@ func (h *Handler) getPriceHistory(c *gin.Context) {
 	})
 }
 
+// getPriceCandles serves downsampled OHLCV history for getPriceHistory
+func (h *Handler) getPriceCandles(c *gin.Context, pair, resolution string, window int) {
+	if !database.IsValidCandleResolution(resolution) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "Invalid resolution",
+			Code:      ErrCodeValidation,
+			Details:   map[string]interface{}{"allowed": []string{"raw", "1m", "5m", "1h", "1d"}},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	since := time.Now().Add(-time.Duration(window) * time.Minute)
+	candles, err := h.db.GetCandles(pair, database.CandleResolution(resolution), since)
+	if err != nil {
+		h.logger.Error("Failed to get price candles",
+			zap.Error(err),
+			zap.String("token_pair", pair),
+			zap.String("resolution", resolution))
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "Failed to retrieve price history",
+			Code:      ErrCodeInternalError,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"token_pair":     pair,
+			"window_minutes": window,
+			"resolution":     resolution,
+			"data_points":    len(candles),
+			"candles":        candles,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
 func (h *Handler) getLatestPrice(c *gin.Context) {
 	pair := c.Param("pair")
 
@@ -1243,7 +2850,234 @@ func (h *Handler) clearCache(c *gin.Context) {
 	})
 }
 
+func (h *Handler) resetCircuitBreaker(c *gin.Context) {
+	pair := c.Param("pair")
+
+	if !h.twapEngine.ResetCircuitBreaker(pair) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:     "No circuit breaker for token pair",
+			Code:      ErrCodeNotFound,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	h.logger.Warn("Circuit breaker reset by admin", zap.String("token_pair", pair))
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"token_pair": pair,
+			"state":      twap.BreakerClosed,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
 // Helper functions
+// tokenApprovals returns the chain's adapter if the bridge there pulls
+// tokens from users
+func (h *Handler) tokenApprovals(chainID string) (adapters.TokenApprovalAdapter, bool) {
+	adapter, err := h.orchestrator.GetAdapterManager().GetAdapter(chainID)
+	if err != nil {
+		return nil, false
+	}
+	approvals, ok := adapter.(adapters.TokenApprovalAdapter)
+	return approvals, ok
+}
+
+// newOrder converts a validated create order request into a pending
+// order, generating its ID if the request has none
+func (h *Handler) newOrder(req *CreateOrderRequest, now time.Time) (*database.Order, int, *ErrorResponse) {
+	id := req.ID
+	if id == "" {
+		var err error
+		if id, err = newID("ord_"); err != nil {
+			return nil, http.StatusInternalServerError, &ErrorResponse{
+				Error:     "Failed to generate order ID",
+				Code:      ErrCodeInternalError,
+				Timestamp: time.Now(),
+			}
+		}
+	}
+
+	order := &database.Order{
+		ID:                  id,
+		UserAddress:         req.UserAddress,
+		SourceChain:         req.SourceChain,
+		TargetChain:         req.TargetChain,
+		SourceToken:         req.SourceToken,
+		SourceAmount:        req.SourceAmount,
+		TargetToken:         req.TargetToken,
+		TargetRecipient:     req.TargetRecipient,
+		MinReceived:         req.MinReceived,
+		WindowMinutes:       req.TWAPConfig.WindowMinutes,
+		ExecutionIntervals:  req.TWAPConfig.ExecutionIntervals,
+		MaxSlippage:         req.TWAPConfig.MaxSlippage,
+		MinFillSize:         req.TWAPConfig.MinFillSize,
+		EnableMEVProtection: req.TWAPConfig.EnableMEVProtection,
+		HTLCHash:            req.HTLCHash,
+		TimeoutHeight:       req.TimeoutHeight,
+		TimeoutTimestamp:    req.TimeoutTimestamp,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+		Status:              string(database.OrderStatusPending),
+		ExecutedAmount:      decimal.Zero,
+		AveragePrice:        decimal.Zero,
+		Metadata:            database.Metadata(req.Metadata),
+		StartAt:             req.TWAPConfig.StartAt,
+		TriggerPrice:        req.TWAPConfig.TriggerPrice,
+		LimitPrice:          req.TWAPConfig.LimitPrice,
+	}
+
+	// A trigger without a direction fires when the pair crosses it from
+	// where it trades now
+	if order.TriggerPrice != nil {
+		condition := req.TWAPConfig.TriggerCondition
+		if condition == "" {
+			var err error
+			condition, err = h.twapEngine.TriggerCondition(order, *order.TriggerPrice)
+			if err != nil {
+				return nil, http.StatusBadRequest, &ErrorResponse{
+					Error:     "Validation failed",
+					Code:      ErrCodeValidation,
+					Details:   map[string]interface{}{"validation_error": "trigger condition is required without a current price: " + err.Error()},
+					Timestamp: time.Now(),
+				}
+			}
+		}
+		order.TriggerCondition = &condition
+	}
+
+	return order, 0, nil
+}
+
+// checkBatchApproval checks the bridge can pull the source tokens of every
+// order in a batch. Orders without a permit draw on the same allowance, so
+// their amounts are summed per chain and token.
+func (h *Handler) checkBatchApproval(reqs []CreateOrderRequest) (int, *ErrorResponse) {
+	type allowanceKey struct{ chain, token, owner string }
+	combined := make(map[allowanceKey]*CreateOrderRequest)
+	members := make(map[allowanceKey][]int)
+	var keys []allowanceKey
+
+	for i := range reqs {
+		req := &reqs[i]
+		if req.Permit != nil {
+			if status, errResp := h.checkSourceApproval(req); errResp != nil {
+				return status, withBatchOrders(errResp, []int{i})
+			}
+			continue
+		}
+
+		key := allowanceKey{req.SourceChain, strings.ToLower(req.SourceToken), strings.ToLower(req.UserAddress)}
+		if total, ok := combined[key]; ok {
+			total.SourceAmount = total.SourceAmount.Add(req.SourceAmount)
+		} else {
+			total := *req
+			combined[key] = &total
+			keys = append(keys, key)
+		}
+		members[key] = append(members[key], i)
+	}
+
+	for _, key := range keys {
+		if status, errResp := h.checkSourceApproval(combined[key]); errResp != nil {
+			return status, withBatchOrders(errResp, members[key])
+		}
+	}
+	return 0, nil
+}
+
+// withBatchOrders records which orders of a batch an error applies to
+func withBatchOrders(errResp *ErrorResponse, indexes []int) *ErrorResponse {
+	if errResp.Details == nil {
+		errResp.Details = map[string]interface{}{}
+	}
+	errResp.Details["orders"] = indexes
+	return errResp
+}
+
+// checkSourceApproval checks the request's permit, or failing that the
+// user's existing allowance, covers the source amount. Native tokens and
+// chains whose bridge does not pull tokens need no approval.
+func (h *Handler) checkSourceApproval(req *CreateOrderRequest) (int, *ErrorResponse) {
+	approvals, ok := h.tokenApprovals(req.SourceChain)
+	if !ok || !ethereumAddressPattern.MatchString(req.SourceToken) || strings.Trim(req.SourceToken[2:], "0") == "" {
+		return 0, nil
+	}
+
+	if req.Permit != nil {
+		err := approvals.VerifyPermit(req.Permit)
+		if err == nil {
+			return 0, nil
+		}
+		if errors.Is(err, adapters.ErrInvalidPermit) || errors.Is(err, adapters.ErrInsufficientAllowance) ||
+			errors.Is(err, adapters.ErrPermitNotSupported) {
+			return http.StatusBadRequest, &ErrorResponse{
+				Error:     "Invalid permit",
+				Code:      ErrCodeInvalidPermit,
+				Details:   map[string]interface{}{"permit_error": err.Error()},
+				Timestamp: time.Now(),
+			}
+		}
+		h.logger.Error("Failed to verify permit", zap.Error(err), zap.String("order_id", req.ID))
+		return http.StatusServiceUnavailable, &ErrorResponse{
+			Error:     "Failed to verify permit",
+			Code:      ErrCodeChainError,
+			Timestamp: time.Now(),
+		}
+	}
+
+	allowance, err := approvals.GetAllowance(req.SourceToken, req.UserAddress)
+	if err != nil {
+		h.logger.Error("Failed to check allowance", zap.Error(err), zap.String("order_id", req.ID))
+		return http.StatusServiceUnavailable, &ErrorResponse{
+			Error:     "Failed to check token allowance",
+			Code:      ErrCodeChainError,
+			Timestamp: time.Now(),
+		}
+	}
+
+	if allowance.LessThan(req.SourceAmount) {
+		return http.StatusBadRequest, &ErrorResponse{
+			Error: "Insufficient token allowance",
+			Code:  ErrCodeInsufficientAllowance,
+			Details: map[string]interface{}{
+				"allowance":       allowance.String(),
+				"required":        req.SourceAmount.String(),
+				"permit_endpoint": "/api/v1/orders/permit",
+			},
+			Timestamp: time.Now(),
+		}
+	}
+
+	return 0, nil
+}
+
+func (h *Handler) twapDataResponse(data *database.TWAPData, cached bool) map[string]interface{} {
+	// VWAP is only meaningful when the feeds reported volume
+	var vwap interface{}
+	if !data.VWAPPrice.IsZero() {
+		vwap = data.VWAPPrice
+	}
+
+	return map[string]interface{}{
+		"token_pair":           data.TokenPair,
+		"window_minutes":       data.WindowMinutes,
+		"twap_price":           data.TWAPPrice,
+		"geometric_twap_price": data.GeometricTWAPPrice,
+		"vwap_price":           vwap,
+		"last_price":           data.LastPrice,
+		"price_change":         data.PriceChange,
+		"price_change_percent": data.PriceChangePercent,
+		"volume":               data.Volume,
+		"data_points":          data.NumDataPoints,
+		"calculated_at":        data.CalculatedAt.UTC(),
+		"cached":               cached,
+	}
+}
+
 func (h *Handler) convertExecutionHistory(history []*database.ExecutionRecord) []ExecutionHistoryResponse {
 	response := make([]ExecutionHistoryResponse, 0, len(history))
 	for _, record := range history {
@@ -1256,6 +3090,15 @@ func (h *Handler) convertExecutionHistory(history []*database.ExecutionRecord) [
 			Slippage:       record.Slippage,
 			TxHash:         record.TxHash,
 			ChainID:        record.ChainID,
+
+			SubmissionRoute: record.SubmissionRoute,
+			BundleHash:      record.BundleHash,
+			RelayAttempts:   record.RelayAttempts,
+			InclusionBlock:  record.InclusionBlock,
+
+			GasPrice:      record.GasPrice,
+			GasCostNative: record.GasCostNative,
+			GasCostQuote:  record.GasCostQuote,
 		})
 	}
 	return response
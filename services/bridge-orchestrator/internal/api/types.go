@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"net/http"
 	"regexp"
 	"strconv"
@@ -11,13 +12,18 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+	"flowfusion/bridge-orchestrator/pkg/adapters"
 )
 
 // Request/Response Types
 
 type CreateOrderRequest struct {
-	ID               string                 `json:"id" binding:"required"`
+	// ID is generated by the server when omitted
+	ID               string                 `json:"id,omitempty"`
 	UserAddress      string                 `json:"user_address" binding:"required"`
 	SourceChain      string                 `json:"source_chain" binding:"required"`
 	TargetChain      string                 `json:"target_chain" binding:"required"`
@@ -31,6 +37,81 @@ type CreateOrderRequest struct {
 	TimeoutHeight    int64                  `json:"timeout_height" binding:"required"`
 	TimeoutTimestamp int64                  `json:"timeout_timestamp" binding:"required"`
 	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+
+	// Permit is a signed EIP-2612 or Permit2 approval from
+	// POST /orders/permit, used instead of an on-chain approve
+	Permit *adapters.Permit `json:"permit,omitempty"`
+}
+
+// AmendOrderRequest changes a live order's terms; omitted fields are left
+// unchanged
+type AmendOrderRequest struct {
+	SourceAmount       *decimal.Decimal `json:"source_amount,omitempty"`
+	WindowMinutes      *int             `json:"window_minutes,omitempty" binding:"omitempty,min=5,max=1440"`
+	ExecutionIntervals *int             `json:"execution_intervals,omitempty" binding:"omitempty,min=2,max=20"`
+	MaxSlippage        *int             `json:"max_slippage,omitempty" binding:"omitempty,min=1,max=1000"`
+	Reason             string           `json:"reason,omitempty" binding:"max=500"`
+}
+
+// PauseOrderRequest pauses a live order
+type PauseOrderRequest struct {
+	Reason string `json:"reason,omitempty" binding:"max=500"`
+}
+
+// ResumeOrderRequest resumes a paused order. Mode "extend" (the default)
+// pushes the window out by the time spent paused; "compress" keeps the
+// original window end and runs the remainder in fewer, larger intervals.
+type ResumeOrderRequest struct {
+	Mode string `json:"mode,omitempty" binding:"omitempty,oneof=extend compress"`
+}
+
+// BatchCreateOrderRequest creates several orders in one basket. The batch
+// is all or nothing: if any order fails validation, none is created. With
+// SyncProgress set, orders that run more than MaxProgressDrift percentage
+// points ahead of the basket's slowest wait for it to catch up.
+type BatchCreateOrderRequest struct {
+	BasketID         string                 `json:"basket_id,omitempty" binding:"omitempty,max=48"`
+	Orders           []CreateOrderRequest   `json:"orders" binding:"required,min=1,max=20,dive"`
+	SyncProgress     bool                   `json:"sync_progress"`
+	MaxProgressDrift *decimal.Decimal       `json:"max_progress_drift,omitempty"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// CreateRecurringOrderRequest creates a series of TWAP orders, one per run
+// of Schedule, a five-field cron expression in UTC. The series ends at
+// EndAt, after MaxRuns runs, or once Budget cannot fund another run. The
+// bridge pulls each run's AmountPerRun under the user's approval.
+type CreateRecurringOrderRequest struct {
+	ID              string                 `json:"id" binding:"required,max=48"`
+	UserAddress     string                 `json:"user_address" binding:"required"`
+	SourceChain     string                 `json:"source_chain" binding:"required"`
+	TargetChain     string                 `json:"target_chain" binding:"required"`
+	SourceToken     string                 `json:"source_token" binding:"required"`
+	TargetToken     string                 `json:"target_token" binding:"required"`
+	TargetRecipient string                 `json:"target_recipient" binding:"required"`
+	Schedule        string                 `json:"schedule" binding:"required,max=100"`
+	AmountPerRun    decimal.Decimal        `json:"amount_per_run" binding:"required"`
+	TWAPConfig      TWAPConfigRequest      `json:"twap_config" binding:"required"`
+	HTLCHash        string                 `json:"htlc_hash" binding:"required"`
+	Budget          decimal.Decimal        `json:"budget" binding:"required"`
+	EndAt           *time.Time             `json:"end_at,omitempty"`
+	MaxRuns         *int                   `json:"max_runs,omitempty" binding:"omitempty,min=1"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// CancelRecurringOrderRequest cancels a recurring order, and with
+// CancelRuns its live runs too
+type CancelRecurringOrderRequest struct {
+	CancelRuns bool `json:"cancel_runs"`
+}
+
+type PermitTypedDataRequest struct {
+	Chain    string          `json:"chain" binding:"required"`
+	Kind     string          `json:"kind" binding:"required"`
+	Token    string          `json:"token" binding:"required"`
+	Owner    string          `json:"owner" binding:"required"`
+	Amount   decimal.Decimal `json:"amount" binding:"required"`
+	Deadline int64           `json:"deadline,omitempty"`
 }
 
 type TWAPConfigRequest struct {
@@ -39,6 +120,15 @@ type TWAPConfigRequest struct {
 	MaxSlippage         int             `json:"max_slippage" binding:"required,min=1,max=1000"`
 	MinFillSize         decimal.Decimal `json:"min_fill_size" binding:"required"`
 	EnableMEVProtection bool            `json:"enable_mev_protection"`
+
+	// Optional conditions: start the window no earlier than StartAt or once
+	// the pair crosses TriggerPrice, and skip intervals priced below
+	// LimitPrice. TriggerCondition defaults to the direction of the cross
+	// from the current price.
+	StartAt          *time.Time       `json:"start_at,omitempty"`
+	TriggerPrice     *decimal.Decimal `json:"trigger_price,omitempty"`
+	TriggerCondition string           `json:"trigger_condition,omitempty" binding:"omitempty,oneof=above below"`
+	LimitPrice       *decimal.Decimal `json:"limit_price,omitempty"`
 }
 
 type OrderResponse struct {
@@ -62,8 +152,18 @@ type OrderResponse struct {
 	Status            string                     `json:"status"`
 	AveragePrice      decimal.Decimal            `json:"average_price"`
 	CompletionRate    float64                    `json:"completion_rate"`
+	GasSpent          GasSpentResponse           `json:"gas_spent"`
 	ExecutionHistory  []ExecutionHistoryResponse `json:"execution_history"`
 	Metadata          map[string]interface{}     `json:"metadata,omitempty"`
+	BasketID          *string                    `json:"basket_id,omitempty"`
+	ChainOrderID      *string                    `json:"chain_order_id,omitempty"`
+}
+
+// GasSpentResponse is the gas an order has paid across its intervals
+type GasSpentResponse struct {
+	NativeToken string          `json:"native_token"`
+	Native      decimal.Decimal `json:"native"`
+	Quote       decimal.Decimal `json:"quote"` // in target token base units
 }
 
 type TWAPConfigResponse struct {
@@ -72,16 +172,27 @@ type TWAPConfigResponse struct {
 	MaxSlippage         int             `json:"max_slippage"`
 	MinFillSize         decimal.Decimal `json:"min_fill_size"`
 	EnableMEVProtection bool            `json:"enable_mev_protection"`
+
+	StartAt          *time.Time       `json:"start_at,omitempty"`
+	TriggerPrice     *decimal.Decimal `json:"trigger_price,omitempty"`
+	TriggerCondition *string          `json:"trigger_condition,omitempty"`
+	TriggeredAt      *time.Time       `json:"triggered_at,omitempty"`
+	LimitPrice       *decimal.Decimal `json:"limit_price,omitempty"`
+	SkippedIntervals int              `json:"skipped_intervals"`
 }
 
 type OrderSummaryResponse struct {
 	ID                string          `json:"id"`
+	UserAddress       string          `json:"user_address"`
 	SourceChain       string          `json:"source_chain"`
 	TargetChain       string          `json:"target_chain"`
+	SourceToken       string          `json:"source_token"`
+	TargetToken       string          `json:"target_token"`
 	SourceAmount      decimal.Decimal `json:"source_amount"`
 	ExecutedAmount    decimal.Decimal `json:"executed_amount"`
 	Status            string          `json:"status"`
 	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
 	CompletionRate    float64         `json:"completion_rate"`
 	AveragePrice      decimal.Decimal `json:"average_price"`
 	IntervalsExecuted int             `json:"intervals_executed"`
@@ -97,6 +208,15 @@ type ExecutionHistoryResponse struct {
 	Slippage       *int            `json:"slippage,omitempty"`
 	TxHash         *string         `json:"tx_hash,omitempty"`
 	ChainID        string          `json:"chain_id"`
+
+	SubmissionRoute *string `json:"submission_route,omitempty"`
+	BundleHash      *string `json:"bundle_hash,omitempty"`
+	RelayAttempts   *int    `json:"relay_attempts,omitempty"`
+	InclusionBlock  *int64  `json:"inclusion_block,omitempty"`
+
+	GasPrice      *decimal.Decimal `json:"gas_price,omitempty"`
+	GasCostNative *decimal.Decimal `json:"gas_cost_native,omitempty"`
+	GasCostQuote  *decimal.Decimal `json:"gas_cost_quote,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -122,12 +242,15 @@ type PaginationResponse struct {
 	TotalPages int   `json:"total_pages"`
 	HasNext    bool  `json:"has_next"`
 	HasPrev    bool  `json:"has_prev"`
+	NextCursor string `json:"next_cursor,omitempty"` // continues after this page
 }
 
 type ListOrdersParams struct {
 	UserAddress   string `form:"user"`
 	SourceChain   string `form:"source_chain"`
 	TargetChain   string `form:"target_chain"`
+	SourceToken   string `form:"source_token"`
+	TargetToken   string `form:"target_token"`
 	Status        string `form:"status"`
 	CreatedAfter  string `form:"created_after"`
 	CreatedBefore string `form:"created_before"`
@@ -136,6 +259,53 @@ type ListOrdersParams struct {
 	Page          int    `form:"page"`
 	SortBy        string `form:"sort_by"`
 	SortOrder     string `form:"sort_order"`
+	Cursor        string `form:"cursor"`
+}
+
+// orderFilter converts list parameters into a database filter
+func (p *ListOrdersParams) orderFilter() (database.OrderFilter, error) {
+	filter := database.OrderFilter{
+		SortBy:    database.OrderSortField(p.SortBy),
+		Ascending: p.SortOrder == "asc",
+		Cursor:    p.Cursor,
+		Limit:     p.Limit,
+		Offset:    p.Offset,
+	}
+	optional := func(value string) *string {
+		if value == "" {
+			return nil
+		}
+		return &value
+	}
+	filter.UserAddress = optional(p.UserAddress)
+	filter.SourceChain = optional(p.SourceChain)
+	filter.TargetChain = optional(p.TargetChain)
+	filter.SourceToken = optional(p.SourceToken)
+	filter.TargetToken = optional(p.TargetToken)
+	if p.Status != "" {
+		status := database.OrderStatus(p.Status)
+		filter.Status = &status
+	}
+
+	optionalTime := func(value string) (*time.Time, error) {
+		if value == "" {
+			return nil, nil
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, errors.New("created_after and created_before must be RFC 3339 times")
+		}
+		return &t, nil
+	}
+	var err error
+	if filter.CreatedAfter, err = optionalTime(p.CreatedAfter); err != nil {
+		return filter, err
+	}
+	if filter.CreatedBefore, err = optionalTime(p.CreatedBefore); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
 }
 
 // Error codes
@@ -154,6 +324,8 @@ const (
 	ErrCodeOrderExpired      = "ORDER_EXPIRED"
 	ErrCodeInvalidChain      = "INVALID_CHAIN"
 	ErrCodeInvalidToken      = "INVALID_TOKEN"
+	ErrCodeInsufficientAllowance = "INSUFFICIENT_ALLOWANCE"
+	ErrCodeInvalidPermit         = "INVALID_PERMIT"
 )
 
 // Validation patterns
@@ -255,7 +427,7 @@ func (h *Handler) validateCreateOrder() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Additional validation beyond binding
 		var req CreateOrderRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
+		if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
 			c.JSON(http.StatusBadRequest, ErrorResponse{
 				Error:     "Invalid request format",
 				Code:      ErrCodeValidation,
@@ -375,18 +547,9 @@ func (h *Handler) canModifyOrder(userAddress, orderUserAddress string) bool {
 }
 
 func (h *Handler) canCancelOrder(status string) bool {
-	cancelableStatuses := []string{
-		"pending",
-		"executing",
-		"partially_filled",
-	}
-	
-	for _, s := range cancelableStatuses {
-		if status == s {
-			return true
-		}
-	}
-	return false
+	// A cancellation that failed part way can be retried
+	return status == string(database.OrderStatusCancelling) ||
+		database.CanTransition(database.OrderStatus(status), database.OrderStatusCancelling)
 }
 
 func (h *Handler) isAdmin(userAddress string) bool {
@@ -467,7 +630,7 @@ func (h *Handler) clearOrderCache(orderID string) {
 
 func (h *Handler) validateCreateOrderRequest(req *CreateOrderRequest) error {
 	// Validate order ID
-	if !h.isValidOrderID(req.ID) {
+	if req.ID != "" && !h.isValidOrderID(req.ID) {
 		return errors.New("invalid order ID format")
 	}
 
@@ -512,10 +675,143 @@ func (h *Handler) validateCreateOrderRequest(req *CreateOrderRequest) error {
 		return errors.New("timeout timestamp must be in the future")
 	}
 
+	// A permit must approve this order's tokens
+	if req.Permit != nil {
+		if !strings.EqualFold(req.Permit.Owner, req.UserAddress) {
+			return errors.New("permit owner must be the user address")
+		}
+		if !strings.EqualFold(req.Permit.Token, req.SourceToken) {
+			return errors.New("permit token must be the source token")
+		}
+		if req.Permit.Amount.LessThan(req.SourceAmount) {
+			return errors.New("permit amount is less than the source amount")
+		}
+	}
+
+	// The order must be able to start before it times out
+	if startAt := req.TWAPConfig.StartAt; startAt != nil {
+		if !startAt.After(time.Now()) {
+			return errors.New("start time must be in the future")
+		}
+		if startAt.Unix() >= req.TimeoutTimestamp {
+			return errors.New("start time must be before the timeout timestamp")
+		}
+	}
+
 	// Validate TWAP config
 	return h.validateTWAPConfig(&req.TWAPConfig)
 }
 
+// validateBatchCreateOrderRequest validates every order in the batch,
+// returning the errors keyed by the order's position
+func (h *Handler) validateBatchCreateOrderRequest(req *BatchCreateOrderRequest) map[string]string {
+	errs := make(map[string]string)
+
+	if req.BasketID != "" && !h.isValidOrderID(req.BasketID) {
+		errs["basket_id"] = "invalid basket ID format"
+	}
+
+	if req.MaxProgressDrift != nil && (req.MaxProgressDrift.IsNegative() || req.MaxProgressDrift.GreaterThan(decimal.NewFromInt(100))) {
+		errs["max_progress_drift"] = "max progress drift must be between 0 and 100 percentage points"
+	}
+
+	ids := make(map[string]int)
+	for i := range req.Orders {
+		order := &req.Orders[i]
+		field := fmt.Sprintf("orders[%d]", i)
+
+		if err := h.validateCreateOrderRequest(order); err != nil {
+			errs[field] = err.Error()
+			continue
+		}
+
+		if !strings.EqualFold(order.UserAddress, req.Orders[0].UserAddress) {
+			errs[field] = "all orders in a batch must belong to the same user"
+			continue
+		}
+
+		if order.ID != "" {
+			if first, ok := ids[order.ID]; ok {
+				errs[field] = fmt.Sprintf("order ID duplicates orders[%d]", first)
+				continue
+			}
+			ids[order.ID] = i
+		}
+	}
+
+	return errs
+}
+
+func (h *Handler) validateCreateRecurringOrderRequest(req *CreateRecurringOrderRequest) error {
+	if !h.isValidOrderID(req.ID) {
+		return errors.New("invalid recurring order ID format")
+	}
+
+	if !h.isValidAddress(req.UserAddress) {
+		return errors.New("invalid user address")
+	}
+
+	if !h.isValidRecipientAddress(req.TargetRecipient, req.TargetChain) {
+		return errors.New("invalid target recipient address")
+	}
+
+	if !h.isValidChainID(req.SourceChain) || !h.isValidChainID(req.TargetChain) {
+		return errors.New("invalid chain ID")
+	}
+
+	if req.SourceChain == req.TargetChain {
+		return errors.New("source and target chains must be different")
+	}
+
+	if !req.AmountPerRun.IsPositive() || !req.AmountPerRun.Equal(req.AmountPerRun.Truncate(0)) {
+		return errors.New("amount per run must be a positive integer in base units")
+	}
+
+	if req.Budget.LessThan(req.AmountPerRun) || !req.Budget.Equal(req.Budget.Truncate(0)) {
+		return errors.New("budget must be an integer covering at least one run")
+	}
+
+	if !h.isValidHash(req.HTLCHash) {
+		return errors.New("invalid HTLC hash format")
+	}
+
+	if req.EndAt != nil && !req.EndAt.After(time.Now()) {
+		return errors.New("end time must be in the future")
+	}
+
+	// Runs start when spawned and fill at any price within slippage
+	config := req.TWAPConfig
+	if config.StartAt != nil || config.TriggerPrice != nil || config.LimitPrice != nil {
+		return errors.New("start time, trigger and limit prices are not supported on recurring orders")
+	}
+
+	return h.validateTWAPConfig(&config)
+}
+
+func (h *Handler) validatePermitTypedDataRequest(req *PermitTypedDataRequest) error {
+	if !h.isValidChainID(req.Chain) {
+		return errors.New("invalid chain ID")
+	}
+
+	if req.Kind != adapters.PermitKindEIP2612 && req.Kind != adapters.PermitKindPermit2 {
+		return errors.New("kind must be eip2612 or permit2")
+	}
+
+	if !ethereumAddressPattern.MatchString(req.Token) || !ethereumAddressPattern.MatchString(req.Owner) {
+		return errors.New("token and owner must be EVM addresses")
+	}
+
+	if req.Amount.LessThanOrEqual(decimal.Zero) || !req.Amount.Equal(req.Amount.Truncate(0)) {
+		return errors.New("amount must be a positive integer in base units")
+	}
+
+	if req.Deadline != 0 && req.Deadline <= time.Now().Unix() {
+		return errors.New("deadline must be in the future")
+	}
+
+	return nil
+}
+
 func (h *Handler) validateTWAPConfig(config *TWAPConfigRequest) error {
 	if config.WindowMinutes < 5 || config.WindowMinutes > 1440 {
 		return errors.New("window minutes must be between 5 and 1440")
@@ -539,6 +835,18 @@ func (h *Handler) validateTWAPConfig(config *TWAPConfigRequest) error {
 		return errors.New("execution intervals too frequent for the given window")
 	}
 
+	if config.TriggerPrice != nil && !config.TriggerPrice.IsPositive() {
+		return errors.New("trigger price must be greater than zero")
+	}
+
+	if config.TriggerCondition != "" && config.TriggerPrice == nil {
+		return errors.New("trigger condition requires a trigger price")
+	}
+
+	if config.LimitPrice != nil && !config.LimitPrice.IsPositive() {
+		return errors.New("limit price must be greater than zero")
+	}
+
 	return nil
 }
 
@@ -571,6 +879,8 @@ func (h *Handler) parseListOrdersParams(c *gin.Context) *ListOrdersParams {
 		UserAddress:   c.Query("user"),
 		SourceChain:   c.Query("source_chain"),
 		TargetChain:   c.Query("target_chain"),
+		SourceToken:   c.Query("source_token"),
+		TargetToken:   c.Query("target_token"),
 		Status:        c.Query("status"),
 		CreatedAfter:  c.Query("created_after"),
 		CreatedBefore: c.Query("created_before"),
@@ -579,9 +889,31 @@ func (h *Handler) parseListOrdersParams(c *gin.Context) *ListOrdersParams {
 		Page:          page,
 		SortBy:        c.DefaultQuery("sort_by", "created_at"),
 		SortOrder:     c.DefaultQuery("sort_order", "desc"),
+		Cursor:        c.Query("cursor"),
 	}
 }
 
+// validatePagination rejects limit, offset and page query parameters that
+// parseListOrdersParams would otherwise replace with their defaults
+func validatePagination(c *gin.Context) error {
+	if raw, ok := c.GetQuery("limit"); ok {
+		if limit, err := strconv.Atoi(raw); err != nil || limit <= 0 || limit > MaxPageSize {
+			return fmt.Errorf("limit must be between 1 and %d", MaxPageSize)
+		}
+	}
+	if raw, ok := c.GetQuery("offset"); ok {
+		if offset, err := strconv.Atoi(raw); err != nil || offset < 0 {
+			return errors.New("offset must not be negative")
+		}
+	}
+	if raw, ok := c.GetQuery("page"); ok {
+		if page, err := strconv.Atoi(raw); err != nil || page <= 0 {
+			return errors.New("page must be at least 1")
+		}
+	}
+	return nil
+}
+
 func (h *Handler) validateListOrdersParams(params *ListOrdersParams) error {
 	if params.UserAddress != "" && !h.isValidAddress(params.UserAddress) {
 		return errors.New("invalid user address")
@@ -595,18 +927,16 @@ func (h *Handler) validateListOrdersParams(params *ListOrdersParams) error {
 		return errors.New("invalid target chain")
 	}
 
-	validStatuses := []string{"pending", "executing", "partially_filled", "completed", "cancelled", "expired", "refunded", "claimed"}
-	if params.Status != "" {
-		isValid := false
-		for _, status := range validStatuses {
-			if params.Status == status {
-				isValid = true
-				break
-			}
-		}
-		if !isValid {
-			return errors.New("invalid status")
-		}
+	if params.Status != "" && !database.IsValidOrderStatus(params.Status) {
+		return errors.New("invalid status")
+	}
+
+	if len(params.SourceToken) > 42 || len(params.TargetToken) > 50 {
+		return errors.New("invalid token")
+	}
+
+	if _, err := params.orderFilter(); err != nil {
+		return err
 	}
 
 	validSortFields := []string{"created_at", "updated_at", "source_amount", "executed_amount", "completion_rate"}
@@ -677,6 +1007,16 @@ func generateRequestID() string {
 	return hex.EncodeToString(bytes)
 }
 
+// newID generates an ID with the given prefix for requests that leave it
+// to the server
+func newID(prefix string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
+
 func NewPaginationResponse(page, limit int, total int64) *PaginationResponse {
 	totalPages := int((total + int64(limit) - 1) / int64(limit))
 	if totalPages == 0 {
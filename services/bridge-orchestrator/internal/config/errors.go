@@ -12,5 +12,16 @@ var (
 	ErrMissingStellarSecretKey   = errors.New("stellar secret key is required")
 	ErrInvalidTWAPWindow         = errors.New("invalid TWAP window configuration")
 	ErrInvalidSlippage           = errors.New("invalid slippage configuration")
+	ErrInvalidCoverage           = errors.New("invalid TWAP window coverage configuration")
+	ErrInvalidRetention          = errors.New("price retention is shorter than required")
+	ErrInvalidCircuitBreaker     = errors.New("invalid circuit breaker configuration")
+	ErrInvalidRelayFallback      = errors.New("relay fallback must be none or public")
+	ErrInvalidFeeStrategy        = errors.New("invalid EIP-1559 fee strategy configuration")
+	ErrInvalidProtocolFee        = errors.New("protocol fee must be between 0 and 100 basis points")
+	ErrInvalidGasPolicy          = errors.New("invalid gas policy configuration")
+	ErrInvalidRPCFailover        = errors.New("invalid RPC failover configuration")
+	ErrInvalidChainMonitor       = errors.New("invalid chain monitor configuration")
+	ErrInvalidRecurring          = errors.New("invalid recurring order configuration")
+	ErrInvalidStats              = errors.New("stats refresh interval must be at least 10s")
 	ErrUnsupportedChain          = errors.New("unsupported blockchain")
 )
\ No newline at end of file
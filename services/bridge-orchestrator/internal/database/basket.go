@@ -0,0 +1,28 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrBasketNotFound is returned when a basket does not exist
+	ErrBasketNotFound = errors.New("basket not found")
+	// ErrDuplicateBasket is returned when a basket ID is taken
+	ErrDuplicateBasket = errors.New("duplicate basket")
+)
+
+// Basket groups orders submitted together in one batch. With SyncProgress
+// set, an order whose completion runs more than MaxProgressDrift percentage
+// points ahead of the basket's slowest live order waits for it to catch up.
+type Basket struct {
+	ID               string          `json:"id" db:"id"`
+	UserAddress      string          `json:"user_address" db:"user_address"`
+	SyncProgress     bool            `json:"sync_progress" db:"sync_progress"`
+	MaxProgressDrift decimal.Decimal `json:"max_progress_drift" db:"max_progress_drift"`
+	Metadata         Metadata        `json:"metadata" db:"metadata"`
+	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at" db:"updated_at"`
+}
@@ -2,11 +2,17 @@ package database
 
 import (
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 	"context"
 
-	_ "github.com/lib/pq" 
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
 
@@ -16,8 +22,34 @@ type DB interface {
 	CreateOrder(order *Order) error
 	GetOrder(orderID string) (*Order, error)
 	GetOrdersByUser(userAddress string, limit, offset int) ([]*Order, error)
+	SearchOrders(filter OrderFilter) (*OrderPage, error)
 	UpdateOrder(order *Order) error
+	SetChainOrderID(orderID, chainOrderID string) error
 	GetExecutableOrders() ([]*Order, error)
+	GetPausedOrders() ([]*Order, error)
+	GetOrderEvents(orderID string) ([]*OrderEvent, error)
+	AmendOrder(order *Order, amendment *OrderAmendment) error
+	GetOrderAmendments(orderID string) ([]*OrderAmendment, error)
+	GetOrdersByParent(parentID string) ([]*Order, error)
+
+	// Basket operations
+	CreateBasket(basket *Basket, orders []*Order) error
+	GetBasket(id string) (*Basket, error)
+	GetOrdersByBasket(basketID string) ([]*Order, error)
+
+	// Recurring order operations
+	CreateRecurringOrder(series *RecurringOrder) error
+	GetRecurringOrder(id string) (*RecurringOrder, error)
+	GetRecurringOrdersByUser(userAddress string, limit, offset int) ([]*RecurringOrder, int64, error)
+	GetDueRecurringOrders(now time.Time) ([]*RecurringOrder, error)
+	UpdateRecurringOrder(series *RecurringOrder) error
+	CreateRecurringRun(series *RecurringOrder, run *Order) error
+
+	// Idempotency key operations
+	ReserveIdempotencyKey(key *IdempotencyKey) (*IdempotencyKey, error)
+	CompleteIdempotencyKey(key *IdempotencyKey) error
+	ReleaseIdempotencyKey(userAddress, key string) error
+	CleanupExpiredIdempotencyKeys(now time.Time) error
 
 	// Execution history operations
 	CreateExecutionRecord(record *ExecutionRecord) error
@@ -31,17 +63,31 @@ type DB interface {
 	// Price point methods
     StorePricePoint(point *PricePoint) error
     GetPricePoints(tokenPair string, since time.Time) ([]*PricePoint, error)
+	GetPricePointsBetween(tokenPair string, from, to time.Time) ([]*PricePoint, error)
     GetLatestPrice(tokenPair, source string) (*PricePoint, error)
     CleanupOldPricePoints(olderThan time.Time) error
 
+	// Statistics rollups
+	RefreshStatsRollups() error
+	GetExecutionRollups(bucket StatsBucket, since time.Time) ([]*ExecutionRollup, error)
+	GetOrderRollups(bucket StatsBucket, since time.Time) ([]*OrderRollup, error)
+
+	// Candle operations
+	UpsertCandles(candles []*Candle) error
+	GetCandles(tokenPair string, resolution CandleResolution, since time.Time) ([]*Candle, error)
+	CleanupOldCandles(resolution CandleResolution, olderThan time.Time) error
+
 	// HTLC operations
 	CreateHTLC(htlc *HTLC) error
 	GetHTLC(htlcAddress string) (*HTLC, error)
+	GetHTLCsByOrder(orderID string) ([]*HTLC, error)
 	UpdateHTLC(htlc *HTLC) error
 
 	// Chain operations
 	GetSupportedChains() ([]string, error)
 	GetChainStatus(chainID string) (*ChainStatus, error)
+	UpdateChainStatus(status *ChainStatus) error
+	GetChainMetrics(chainID string) (*ChainMetrics, error)
 
 	// Health check
 	Health() error
@@ -74,7 +120,12 @@ func Initialize(databaseURL string) (DB, error) {
         return nil, fmt.Errorf("failed to ping database: %w", err)
     }
 
-    return &PostgreSQLDB{db: db}, nil
+    pg := &PostgreSQLDB{db: db, logger: zap.NewNop()}
+    if err := pg.initSchema(); err != nil {
+        return nil, fmt.Errorf("failed to initialize schema: %w", err)
+    }
+
+    return pg, nil
 }
 
 
@@ -106,9 +157,46 @@ func (db *PostgreSQLDB) initSchema() error {
 			last_execution TIMESTAMP WITH TIME ZONE,
 			status VARCHAR(20) DEFAULT 'pending',
 			average_price DECIMAL(78, 18) DEFAULT 0,
-			metadata JSONB
+			metadata JSONB,
+			gas_spent_native DECIMAL(78, 18) NOT NULL DEFAULT 0,
+			gas_spent_quote DECIMAL(78, 18) NOT NULL DEFAULT 0,
+			paused_at TIMESTAMP WITH TIME ZONE,
+			paused_by VARCHAR(20),
+			pause_reason TEXT,
+			paused_seconds BIGINT NOT NULL DEFAULT 0,
+			refund_tx_hash VARCHAR(100),
+			refunded_amount DECIMAL(78, 0) NOT NULL DEFAULT 0,
+			start_at TIMESTAMP WITH TIME ZONE,
+			trigger_price DECIMAL(78, 18),
+			trigger_condition VARCHAR(10),
+			triggered_at TIMESTAMP WITH TIME ZONE,
+			limit_price DECIMAL(78, 18),
+			skipped_intervals INTEGER NOT NULL DEFAULT 0,
+			last_skipped_at TIMESTAMP WITH TIME ZONE,
+			parent_id VARCHAR(66),
+			basket_id VARCHAR(48),
+			chain_order_id VARCHAR(66)
 		);
 
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS gas_spent_native DECIMAL(78, 18) NOT NULL DEFAULT 0;
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS gas_spent_quote DECIMAL(78, 18) NOT NULL DEFAULT 0;
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS paused_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS paused_by VARCHAR(20);
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS pause_reason TEXT;
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS paused_seconds BIGINT NOT NULL DEFAULT 0;
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS refund_tx_hash VARCHAR(100);
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS refunded_amount DECIMAL(78, 0) NOT NULL DEFAULT 0;
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS start_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS trigger_price DECIMAL(78, 18);
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS trigger_condition VARCHAR(10);
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS triggered_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS limit_price DECIMAL(78, 18);
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS skipped_intervals INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS last_skipped_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS parent_id VARCHAR(66);
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS basket_id VARCHAR(48);
+		ALTER TABLE orders ADD COLUMN IF NOT EXISTS chain_order_id VARCHAR(66);
+
 		-- Execution history table
 		CREATE TABLE IF NOT EXISTS execution_history (
 			id SERIAL PRIMARY KEY,
@@ -120,9 +208,24 @@ func (db *PostgreSQLDB) initSchema() error {
 			gas_used BIGINT,
 			slippage INTEGER,
 			tx_hash VARCHAR(66),
-			chain_id VARCHAR(20)
+			chain_id VARCHAR(20),
+			submission_route VARCHAR(32),
+			bundle_hash VARCHAR(66),
+			relay_attempts INTEGER,
+			inclusion_block BIGINT,
+			gas_price DECIMAL(78, 0),
+			gas_cost_native DECIMAL(78, 18),
+			gas_cost_quote DECIMAL(78, 18)
 		);
 
+		ALTER TABLE execution_history ADD COLUMN IF NOT EXISTS submission_route VARCHAR(32);
+		ALTER TABLE execution_history ADD COLUMN IF NOT EXISTS bundle_hash VARCHAR(66);
+		ALTER TABLE execution_history ADD COLUMN IF NOT EXISTS relay_attempts INTEGER;
+		ALTER TABLE execution_history ADD COLUMN IF NOT EXISTS inclusion_block BIGINT;
+		ALTER TABLE execution_history ADD COLUMN IF NOT EXISTS gas_price DECIMAL(78, 0);
+		ALTER TABLE execution_history ADD COLUMN IF NOT EXISTS gas_cost_native DECIMAL(78, 18);
+		ALTER TABLE execution_history ADD COLUMN IF NOT EXISTS gas_cost_quote DECIMAL(78, 18);
+
 		-- Price history table
 		CREATE TABLE IF NOT EXISTS price_history (
 			id SERIAL PRIMARY KEY,
@@ -134,6 +237,31 @@ func (db *PostgreSQLDB) initSchema() error {
 			chain_id VARCHAR(20)
 		);
 
+		-- Price points recorded by the TWAP engine's feeds
+		CREATE TABLE IF NOT EXISTS price_points (
+			id SERIAL PRIMARY KEY,
+			token_pair VARCHAR(100) NOT NULL,
+			source VARCHAR(50) NOT NULL,
+			price DECIMAL(78, 18) NOT NULL,
+			volume DECIMAL(78, 18),
+			timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+
+		-- OHLCV candles rolled up from price points
+		CREATE TABLE IF NOT EXISTS price_candles (
+			token_pair VARCHAR(100) NOT NULL,
+			resolution VARCHAR(4) NOT NULL,
+			bucket_start TIMESTAMP WITH TIME ZONE NOT NULL,
+			open DECIMAL(78, 18) NOT NULL,
+			high DECIMAL(78, 18) NOT NULL,
+			low DECIMAL(78, 18) NOT NULL,
+			close DECIMAL(78, 18) NOT NULL,
+			volume DECIMAL(78, 18) NOT NULL DEFAULT 0,
+			num_points INTEGER NOT NULL,
+			PRIMARY KEY (token_pair, resolution, bucket_start)
+		);
+
 		-- HTLC table
 		CREATE TABLE IF NOT EXISTS htlcs (
 			address VARCHAR(100) PRIMARY KEY,
@@ -162,7 +290,89 @@ func (db *PostgreSQLDB) initSchema() error {
 			avg_block_time INTERVAL,
 			gas_price DECIMAL(78, 0),
 			health_status VARCHAR(20) DEFAULT 'unknown',
-			last_health_check TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+			last_health_check TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			peer_count INTEGER,
+			error_message TEXT
+		);
+
+		ALTER TABLE chain_status ADD COLUMN IF NOT EXISTS peer_count INTEGER;
+		ALTER TABLE chain_status ADD COLUMN IF NOT EXISTS error_message TEXT;
+
+		-- Order status transitions
+		CREATE TABLE IF NOT EXISTS order_events (
+			id SERIAL PRIMARY KEY,
+			order_id VARCHAR(66) NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+			from_status VARCHAR(20),
+			to_status VARCHAR(20) NOT NULL,
+			cause TEXT NOT NULL,
+			actor VARCHAR(20) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+
+		-- Amendments to live orders
+		CREATE TABLE IF NOT EXISTS order_amendments (
+			id SERIAL PRIMARY KEY,
+			order_id VARCHAR(66) NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+			changes JSONB NOT NULL,
+			actor VARCHAR(20) NOT NULL,
+			reason TEXT,
+			tx_hash VARCHAR(100),
+			refund_amount DECIMAL(78, 0) NOT NULL DEFAULT 0,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+
+		-- Recurring orders, which spawn a TWAP order per scheduled run
+		CREATE TABLE IF NOT EXISTS recurring_orders (
+			id VARCHAR(48) PRIMARY KEY,
+			user_address VARCHAR(42) NOT NULL,
+			source_chain VARCHAR(20) NOT NULL,
+			target_chain VARCHAR(20) NOT NULL,
+			source_token VARCHAR(42) NOT NULL,
+			target_token VARCHAR(50) NOT NULL,
+			target_recipient TEXT NOT NULL,
+			schedule VARCHAR(100) NOT NULL,
+			amount_per_run DECIMAL(78, 0) NOT NULL,
+			window_minutes INTEGER NOT NULL,
+			execution_intervals INTEGER NOT NULL,
+			max_slippage INTEGER NOT NULL,
+			min_fill_size DECIMAL(78, 0) NOT NULL,
+			enable_mev_protection BOOLEAN NOT NULL DEFAULT true,
+			htlc_hash VARCHAR(66) NOT NULL,
+			budget DECIMAL(78, 0) NOT NULL,
+			end_at TIMESTAMP WITH TIME ZONE,
+			max_runs INTEGER,
+			run_count INTEGER NOT NULL DEFAULT 0,
+			spent_amount DECIMAL(78, 0) NOT NULL DEFAULT 0,
+			next_run_at TIMESTAMP WITH TIME ZONE,
+			last_run_at TIMESTAMP WITH TIME ZONE,
+			status VARCHAR(20) NOT NULL DEFAULT 'active',
+			metadata JSONB,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+
+		-- Groups of orders submitted together
+		CREATE TABLE IF NOT EXISTS baskets (
+			id VARCHAR(48) PRIMARY KEY,
+			user_address VARCHAR(42) NOT NULL,
+			sync_progress BOOLEAN NOT NULL DEFAULT false,
+			max_progress_drift DECIMAL(5, 2) NOT NULL DEFAULT 0,
+			metadata JSONB,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+
+		-- Responses to requests made with an Idempotency-Key header
+		CREATE TABLE IF NOT EXISTS idempotency_keys (
+			user_address VARCHAR(42) NOT NULL,
+			idempotency_key VARCHAR(255) NOT NULL,
+			request_path VARCHAR(255) NOT NULL,
+			fingerprint VARCHAR(64) NOT NULL,
+			status_code INTEGER NOT NULL DEFAULT 0,
+			response_body BYTEA,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			PRIMARY KEY (user_address, idempotency_key)
 		);
 
 		-- Indexes for performance
@@ -172,6 +382,9 @@ func (db *PostgreSQLDB) initSchema() error {
 		CREATE INDEX IF NOT EXISTS idx_orders_target_chain ON orders(target_chain);
 		CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders(created_at);
 		CREATE INDEX IF NOT EXISTS idx_orders_last_execution ON orders(last_execution);
+		CREATE INDEX IF NOT EXISTS idx_orders_parent_id ON orders(parent_id, created_at);
+		CREATE INDEX IF NOT EXISTS idx_orders_basket_id ON orders(basket_id, created_at);
+		CREATE INDEX IF NOT EXISTS idx_orders_token_pair ON orders(source_token, target_token, created_at);
 
 		CREATE INDEX IF NOT EXISTS idx_execution_history_order_id ON execution_history(order_id);
 		CREATE INDEX IF NOT EXISTS idx_execution_history_timestamp ON execution_history(timestamp);
@@ -179,11 +392,63 @@ func (db *PostgreSQLDB) initSchema() error {
 		CREATE INDEX IF NOT EXISTS idx_price_history_token_pair ON price_history(token_pair);
 		CREATE INDEX IF NOT EXISTS idx_price_history_timestamp ON price_history(timestamp);
 		CREATE INDEX IF NOT EXISTS idx_price_history_composite ON price_history(token_pair, timestamp);
+		CREATE INDEX IF NOT EXISTS idx_price_points_pair_timestamp ON price_points(token_pair, timestamp);
 
 		CREATE INDEX IF NOT EXISTS idx_htlcs_order_id ON htlcs(order_id);
 		CREATE INDEX IF NOT EXISTS idx_htlcs_status ON htlcs(status);
 		CREATE INDEX IF NOT EXISTS idx_htlcs_chain_id ON htlcs(chain_id);
 
+		CREATE INDEX IF NOT EXISTS idx_order_events_order_id ON order_events(order_id, created_at);
+		CREATE INDEX IF NOT EXISTS idx_order_amendments_order_id ON order_amendments(order_id, created_at);
+		CREATE INDEX IF NOT EXISTS idx_recurring_orders_user_address ON recurring_orders(user_address);
+		CREATE INDEX IF NOT EXISTS idx_recurring_orders_next_run_at ON recurring_orders(status, next_run_at);
+		CREATE INDEX IF NOT EXISTS idx_baskets_user_address ON baskets(user_address);
+		CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+		-- Statistics rollups, refreshed concurrently by the stats service,
+		-- which needs a unique index on each
+		CREATE MATERIALIZED VIEW IF NOT EXISTS stats_executions_hourly AS
+			SELECT date_trunc('hour', e.timestamp) AS bucket,
+				   COALESCE(e.chain_id, o.target_chain) AS chain_id,
+				   o.source_token || '_' || o.target_token AS token_pair,
+				   COUNT(*) AS executions,
+				   SUM(e.amount) AS volume,
+				   SUM(e.amount * e.price) AS received,
+				   COALESCE(SUM(e.slippage), 0) AS slippage_sum,
+				   COUNT(e.slippage) AS slippage_count,
+				   COALESCE(SUM(e.gas_cost_quote), 0) AS gas_cost_quote
+			FROM execution_history e
+			JOIN orders o ON o.id = e.order_id
+			WHERE e.timestamp IS NOT NULL
+			GROUP BY 1, 2, 3;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_stats_executions_hourly
+			ON stats_executions_hourly(bucket, chain_id, token_pair);
+
+		CREATE MATERIALIZED VIEW IF NOT EXISTS stats_orders_hourly AS
+			SELECT date_trunc('hour', o.created_at) AS bucket,
+				   o.source_chain, o.target_chain,
+				   o.source_token || '_' || o.target_token AS token_pair,
+				   COALESCE(o.status, 'pending') AS status,
+				   COUNT(*) AS orders,
+				   SUM(o.source_amount) AS source_amount,
+				   SUM(o.executed_amount) AS executed_amount,
+				   COUNT(f.first_fill) AS filled_orders,
+				   COALESCE(SUM(EXTRACT(EPOCH FROM f.first_fill - a.activated_at)), 0) AS fill_latency_seconds,
+				   COUNT(f.last_fill) FILTER (WHERE o.status IN ('completed', 'claimed')) AS completed_orders,
+				   COALESCE(SUM(EXTRACT(EPOCH FROM f.last_fill - a.activated_at))
+					   FILTER (WHERE o.status IN ('completed', 'claimed')), 0) AS completion_time_seconds
+			FROM orders o
+			CROSS JOIN LATERAL (SELECT GREATEST(o.created_at, o.start_at, o.triggered_at) AS activated_at) a
+			LEFT JOIN LATERAL (
+				SELECT MIN(e.timestamp) AS first_fill, MAX(e.timestamp) AS last_fill
+				FROM execution_history e
+				WHERE e.order_id = o.id
+			) f ON true
+			WHERE o.created_at IS NOT NULL
+			GROUP BY 1, 2, 3, 4, 5;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_stats_orders_hourly
+			ON stats_orders_hourly(bucket, source_chain, target_chain, token_pair, status);
+
 		-- Insert default chain status
 		INSERT INTO chain_status (chain_id, name, enabled) 
 		VALUES 
@@ -198,19 +463,92 @@ func (db *PostgreSQLDB) initSchema() error {
 	return err
 }
 
+// orderColumns are the columns scanOrder reads, in order
+const orderColumns = `id, user_address, source_chain, target_chain, source_token,
+			   source_amount, target_token, target_recipient, min_received,
+			   window_minutes, execution_intervals, max_slippage, min_fill_size,
+			   enable_mev_protection, htlc_hash, timeout_height, timeout_timestamp,
+			   created_at, updated_at, executed_amount, last_execution,
+			   status, average_price, metadata,
+			   gas_spent_native, gas_spent_quote,
+			   paused_at, paused_by, pause_reason, paused_seconds,
+			   refund_tx_hash, refunded_amount,
+			   start_at, trigger_price, trigger_condition, triggered_at,
+			   limit_price, skipped_intervals, last_skipped_at,
+			   parent_id, basket_id, chain_order_id`
+
+// rowScanner is a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanOrder scans a row selected with orderColumns
+func scanOrder(row rowScanner) (*Order, error) {
+	order := &Order{}
+	if err := row.Scan(orderFields(order)...); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// orderFields returns the scan destinations of orderColumns
+func orderFields(order *Order) []interface{} {
+	return []interface{}{
+		&order.ID, &order.UserAddress, &order.SourceChain, &order.TargetChain,
+		&order.SourceToken, &order.SourceAmount, &order.TargetToken,
+		&order.TargetRecipient, &order.MinReceived, &order.WindowMinutes,
+		&order.ExecutionIntervals, &order.MaxSlippage, &order.MinFillSize,
+		&order.EnableMEVProtection, &order.HTLCHash, &order.TimeoutHeight,
+		&order.TimeoutTimestamp, &order.CreatedAt, &order.UpdatedAt,
+		&order.ExecutedAmount, &order.LastExecution, &order.Status,
+		&order.AveragePrice, &order.Metadata,
+		&order.GasSpentNative, &order.GasSpentQuote,
+		&order.PausedAt, &order.PausedBy, &order.PauseReason, &order.PausedSeconds,
+		&order.RefundTxHash, &order.RefundedAmount,
+		&order.StartAt, &order.TriggerPrice, &order.TriggerCondition, &order.TriggeredAt,
+		&order.LimitPrice, &order.SkippedIntervals, &order.LastSkippedAt,
+		&order.ParentID, &order.BasketID, &order.ChainOrderID,
+	}
+}
+
 // Order operations
 func (db *PostgreSQLDB) CreateOrder(order *Order) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = insertOrder(tx, order, ActorUser, "order created")
+	if err == nil {
+		err = tx.Commit()
+	}
+
+	if err != nil {
+		db.logger.Error("Failed to create order", zap.Error(err), zap.String("order_id", order.ID))
+		return err
+	}
+
+	db.logger.Info("Order created", zap.String("order_id", order.ID))
+	return nil
+}
+
+// insertOrder inserts the order and the event recording its creation
+func insertOrder(tx *sql.Tx, order *Order, actor, cause string) error {
 	query := `
 		INSERT INTO orders (
 			id, user_address, source_chain, target_chain, source_token, 
 			source_amount, target_token, target_recipient, min_received,
 			window_minutes, execution_intervals, max_slippage, min_fill_size,
 			enable_mev_protection, htlc_hash, timeout_height, timeout_timestamp,
-			status, metadata
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+			status, metadata,
+			start_at, trigger_price, trigger_condition, limit_price,
+			parent_id, basket_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19,
+			$20, $21, $22, $23, $24, $25)
 	`
 
-	_, err := db.db.Exec(
+	_, err := tx.Exec(
 		query,
 		order.ID, order.UserAddress, order.SourceChain, order.TargetChain,
 		order.SourceToken, order.SourceAmount, order.TargetToken,
@@ -218,42 +556,31 @@ func (db *PostgreSQLDB) CreateOrder(order *Order) error {
 		order.ExecutionIntervals, order.MaxSlippage, order.MinFillSize,
 		order.EnableMEVProtection, order.HTLCHash, order.TimeoutHeight,
 		order.TimeoutTimestamp, order.Status, order.Metadata,
+		order.StartAt, order.TriggerPrice, order.TriggerCondition, order.LimitPrice,
+		order.ParentID, order.BasketID,
 	)
-
 	if err != nil {
-		db.logger.Error("Failed to create order", zap.Error(err), zap.String("order_id", order.ID))
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: %s", ErrDuplicateOrder, order.ID)
+		}
 		return err
 	}
 
-	db.logger.Info("Order created", zap.String("order_id", order.ID))
-	return nil
+	return insertOrderEvents(tx, &OrderEvent{
+		OrderID:   order.ID,
+		ToStatus:  order.Status,
+		Cause:     cause,
+		Actor:     actor,
+		CreatedAt: time.Now(),
+	})
 }
 
 func (db *PostgreSQLDB) GetOrder(orderID string) (*Order, error) {
-	query := `
-		SELECT id, user_address, source_chain, target_chain, source_token,
-			   source_amount, target_token, target_recipient, min_received,
-			   window_minutes, execution_intervals, max_slippage, min_fill_size,
-			   enable_mev_protection, htlc_hash, timeout_height, timeout_timestamp,
-			   created_at, updated_at, executed_amount, last_execution,
-			   status, average_price, metadata
+	query := `SELECT ` + orderColumns + `
 		FROM orders WHERE id = $1
 	`
 
-	row := db.db.QueryRow(query, orderID)
-	
-	order := &Order{}
-	err := row.Scan(
-		&order.ID, &order.UserAddress, &order.SourceChain, &order.TargetChain,
-		&order.SourceToken, &order.SourceAmount, &order.TargetToken,
-		&order.TargetRecipient, &order.MinReceived, &order.WindowMinutes,
-		&order.ExecutionIntervals, &order.MaxSlippage, &order.MinFillSize,
-		&order.EnableMEVProtection, &order.HTLCHash, &order.TimeoutHeight,
-		&order.TimeoutTimestamp, &order.CreatedAt, &order.UpdatedAt,
-		&order.ExecutedAmount, &order.LastExecution, &order.Status,
-		&order.AveragePrice, &order.Metadata,
-	)
-
+	order, err := scanOrder(db.db.QueryRow(query, orderID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrOrderNotFound
@@ -265,13 +592,7 @@ func (db *PostgreSQLDB) GetOrder(orderID string) (*Order, error) {
 }
 
 func (db *PostgreSQLDB) GetOrdersByUser(userAddress string, limit, offset int) ([]*Order, error) {
-	query := `
-		SELECT id, user_address, source_chain, target_chain, source_token,
-			   source_amount, target_token, target_recipient, min_received,
-			   window_minutes, execution_intervals, max_slippage, min_fill_size,
-			   enable_mev_protection, htlc_hash, timeout_height, timeout_timestamp,
-			   created_at, updated_at, executed_amount, last_execution,
-			   status, average_price, metadata
+	query := `SELECT ` + orderColumns + `
 		FROM orders 
 		WHERE user_address = $1 
 		ORDER BY created_at DESC 
@@ -286,17 +607,7 @@ func (db *PostgreSQLDB) GetOrdersByUser(userAddress string, limit, offset int) (
 
 	var orders []*Order
 	for rows.Next() {
-		order := &Order{}
-		err := rows.Scan(
-			&order.ID, &order.UserAddress, &order.SourceChain, &order.TargetChain,
-			&order.SourceToken, &order.SourceAmount, &order.TargetToken,
-			&order.TargetRecipient, &order.MinReceived, &order.WindowMinutes,
-			&order.ExecutionIntervals, &order.MaxSlippage, &order.MinFillSize,
-			&order.EnableMEVProtection, &order.HTLCHash, &order.TimeoutHeight,
-			&order.TimeoutTimestamp, &order.CreatedAt, &order.UpdatedAt,
-			&order.ExecutedAmount, &order.LastExecution, &order.Status,
-			&order.AveragePrice, &order.Metadata,
-		)
+		order, err := scanOrder(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -306,6 +617,196 @@ func (db *PostgreSQLDB) GetOrdersByUser(userAddress string, limit, offset int) (
 	return orders, nil
 }
 
+// orderSortColumns maps sort fields to the expression orders are sorted by
+// and the type a cursor's value is cast back to
+var orderSortColumns = map[OrderSortField]struct{ expr, castType string }{
+	OrderSortCreatedAt:      {"created_at", "timestamptz"},
+	OrderSortUpdatedAt:      {"updated_at", "timestamptz"},
+	OrderSortSourceAmount:   {"source_amount", "numeric"},
+	OrderSortExecutedAmount: {"executed_amount", "numeric"},
+	OrderSortCompletionRate: {"COALESCE(executed_amount / NULLIF(source_amount, 0), 0)", "numeric"},
+}
+
+// orderCursor is the position after the last order of a page: its sort
+// value, as Postgres renders it, and its ID to break ties. The sort it was
+// made for is kept so it cannot continue a page sorted another way.
+type orderCursor struct {
+	SortBy    OrderSortField `json:"s"`
+	Ascending bool           `json:"asc"`
+	Value     string         `json:"v"`
+	ID        string         `json:"id"`
+}
+
+// SearchOrders returns a page of the orders matching filter with the
+// number of intervals each has executed. Pages are keyset paginated, so
+// orders created while paging do not shift later pages.
+func (db *PostgreSQLDB) SearchOrders(filter OrderFilter) (*OrderPage, error) {
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = OrderSortCreatedAt
+	}
+	sortColumn, ok := orderSortColumns[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort field %q", sortBy)
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	var conditions []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.UserAddress != nil {
+		conditions = append(conditions, "user_address = "+arg(*filter.UserAddress))
+	}
+	if filter.SourceChain != nil {
+		conditions = append(conditions, "source_chain = "+arg(*filter.SourceChain))
+	}
+	if filter.TargetChain != nil {
+		conditions = append(conditions, "target_chain = "+arg(*filter.TargetChain))
+	}
+	if filter.SourceToken != nil {
+		conditions = append(conditions, "source_token = "+arg(*filter.SourceToken))
+	}
+	if filter.TargetToken != nil {
+		conditions = append(conditions, "target_token = "+arg(*filter.TargetToken))
+	}
+	if filter.Status != nil {
+		conditions = append(conditions, "status = "+arg(string(*filter.Status)))
+	}
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, "created_at >= "+arg(*filter.CreatedAfter))
+	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, "created_at < "+arg(*filter.CreatedBefore))
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	page := &OrderPage{Orders: []*Order{}, IntervalsExecuted: make(map[string]int)}
+	if err := db.db.QueryRow(`SELECT COUNT(*) FROM orders `+whereClause, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	direction, comparison := "DESC", "<"
+	if filter.Ascending {
+		direction, comparison = "ASC", ">"
+	}
+
+	offset := filter.Offset
+	if filter.Cursor != "" {
+		cursor, err := decodeOrderCursor(filter.Cursor, sortBy, filter.Ascending)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s::%s, %s)",
+			sortColumn.expr, comparison, arg(cursor.Value), sortColumn.castType, arg(cursor.ID)))
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+		offset = 0
+	}
+
+	// One extra row tells whether there is a next page
+	query := `SELECT ` + orderColumns + `,
+			(SELECT COUNT(*) FROM execution_history e WHERE e.order_id = orders.id),
+			(` + sortColumn.expr + `)::text
+		FROM orders
+		` + whereClause + `
+		ORDER BY ` + sortColumn.expr + ` ` + direction + `, id ` + direction + `
+		LIMIT ` + strconv.Itoa(limit+1) + ` OFFSET ` + strconv.Itoa(offset)
+
+	rows, err := db.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lastSortValue string
+	for rows.Next() {
+		order := &Order{}
+		var intervals int
+		var sortValue string
+		if err := rows.Scan(append(orderFields(order), &intervals, &sortValue)...); err != nil {
+			return nil, err
+		}
+
+		if len(page.Orders) == limit {
+			last := page.Orders[limit-1]
+			page.NextCursor = encodeOrderCursor(orderCursor{
+				SortBy:    sortBy,
+				Ascending: filter.Ascending,
+				Value:     lastSortValue,
+				ID:        last.ID,
+			})
+			break
+		}
+		page.Orders = append(page.Orders, order)
+		page.IntervalsExecuted[order.ID] = intervals
+		lastSortValue = sortValue
+	}
+
+	return page, rows.Err()
+}
+
+func encodeOrderCursor(cursor orderCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeOrderCursor returns ErrInvalidCursor unless encoded is a cursor
+// made for the same sort whose value parses as the sort column's type
+func decodeOrderCursor(encoded string, sortBy OrderSortField, ascending bool) (orderCursor, error) {
+	var cursor orderCursor
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(raw, &cursor) != nil || cursor.Value == "" || cursor.ID == "" {
+		return cursor, ErrInvalidCursor
+	}
+	if cursor.SortBy != sortBy || cursor.Ascending != ascending {
+		return cursor, ErrInvalidCursor
+	}
+	if !parsesAs(orderSortColumns[sortBy].castType, cursor.Value) {
+		return cursor, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// postgresTimestampLayouts are how Postgres renders a timestamptz as text,
+// with a whole hour or an hour and minute offset. Fractional seconds are
+// accepted without a layout.
+var postgresTimestampLayouts = []string{
+	"2006-01-02 15:04:05-07",
+	"2006-01-02 15:04:05-07:00",
+}
+
+// parsesAs checks value can be cast to castType, so a bad cursor is
+// rejected before Postgres fails on it
+func parsesAs(castType, value string) bool {
+	switch castType {
+	case "numeric":
+		_, err := decimal.NewFromString(value)
+		return err == nil
+	case "timestamptz":
+		for _, layout := range postgresTimestampLayouts {
+			if _, err := time.Parse(layout, value); err == nil {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// UpdateOrder saves the order and its pending status transitions. The
+// update only applies if the stored status is still the one the order was
+// read with, so concurrent transitions cannot overwrite each other.
 func (db *PostgreSQLDB) UpdateOrder(order *Order) error {
     query := `
         UPDATE orders SET
@@ -313,17 +814,46 @@ func (db *PostgreSQLDB) UpdateOrder(order *Order) error {
             last_execution = $3,
             status = $4,
             average_price = $5,
+            gas_spent_native = $6,
+            gas_spent_quote = $7,
+            paused_at = $8,
+            paused_by = $9,
+            pause_reason = $10,
+            paused_seconds = $11,
+            refund_tx_hash = $12,
+            refunded_amount = $13,
+            triggered_at = $14,
+            skipped_intervals = $15,
+            last_skipped_at = $16,
             updated_at = NOW()
-        WHERE id = $1
+        WHERE id = $1 AND status = $17
     `
 
-    result, err := db.db.Exec(
+    tx, err := db.db.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    result, err := tx.Exec(
         query,
         order.ID, 
         order.ExecutedAmount,
         order.LastExecution,
         order.Status,
         order.AveragePrice,
+        order.GasSpentNative,
+        order.GasSpentQuote,
+        order.PausedAt,
+        order.PausedBy,
+        order.PauseReason,
+        order.PausedSeconds,
+        order.RefundTxHash,
+        order.RefundedAmount,
+        order.TriggeredAt,
+        order.SkippedIntervals,
+        order.LastSkippedAt,
+        order.persistedStatus(),
     )
     
     if err != nil {
@@ -336,20 +866,186 @@ func (db *PostgreSQLDB) UpdateOrder(order *Order) error {
     }
 
     if rowsAffected == 0 {
+        var exists bool
+        if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM orders WHERE id = $1)`, order.ID).Scan(&exists); err != nil {
+            return err
+        }
+        if exists {
+            return fmt.Errorf("%w: order %s is no longer %s", ErrStatusConflict, order.ID, order.persistedStatus())
+        }
         return ErrOrderNotFound
     }
 
+    if err := insertOrderEvents(tx, order.events...); err != nil {
+        return err
+    }
+    if err := tx.Commit(); err != nil {
+        return err
+    }
+
+    order.events = nil
     return nil
 }
 
-func (db *PostgreSQLDB) GetExecutableOrders() ([]*Order, error) {
+// SetChainOrderID records the id the source chain's bridge assigned an
+// order when it was created there
+func (db *PostgreSQLDB) SetChainOrderID(orderID, chainOrderID string) error {
+	result, err := db.db.Exec(
+		`UPDATE orders SET chain_order_id = $2, updated_at = NOW() WHERE id = $1`,
+		orderID, chainOrderID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrOrderNotFound
+	}
+	return nil
+}
+
+// insertOrderEvents writes status transitions within tx
+func insertOrderEvents(tx *sql.Tx, events ...*OrderEvent) error {
 	query := `
-		SELECT id, user_address, source_chain, target_chain, source_token,
-			   source_amount, target_token, target_recipient, min_received,
-			   window_minutes, execution_intervals, max_slippage, min_fill_size,
-			   enable_mev_protection, htlc_hash, timeout_height, timeout_timestamp,
-			   created_at, updated_at, executed_amount, last_execution,
-			   status, average_price, metadata
+		INSERT INTO order_events (order_id, from_status, to_status, cause, actor, created_at)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	for _, event := range events {
+		err := tx.QueryRow(
+			query,
+			event.OrderID, event.FromStatus, event.ToStatus,
+			event.Cause, event.Actor, event.CreatedAt,
+		).Scan(&event.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetOrderEvents returns an order's status transitions, oldest first
+func (db *PostgreSQLDB) GetOrderEvents(orderID string) ([]*OrderEvent, error) {
+	query := `
+		SELECT id, order_id, COALESCE(from_status, ''), to_status, cause, actor, created_at
+		FROM order_events
+		WHERE order_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := db.db.Query(query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*OrderEvent
+	for rows.Next() {
+		event := &OrderEvent{}
+		err := rows.Scan(
+			&event.ID, &event.OrderID, &event.FromStatus, &event.ToStatus,
+			&event.Cause, &event.Actor, &event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// AmendOrder saves an order's amended terms and records the amendment.
+// Like UpdateOrder it only applies while the order's status is unchanged.
+func (db *PostgreSQLDB) AmendOrder(order *Order, amendment *OrderAmendment) error {
+	query := `
+		UPDATE orders SET
+			source_amount = $2,
+			window_minutes = $3,
+			execution_intervals = $4,
+			max_slippage = $5,
+			refunded_amount = $6,
+			min_received = $8,
+			updated_at = NOW()
+		WHERE id = $1 AND status = $7
+	`
+
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		query,
+		order.ID, order.SourceAmount, order.WindowMinutes,
+		order.ExecutionIntervals, order.MaxSlippage, order.RefundedAmount,
+		order.persistedStatus(), order.MinReceived,
+	)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: order %s is no longer %s", ErrStatusConflict, order.ID, order.persistedStatus())
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO order_amendments (order_id, changes, actor, reason, tx_hash, refund_amount, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7)
+		RETURNING id
+	`,
+		amendment.OrderID, amendment.Changes, amendment.Actor, amendment.Reason,
+		amendment.TxHash, amendment.RefundAmount, amendment.CreatedAt,
+	).Scan(&amendment.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetOrderAmendments returns an order's amendments, oldest first
+func (db *PostgreSQLDB) GetOrderAmendments(orderID string) ([]*OrderAmendment, error) {
+	query := `
+		SELECT id, order_id, changes, actor, COALESCE(reason, ''), tx_hash, refund_amount, created_at
+		FROM order_amendments
+		WHERE order_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := db.db.Query(query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var amendments []*OrderAmendment
+	for rows.Next() {
+		amendment := &OrderAmendment{}
+		err := rows.Scan(
+			&amendment.ID, &amendment.OrderID, &amendment.Changes, &amendment.Actor,
+			&amendment.Reason, &amendment.TxHash, &amendment.RefundAmount, &amendment.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		amendments = append(amendments, amendment)
+	}
+
+	return amendments, rows.Err()
+}
+
+func (db *PostgreSQLDB) GetExecutableOrders() ([]*Order, error) {
+	query := `SELECT ` + orderColumns + `
 		FROM orders 
 		WHERE status IN ('pending', 'executing')
 		AND timeout_height > $1
@@ -360,35 +1056,385 @@ func (db *PostgreSQLDB) GetExecutableOrders() ([]*Order, error) {
 		ORDER BY created_at ASC
 	`
 
-	// Use a mock current block height for now
-	currentHeight := int64(1000000)
+	// Use a mock current block height for now
+	currentHeight := int64(1000000)
+
+	rows, err := db.db.Query(query, currentHeight)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+// GetPausedOrders returns every paused order, oldest pause first
+func (db *PostgreSQLDB) GetPausedOrders() ([]*Order, error) {
+	query := `SELECT ` + orderColumns + `
+		FROM orders
+		WHERE status = 'paused'
+		ORDER BY paused_at ASC
+	`
+
+	rows, err := db.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+// GetOrdersByParent returns the orders a recurring order spawned, oldest
+// first
+func (db *PostgreSQLDB) GetOrdersByParent(parentID string) ([]*Order, error) {
+	query := `SELECT ` + orderColumns + `
+		FROM orders
+		WHERE parent_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := db.db.Query(query, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+// Basket operations
+
+// CreateBasket creates the basket and its orders in one transaction, so
+// either all of them are created or none is
+func (db *PostgreSQLDB) CreateBasket(basket *Basket, orders []*Order) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO baskets (
+			id, user_address, sync_progress, max_progress_drift, metadata,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err = tx.Exec(query, basket.ID, basket.UserAddress, basket.SyncProgress, basket.MaxProgressDrift,
+		basket.Metadata, basket.CreatedAt, basket.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: %s", ErrDuplicateBasket, basket.ID)
+		}
+		return err
+	}
+
+	cause := fmt.Sprintf("order created in basket %s", basket.ID)
+	for _, order := range orders {
+		if err := insertOrder(tx, order, ActorUser, cause); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	db.logger.Info("Basket created",
+		zap.String("basket_id", basket.ID),
+		zap.Int("orders", len(orders)))
+	return nil
+}
+
+func (db *PostgreSQLDB) GetBasket(id string) (*Basket, error) {
+	query := `
+		SELECT id, user_address, sync_progress, max_progress_drift, metadata,
+			created_at, updated_at
+		FROM baskets WHERE id = $1
+	`
+
+	basket := &Basket{}
+	err := db.db.QueryRow(query, id).Scan(
+		&basket.ID, &basket.UserAddress, &basket.SyncProgress, &basket.MaxProgressDrift,
+		&basket.Metadata, &basket.CreatedAt, &basket.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrBasketNotFound
+		}
+		return nil, err
+	}
+
+	return basket, nil
+}
+
+func (db *PostgreSQLDB) GetOrdersByBasket(basketID string) ([]*Order, error) {
+	query := `SELECT ` + orderColumns + `
+		FROM orders
+		WHERE basket_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := db.db.Query(query, basketID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+// Recurring order operations
+
+// recurringColumns are the columns scanRecurringOrder reads, in order
+const recurringColumns = `id, user_address, source_chain, target_chain, source_token,
+			   target_token, target_recipient, schedule, amount_per_run,
+			   window_minutes, execution_intervals, max_slippage, min_fill_size,
+			   enable_mev_protection, htlc_hash, budget, end_at, max_runs,
+			   run_count, spent_amount, next_run_at, last_run_at,
+			   status, metadata, created_at, updated_at`
+
+// scanRecurringOrder scans a row selected with recurringColumns
+func scanRecurringOrder(row rowScanner) (*RecurringOrder, error) {
+	series := &RecurringOrder{}
+	err := row.Scan(
+		&series.ID, &series.UserAddress, &series.SourceChain, &series.TargetChain,
+		&series.SourceToken, &series.TargetToken, &series.TargetRecipient,
+		&series.Schedule, &series.AmountPerRun,
+		&series.WindowMinutes, &series.ExecutionIntervals, &series.MaxSlippage, &series.MinFillSize,
+		&series.EnableMEVProtection, &series.HTLCHash, &series.Budget, &series.EndAt, &series.MaxRuns,
+		&series.RunCount, &series.SpentAmount, &series.NextRunAt, &series.LastRunAt,
+		&series.Status, &series.Metadata, &series.CreatedAt, &series.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	series.readStatus, series.readRunCount = series.Status, series.RunCount
+	return series, nil
+}
+
+// queryRecurringOrders runs a query selecting recurringColumns
+func (db *PostgreSQLDB) queryRecurringOrders(query string, args ...interface{}) ([]*RecurringOrder, error) {
+	rows, err := db.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var series []*RecurringOrder
+	for rows.Next() {
+		s, err := scanRecurringOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		series = append(series, s)
+	}
+
+	return series, rows.Err()
+}
+
+func (db *PostgreSQLDB) CreateRecurringOrder(series *RecurringOrder) error {
+	query := `
+		INSERT INTO recurring_orders (
+			id, user_address, source_chain, target_chain, source_token,
+			target_token, target_recipient, schedule, amount_per_run,
+			window_minutes, execution_intervals, max_slippage, min_fill_size,
+			enable_mev_protection, htlc_hash, budget, end_at, max_runs,
+			next_run_at, status, metadata, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18,
+			$19, $20, $21, $22, $23)
+	`
+
+	_, err := db.db.Exec(
+		query,
+		series.ID, series.UserAddress, series.SourceChain, series.TargetChain, series.SourceToken,
+		series.TargetToken, series.TargetRecipient, series.Schedule, series.AmountPerRun,
+		series.WindowMinutes, series.ExecutionIntervals, series.MaxSlippage, series.MinFillSize,
+		series.EnableMEVProtection, series.HTLCHash, series.Budget, series.EndAt, series.MaxRuns,
+		series.NextRunAt, series.Status, series.Metadata, series.CreatedAt, series.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: %s", ErrDuplicateRecurringOrder, series.ID)
+		}
+		db.logger.Error("Failed to create recurring order", zap.Error(err), zap.String("recurring_order_id", series.ID))
+		return err
+	}
+
+	series.readStatus, series.readRunCount = series.Status, series.RunCount
+	return nil
+}
+
+func (db *PostgreSQLDB) GetRecurringOrder(id string) (*RecurringOrder, error) {
+	query := `SELECT ` + recurringColumns + `
+		FROM recurring_orders WHERE id = $1
+	`
+
+	series, err := scanRecurringOrder(db.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrRecurringOrderNotFound
+		}
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// GetRecurringOrdersByUser returns a page of the user's recurring orders,
+// newest first, and how many they have in all
+func (db *PostgreSQLDB) GetRecurringOrdersByUser(userAddress string, limit, offset int) ([]*RecurringOrder, int64, error) {
+	var total int64
+	if err := db.db.QueryRow(`SELECT COUNT(*) FROM recurring_orders WHERE user_address = $1`, userAddress).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + recurringColumns + `
+		FROM recurring_orders
+		WHERE user_address = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	series, err := db.queryRecurringOrders(query, userAddress, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return series, total, nil
+}
+
+// GetDueRecurringOrders returns the active recurring orders whose next run
+// is at or before now, most overdue first
+func (db *PostgreSQLDB) GetDueRecurringOrders(now time.Time) ([]*RecurringOrder, error) {
+	query := `SELECT ` + recurringColumns + `
+		FROM recurring_orders
+		WHERE status = 'active' AND next_run_at <= $1
+		ORDER BY next_run_at ASC
+	`
+
+	return db.queryRecurringOrders(query, now)
+}
+
+// UpdateRecurringOrder saves the series' schedule and status. Like
+// UpdateOrder it only applies if the stored status and run count are still
+// the ones the series was read with.
+func (db *PostgreSQLDB) UpdateRecurringOrder(series *RecurringOrder) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateRecurringOrder(tx, series); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	series.readStatus, series.readRunCount = series.Status, series.RunCount
+	return nil
+}
+
+// CreateRecurringRun records a run: the order it spawned and the series'
+// updated run count, spend and schedule, together
+func (db *PostgreSQLDB) CreateRecurringRun(series *RecurringOrder, run *Order) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	cause := fmt.Sprintf("run %d of recurring order %s", series.RunCount, series.ID)
+	if err := insertOrder(tx, run, ActorSystem, cause); err != nil {
+		return err
+	}
+	if err := updateRecurringOrder(tx, series); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	series.readStatus, series.readRunCount = series.Status, series.RunCount
+	db.logger.Info("Recurring run created",
+		zap.String("recurring_order_id", series.ID),
+		zap.String("order_id", run.ID))
+	return nil
+}
+
+func updateRecurringOrder(tx *sql.Tx, series *RecurringOrder) error {
+	query := `
+		UPDATE recurring_orders SET
+			run_count = $2,
+			spent_amount = $3,
+			next_run_at = $4,
+			last_run_at = $5,
+			status = $6,
+			updated_at = NOW()
+		WHERE id = $1 AND status = $7 AND run_count = $8
+	`
 
-	rows, err := db.db.Query(query, currentHeight)
+	result, err := tx.Exec(
+		query,
+		series.ID, series.RunCount, series.SpentAmount, series.NextRunAt, series.LastRunAt,
+		series.Status, series.readStatus, series.readRunCount,
+	)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
 
-	var orders []*Order
-	for rows.Next() {
-		order := &Order{}
-		err := rows.Scan(
-			&order.ID, &order.UserAddress, &order.SourceChain, &order.TargetChain,
-			&order.SourceToken, &order.SourceAmount, &order.TargetToken,
-			&order.TargetRecipient, &order.MinReceived, &order.WindowMinutes,
-			&order.ExecutionIntervals, &order.MaxSlippage, &order.MinFillSize,
-			&order.EnableMEVProtection, &order.HTLCHash, &order.TimeoutHeight,
-			&order.TimeoutTimestamp, &order.CreatedAt, &order.UpdatedAt,
-			&order.ExecutedAmount, &order.LastExecution, &order.Status,
-			&order.AveragePrice, &order.Metadata,
-		)
-		if err != nil {
-			return nil, err
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM recurring_orders WHERE id = $1)`, series.ID).Scan(&exists); err != nil {
+			return err
 		}
-		orders = append(orders, order)
+		if !exists {
+			return ErrRecurringOrderNotFound
+		}
+		return ErrStatusConflict
 	}
 
-	return orders, nil
+	return nil
 }
 
 // Execution history operations
@@ -396,8 +1442,10 @@ func (db *PostgreSQLDB) CreateExecutionRecord(record *ExecutionRecord) error {
 	query := `
 		INSERT INTO execution_history (
 			order_id, interval_number, timestamp, amount, price,
-			gas_used, slippage, tx_hash, chain_id
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			gas_used, slippage, tx_hash, chain_id,
+			submission_route, bundle_hash, relay_attempts, inclusion_block,
+			gas_price, gas_cost_native, gas_cost_quote
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	`
 
 	_, err := db.db.Exec(
@@ -405,6 +1453,8 @@ func (db *PostgreSQLDB) CreateExecutionRecord(record *ExecutionRecord) error {
 		record.OrderID, record.IntervalNumber, record.Timestamp,
 		record.Amount, record.Price, record.GasUsed, record.Slippage,
 		record.TxHash, record.ChainID,
+		record.SubmissionRoute, record.BundleHash, record.RelayAttempts, record.InclusionBlock,
+		record.GasPrice, record.GasCostNative, record.GasCostQuote,
 	)
 
 	return err
@@ -413,7 +1463,9 @@ func (db *PostgreSQLDB) CreateExecutionRecord(record *ExecutionRecord) error {
 func (db *PostgreSQLDB) GetExecutionHistory(orderID string) ([]*ExecutionRecord, error) {
 	query := `
 		SELECT id, order_id, interval_number, timestamp, amount, price,
-			   gas_used, slippage, tx_hash, chain_id
+			   gas_used, slippage, tx_hash, chain_id,
+			   submission_route, bundle_hash, relay_attempts, inclusion_block,
+			   gas_price, gas_cost_native, gas_cost_quote
 		FROM execution_history 
 		WHERE order_id = $1 
 		ORDER BY interval_number ASC
@@ -432,6 +1484,8 @@ func (db *PostgreSQLDB) GetExecutionHistory(orderID string) ([]*ExecutionRecord,
 			&record.ID, &record.OrderID, &record.IntervalNumber,
 			&record.Timestamp, &record.Amount, &record.Price,
 			&record.GasUsed, &record.Slippage, &record.TxHash, &record.ChainID,
+			&record.SubmissionRoute, &record.BundleHash, &record.RelayAttempts, &record.InclusionBlock,
+			&record.GasPrice, &record.GasCostNative, &record.GasCostQuote,
 		)
 		if err != nil {
 			return nil, err
@@ -458,35 +1512,11 @@ func (db *PostgreSQLDB) CreatePricePoint(point *PricePoint) error {
 	return err
 }
 
+// GetPriceHistory returns the raw price points the feeds recorded for a
+// pair in the last windowMinutes, oldest first. Raw points are kept for
+// PRICE_RETENTION_RAW; older history is served from candles.
 func (db *PostgreSQLDB) GetPriceHistory(tokenPair string, windowMinutes int) ([]*PricePoint, error) {
-	query := `
-		SELECT id, token_pair, timestamp, price, volume, source, chain_id
-		FROM price_history 
-		WHERE token_pair = $1 
-		AND timestamp >= NOW() - INTERVAL '%d minutes'
-		ORDER BY timestamp ASC
-	`
-
-	rows, err := db.db.Query(fmt.Sprintf(query, windowMinutes), tokenPair)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var points []*PricePoint
-	for rows.Next() {
-		point := &PricePoint{}
-		err := rows.Scan(
-			&point.ID, &point.TokenPair, &point.Timestamp,
-			&point.Price, &point.Volume, &point.Source, &point.ChainID,
-		)
-		if err != nil {
-			return nil, err
-		}
-		points = append(points, point)
-	}
-
-	return points, nil
+	return db.GetPricePoints(tokenPair, time.Now().Add(-time.Duration(windowMinutes)*time.Minute))
 }
 
 func (db *PostgreSQLDB) UpdatePriceHistory(tokenPair string, points []*PricePoint) error {
@@ -565,6 +1595,40 @@ func (db *PostgreSQLDB) GetHTLC(htlcAddress string) (*HTLC, error) {
 	return htlc, nil
 }
 
+// GetHTLCsByOrder returns the HTLCs locked for an order
+func (db *PostgreSQLDB) GetHTLCsByOrder(orderID string) ([]*HTLC, error) {
+	query := `
+		SELECT address, order_id, hashed_secret, amount, token,
+			   sender, receiver, timeout_height, timeout_timestamp,
+			   status, created_at, claimed_at, secret, chain_id
+		FROM htlcs WHERE order_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := db.db.Query(query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var htlcs []*HTLC
+	for rows.Next() {
+		htlc := &HTLC{}
+		err := rows.Scan(
+			&htlc.Address, &htlc.OrderID, &htlc.HashedSecret, &htlc.Amount,
+			&htlc.Token, &htlc.Sender, &htlc.Receiver, &htlc.TimeoutHeight,
+			&htlc.TimeoutTimestamp, &htlc.Status, &htlc.CreatedAt,
+			&htlc.ClaimedAt, &htlc.Secret, &htlc.ChainID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		htlcs = append(htlcs, htlc)
+	}
+
+	return htlcs, rows.Err()
+}
+
 func (db *PostgreSQLDB) UpdateHTLC(htlc *HTLC) error {
 	query := `
 		UPDATE htlcs SET
@@ -607,7 +1671,8 @@ func (db *PostgreSQLDB) GetSupportedChains() ([]string, error) {
 func (db *PostgreSQLDB) GetChainStatus(chainID string) (*ChainStatus, error) {
 	query := `
 		SELECT chain_id, name, enabled, last_block_height, last_block_time,
-			   avg_block_time, gas_price, health_status, last_health_check
+			   avg_block_time, gas_price, health_status, last_health_check,
+			   peer_count, error_message
 		FROM chain_status WHERE chain_id = $1
 	`
 
@@ -619,6 +1684,7 @@ func (db *PostgreSQLDB) GetChainStatus(chainID string) (*ChainStatus, error) {
 		&status.LastBlockHeight, &status.LastBlockTime,
 		&status.AvgBlockTime, &status.GasPrice,
 		&status.HealthStatus, &status.LastHealthCheck,
+		&status.PeerCount, &status.ErrorMessage,
 	)
 
 	if err != nil {
@@ -631,6 +1697,71 @@ func (db *PostgreSQLDB) GetChainStatus(chainID string) (*ChainStatus, error) {
 	return status, nil
 }
 
+// UpdateChainStatus records a polled chain status. AvgBlockTime must be a
+// PostgreSQL interval such as "12.5 seconds".
+func (db *PostgreSQLDB) UpdateChainStatus(status *ChainStatus) error {
+	query := `
+		INSERT INTO chain_status (
+			chain_id, name, last_block_height, last_block_time, avg_block_time,
+			gas_price, health_status, last_health_check, peer_count, error_message
+		) VALUES ($1, $2, $3, $4, CAST($5 AS INTERVAL), $6, $7, $8, $9, $10)
+		ON CONFLICT (chain_id)
+		DO UPDATE SET name = EXCLUDED.name,
+			last_block_height = COALESCE(EXCLUDED.last_block_height, chain_status.last_block_height),
+			last_block_time = COALESCE(EXCLUDED.last_block_time, chain_status.last_block_time),
+			avg_block_time = COALESCE(EXCLUDED.avg_block_time, chain_status.avg_block_time),
+			gas_price = COALESCE(EXCLUDED.gas_price, chain_status.gas_price),
+			health_status = EXCLUDED.health_status,
+			last_health_check = EXCLUDED.last_health_check,
+			peer_count = COALESCE(EXCLUDED.peer_count, chain_status.peer_count),
+			error_message = EXCLUDED.error_message
+	`
+
+	_, err := db.db.Exec(
+		query,
+		status.ChainID, status.Name, status.LastBlockHeight, status.LastBlockTime,
+		status.AvgBlockTime, status.GasPrice, status.HealthStatus,
+		status.LastHealthCheck, status.PeerCount, status.ErrorMessage,
+	)
+
+	return err
+}
+
+// GetChainMetrics combines the last polled status of a chain with the
+// orders and executions on it. Only successful executions are recorded,
+// so FailedExecutions is zero.
+func (db *PostgreSQLDB) GetChainMetrics(chainID string) (*ChainMetrics, error) {
+	query := `
+		SELECT cs.chain_id, cs.name,
+			COALESCE(cs.last_block_height, 0),
+			COALESCE(EXTRACT(EPOCH FROM cs.avg_block_time), 0),
+			COALESCE(cs.gas_price, 0),
+			cs.health_status, cs.last_health_check,
+			(SELECT COUNT(*) FROM orders o WHERE o.source_chain = cs.chain_id OR o.target_chain = cs.chain_id),
+			(SELECT COALESCE(SUM(o.source_amount), 0) FROM orders o WHERE o.source_chain = cs.chain_id),
+			(SELECT COUNT(*) FROM execution_history e WHERE e.chain_id = cs.chain_id)
+		FROM chain_status cs WHERE cs.chain_id = $1
+	`
+
+	metrics := &ChainMetrics{}
+	var avgBlockSeconds float64
+	err := db.db.QueryRow(query, chainID).Scan(
+		&metrics.ChainID, &metrics.Name,
+		&metrics.CurrentBlockHeight, &avgBlockSeconds, &metrics.GasPrice,
+		&metrics.HealthStatus, &metrics.LastHealthCheck,
+		&metrics.OrderCount, &metrics.TotalVolume, &metrics.SuccessfulExecutions,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrChainNotFound
+		}
+		return nil, err
+	}
+
+	metrics.AverageBlockTime = time.Duration(avgBlockSeconds * float64(time.Second))
+	return metrics, nil
+}
+
 func (db *PostgreSQLDB) StorePricePoint(point *PricePoint) error {
     query := `
         INSERT INTO price_points (token_pair, source, price, volume, timestamp, created_at)
@@ -684,6 +1815,43 @@ func (db *PostgreSQLDB) GetPricePoints(tokenPair string, since time.Time) ([]*Pr
     return points, nil
 }
 
+// GetPricePointsBetween returns a pair's recorded price points within
+// [from, to], oldest first
+func (db *PostgreSQLDB) GetPricePointsBetween(tokenPair string, from, to time.Time) ([]*PricePoint, error) {
+	query := `
+		SELECT id, token_pair, source, price, volume, timestamp, created_at
+		FROM price_points
+		WHERE token_pair = $1 AND timestamp >= $2 AND timestamp <= $3
+		ORDER BY timestamp ASC
+	`
+
+	rows, err := db.db.Query(query, tokenPair, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []*PricePoint
+	for rows.Next() {
+		point := &PricePoint{}
+		err := rows.Scan(
+			&point.ID,
+			&point.TokenPair,
+			&point.Source,
+			&point.Price,
+			&point.Volume,
+			&point.Timestamp,
+			&point.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+
+	return points, rows.Err()
+}
+
 func (db *PostgreSQLDB) GetLatestPrice(tokenPair, source string) (*PricePoint, error) {
     query := `
         SELECT id, token_pair, source, price, volume, timestamp, created_at
@@ -730,13 +1898,283 @@ func (db *PostgreSQLDB) CleanupOldPricePoints(olderThan time.Time) error {
     }
     
     if rowsAffected > 0 {
-        // Log cleanup activity
-        fmt.Printf("Cleaned up %d old price points\n", rowsAffected)
+        db.logger.Info("Cleaned up old price points", zap.Int64("rows", rowsAffected))
     }
     
     return nil
 }
 
+// Candle operations
+func (db *PostgreSQLDB) UpsertCandles(candles []*Candle) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO price_candles (
+			token_pair, resolution, bucket_start, open, high, low, close, volume, num_points
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (token_pair, resolution, bucket_start)
+		DO UPDATE SET open = EXCLUDED.open, high = EXCLUDED.high, low = EXCLUDED.low,
+			close = EXCLUDED.close, volume = EXCLUDED.volume, num_points = EXCLUDED.num_points
+	`
+
+	for _, candle := range candles {
+		_, err = tx.Exec(
+			query,
+			candle.TokenPair, candle.Resolution, candle.BucketStart,
+			candle.Open, candle.High, candle.Low, candle.Close,
+			candle.Volume, candle.NumPoints,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RefreshStatsRollups recomputes the statistics rollups. Concurrent
+// refreshes keep the rollups readable while they run.
+func (db *PostgreSQLDB) RefreshStatsRollups() error {
+	for _, view := range []string{"stats_executions_hourly", "stats_orders_hourly"} {
+		if _, err := db.db.Exec(`REFRESH MATERIALIZED VIEW CONCURRENTLY ` + view); err != nil {
+			return fmt.Errorf("failed to refresh %s: %w", view, err)
+		}
+	}
+	return nil
+}
+
+// GetExecutionRollups returns the hourly execution rollups since a time,
+// regrouped into buckets of the given width, oldest first
+func (db *PostgreSQLDB) GetExecutionRollups(bucket StatsBucket, since time.Time) ([]*ExecutionRollup, error) {
+	query := `
+		SELECT date_trunc($1, bucket), chain_id, token_pair,
+			   SUM(executions), SUM(volume), SUM(received),
+			   SUM(slippage_sum), SUM(slippage_count), SUM(gas_cost_quote)
+		FROM stats_executions_hourly
+		WHERE bucket >= $2
+		GROUP BY 1, 2, 3
+		ORDER BY 1 ASC, 2 ASC, 3 ASC
+	`
+
+	rows, err := db.db.Query(query, string(bucket), since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rollups []*ExecutionRollup
+	for rows.Next() {
+		rollup := &ExecutionRollup{}
+		err := rows.Scan(
+			&rollup.Bucket, &rollup.ChainID, &rollup.TokenPair,
+			&rollup.Executions, &rollup.Volume, &rollup.Received,
+			&rollup.SlippageSum, &rollup.SlippageCount, &rollup.GasCostQuote,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, rollup)
+	}
+
+	return rollups, rows.Err()
+}
+
+// GetOrderRollups returns the hourly order rollups since a time, regrouped
+// into buckets of the given width, oldest first
+func (db *PostgreSQLDB) GetOrderRollups(bucket StatsBucket, since time.Time) ([]*OrderRollup, error) {
+	query := `
+		SELECT date_trunc($1, bucket), source_chain, target_chain, token_pair, status,
+			   SUM(orders), SUM(source_amount), SUM(executed_amount),
+			   SUM(filled_orders), SUM(fill_latency_seconds),
+			   SUM(completed_orders), SUM(completion_time_seconds)
+		FROM stats_orders_hourly
+		WHERE bucket >= $2
+		GROUP BY 1, 2, 3, 4, 5
+		ORDER BY 1 ASC, 2 ASC, 3 ASC, 4 ASC, 5 ASC
+	`
+
+	rows, err := db.db.Query(query, string(bucket), since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rollups []*OrderRollup
+	for rows.Next() {
+		rollup := &OrderRollup{}
+		err := rows.Scan(
+			&rollup.Bucket, &rollup.SourceChain, &rollup.TargetChain, &rollup.TokenPair, &rollup.Status,
+			&rollup.Orders, &rollup.SourceAmount, &rollup.ExecutedAmount,
+			&rollup.FilledOrders, &rollup.FillLatencySeconds,
+			&rollup.CompletedOrders, &rollup.CompletionTimeSeconds,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, rollup)
+	}
+
+	return rollups, rows.Err()
+}
+
+func (db *PostgreSQLDB) GetCandles(tokenPair string, resolution CandleResolution, since time.Time) ([]*Candle, error) {
+	query := `
+		SELECT token_pair, resolution, bucket_start, open, high, low, close, volume, num_points
+		FROM price_candles
+		WHERE token_pair = $1 AND resolution = $2 AND bucket_start >= $3
+		ORDER BY bucket_start ASC
+	`
+
+	rows, err := db.db.Query(query, tokenPair, resolution, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candles []*Candle
+	for rows.Next() {
+		candle := &Candle{}
+		err := rows.Scan(
+			&candle.TokenPair, &candle.Resolution, &candle.BucketStart,
+			&candle.Open, &candle.High, &candle.Low, &candle.Close,
+			&candle.Volume, &candle.NumPoints,
+		)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, candle)
+	}
+
+	return candles, nil
+}
+
+func (db *PostgreSQLDB) CleanupOldCandles(resolution CandleResolution, olderThan time.Time) error {
+	query := `DELETE FROM price_candles WHERE resolution = $1 AND bucket_start < $2`
+
+	result, err := db.db.Exec(query, resolution, olderThan)
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected > 0 {
+		db.logger.Info("Cleaned up old candles",
+			zap.String("resolution", string(resolution)),
+			zap.Int64("rows", rowsAffected))
+	}
+
+	return nil
+}
+
+// Idempotency key operations
+
+// ReserveIdempotencyKey claims key for a request about to run. It returns
+// nil once reserved, or the live record of an earlier request made with the
+// same key. An expired record is replaced.
+func (db *PostgreSQLDB) ReserveIdempotencyKey(key *IdempotencyKey) (*IdempotencyKey, error) {
+	query := `
+		INSERT INTO idempotency_keys (
+			user_address, idempotency_key, request_path, fingerprint,
+			status_code, response_body, created_at, expires_at
+		) VALUES ($1, $2, $3, $4, 0, NULL, $5, $6)
+		ON CONFLICT (user_address, idempotency_key) DO UPDATE SET
+			request_path = EXCLUDED.request_path,
+			fingerprint = EXCLUDED.fingerprint,
+			status_code = 0,
+			response_body = NULL,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+	`
+
+	result, err := db.db.Exec(query, key.UserAddress, key.Key, key.RequestPath, key.Fingerprint,
+		key.CreatedAt, key.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil || rowsAffected == 1 {
+		return nil, err
+	}
+
+	existing := &IdempotencyKey{}
+	err = db.db.QueryRow(`
+		SELECT user_address, idempotency_key, request_path, fingerprint,
+			status_code, response_body, created_at, expires_at
+		FROM idempotency_keys WHERE user_address = $1 AND idempotency_key = $2
+	`, key.UserAddress, key.Key).Scan(
+		&existing.UserAddress, &existing.Key, &existing.RequestPath, &existing.Fingerprint,
+		&existing.StatusCode, &existing.ResponseBody, &existing.CreatedAt, &existing.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// CompleteIdempotencyKey stores the response to a reserved key's request
+func (db *PostgreSQLDB) CompleteIdempotencyKey(key *IdempotencyKey) error {
+	query := `
+		UPDATE idempotency_keys SET status_code = $3, response_body = $4
+		WHERE user_address = $1 AND idempotency_key = $2 AND status_code = 0
+	`
+
+	result, err := db.db.Exec(query, key.UserAddress, key.Key, key.StatusCode, key.ResponseBody)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rowsAffected == 0 {
+		return ErrIdempotencyKeyNotFound
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey drops a reservation whose request failed, so a
+// retry runs it again
+func (db *PostgreSQLDB) ReleaseIdempotencyKey(userAddress, key string) error {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE user_address = $1 AND idempotency_key = $2 AND status_code = 0
+	`
+
+	result, err := db.db.Exec(query, userAddress, key)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rowsAffected == 0 {
+		return ErrIdempotencyKeyNotFound
+	}
+	return nil
+}
+
+// CleanupExpiredIdempotencyKeys deletes keys past their TTL
+func (db *PostgreSQLDB) CleanupExpiredIdempotencyKeys(now time.Time) error {
+	query := `DELETE FROM idempotency_keys WHERE expires_at <= $1`
+
+	result, err := db.db.Exec(query, now)
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected > 0 {
+		db.logger.Info("Cleaned up expired idempotency keys", zap.Int64("rows", rowsAffected))
+	}
+
+	return nil
+}
+
+// isUniqueViolation checks if err is a PostgreSQL unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
 // Health check
 func (db *PostgreSQLDB) Health() error {
 	return db.db.Ping()
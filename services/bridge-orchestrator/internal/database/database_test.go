@@ -0,0 +1,47 @@
+package database
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestDecodeOrderCursor(t *testing.T) {
+	created := orderCursor{
+		SortBy: OrderSortCreatedAt,
+		Value:  "2024-01-01 12:00:00.123456+00",
+		ID:     "ord_1",
+	}
+	cursor, err := decodeOrderCursor(encodeOrderCursor(created), OrderSortCreatedAt, false)
+	if err != nil || cursor != created {
+		t.Fatalf("round trip gave %+v, %v", cursor, err)
+	}
+
+	amount := orderCursor{SortBy: OrderSortSourceAmount, Ascending: true, Value: "1500.25", ID: "ord_2"}
+	if _, err := decodeOrderCursor(encodeOrderCursor(amount), OrderSortSourceAmount, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	invalid := map[string]struct {
+		encoded   string
+		sortBy    OrderSortField
+		ascending bool
+	}{
+		"not base64":        {"!!", OrderSortCreatedAt, false},
+		"not JSON":          {base64.RawURLEncoding.EncodeToString([]byte("{")), OrderSortCreatedAt, false},
+		"another field":     {encodeOrderCursor(created), OrderSortUpdatedAt, false},
+		"another direction": {encodeOrderCursor(created), OrderSortCreatedAt, true},
+		"no sort":           {encodeOrderCursor(orderCursor{Value: created.Value, ID: "ord_1"}), OrderSortCreatedAt, false},
+		"bad timestamp": {encodeOrderCursor(orderCursor{SortBy: OrderSortCreatedAt, Value: "yesterday", ID: "ord_1"}),
+			OrderSortCreatedAt, false},
+		"bad number": {encodeOrderCursor(orderCursor{SortBy: OrderSortCompletionRate, Value: "0.5; DROP", ID: "ord_1"}),
+			OrderSortCompletionRate, false},
+		"timestamp for a number": {encodeOrderCursor(orderCursor{SortBy: OrderSortSourceAmount, Value: created.Value, ID: "ord_1"}),
+			OrderSortSourceAmount, false},
+	}
+	for name, tc := range invalid {
+		if _, err := decodeOrderCursor(tc.encoded, tc.sortBy, tc.ascending); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: got %v, want %v", name, err, ErrInvalidCursor)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrIdempotencyKeyNotFound is returned when an idempotency key has no
+// reservation to complete or release
+var ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+
+// IdempotencyKey records a request made with an Idempotency-Key header so
+// retries replay its response. Keys are scoped to the user that sent them.
+// A key whose StatusCode is zero is reserved by a request still in flight.
+type IdempotencyKey struct {
+	Key          string    `json:"key" db:"idempotency_key"`
+	UserAddress  string    `json:"user_address" db:"user_address"`
+	RequestPath  string    `json:"request_path" db:"request_path"`
+	Fingerprint  string    `json:"fingerprint" db:"fingerprint"`
+	StatusCode   int       `json:"status_code" db:"status_code"`
+	ResponseBody []byte    `json:"-" db:"response_body"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// Completed checks if the key's response has been stored
+func (k *IdempotencyKey) Completed() bool {
+	return k.StatusCode != 0
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidTransition is returned when an order may not move between two
+// statuses
+var ErrInvalidTransition = errors.New("invalid order status transition")
+
+// ErrStatusConflict is returned when an order's status changed in the
+// database since it was read
+var ErrStatusConflict = errors.New("order status changed concurrently")
+
+// Who caused an order's status to change
+const (
+	ActorSystem = "system"
+	ActorUser   = "user"
+	ActorChain  = "chain"
+)
+
+// orderTransitions is the order lifecycle: the statuses each status may
+// move to. An order is executing once its first interval has filled and
+// partially filled when its intervals ran out below MinReceived. A user's
+// cancellation holds the order in cancelling until it is cancelled on-chain.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending: {
+		OrderStatusExecuting, OrderStatusPartiallyFilled, OrderStatusCompleted,
+		OrderStatusPaused, OrderStatusCancelling, OrderStatusCancelled, OrderStatusExpired,
+	},
+	OrderStatusExecuting: {
+		OrderStatusPartiallyFilled, OrderStatusCompleted,
+		OrderStatusPaused, OrderStatusCancelling, OrderStatusCancelled, OrderStatusExpired,
+	},
+	OrderStatusPaused: {
+		OrderStatusPending, OrderStatusExecuting,
+		OrderStatusCancelling, OrderStatusCancelled, OrderStatusExpired,
+	},
+	OrderStatusPartiallyFilled: {
+		OrderStatusCancelling, OrderStatusCancelled, OrderStatusExpired, OrderStatusRefunded,
+	},
+	OrderStatusCancelling: {OrderStatusCancelled},
+	OrderStatusCompleted:  {OrderStatusClaimed},
+	OrderStatusCancelled:  {OrderStatusRefunded},
+	OrderStatusExpired:    {OrderStatusRefunded},
+	OrderStatusRefunded:   {},
+	OrderStatusClaimed:    {},
+}
+
+// OrderEvent records one change of an order's status. FromStatus is empty
+// for the event created with the order.
+type OrderEvent struct {
+	ID         int64     `json:"id" db:"id"`
+	OrderID    string    `json:"order_id" db:"order_id"`
+	FromStatus string    `json:"from_status,omitempty" db:"from_status"`
+	ToStatus   string    `json:"to_status" db:"to_status"`
+	Cause      string    `json:"cause" db:"cause"`
+	Actor      string    `json:"actor" db:"actor"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// CanTransition checks if an order may move from one status to another.
+// Staying in the same status is always allowed.
+func CanTransition(from, to OrderStatus) bool {
+	if from == to {
+		return IsValidOrderStatus(string(to))
+	}
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminalStatus checks if no further transition is possible from status
+func IsTerminalStatus(status OrderStatus) bool {
+	next, ok := orderTransitions[status]
+	return ok && len(next) == 0
+}
+
+// Transition moves the order to status, recording why and who caused it.
+// The event is persisted by the next UpdateOrder. Moving to the current
+// status is a no-op.
+func (o *Order) Transition(to OrderStatus, actor, cause string) error {
+	return o.transition(to, actor, cause, time.Now())
+}
+
+func (o *Order) transition(to OrderStatus, actor, cause string, now time.Time) error {
+	from := OrderStatus(o.Status)
+	if from == to {
+		return nil
+	}
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+	}
+
+	o.events = append(o.events, &OrderEvent{
+		OrderID:    o.ID,
+		FromStatus: string(from),
+		ToStatus:   string(to),
+		Cause:      cause,
+		Actor:      actor,
+		CreatedAt:  now,
+	})
+	o.Status = string(to)
+	o.UpdatedAt = now
+	return nil
+}
+
+// PendingEvents returns the transitions not yet persisted
+func (o *Order) PendingEvents() []*OrderEvent {
+	return o.events
+}
+
+// persistedStatus is the status the order had when it was read, before any
+// pending transition
+func (o *Order) persistedStatus() string {
+	if len(o.events) > 0 {
+		return o.events[0].FromStatus
+	}
+	return o.Status
+}
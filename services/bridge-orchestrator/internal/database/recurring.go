@@ -0,0 +1,135 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrRecurringOrderNotFound is returned when a recurring order does not exist
+	ErrRecurringOrderNotFound = errors.New("recurring order not found")
+	// ErrDuplicateRecurringOrder is returned when a recurring order ID is taken
+	ErrDuplicateRecurringOrder = errors.New("duplicate recurring order")
+)
+
+// RecurringStatus represents the states of a recurring order
+type RecurringStatus string
+
+const (
+	RecurringStatusActive    RecurringStatus = "active"
+	RecurringStatusPaused    RecurringStatus = "paused"
+	RecurringStatusCancelled RecurringStatus = "cancelled"
+	RecurringStatusCompleted RecurringStatus = "completed"
+)
+
+// RecurringOrder is a series of TWAP orders, one spawned per run of its
+// cron schedule. Each run sells AmountPerRun over a WindowMinutes TWAP. The
+// series completes at EndAt, after MaxRuns runs, or once another run would
+// exceed Budget. Its runs share HTLCHash.
+type RecurringOrder struct {
+	ID                  string          `json:"id" db:"id"`
+	UserAddress         string          `json:"user_address" db:"user_address"`
+	SourceChain         string          `json:"source_chain" db:"source_chain"`
+	TargetChain         string          `json:"target_chain" db:"target_chain"`
+	SourceToken         string          `json:"source_token" db:"source_token"`
+	TargetToken         string          `json:"target_token" db:"target_token"`
+	TargetRecipient     string          `json:"target_recipient" db:"target_recipient"`
+	Schedule            string          `json:"schedule" db:"schedule"`
+	AmountPerRun        decimal.Decimal `json:"amount_per_run" db:"amount_per_run"`
+	WindowMinutes       int             `json:"window_minutes" db:"window_minutes"`
+	ExecutionIntervals  int             `json:"execution_intervals" db:"execution_intervals"`
+	MaxSlippage         int             `json:"max_slippage" db:"max_slippage"`
+	MinFillSize         decimal.Decimal `json:"min_fill_size" db:"min_fill_size"`
+	EnableMEVProtection bool            `json:"enable_mev_protection" db:"enable_mev_protection"`
+	HTLCHash            string          `json:"htlc_hash" db:"htlc_hash"`
+	Budget              decimal.Decimal `json:"budget" db:"budget"`
+	EndAt               *time.Time      `json:"end_at,omitempty" db:"end_at"`
+	MaxRuns             *int            `json:"max_runs,omitempty" db:"max_runs"`
+	RunCount            int             `json:"run_count" db:"run_count"`
+	SpentAmount         decimal.Decimal `json:"spent_amount" db:"spent_amount"`
+	NextRunAt           *time.Time      `json:"next_run_at,omitempty" db:"next_run_at"`
+	LastRunAt           *time.Time      `json:"last_run_at,omitempty" db:"last_run_at"`
+	Status              string          `json:"status" db:"status"`
+	Metadata            Metadata        `json:"metadata" db:"metadata"`
+	CreatedAt           time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time       `json:"updated_at" db:"updated_at"`
+
+	// Status and run count as read, which UpdateRecurringOrder requires to
+	// be unchanged in the database
+	readStatus   string
+	readRunCount int
+}
+
+// RemainingBudget is the budget not yet committed to runs
+func (r *RecurringOrder) RemainingBudget() decimal.Decimal {
+	return r.Budget.Sub(r.SpentAmount)
+}
+
+// Exhausted checks if the series' end condition is met at now
+func (r *RecurringOrder) Exhausted(now time.Time) bool {
+	switch {
+	case r.EndAt != nil && !now.Before(*r.EndAt):
+		return true
+	case r.MaxRuns != nil && r.RunCount >= *r.MaxRuns:
+		return true
+	default:
+		return r.RemainingBudget().LessThan(r.AmountPerRun)
+	}
+}
+
+// RecordRun books a run spawned at now and schedules the next one. With no
+// next run the series is completed.
+func (r *RecurringOrder) RecordRun(now time.Time, next *time.Time) {
+	r.RunCount++
+	r.SpentAmount = r.SpentAmount.Add(r.AmountPerRun)
+	r.LastRunAt = &now
+	r.Reschedule(now, next)
+}
+
+// Reschedule sets the next run, completing the series if there is none or
+// its end condition is met by then
+func (r *RecurringOrder) Reschedule(now time.Time, next *time.Time) {
+	r.NextRunAt = next
+	r.UpdatedAt = now
+	if next == nil || r.Exhausted(*next) {
+		r.NextRunAt = nil
+		r.Status = string(RecurringStatusCompleted)
+	}
+}
+
+// Pause stops the series spawning runs
+func (r *RecurringOrder) Pause(now time.Time) error {
+	if r.Status != string(RecurringStatusActive) {
+		return fmt.Errorf("%w: recurring order is %s", ErrInvalidTransition, r.Status)
+	}
+	r.Status = string(RecurringStatusPaused)
+	r.NextRunAt = nil
+	r.UpdatedAt = now
+	return nil
+}
+
+// Resume restarts a paused series from its next scheduled run
+func (r *RecurringOrder) Resume(now time.Time, next *time.Time) error {
+	if r.Status != string(RecurringStatusPaused) {
+		return fmt.Errorf("%w: recurring order is %s", ErrInvalidTransition, r.Status)
+	}
+	r.Status = string(RecurringStatusActive)
+	r.Reschedule(now, next)
+	return nil
+}
+
+// Cancel ends the series; runs already spawned are left to the caller
+func (r *RecurringOrder) Cancel(now time.Time) error {
+	switch RecurringStatus(r.Status) {
+	case RecurringStatusActive, RecurringStatusPaused:
+	default:
+		return fmt.Errorf("%w: recurring order is %s", ErrInvalidTransition, r.Status)
+	}
+	r.Status = string(RecurringStatusCancelled)
+	r.NextRunAt = nil
+	r.UpdatedAt = now
+	return nil
+}
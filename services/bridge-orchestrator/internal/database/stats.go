@@ -0,0 +1,60 @@
+package database
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// StatsBucket is the width of the time buckets stats are grouped into
+type StatsBucket string
+
+const (
+	StatsBucketHour  StatsBucket = "hour"
+	StatsBucketDay   StatsBucket = "day"
+	StatsBucketWeek  StatsBucket = "week"
+	StatsBucketMonth StatsBucket = "month"
+)
+
+// IsValidStatsBucket checks if bucket is a supported bucket width
+func IsValidStatsBucket(bucket string) bool {
+	switch StatsBucket(bucket) {
+	case StatsBucketHour, StatsBucketDay, StatsBucketWeek, StatsBucketMonth:
+		return true
+	default:
+		return false
+	}
+}
+
+// ExecutionRollup aggregates the intervals executed in one bucket on one
+// chain for one pair. Volume is in source token base units and Received
+// in target token base units.
+type ExecutionRollup struct {
+	Bucket        time.Time       `json:"bucket" db:"bucket"`
+	ChainID       string          `json:"chain_id" db:"chain_id"`
+	TokenPair     string          `json:"token_pair" db:"token_pair"`
+	Executions    int64           `json:"executions" db:"executions"`
+	Volume        decimal.Decimal `json:"volume" db:"volume"`
+	Received      decimal.Decimal `json:"received" db:"received"`
+	SlippageSum   int64           `json:"slippage_sum" db:"slippage_sum"` // basis points
+	SlippageCount int64           `json:"slippage_count" db:"slippage_count"`
+	GasCostQuote  decimal.Decimal `json:"gas_cost_quote" db:"gas_cost_quote"`
+}
+
+// OrderRollup aggregates the orders created in one bucket by route, pair
+// and current status. Fill latency runs from an order's activation to its
+// first fill, completion time to its last fill for completed orders.
+type OrderRollup struct {
+	Bucket                time.Time       `json:"bucket" db:"bucket"`
+	SourceChain           string          `json:"source_chain" db:"source_chain"`
+	TargetChain           string          `json:"target_chain" db:"target_chain"`
+	TokenPair             string          `json:"token_pair" db:"token_pair"`
+	Status                string          `json:"status" db:"status"`
+	Orders                int64           `json:"orders" db:"orders"`
+	SourceAmount          decimal.Decimal `json:"source_amount" db:"source_amount"`
+	ExecutedAmount        decimal.Decimal `json:"executed_amount" db:"executed_amount"`
+	FilledOrders          int64           `json:"filled_orders" db:"filled_orders"`
+	FillLatencySeconds    float64         `json:"fill_latency_seconds" db:"fill_latency_seconds"` // summed over filled orders
+	CompletedOrders       int64           `json:"completed_orders" db:"completed_orders"`
+	CompletionTimeSeconds float64         `json:"completion_time_seconds" db:"completion_time_seconds"` // summed over completed orders
+}
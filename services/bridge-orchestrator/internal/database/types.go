@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -35,6 +36,60 @@ type Order struct {
 	Status              string          `json:"status" db:"status"`
 	AveragePrice        decimal.Decimal `json:"average_price" db:"average_price"`
 	Metadata            Metadata        `json:"metadata" db:"metadata"`
+
+	// ChainOrderID is the id the source chain's bridge assigned the order,
+	// set once the order is created there. Later calls to the bridge name
+	// the order by it.
+	ChainOrderID *string `json:"chain_order_id,omitempty" db:"chain_order_id"`
+
+	// Cumulative gas paid across intervals, in the target chain's native
+	// token and converted to target token base units at execution time
+	GasSpentNative decimal.Decimal `json:"gas_spent_native" db:"gas_spent_native"`
+	GasSpentQuote  decimal.Decimal `json:"gas_spent_quote" db:"gas_spent_quote"`
+
+	// Set while the order is paused. PausedSeconds is the total time spent
+	// paused, by which the order's window is extended.
+	PausedAt      *time.Time `json:"paused_at,omitempty" db:"paused_at"`
+	PausedBy      *string    `json:"paused_by,omitempty" db:"paused_by"`
+	PauseReason   *string    `json:"pause_reason,omitempty" db:"pause_reason"`
+	PausedSeconds int64      `json:"paused_seconds" db:"paused_seconds"`
+
+	// Set once a cancelled order's unexecuted remainder is refunded
+	RefundTxHash   *string         `json:"refund_tx_hash,omitempty" db:"refund_tx_hash"`
+	RefundedAmount decimal.Decimal `json:"refunded_amount" db:"refunded_amount"`
+
+	// Optional conditions. The order's window starts at StartAt, or once
+	// the pair's price crosses TriggerPrice in TriggerCondition's direction.
+	// Intervals priced below LimitPrice are skipped.
+	StartAt          *time.Time       `json:"start_at,omitempty" db:"start_at"`
+	TriggerPrice     *decimal.Decimal `json:"trigger_price,omitempty" db:"trigger_price"`
+	TriggerCondition *string          `json:"trigger_condition,omitempty" db:"trigger_condition"`
+	TriggeredAt      *time.Time       `json:"triggered_at,omitempty" db:"triggered_at"`
+	LimitPrice       *decimal.Decimal `json:"limit_price,omitempty" db:"limit_price"`
+	SkippedIntervals int              `json:"skipped_intervals" db:"skipped_intervals"`
+	LastSkippedAt    *time.Time       `json:"last_skipped_at,omitempty" db:"last_skipped_at"`
+
+	// The recurring order that spawned this order, if any
+	ParentID *string `json:"parent_id,omitempty" db:"parent_id"`
+	// The basket the order was submitted in, if any
+	BasketID *string `json:"basket_id,omitempty" db:"basket_id"`
+
+	// Status transitions made since the order was read, written to
+	// order_events by UpdateOrder
+	events []*OrderEvent
+}
+
+// OrderAmendment records a change to a live order's terms. Changes maps
+// each amended field to its previous and new value.
+type OrderAmendment struct {
+	ID           int64           `json:"id" db:"id"`
+	OrderID      string          `json:"order_id" db:"order_id"`
+	Changes      Metadata        `json:"changes" db:"changes"`
+	Actor        string          `json:"actor" db:"actor"`
+	Reason       string          `json:"reason,omitempty" db:"reason"`
+	TxHash       *string         `json:"tx_hash,omitempty" db:"tx_hash"`
+	RefundAmount decimal.Decimal `json:"refund_amount" db:"refund_amount"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
 }
 
 // ExecutionRecord represents a single TWAP execution interval
@@ -49,6 +104,18 @@ type ExecutionRecord struct {
 	Slippage       *int            `json:"slippage" db:"slippage"`
 	TxHash         *string         `json:"tx_hash" db:"tx_hash"`
 	ChainID        string          `json:"chain_id" db:"chain_id"`
+
+	// Submission details, set when the adapter broadcast a transaction
+	SubmissionRoute *string `json:"submission_route,omitempty" db:"submission_route"`
+	BundleHash      *string `json:"bundle_hash,omitempty" db:"bundle_hash"`
+	RelayAttempts   *int    `json:"relay_attempts,omitempty" db:"relay_attempts"`
+	InclusionBlock  *int64  `json:"inclusion_block,omitempty" db:"inclusion_block"`
+
+	// Gas paid for the interval. GasPrice is in the smallest native unit;
+	// GasCostQuote is unset when the native token could not be priced.
+	GasPrice      *decimal.Decimal `json:"gas_price,omitempty" db:"gas_price"`
+	GasCostNative *decimal.Decimal `json:"gas_cost_native,omitempty" db:"gas_cost_native"`
+	GasCostQuote  *decimal.Decimal `json:"gas_cost_quote,omitempty" db:"gas_cost_quote"`
 }
 
 // PricePoint represents a price data point for TWAP calculations
@@ -64,6 +131,19 @@ type PricePoint struct {
 }
 
 
+// Candle represents an OHLCV aggregate of price points over one bucket
+type Candle struct {
+	TokenPair   string           `json:"token_pair" db:"token_pair"`
+	Resolution  CandleResolution `json:"resolution" db:"resolution"`
+	BucketStart time.Time        `json:"bucket_start" db:"bucket_start"`
+	Open        decimal.Decimal  `json:"open" db:"open"`
+	High        decimal.Decimal  `json:"high" db:"high"`
+	Low         decimal.Decimal  `json:"low" db:"low"`
+	Close       decimal.Decimal  `json:"close" db:"close"`
+	Volume      decimal.Decimal  `json:"volume" db:"volume"`
+	NumPoints   int              `json:"num_points" db:"num_points"`
+}
+
 // HTLC represents a Hash Time Lock Contract
 type HTLC struct {
 	Address          string     `json:"address" db:"address"`
@@ -93,6 +173,8 @@ type ChainStatus struct {
 	GasPrice        *decimal.Decimal `json:"gas_price" db:"gas_price"`
 	HealthStatus    string     `json:"health_status" db:"health_status"`
 	LastHealthCheck time.Time  `json:"last_health_check" db:"last_health_check"`
+	PeerCount       *int       `json:"peer_count" db:"peer_count"`
+	ErrorMessage    *string    `json:"error_message,omitempty" db:"error_message"`
 }
 
 // Metadata represents additional order metadata
@@ -135,6 +217,28 @@ const (
 	OrderStatusExpired         OrderStatus = "expired"
 	OrderStatusRefunded        OrderStatus = "refunded"
 	OrderStatusClaimed         OrderStatus = "claimed"
+	OrderStatusPaused          OrderStatus = "paused"
+	OrderStatusCancelling      OrderStatus = "cancelling"
+)
+
+// Who paused an order. The engine only resumes orders it paused itself.
+const (
+	PausedBySystem = "system"
+	PausedByUser   = "user"
+)
+
+// Direction in which a pair's price must cross an order's trigger price
+const (
+	TriggerAbove = "above"
+	TriggerBelow = "below"
+)
+
+// ResumeMode is how a paused order's schedule is re-planned on resume
+type ResumeMode string
+
+const (
+	ResumeExtend   ResumeMode = "extend"   // push the window out by the time paused
+	ResumeCompress ResumeMode = "compress" // keep the window's end
 )
 
 // HTLCStatus represents the various states of an HTLC
@@ -157,16 +261,77 @@ const (
 	HealthStatusUnknown   HealthStatus = "unknown"
 )
 
-// OrderFilter represents filters for querying orders
+// CandleResolution is the bucket width of a candle
+type CandleResolution string
+
+const (
+	CandleResolution1m CandleResolution = "1m"
+	CandleResolution5m CandleResolution = "5m"
+	CandleResolution1h CandleResolution = "1h"
+	CandleResolution1d CandleResolution = "1d"
+)
+
+// CandleResolutions lists the supported resolutions from finest to coarsest
+var CandleResolutions = []CandleResolution{
+	CandleResolution1m,
+	CandleResolution5m,
+	CandleResolution1h,
+	CandleResolution1d,
+}
+
+// Duration returns the bucket width of the resolution
+func (r CandleResolution) Duration() time.Duration {
+	switch r {
+	case CandleResolution1m:
+		return time.Minute
+	case CandleResolution5m:
+		return 5 * time.Minute
+	case CandleResolution1h:
+		return time.Hour
+	case CandleResolution1d:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// OrderFilter represents filters for querying orders. Unset fields match
+// every order. Pages continue from Cursor, the NextCursor of the previous
+// page, or else skip Offset orders.
 type OrderFilter struct {
-	UserAddress  *string
-	SourceChain  *string
-	TargetChain  *string
-	Status       *OrderStatus
-	CreatedAfter *time.Time
+	UserAddress   *string
+	SourceChain   *string
+	TargetChain   *string
+	SourceToken   *string
+	TargetToken   *string
+	Status        *OrderStatus
+	CreatedAfter  *time.Time
 	CreatedBefore *time.Time
-	Limit        int
-	Offset       int
+	SortBy        OrderSortField
+	Ascending     bool
+	Cursor        string
+	Limit         int
+	Offset        int
+}
+
+// OrderSortField is a column orders can be sorted by
+type OrderSortField string
+
+const (
+	OrderSortCreatedAt      OrderSortField = "created_at"
+	OrderSortUpdatedAt      OrderSortField = "updated_at"
+	OrderSortSourceAmount   OrderSortField = "source_amount"
+	OrderSortExecutedAmount OrderSortField = "executed_amount"
+	OrderSortCompletionRate OrderSortField = "completion_rate"
+)
+
+// OrderPage is one page of the orders matching a filter. Total counts
+// every match, not just this page.
+type OrderPage struct {
+	Orders            []*Order
+	IntervalsExecuted map[string]int // by order ID
+	Total             int64
+	NextCursor        string // empty on the last page
 }
 
 // PriceFilter represents filters for querying price history
@@ -222,6 +387,7 @@ type TWAPData struct {
 	TokenPair        string          `json:"token_pair"`
 	WindowMinutes    int             `json:"window_minutes"`
 	TWAPPrice        decimal.Decimal `json:"twap_price"`
+	GeometricTWAPPrice decimal.Decimal `json:"geometric_twap_price"`
 	VWAPPrice        decimal.Decimal `json:"vwap_price"`
 	LastPrice        decimal.Decimal `json:"last_price"`
 	PriceChange      decimal.Decimal `json:"price_change"`
@@ -256,6 +422,7 @@ var (
 	ErrInvalidOrderStatus = errors.New("invalid order status")
 	ErrOrderExpired      = errors.New("order expired")
 	ErrInsufficientData  = errors.New("insufficient data for calculation")
+	ErrInvalidCursor     = errors.New("invalid pagination cursor")
 )
 
 // Helper functions
@@ -265,13 +432,29 @@ func IsValidOrderStatus(status string) bool {
 	switch OrderStatus(status) {
 	case OrderStatusPending, OrderStatusExecuting, OrderStatusPartiallyFilled,
 		 OrderStatusCompleted, OrderStatusCancelled, OrderStatusExpired,
-		 OrderStatusRefunded, OrderStatusClaimed:
+		 OrderStatusRefunded, OrderStatusClaimed, OrderStatusPaused,
+		 OrderStatusCancelling:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsValidResumeMode checks if the resume mode is supported
+func IsValidResumeMode(mode string) bool {
+	switch ResumeMode(mode) {
+	case ResumeExtend, ResumeCompress:
 		return true
 	default:
 		return false
 	}
 }
 
+// IsValidCandleResolution checks if the candle resolution is supported
+func IsValidCandleResolution(resolution string) bool {
+	return CandleResolution(resolution).Duration() > 0
+}
+
 // IsValidHTLCStatus checks if the HTLC status is valid
 func IsValidHTLCStatus(status string) bool {
 	switch HTLCStatus(status) {
@@ -302,14 +485,65 @@ func (o *Order) IsTimedOut() bool {
 	return time.Now().Unix() >= o.TimeoutTimestamp
 }
 
-// GetNextExecutionTime calculates when the next execution should occur
+// GetNextExecutionTime calculates when the next execution should occur.
+// A skipped interval takes its slot like an executed one.
 func (o *Order) GetNextExecutionTime() time.Time {
-	if o.LastExecution == nil {
-		return o.CreatedAt
+	last := o.LastExecution
+	if o.LastSkippedAt != nil && (last == nil || o.LastSkippedAt.After(*last)) {
+		last = o.LastSkippedAt
+	}
+	if last == nil {
+		return o.ActivatedAt()
 	}
 	
 	intervalDuration := time.Duration(o.WindowMinutes/o.ExecutionIntervals) * time.Minute
-	return o.LastExecution.Add(intervalDuration)
+	return last.Add(intervalDuration)
+}
+
+// ActivatedAt returns when the order's window starts: when it was created,
+// or later at its start time or when its trigger fired
+func (o *Order) ActivatedAt() time.Time {
+	activated := o.CreatedAt
+	for _, t := range []*time.Time{o.StartAt, o.TriggeredAt} {
+		if t != nil && t.After(activated) {
+			activated = *t
+		}
+	}
+	return activated
+}
+
+// IsActivated checks if the order's start time has passed and its trigger,
+// if any, has fired
+func (o *Order) IsActivated(now time.Time) bool {
+	if o.StartAt != nil && now.Before(*o.StartAt) {
+		return false
+	}
+	return o.TriggerPrice == nil || o.TriggeredAt != nil
+}
+
+// TriggerMet checks if price has crossed the order's trigger price
+func (o *Order) TriggerMet(price decimal.Decimal) bool {
+	if o.TriggerPrice == nil {
+		return true
+	}
+	if o.TriggerCondition != nil && *o.TriggerCondition == TriggerBelow {
+		return price.LessThanOrEqual(*o.TriggerPrice)
+	}
+	return price.GreaterThanOrEqual(*o.TriggerPrice)
+}
+
+// WithinLimit checks if an interval may fill at price. Prices are target
+// tokens per source token, so a lower price is worse for the user.
+func (o *Order) WithinLimit(price decimal.Decimal) bool {
+	return o.LimitPrice == nil || price.GreaterThanOrEqual(*o.LimitPrice)
+}
+
+// SkipInterval gives up the current interval. Its share of the remainder
+// is spread over the intervals left.
+func (o *Order) SkipInterval(now time.Time) {
+	o.SkippedIntervals++
+	o.LastSkippedAt = &now
+	o.UpdatedAt = now
 }
 
 // CanExecuteInterval checks if an interval can be executed now
@@ -319,20 +553,103 @@ func (o *Order) CanExecuteInterval() bool {
 	return now.After(nextExecution) || now.Equal(nextExecution)
 }
 
+// WindowEnd returns when the order's window closes, extended by the time it
+// spent paused
+func (o *Order) WindowEnd() time.Time {
+	window := time.Duration(o.WindowMinutes) * time.Minute
+	return o.ActivatedAt().Add(window).Add(time.Duration(o.PausedSeconds) * time.Second)
+}
+
+// IsExecutable checks if the order's intervals may run
+func (o *Order) IsExecutable() bool {
+	return o.Status == string(OrderStatusPending) || o.Status == string(OrderStatusExecuting)
+}
+
+// IsPaused checks if the order is paused
+func (o *Order) IsPaused() bool {
+	return o.Status == string(OrderStatusPaused)
+}
+
+// Pause stops the order's intervals until it is resumed
+func (o *Order) Pause(by, reason string, now time.Time) error {
+	if err := o.transition(OrderStatusPaused, by, reason, now); err != nil {
+		return err
+	}
+	o.PausedAt = &now
+	o.PausedBy = &by
+	o.PauseReason = &reason
+	return nil
+}
+
+// Resume returns a paused order to execution. With ResumeExtend the
+// schedule shifts by the time spent paused, so the next interval keeps its
+// spacing and the window keeps its remaining length. With ResumeCompress
+// the window keeps its end and the engine spreads the remainder over the
+// intervals that still fit.
+func (o *Order) Resume(mode ResumeMode, actor, cause string, now time.Time) error {
+	if !IsValidResumeMode(string(mode)) {
+		return fmt.Errorf("invalid resume mode: %s", mode)
+	}
+
+	next := OrderStatusPending
+	if o.ExecutedAmount.IsPositive() {
+		next = OrderStatusExecuting
+	}
+	if err := o.transition(next, actor, cause, now); err != nil {
+		return err
+	}
+
+	if o.PausedAt != nil && mode == ResumeExtend {
+		paused := now.Sub(*o.PausedAt)
+		if paused > 0 {
+			o.PausedSeconds += int64(paused / time.Second)
+			if o.LastExecution != nil {
+				shifted := o.LastExecution.Add(paused)
+				o.LastExecution = &shifted
+			}
+			if o.LastSkippedAt != nil {
+				shifted := o.LastSkippedAt.Add(paused)
+				o.LastSkippedAt = &shifted
+			}
+		}
+	}
+
+	o.PausedAt = nil
+	o.PausedBy = nil
+	o.PauseReason = nil
+	return nil
+}
+
 // GetRemainingAmount calculates the remaining amount to be executed
 func (o *Order) GetRemainingAmount() decimal.Decimal {
 	return o.SourceAmount.Sub(o.ExecutedAmount)
 }
 
+// GetReceivedAmount estimates the target tokens received so far from the
+// executed amount and its average price
+func (o *Order) GetReceivedAmount() decimal.Decimal {
+	return o.ExecutedAmount.Mul(o.AveragePrice)
+}
+
+// GetChainOrderID returns the source chain's id for the order, or "" while
+// it is not yet created there
+func (o *Order) GetChainOrderID() string {
+	if o.ChainOrderID == nil {
+		return ""
+	}
+	return *o.ChainOrderID
+}
+
 // GetExecutedIntervals calculates how many intervals have been executed
 func (o *Order) GetExecutedIntervals(executionHistory []*ExecutionRecord) int {
 	return len(executionHistory)
 }
 
-// GetRemainingIntervals calculates how many intervals remain
+// GetRemainingIntervals calculates how many intervals remain, neither
+// executed nor skipped
 func (o *Order) GetRemainingIntervals(executionHistory []*ExecutionRecord) int {
 	executed := o.GetExecutedIntervals(executionHistory)
-	return o.ExecutionIntervals - executed
+	return o.ExecutionIntervals - executed - o.SkippedIntervals
 }
 
 // UpdateAveragePrice updates the weighted average price with a new execution
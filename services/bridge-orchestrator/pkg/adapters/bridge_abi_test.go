@@ -0,0 +1,283 @@
+package adapters
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+)
+
+// bridgeABIJSON is the part of FlowFusionBridge's ABI the adapter speaks,
+// as the Solidity compiler emits it. Calldata the adapter builds by hand is
+// checked against it.
+const bridgeABIJSON = `[
+	{"type":"function","name":"executeTWAPInterval","inputs":[
+		{"name":"orderId","type":"bytes32"},
+		{"name":"intervalAmount","type":"uint256"},
+		{"name":"executionPrice","type":"uint256"},
+		{"name":"minOutput","type":"uint256"},
+		{"name":"priceProof","type":"bytes"}]},
+	{"type":"function","name":"createTWAPOrderFor","inputs":[
+		{"name":"user","type":"address"},
+		{"name":"sourceToken","type":"address"},
+		{"name":"sourceAmount","type":"uint256"},
+		{"name":"targetChain","type":"string"},
+		{"name":"targetToken","type":"string"},
+		{"name":"targetRecipient","type":"string"},
+		{"name":"twapConfig","type":"tuple","components":[
+			{"name":"windowMinutes","type":"uint256"},
+			{"name":"executionIntervals","type":"uint256"},
+			{"name":"maxSlippage","type":"uint256"},
+			{"name":"minFillSize","type":"uint256"},
+			{"name":"enableMEVProtection","type":"bool"}]},
+		{"name":"htlcHash","type":"bytes32"},
+		{"name":"timeoutHeight","type":"uint256"}],
+	 "outputs":[{"name":"orderId","type":"bytes32"}]},
+	{"type":"function","name":"cancelOrderFor","inputs":[
+		{"name":"orderId","type":"bytes32"}]},
+	{"type":"function","name":"amendOrderFor","inputs":[
+		{"name":"orderId","type":"bytes32"},
+		{"name":"sourceAmount","type":"uint256"},
+		{"name":"twapConfig","type":"tuple","components":[
+			{"name":"windowMinutes","type":"uint256"},
+			{"name":"executionIntervals","type":"uint256"},
+			{"name":"maxSlippage","type":"uint256"},
+			{"name":"minFillSize","type":"uint256"},
+			{"name":"enableMEVProtection","type":"bool"}]}],
+	 "outputs":[]},
+	{"type":"event","name":"OrderAmended","inputs":[
+		{"name":"orderId","type":"bytes32","indexed":true},
+		{"name":"sourceAmount","type":"uint256"},
+		{"name":"refundAmount","type":"uint256"},
+		{"name":"twapConfig","type":"tuple","components":[
+			{"name":"windowMinutes","type":"uint256"},
+			{"name":"executionIntervals","type":"uint256"},
+			{"name":"maxSlippage","type":"uint256"},
+			{"name":"minFillSize","type":"uint256"},
+			{"name":"enableMEVProtection","type":"bool"}]},
+		{"name":"amendedAt","type":"uint256"}]},
+	{"type":"event","name":"OrderCancelled","inputs":[
+		{"name":"orderId","type":"bytes32","indexed":true},
+		{"name":"user","type":"address","indexed":true},
+		{"name":"refundAmount","type":"uint256"},
+		{"name":"cancelledAt","type":"uint256"}]},
+	{"type":"event","name":"OrderCreated","inputs":[
+		{"name":"orderId","type":"bytes32","indexed":true},
+		{"name":"user","type":"address","indexed":true},
+		{"name":"targetChain","type":"string"},
+		{"name":"sourceToken","type":"address"},
+		{"name":"sourceAmount","type":"uint256"},
+		{"name":"targetToken","type":"string"},
+		{"name":"twapConfig","type":"tuple","components":[
+			{"name":"windowMinutes","type":"uint256"},
+			{"name":"executionIntervals","type":"uint256"},
+			{"name":"maxSlippage","type":"uint256"},
+			{"name":"minFillSize","type":"uint256"},
+			{"name":"enableMEVProtection","type":"bool"}]}]}
+]`
+
+func loadBridgeABI(t *testing.T) abi.ABI {
+	t.Helper()
+	parsed, err := abi.JSON(strings.NewReader(bridgeABIJSON))
+	if err != nil {
+		t.Fatalf("invalid bridge ABI: %v", err)
+	}
+	return parsed
+}
+
+// unpackBridgeCall decodes calldata for method, checking its selector
+func unpackBridgeCall(t *testing.T, bridgeABI abi.ABI, method string, data []byte) []interface{} {
+	t.Helper()
+	m, ok := bridgeABI.Methods[method]
+	if !ok {
+		t.Fatalf("no method %s in bridge ABI", method)
+	}
+	if len(data) < 4 || string(data[:4]) != string(m.ID) {
+		t.Fatalf("calldata is not %s", m.Sig)
+	}
+	args, err := m.Inputs.Unpack(data[4:])
+	if err != nil {
+		t.Fatalf("failed to unpack %s: %v", method, err)
+	}
+	return args
+}
+
+// bridgeFeeRate is the protocol fee the stub bridge takes, in basis points
+const bridgeFeeRate = 25
+
+// bridgeStub runs the bridge's order calls as the contract does, for
+// chainStub.onBroadcast. Order ids are assigned by the stub, so the adapter
+// can only learn them from the events.
+type bridgeStub struct {
+	t       *testing.T
+	abi     abi.ABI
+	address common.Address
+	nonce   int64
+	orders  map[common.Hash]*bridgeStubOrder
+}
+
+type bridgeStubOrder struct {
+	user      common.Address
+	amount    *big.Int // net of the protocol fee
+	executed  *big.Int
+	cancelled bool
+}
+
+func newBridgeStub(t *testing.T, address common.Address) *bridgeStub {
+	return &bridgeStub{t: t, abi: loadBridgeABI(t), address: address, orders: make(map[common.Hash]*bridgeStubOrder)}
+}
+
+// handle runs tx if it calls the bridge, returning its events and whether
+// it succeeded
+func (b *bridgeStub) handle(tx *decodedDynamicTx) ([]*txLog, bool) {
+	if tx.To != b.address {
+		return nil, true
+	}
+	method, err := b.abi.MethodById(tx.Data)
+	if err != nil {
+		b.t.Errorf("bridge called with unknown selector %x", tx.Data[:4])
+		return nil, false
+	}
+	args := unpackBridgeCall(b.t, b.abi, method.Name, tx.Data)
+
+	switch method.Name {
+	case "createTWAPOrderFor":
+		return b.create(args), true
+	case "cancelOrderFor":
+		return b.cancel(common.Hash(args[0].([32]byte)))
+	case "amendOrderFor":
+		return b.amend(common.Hash(args[0].([32]byte)), args[1].(*big.Int), args[2])
+	default:
+		b.t.Errorf("unexpected bridge call %s", method.Name)
+		return nil, false
+	}
+}
+
+func (b *bridgeStub) create(args []interface{}) []*txLog {
+	user := args[0].(common.Address)
+	amount := args[2].(*big.Int)
+	fee := new(big.Int).Div(new(big.Int).Mul(amount, big.NewInt(bridgeFeeRate)), big.NewInt(10000))
+
+	b.nonce++
+	orderID := crypto.Keccak256Hash(user.Bytes(), big.NewInt(b.nonce).Bytes())
+	b.orders[orderID] = &bridgeStubOrder{user: user, amount: new(big.Int).Sub(amount, fee), executed: new(big.Int)}
+
+	event := b.abi.Events["OrderCreated"]
+	data, err := event.Inputs.NonIndexed().Pack(args[3], args[1], amount, args[4], args[6])
+	if err != nil {
+		b.t.Fatalf("failed to pack OrderCreated: %v", err)
+	}
+	return []*txLog{{
+		Address: b.address,
+		Topics:  []common.Hash{event.ID, orderID, common.BytesToHash(user.Bytes())},
+		Data:    data,
+	}}
+}
+
+// cancel refunds the unexecuted remainder to the order's owner, emitting
+// OrderCancelled only when there is something to refund
+func (b *bridgeStub) cancel(orderID common.Hash) ([]*txLog, bool) {
+	order, ok := b.orders[orderID]
+	if !ok || order.cancelled {
+		return nil, false
+	}
+
+	refund := new(big.Int).Sub(order.amount, order.executed)
+	if refund.Sign() == 0 {
+		return nil, true
+	}
+	order.cancelled = true
+
+	event := b.abi.Events["OrderCancelled"]
+	data, err := event.Inputs.NonIndexed().Pack(refund, big.NewInt(1700000000))
+	if err != nil {
+		b.t.Fatalf("failed to pack OrderCancelled: %v", err)
+	}
+	return []*txLog{{
+		Address: b.address,
+		Topics:  []common.Hash{event.ID, orderID, common.BytesToHash(order.user.Bytes())},
+		Data:    data,
+	}}, true
+}
+
+// amend shrinks an order, refunding the difference to its owner
+func (b *bridgeStub) amend(orderID common.Hash, amount *big.Int, twapConfig interface{}) ([]*txLog, bool) {
+	order, ok := b.orders[orderID]
+	if !ok || order.cancelled || amount.Cmp(order.executed) <= 0 || amount.Cmp(order.amount) > 0 {
+		return nil, false
+	}
+	refund := new(big.Int).Sub(order.amount, amount)
+	order.amount = amount
+
+	event := b.abi.Events["OrderAmended"]
+	data, err := event.Inputs.NonIndexed().Pack(amount, refund, twapConfig, big.NewInt(1700000000))
+	if err != nil {
+		b.t.Fatalf("failed to pack OrderAmended: %v", err)
+	}
+	return []*txLog{{
+		Address: b.address,
+		Topics:  []common.Hash{event.ID, orderID},
+		Data:    data,
+	}}, true
+}
+
+func TestExecuteIntervalMatchesBridgeABI(t *testing.T) {
+	bridgeABI := loadBridgeABI(t)
+	orderID := common.HexToHash("0x01")
+
+	data := encodeExecuteTWAPInterval(orderID, decimal.NewFromInt(1000), decimal.RequireFromString("2.5"),
+		decimal.RequireFromString("2400.2"))
+	args := unpackBridgeCall(t, bridgeABI, "executeTWAPInterval", data)
+
+	if common.Hash(args[0].([32]byte)) != orderID {
+		t.Fatalf("order id = %x", args[0])
+	}
+	price, _ := new(big.Int).SetString("2500000000000000000", 10)
+	if args[1].(*big.Int).Int64() != 1000 || args[2].(*big.Int).Cmp(price) != 0 {
+		t.Fatalf("unexpected amount %v or price %v", args[1], args[2])
+	}
+	// Rounded up so the contract holds the engine's floor
+	if args[3].(*big.Int).Int64() != 2401 {
+		t.Fatalf("min output = %v, want 2401", args[3])
+	}
+	if len(args[4].([]byte)) == 0 {
+		t.Fatalf("the bridge rejects an empty price proof")
+	}
+}
+
+func TestExecuteIntervalPricesInBaseUnits(t *testing.T) {
+	stub := newChainStub()
+	adapter := newTestEthereumAdapter(t, stub, false, RelayFallbackNone)
+	bridgeABI := loadBridgeABI(t)
+	var sent []interface{}
+	stub.onBroadcast = func(tx *decodedDynamicTx) ([]*txLog, bool) {
+		sent = unpackBridgeCall(t, bridgeABI, "executeTWAPInterval", tx.Data)
+		return nil, true
+	}
+
+	// 1 WETH at 2500 USDC, which has 12 fewer decimals
+	params := testIntervalParams(false)
+	params.Amount = decimal.NewFromInt(1).Shift(18)
+	params.PriceHint = decimal.NewFromInt(2500)
+	params.PriceScale = -12
+	params.MinOutput = decimal.NewFromInt(2501).Shift(6)
+	if _, err := adapter.ExecuteTWAPInterval(params); !errors.Is(err, ErrMinOutputNotMet) {
+		t.Fatalf("expected ErrMinOutputNotMet, got %v", err)
+	}
+
+	params.MinOutput = decimal.NewFromInt(2475).Shift(6)
+	if _, err := adapter.ExecuteTWAPInterval(params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The bridge's output, amount * price / 1e18, is 2500 USDC in base units
+	price := sent[2].(*big.Int)
+	output := new(big.Int).Div(new(big.Int).Mul(sent[1].(*big.Int), price), big.NewInt(1e18))
+	if price.Int64() != 2500000000 || output.Int64() != 2500000000 {
+		t.Fatalf("price = %s and output = %s, want 2500 USDC per WETH in base units", price, output)
+	}
+}
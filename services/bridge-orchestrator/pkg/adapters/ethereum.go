@@ -0,0 +1,566 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+)
+
+const (
+	// rpcTimeout bounds a single JSON-RPC call
+	rpcTimeout = 15 * time.Second
+	// executionTimeout bounds submitting an interval and waiting for it to land
+	executionTimeout = 10 * time.Minute
+	// defaultPollInterval is how often receipts and block numbers are polled
+	defaultPollInterval = 2 * time.Second
+
+	// Gas limits for order creation; estimates are unreliable there because
+	// createTWAPOrderFor reverts until the permit is mined
+	permitGasLimit      = 120000
+	createOrderGasLimit = 800000
+	cancelOrderGasLimit = 150000
+	amendOrderGasLimit  = 150000
+	// defaultTimeoutBlocks is the HTLC timeout used when an order has none,
+	// roughly a day of 12 second blocks
+	defaultTimeoutBlocks = 7200
+)
+
+// executeTWAPIntervalSelector is the selector of
+// executeTWAPInterval(bytes32,uint256,uint256,uint256,bytes) on the bridge
+// contract, which reverts when the interval delivers less than minOutput
+var executeTWAPIntervalSelector = abiSelector("executeTWAPInterval(bytes32,uint256,uint256,uint256,bytes)")
+
+// createTWAPOrderForSelector is the selector of createTWAPOrderFor on the
+// bridge contract; the TWAPConfig tuple is static and encoded inline
+var createTWAPOrderForSelector = abiSelector("createTWAPOrderFor(address,address,uint256,string,string,string,(uint256,uint256,uint256,uint256,bool),bytes32,uint256)")
+
+// orderCreatedTopic is the signature of the bridge's OrderCreated event,
+// whose first indexed argument is the id the bridge assigned the order
+var orderCreatedTopic = crypto.Keccak256Hash([]byte("OrderCreated(bytes32,address,string,address,uint256,string,(uint256,uint256,uint256,uint256,bool))"))
+
+// orderCancelledTopic is the signature of the bridge's OrderCancelled event,
+// which carries the owner as its second topic and the refund as its first
+// data word. Nothing is emitted when there is nothing to refund.
+var orderCancelledTopic = crypto.Keccak256Hash([]byte("OrderCancelled(bytes32,address,uint256,uint256)"))
+
+// orderAmendedTopic is the signature of the bridge's OrderAmended event,
+// whose data starts with the new source amount and the refund
+var orderAmendedTopic = crypto.Keccak256Hash([]byte("OrderAmended(bytes32,uint256,uint256,(uint256,uint256,uint256,uint256,bool),uint256)"))
+
+// EthereumAdapter submits TWAP intervals to the FlowFusion bridge contract.
+// Operations that are not yet implemented on-chain return ErrNotImplemented.
+type EthereumAdapter struct {
+	adapterBase
+
+	config config.EthereumConfig
+	logger *zap.Logger
+	client *rpcClient
+	signer *evmSigner
+	txs    *txManager
+	bridge common.Address
+	relay  *BundleRelay
+}
+
+// newEthereumAdapter creates an adapter that signs with config.PrivateKey
+func newEthereumAdapter(cfg config.EthereumConfig, logger *zap.Logger) (*EthereumAdapter, error) {
+	signer, err := newEVMSigner(cfg.PrivateKey, cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !common.IsHexAddress(cfg.BridgeAddress) {
+		return nil, fmt.Errorf("invalid bridge address: %s", cfg.BridgeAddress)
+	}
+
+	client := newPooledRPCClient(endpointList(cfg.RPCURL, cfg.RPCURLs), cfg.RPCFailover, rpcTimeout)
+	adapter := &EthereumAdapter{
+		adapterBase: adapterBase{chainID: "ethereum", name: "Ethereum"},
+		config:      cfg,
+		logger:      logger,
+		client:      client,
+		signer:      signer,
+		txs:         newTxManager(client, signer, cfg, logger),
+		bridge:      common.HexToAddress(cfg.BridgeAddress),
+	}
+	adapter.identity = evmIdentityCheck(client, cfg.ChainID, cfg.BridgeAddress)
+
+	if cfg.RelayURL != "" {
+		adapter.relay, err = NewBundleRelay(cfg.RelayURL, cfg.RelayAuthKey, rpcTimeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return adapter, nil
+}
+
+// GetAddress returns the address transactions are sent from
+func (a *EthereumAdapter) GetAddress() (string, error) {
+	return a.signer.address.Hex(), nil
+}
+
+// ExecuteTWAPInterval submits an interval to the bridge contract and waits
+// for it to be mined. Orders with MEV protection are sent through the relay.
+func (a *EthereumAdapter) ExecuteTWAPInterval(params ExecuteIntervalParams) (*ExecutionResult, error) {
+	// The bridge reverts below MinOutput at the submitted price; checking
+	// first avoids paying gas for an interval that cannot land
+	if output := params.Output(params.Amount, params.PriceHint); params.MinOutput.IsPositive() && output.LessThan(params.MinOutput) {
+		return nil, fmt.Errorf("%w: got %s, need %s", ErrMinOutputNotMet, output.String(), params.MinOutput.String())
+	}
+
+	orderID, err := bridgeOrderID(params.ChainOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), executionTimeout)
+	defer cancel()
+
+	operationID := fmt.Sprintf("%s/%d", params.OrderID, params.IntervalNumber)
+	// The bridge prices in base units, so its output is comparable to MinOutput
+	data := encodeExecuteTWAPInterval(orderID, params.Amount, params.PriceHint.Shift(params.PriceScale), params.MinOutput)
+
+	if params.MEVProtection && a.relay == nil && a.config.RelayFallback != RelayFallbackPublic {
+		return nil, ErrNoRelayConfigured
+	}
+
+	tx, resumed, err := a.txs.prepare(ctx, operationID, a.bridge, data, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var sub *txSubmission
+	switch {
+	case resumed && tx.BundleTarget > 0 && len(tx.Sent) == 0:
+		// A previous attempt's bundle may still land; settle it before
+		// sending the same transaction again
+		a.logger.Info("Resuming unsettled interval bundle",
+			zap.String("operation", operationID),
+			zap.String("tx_hash", tx.Hash.Hex()))
+		sub, err = a.submitPrivate(ctx, tx)
+	case resumed:
+		// A previous attempt is still pending publicly; wait on it rather
+		// than submit the interval twice
+		a.logger.Info("Resuming pending interval transaction",
+			zap.String("operation", operationID),
+			zap.String("tx_hash", tx.Hash.Hex()))
+		sub, err = a.awaitPublic(ctx, tx, tx.Route)
+	case params.MEVProtection && a.relay != nil:
+		sub, err = a.submitPrivate(ctx, tx)
+	case params.MEVProtection:
+		// No relay configured and the fallback policy allows going public
+		sub, err = a.submitPublic(ctx, tx, SubmissionRoutePublicFallback)
+	default:
+		sub, err = a.submitPublic(ctx, tx, SubmissionRoutePublic)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("Interval transaction mined",
+		zap.String("order_id", params.OrderID),
+		zap.String("tx_hash", sub.TxHash.Hex()),
+		zap.String("route", sub.Route),
+		zap.Int("relay_attempts", sub.Attempts),
+		zap.Uint64("block", uint64(sub.Receipt.BlockNumber)))
+
+	gasPrice := tx.Fees.maxPrice()
+	if sub.Receipt.EffectiveGasPrice != nil {
+		gasPrice = sub.Receipt.EffectiveGasPrice.ToInt()
+	}
+	paid := decimal.NewFromBigInt(gasPrice, 0)
+
+	result := &ExecutionResult{
+		Success:         sub.Receipt.Status == 1,
+		TxHash:          sub.TxHash.Hex(),
+		ExecutedAmount:  params.Amount,
+		ExecutionPrice:  params.PriceHint,
+		GasUsed:         uint64(sub.Receipt.GasUsed),
+		GasPrice:        paid,
+		GasCost:         GasCostInNative(a.chainID, uint64(sub.Receipt.GasUsed), paid),
+		SubmissionRoute: sub.Route,
+		BundleHash:      sub.BundleHash,
+		RelayAttempts:   sub.Attempts,
+		InclusionBlock:  uint64(sub.Receipt.BlockNumber),
+		Replacements:    sub.Replacements,
+	}
+	if !result.Success {
+		result.Error = "transaction reverted"
+	}
+
+	return result, nil
+}
+
+// CreateTWAPOrder creates the order on the bridge on behalf of the user and
+// returns the transaction hash and the id the bridge assigned. A permit in params is submitted first so the
+// bridge can pull the source tokens; without one the user must already have
+// approved the bridge.
+func (a *EthereumAdapter) CreateTWAPOrder(params CreateTWAPOrderParams) (*CreateOrderResult, error) {
+	if !common.IsHexAddress(params.UserAddress) || !common.IsHexAddress(params.SourceToken) {
+		return nil, fmt.Errorf("invalid user or source token address")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), executionTimeout)
+	defer cancel()
+
+	if params.Permit != nil {
+		if err := a.applyPermit(ctx, params.OrderID, params.Permit); err != nil {
+			return nil, err
+		}
+	}
+
+	timeoutHeight := params.TimeoutHeight
+	if timeoutHeight <= 0 {
+		current, err := a.client.blockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block number: %w", err)
+		}
+		timeoutHeight = int64(current) + defaultTimeoutBlocks
+	}
+
+	data := encodeCreateTWAPOrderFor(params, timeoutHeight)
+	sub, err := a.sendAndWait(ctx, params.OrderID+"/create", a.bridge, data, createOrderGasLimit)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Receipt.Status != 1 {
+		return nil, fmt.Errorf("createTWAPOrderFor reverted in %s", sub.TxHash.Hex())
+	}
+
+	// The bridge derives the id from the block timestamp and its own nonce,
+	// so it can only be learned from the event
+	created := sub.Receipt.findLog(a.bridge, orderCreatedTopic)
+	if created == nil || len(created.Topics) < 2 {
+		return nil, fmt.Errorf("no OrderCreated event in %s", sub.TxHash.Hex())
+	}
+	chainOrderID := created.Topics[1].Hex()
+
+	a.logger.Info("Order created on bridge",
+		zap.String("order_id", params.OrderID),
+		zap.String("chain_order_id", chainOrderID),
+		zap.String("user", params.UserAddress),
+		zap.String("tx_hash", sub.TxHash.Hex()),
+		zap.Bool("with_permit", params.Permit != nil))
+
+	return &CreateOrderResult{TxHash: sub.TxHash.Hex(), ChainOrderID: chainOrderID}, nil
+}
+
+// CancelOrder cancels the order on the bridge contract, which refunds the
+// unexecuted remainder to the order's owner. The refund is read from the
+// bridge's event, as the bridge holds the order net of its protocol fee.
+func (a *EthereumAdapter) CancelOrder(params CancelOrderParams) (*CancelResult, error) {
+	orderID, err := bridgeOrderID(params.ChainOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), executionTimeout)
+	defer cancel()
+
+	data := (&abiEncoder{}).bytes32(orderID).encode(cancelOrderForSelector)
+	sub, err := a.sendAndWait(ctx, params.OrderID+"/cancel", a.bridge, data, cancelOrderGasLimit)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Receipt.Status != 1 {
+		return nil, fmt.Errorf("cancelOrderFor reverted in %s", sub.TxHash.Hex())
+	}
+
+	result := &CancelResult{
+		OrderID:      params.OrderID,
+		TxHash:       sub.TxHash.Hex(),
+		RefundAmount: decimal.Zero,
+		Recipient:    params.UserAddress,
+		BlockNumber:  uint64(sub.Receipt.BlockNumber),
+	}
+	if cancelled := sub.Receipt.findLog(a.bridge, orderCancelledTopic); cancelled != nil {
+		refund, ok := logWord(cancelled, 0)
+		if !ok || len(cancelled.Topics) < 3 {
+			return nil, fmt.Errorf("malformed OrderCancelled event in %s", sub.TxHash.Hex())
+		}
+		result.RefundAmount = decimal.NewFromBigInt(refund, 0)
+		result.Recipient = common.BytesToAddress(cancelled.Topics[2].Bytes()).Hex()
+	}
+
+	a.logger.Info("Order cancelled on bridge",
+		zap.String("order_id", params.OrderID),
+		zap.String("refund", result.RefundAmount.String()),
+		zap.String("recipient", result.Recipient),
+		zap.String("tx_hash", sub.TxHash.Hex()))
+
+	return result, nil
+}
+
+// AmendOrder updates the order's amount and TWAP configuration on the
+// bridge contract. The bridge holds orders net of its protocol fee, so the
+// amount is converted to its terms and the refund read from its event.
+func (a *EthereumAdapter) AmendOrder(params AmendOrderParams) (*AmendResult, error) {
+	orderID, err := bridgeOrderID(params.ChainOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), executionTimeout)
+	defer cancel()
+
+	// Each amendment is its own operation; a retry of the same terms
+	// resumes the pending transaction
+	operationID := fmt.Sprintf("%s/amend/%s/%d/%d/%d", params.OrderID, params.SourceAmount.String(),
+		params.WindowMinutes, params.Intervals, params.MaxSlippage)
+	sourceAmount := a.bridgeAmount(params.SourceAmount)
+	data := encodeAmendOrderFor(orderID, sourceAmount, params)
+	sub, err := a.sendAndWait(ctx, operationID, a.bridge, data, amendOrderGasLimit)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Receipt.Status != 1 {
+		return nil, fmt.Errorf("amendOrderFor reverted in %s", sub.TxHash.Hex())
+	}
+
+	amended := sub.Receipt.findLog(a.bridge, orderAmendedTopic)
+	if amended == nil {
+		return nil, fmt.Errorf("no OrderAmended event in %s", sub.TxHash.Hex())
+	}
+	refund, ok := logWord(amended, 1)
+	if !ok {
+		return nil, fmt.Errorf("malformed OrderAmended event in %s", sub.TxHash.Hex())
+	}
+
+	a.logger.Info("Order amended on bridge",
+		zap.String("order_id", params.OrderID),
+		zap.String("source_amount", params.SourceAmount.String()),
+		zap.String("bridge_amount", sourceAmount.String()),
+		zap.String("refund", refund.String()),
+		zap.String("tx_hash", sub.TxHash.Hex()))
+
+	return &AmendResult{
+		OrderID:      params.OrderID,
+		TxHash:       sub.TxHash.Hex(),
+		RefundAmount: decimal.NewFromBigInt(refund, 0),
+	}, nil
+}
+
+// bridgeAmount converts an order amount to the bridge's terms. The bridge
+// takes its protocol fee from the amount at creation and holds the rest.
+func (a *EthereumAdapter) bridgeAmount(amount decimal.Decimal) decimal.Decimal {
+	fee := amount.Mul(decimal.NewFromInt(int64(a.config.ProtocolFeeBps))).Div(decimal.NewFromInt(10000)).Truncate(0)
+	return amount.Sub(fee)
+}
+
+// applyPermit submits permit unless the allowance it grants is already in
+// place, as it is when a retried order's permit was mined the first time
+func (a *EthereumAdapter) applyPermit(ctx context.Context, orderID string, permit *Permit) error {
+	granted, err := a.permitGranted(ctx, permit)
+	if err != nil {
+		return err
+	}
+	if granted {
+		a.logger.Debug("Permit allowance already in place", zap.String("order_id", orderID))
+		return nil
+	}
+
+	if err := a.verifyPermit(ctx, permit); err != nil {
+		return err
+	}
+
+	to, data, err := encodePermitCall(permit)
+	if err != nil {
+		return err
+	}
+	sub, err := a.sendAndWait(ctx, orderID+"/permit", to, data, permitGasLimit)
+	if err != nil {
+		return fmt.Errorf("failed to submit permit: %w", err)
+	}
+	if sub.Receipt.Status != 1 {
+		return fmt.Errorf("%w: permit transaction %s reverted", ErrInvalidPermit, sub.TxHash.Hex())
+	}
+
+	a.logger.Info("Permit submitted",
+		zap.String("order_id", orderID),
+		zap.String("kind", permit.Kind),
+		zap.String("tx_hash", sub.TxHash.Hex()))
+	return nil
+}
+
+// sendAndWait sends a transaction for operationID to the public mempool and
+// waits for it to be mined, resuming one still pending from an earlier try
+func (a *EthereumAdapter) sendAndWait(ctx context.Context, operationID string, to common.Address, data []byte, gasLimit uint64) (*txSubmission, error) {
+	tx, resumed, err := a.txs.prepare(ctx, operationID, to, data, gasLimit)
+	if err != nil {
+		return nil, err
+	}
+	if resumed {
+		return a.awaitPublic(ctx, tx, tx.Route)
+	}
+	return a.submitPublic(ctx, tx, SubmissionRoutePublic)
+}
+
+// GetChainStatus reports the head block, the node's current gas price and
+// peer count, and the health of each RPC endpoint
+func (a *EthereumAdapter) GetChainStatus() (*ChainStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+	defer cancel()
+
+	status := &ChainStatus{
+		ChainID:     a.chainID,
+		Name:        a.name,
+		LastChecked: time.Now(),
+	}
+	defer func() { status.Endpoints = a.client.pool.health() }()
+
+	height, err := a.client.blockNumber(ctx)
+	if err != nil {
+		status.ErrorMessage = err.Error()
+		return status, nil
+	}
+	gasPrice, err := a.client.gasPrice(ctx)
+	if err != nil {
+		status.ErrorMessage = err.Error()
+		return status, nil
+	}
+
+	// A trial request may have just answered while every breaker is open
+	status.IsHealthy = a.client.pool.available()
+	if !status.IsHealthy {
+		status.ErrorMessage = ErrNoHealthyEndpoint.Error()
+	}
+	status.LastBlockHeight = int64(height)
+	status.GasPrice = gasPrice.String()
+
+	// Hosted providers often disable the net namespace
+	if peers, err := a.client.peerCount(ctx); err == nil {
+		status.PeerCount = int(peers)
+	}
+	return status, nil
+}
+
+// notImplemented reports an operation the bridge adapter does not perform
+// on-chain, rather than answering with mock data
+func (a *EthereumAdapter) notImplemented(operation string) error {
+	return fmt.Errorf("%w: %s on %s", ErrNotImplemented, operation, a.chainID)
+}
+
+func (a *EthereumAdapter) GetBalance(tokenAddress string) (string, error) {
+	return "", a.notImplemented("GetBalance")
+}
+
+func (a *EthereumAdapter) GetOrderStatus(orderID string) (*OrderStatus, error) {
+	return nil, a.notImplemented("GetOrderStatus")
+}
+
+func (a *EthereumAdapter) CreateHTLC(params CreateHTLCParams) (string, error) {
+	return "", a.notImplemented("CreateHTLC")
+}
+
+func (a *EthereumAdapter) ClaimHTLC(htlcAddress, secret string) (string, error) {
+	return "", a.notImplemented("ClaimHTLC")
+}
+
+func (a *EthereumAdapter) RefundHTLC(htlcAddress string) (string, error) {
+	return "", a.notImplemented("RefundHTLC")
+}
+
+func (a *EthereumAdapter) GetHTLCStatus(htlcAddress string) (*HTLCStatus, error) {
+	return nil, a.notImplemented("GetHTLCStatus")
+}
+
+func (a *EthereumAdapter) GetCurrentPrice(tokenPair string) (string, error) {
+	return "", a.notImplemented("GetCurrentPrice")
+}
+
+func (a *EthereumAdapter) GetTWAPPrice(tokenPair string, windowMinutes int) (string, error) {
+	return "", a.notImplemented("GetTWAPPrice")
+}
+
+func (a *EthereumAdapter) SubscribeToEvents(callback EventCallback) error {
+	return a.notImplemented("SubscribeToEvents")
+}
+
+// UnsubscribeFromEvents has nothing to undo, as SubscribeToEvents never
+// subscribes
+func (a *EthereumAdapter) UnsubscribeFromEvents() error {
+	return nil
+}
+
+// encodeExecuteTWAPInterval ABI-encodes a call with a placeholder price
+// proof. Prices are passed as 18-decimal fixed point; the minimum output is
+// rounded up so the contract never accepts less than the engine asked for.
+func encodeExecuteTWAPInterval(orderID common.Hash, amount, price, minOutput decimal.Decimal) []byte {
+	return (&abiEncoder{}).
+		bytes32(orderID).
+		uint(amount.BigInt()).
+		uint(price.Shift(18).BigInt()).
+		uint(minOutput.Ceil().BigInt()).
+		bytes([]byte{0x01}).
+		encode(executeTWAPIntervalSelector)
+}
+
+// encodeCreateTWAPOrderFor ABI-encodes createTWAPOrderFor for params
+func encodeCreateTWAPOrderFor(params CreateTWAPOrderParams, timeoutHeight int64) []byte {
+	return (&abiEncoder{}).
+		address(common.HexToAddress(params.UserAddress)).
+		address(common.HexToAddress(params.SourceToken)).
+		uint(params.Amount.BigInt()).
+		string(params.TargetChain).
+		string(params.TargetToken).
+		string(params.TargetRecipient).
+		uint64(uint64(params.WindowMinutes)).
+		uint64(uint64(params.Intervals)).
+		uint64(uint64(params.MaxSlippage)).
+		uint(params.MinFillSize.BigInt()).
+		bool(params.EnableMEVProtection).
+		bytes32(common.HexToHash(params.HashedSecret)).
+		uint64(uint64(timeoutHeight)).
+		encode(createTWAPOrderForSelector)
+}
+
+// cancelOrderForSelector is the selector of cancelOrderFor(bytes32), which
+// refunds the unexecuted remainder to the order's owner
+var cancelOrderForSelector = abiSelector("cancelOrderFor(bytes32)")
+
+// amendOrderForSelector is the selector of amendOrderFor, which refunds a
+// reduced source amount to the order's owner
+var amendOrderForSelector = abiSelector("amendOrderFor(bytes32,uint256,(uint256,uint256,uint256,uint256,bool))")
+
+// encodeAmendOrderFor ABI-encodes amendOrderFor for params, with
+// sourceAmount in the bridge's terms
+func encodeAmendOrderFor(orderID common.Hash, sourceAmount decimal.Decimal, params AmendOrderParams) []byte {
+	return (&abiEncoder{}).
+		bytes32(orderID).
+		uint(sourceAmount.BigInt()).
+		uint64(uint64(params.WindowMinutes)).
+		uint64(uint64(params.Intervals)).
+		uint64(uint64(params.MaxSlippage)).
+		uint(params.MinFillSize.BigInt()).
+		bool(params.EnableMEVProtection).
+		encode(amendOrderForSelector)
+}
+
+// logWord returns the i-th 32-byte word of an event's data
+func logWord(log *txLog, i int) (*big.Int, bool) {
+	if len(log.Data) < (i+1)*32 {
+		return nil, false
+	}
+	return new(big.Int).SetBytes(log.Data[i*32 : (i+1)*32]), true
+}
+
+// bridgeOrderID parses the id the bridge assigned an order
+func bridgeOrderID(chainOrderID string) (common.Hash, error) {
+	if chainOrderID == "" {
+		return common.Hash{}, ErrNoChainOrderID
+	}
+	id, err := hexutil.Decode(chainOrderID)
+	if err != nil || len(id) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid bridge order id %q", chainOrderID)
+	}
+	return common.BytesToHash(id), nil
+}
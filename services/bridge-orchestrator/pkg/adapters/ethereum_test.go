@@ -0,0 +1,120 @@
+package adapters
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+func TestLiveAdapterDoesNotFallBackToMock(t *testing.T) {
+	adapter := newTestEthereumAdapter(t, newChainStub(), false, RelayFallbackNone)
+
+	calls := map[string]func() error{
+		"GetBalance":        func() error { _, err := adapter.GetBalance("0x00"); return err },
+		"GetOrderStatus":    func() error { _, err := adapter.GetOrderStatus("order-1"); return err },
+		"CreateHTLC":        func() error { _, err := adapter.CreateHTLC(CreateHTLCParams{}); return err },
+		"ClaimHTLC":         func() error { _, err := adapter.ClaimHTLC("0x01", "0x02"); return err },
+		"RefundHTLC":        func() error { _, err := adapter.RefundHTLC("0x01"); return err },
+		"GetHTLCStatus":     func() error { _, err := adapter.GetHTLCStatus("0x01"); return err },
+		"GetCurrentPrice":   func() error { _, err := adapter.GetCurrentPrice("ETH_USDC"); return err },
+		"GetTWAPPrice":      func() error { _, err := adapter.GetTWAPPrice("ETH_USDC", 60); return err },
+		"SubscribeToEvents": func() error { return adapter.SubscribeToEvents(nil) },
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, ErrNotImplemented) {
+			t.Errorf("%s: expected ErrNotImplemented, got %v", name, err)
+		}
+	}
+}
+
+func TestCancelNamesOrderByBridgeID(t *testing.T) {
+	stub := newChainStub()
+	adapter := newTestEthereumAdapter(t, stub, false, RelayFallbackNone)
+	bridge := newBridgeStub(t, adapter.bridge)
+	stub.onBroadcast = bridge.handle
+	owner := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	created, err := adapter.CreateTWAPOrder(testCreateParams(owner))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	chainOrderID := common.HexToHash(created.ChainOrderID)
+	if _, ok := bridge.orders[chainOrderID]; !ok {
+		t.Fatalf("returned %s, not the id the bridge assigned", created.ChainOrderID)
+	}
+
+	params := CancelOrderParams{
+		OrderID:         "order-1",
+		UserAddress:     owner.Hex(),
+		RemainingAmount: decimal.NewFromInt(5000),
+	}
+	if _, err := adapter.CancelOrder(params); !errors.Is(err, ErrNoChainOrderID) {
+		t.Fatalf("expected ErrNoChainOrderID before the order is on-chain, got %v", err)
+	}
+
+	params.ChainOrderID = created.ChainOrderID
+	params.UserAddress = "0x00000000000000000000000000000000000000bb"
+	result, err := adapter.CancelOrder(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bridge.orders[chainOrderID].cancelled {
+		t.Fatalf("bridge order not cancelled")
+	}
+	// The bridge holds the order net of its 25 bps fee and refunds its owner
+	if !result.RefundAmount.Equal(decimal.NewFromInt(4988)) {
+		t.Fatalf("refund = %s, want 4988 from the OrderCancelled event", result.RefundAmount)
+	}
+	if result.Recipient != owner.Hex() {
+		t.Fatalf("recipient = %s, want the order's owner %s", result.Recipient, owner.Hex())
+	}
+	if _, err := adapter.CancelOrder(params); err == nil {
+		t.Fatalf("cancelling twice should revert")
+	}
+}
+
+func TestAmendSendsBridgeAmounts(t *testing.T) {
+	stub := newChainStub()
+	adapter := newTestEthereumAdapter(t, stub, false, RelayFallbackNone)
+	bridge := newBridgeStub(t, adapter.bridge)
+	stub.onBroadcast = bridge.handle
+	owner := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	created, err := adapter.CreateTWAPOrder(testCreateParams(owner))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	order := bridge.orders[common.HexToHash(created.ChainOrderID)]
+
+	params := AmendOrderParams{
+		OrderID:       "order-1",
+		ChainOrderID:  created.ChainOrderID,
+		UserAddress:   owner.Hex(),
+		SourceAmount:  decimal.NewFromInt(4000),
+		RefundAmount:  decimal.NewFromInt(1000),
+		WindowMinutes: 60,
+		Intervals:     6,
+		MaxSlippage:   100,
+		MinFillSize:   decimal.NewFromInt(500),
+	}
+	result, err := adapter.AmendOrder(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The bridge holds 5000 less its 25 bps fee, 4988, and 4000 becomes
+	// 3990 in its terms
+	if order.amount.Int64() != 3990 {
+		t.Fatalf("bridge amount = %s, want 3990", order.amount)
+	}
+	if !result.RefundAmount.Equal(decimal.NewFromInt(998)) {
+		t.Fatalf("refund = %s, want 998 from the OrderAmended event", result.RefundAmount)
+	}
+
+	// Going back to the original amount is an increase
+	params.SourceAmount = decimal.NewFromInt(5000)
+	if _, err := adapter.AmendOrder(params); err == nil {
+		t.Fatalf("the bridge rejects an increase")
+	}
+}
@@ -0,0 +1,117 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// abiSelector returns the 4-byte selector of a function signature
+func abiSelector(signature string) []byte {
+	return crypto.Keccak256([]byte(signature))[:4]
+}
+
+// abiEncoder builds ABI call data. Static values go in the head; dynamic
+// values are appended to the tail and referenced by offset.
+type abiEncoder struct {
+	head []byte
+	tail []byte
+	// dynamic holds the positions in head that take tail offsets
+	dynamic []int
+}
+
+func (e *abiEncoder) word(b []byte) *abiEncoder {
+	e.head = append(e.head, common.LeftPadBytes(b, 32)...)
+	return e
+}
+
+func (e *abiEncoder) uint(v *big.Int) *abiEncoder { return e.word(v.Bytes()) }
+
+func (e *abiEncoder) uint64(v uint64) *abiEncoder { return e.uint(new(big.Int).SetUint64(v)) }
+
+func (e *abiEncoder) address(a common.Address) *abiEncoder { return e.word(a.Bytes()) }
+
+func (e *abiEncoder) bytes32(h common.Hash) *abiEncoder { return e.word(h.Bytes()) }
+
+func (e *abiEncoder) bool(b bool) *abiEncoder {
+	if b {
+		return e.uint64(1)
+	}
+	return e.uint64(0)
+}
+
+// bytes appends a dynamic bytes value; strings encode the same way
+func (e *abiEncoder) bytes(b []byte) *abiEncoder {
+	e.dynamic = append(e.dynamic, len(e.head))
+	e.head = append(e.head, make([]byte, 32)...)
+
+	offset := len(e.tail)
+	e.tail = append(e.tail, common.LeftPadBytes(big.NewInt(int64(len(b))).Bytes(), 32)...)
+	e.tail = append(e.tail, b...)
+	if pad := len(b) % 32; pad != 0 {
+		e.tail = append(e.tail, make([]byte, 32-pad)...)
+	}
+
+	// Remember the offset within the tail; it is rebased in encode
+	copy(e.head[len(e.head)-32:], common.LeftPadBytes(big.NewInt(int64(offset)).Bytes(), 32))
+	return e
+}
+
+func (e *abiEncoder) string(s string) *abiEncoder { return e.bytes([]byte(s)) }
+
+// encode returns selector followed by the encoded arguments
+func (e *abiEncoder) encode(selector []byte) []byte {
+	head := append([]byte(nil), e.head...)
+	for _, pos := range e.dynamic {
+		offset := new(big.Int).SetBytes(head[pos : pos+32])
+		offset.Add(offset, big.NewInt(int64(len(e.head))))
+		copy(head[pos:pos+32], common.LeftPadBytes(offset.Bytes(), 32))
+	}
+
+	data := append([]byte(nil), selector...)
+	data = append(data, head...)
+	return append(data, e.tail...)
+}
+
+// abiWord returns the i-th 32-byte word of ABI-encoded return data
+func abiWord(data []byte, i int) ([]byte, error) {
+	if len(data) < (i+1)*32 {
+		return nil, fmt.Errorf("return data too short: %d bytes", len(data))
+	}
+	return data[i*32 : (i+1)*32], nil
+}
+
+// abiDecodeString decodes a single string return value
+func abiDecodeString(data []byte) (string, error) {
+	offsetWord, err := abiWord(data, 0)
+	if err != nil {
+		return "", err
+	}
+	offset := new(big.Int).SetBytes(offsetWord).Uint64()
+	if uint64(len(data)) < offset+32 {
+		return "", fmt.Errorf("invalid string offset %d", offset)
+	}
+
+	length := new(big.Int).SetBytes(data[offset : offset+32]).Uint64()
+	if uint64(len(data)) < offset+32+length {
+		return "", fmt.Errorf("invalid string length %d", length)
+	}
+	return string(data[offset+32 : offset+32+length]), nil
+}
+
+// ethCall executes a read-only call against the latest block
+func (c *rpcClient) ethCall(ctx context.Context, to common.Address, data []byte) ([]byte, error) {
+	var result hexutil.Bytes
+	call := map[string]interface{}{
+		"to":   to,
+		"data": hexutil.Bytes(data),
+	}
+	if err := c.call(ctx, &result, "eth_call", call, "latest"); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,170 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+)
+
+// errNoFeeMarket is returned for chains without EIP-1559 base fees
+var errNoFeeMarket = errors.New("chain has no EIP-1559 fee market")
+
+// txFees are the fee fields of a transaction. Legacy transactions only set
+// GasPrice.
+type txFees struct {
+	Legacy    bool
+	GasPrice  *big.Int
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+// maxPrice is the most a transaction with these fees can pay per gas
+func (f *txFees) maxPrice() *big.Int {
+	if f.Legacy {
+		return f.GasPrice
+	}
+	return f.GasFeeCap
+}
+
+// bump raises the fees by percent, and at least to floor when one is given,
+// for a replacement with the same nonce. It returns false when maxFee
+// leaves no room for a bump nodes would accept.
+func (f *txFees) bump(percent int, floor *txFees, maxFee *big.Int) (*txFees, bool) {
+	raise := func(v *big.Int, min *big.Int) *big.Int {
+		// Round up so small values still increase by the full percentage
+		bumped := new(big.Int).Mul(v, big.NewInt(int64(100+percent)))
+		bumped.Add(bumped, big.NewInt(99))
+		bumped.Div(bumped, big.NewInt(100))
+		if min != nil && min.Cmp(bumped) > 0 {
+			return new(big.Int).Set(min)
+		}
+		return bumped
+	}
+	capped := func(v *big.Int) bool {
+		return maxFee != nil && maxFee.Sign() > 0 && v.Cmp(maxFee) > 0
+	}
+
+	if f.Legacy {
+		var min *big.Int
+		if floor != nil {
+			min = floor.maxPrice()
+		}
+		price := raise(f.GasPrice, min)
+		if capped(price) {
+			return nil, false
+		}
+		return &txFees{Legacy: true, GasPrice: price}, true
+	}
+
+	var minTip, minCap *big.Int
+	if floor != nil && !floor.Legacy {
+		minTip, minCap = floor.GasTipCap, floor.GasFeeCap
+	}
+	bumped := &txFees{
+		GasTipCap: raise(f.GasTipCap, minTip),
+		GasFeeCap: raise(f.GasFeeCap, minCap),
+	}
+	if capped(bumped.GasFeeCap) {
+		return nil, false
+	}
+	if bumped.GasTipCap.Cmp(bumped.GasFeeCap) > 0 {
+		bumped.GasTipCap = new(big.Int).Set(bumped.GasFeeCap)
+	}
+	return bumped, true
+}
+
+// feeHistory is the result of eth_feeHistory
+type feeHistory struct {
+	OldestBlock   hexutil.Uint64   `json:"oldestBlock"`
+	BaseFeePerGas []*hexutil.Big   `json:"baseFeePerGas"`
+	Reward        [][]*hexutil.Big `json:"reward"`
+}
+
+func (c *rpcClient) feeHistory(ctx context.Context, blocks int, percentile float64) (*feeHistory, error) {
+	var result feeHistory
+	if err := c.call(ctx, &result, "eth_feeHistory", hexutil.Uint64(blocks), "latest", []float64{percentile}); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// feeEstimator derives transaction fees from recent blocks
+type feeEstimator struct {
+	client *rpcClient
+	config config.EthereumConfig
+}
+
+// estimate suggests EIP-1559 fees, falling back to a legacy gas price on
+// chains without a fee market
+func (f *feeEstimator) estimate(ctx context.Context) (*txFees, error) {
+	history, err := f.client.feeHistory(ctx, f.config.FeeHistoryBlocks, f.config.FeePercentile)
+	if err == nil {
+		fees, err := suggestFees(history, gweiToWei(f.config.MinPriorityFeeGwei), f.maxFee())
+		if err == nil {
+			return fees, nil
+		}
+		if !errors.Is(err, errNoFeeMarket) {
+			return nil, err
+		}
+	}
+
+	price := gweiToWei(float64(f.config.GasPrice))
+	if maxFee := f.maxFee(); maxFee != nil && price.Cmp(maxFee) > 0 {
+		price = maxFee
+	}
+	return &txFees{Legacy: true, GasPrice: price}, nil
+}
+
+// maxFee returns the configured cap on fees per gas, or nil for none
+func (f *feeEstimator) maxFee() *big.Int {
+	if f.config.MaxFeeGwei <= 0 {
+		return nil
+	}
+	return gweiToWei(f.config.MaxFeeGwei)
+}
+
+// suggestFees sets the tip to the median of the sampled reward percentile
+// and the fee cap to twice the next block's base fee plus the tip, which
+// survives several consecutive full blocks
+func suggestFees(history *feeHistory, minTip, maxFee *big.Int) (*txFees, error) {
+	if len(history.BaseFeePerGas) == 0 || history.BaseFeePerGas[len(history.BaseFeePerGas)-1] == nil {
+		return nil, errNoFeeMarket
+	}
+	baseFee := history.BaseFeePerGas[len(history.BaseFeePerGas)-1].ToInt()
+
+	var rewards []*big.Int
+	for _, block := range history.Reward {
+		if len(block) > 0 && block[0] != nil {
+			rewards = append(rewards, block[0].ToInt())
+		}
+	}
+
+	tip := new(big.Int).Set(minTip)
+	if len(rewards) > 0 {
+		sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+		if median := rewards[len(rewards)/2]; median.Cmp(tip) > 0 {
+			tip.Set(median)
+		}
+	}
+
+	feeCap := new(big.Int).Mul(baseFee, big.NewInt(2))
+	feeCap.Add(feeCap, tip)
+	if maxFee != nil && feeCap.Cmp(maxFee) > 0 {
+		feeCap.Set(maxFee)
+	}
+	if tip.Cmp(feeCap) > 0 {
+		tip.Set(feeCap)
+	}
+
+	return &txFees{GasTipCap: tip, GasFeeCap: feeCap}, nil
+}
+
+func gweiToWei(gwei float64) *big.Int {
+	return decimal.NewFromFloat(gwei).Shift(9).BigInt()
+}
@@ -0,0 +1,250 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+)
+
+// rpcRequest is a JSON-RPC 2.0 request
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response
+type rpcResponse struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// RPCError is an error returned by a JSON-RPC endpoint
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// rpcClient is a minimal JSON-RPC client for EVM nodes and relays. Requests
+// are spread over the endpoints of its pool.
+type rpcClient struct {
+	pool       *endpointPool
+	httpClient *http.Client
+	nextID     uint64
+
+	// headers optionally returns extra headers for a request body, used by
+	// relays that authenticate each payload
+	headers func(body []byte) (map[string]string, error)
+}
+
+func newRPCClient(url string, timeout time.Duration) *rpcClient {
+	return newPooledRPCClient([]string{url}, config.RPCFailoverConfig{}, timeout)
+}
+
+// newPooledRPCClient creates a client that fails over between urls, in
+// preference order
+func newPooledRPCClient(urls []string, policy config.RPCFailoverConfig, timeout time.Duration) *rpcClient {
+	return &rpcClient{
+		pool:       newEndpointPool(urls, policy),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// call invokes method and decodes the result into result, which may be nil
+func (c *rpcClient) call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      atomic.AddUint64(&c.nextID, 1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+
+	var headers map[string]string
+	if c.headers != nil {
+		headers, err = c.headers(body)
+		if err != nil {
+			return fmt.Errorf("failed to sign %s request: %w", method, err)
+		}
+	}
+
+	var rpcResp *rpcResponse
+	if stickyMethods[method] {
+		rpcResp, err = c.sendSticky(ctx, method, body, headers)
+	} else {
+		rpcResp, err = c.sendHedged(ctx, method, body, headers)
+	}
+	if err != nil {
+		return err
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(rpcResp.Result, result); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+
+	return nil
+}
+
+// post sends body to one endpoint and records the outcome against it
+func (c *rpcClient) post(ctx context.Context, e *rpcEndpoint, method string, body []byte, headers map[string]string) (*rpcResponse, error) {
+	start := time.Now()
+	rpcResp, err := c.roundTrip(ctx, e.url, method, body, headers)
+	if err != nil && ctx.Err() != nil {
+		// Cancelled by the caller or a faster hedge, which says nothing
+		// about the endpoint
+		c.pool.release(e)
+		return nil, err
+	}
+	c.pool.record(e, err, time.Since(start))
+	return rpcResp, err
+}
+
+func (c *rpcClient) roundTrip(ctx context.Context, url, method string, body []byte, headers map[string]string) (*rpcResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned HTTP %d", method, resp.StatusCode)
+	}
+
+	var rpcResp rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	return &rpcResp, nil
+}
+
+// txReceipt holds the fields of a transaction receipt the adapters use
+type txReceipt struct {
+	TxHash            common.Hash    `json:"transactionHash"`
+	BlockNumber       hexutil.Uint64 `json:"blockNumber"`
+	Status            hexutil.Uint64 `json:"status"`
+	GasUsed           hexutil.Uint64 `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big   `json:"effectiveGasPrice"`
+	Logs              []*txLog       `json:"logs"`
+}
+
+// txLog is an event emitted by a transaction
+type txLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+// findLog returns the first event with signature topic that address
+// emitted, or nil
+func (r *txReceipt) findLog(address common.Address, topic common.Hash) *txLog {
+	for _, log := range r.Logs {
+		if log.Address == address && len(log.Topics) > 0 && log.Topics[0] == topic {
+			return log
+		}
+	}
+	return nil
+}
+
+func (c *rpcClient) blockNumber(ctx context.Context) (uint64, error) {
+	var result hexutil.Uint64
+	if err := c.call(ctx, &result, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return uint64(result), nil
+}
+
+// gasPrice returns the node's suggested legacy gas price in wei
+func (c *rpcClient) gasPrice(ctx context.Context) (*big.Int, error) {
+	var result hexutil.Big
+	if err := c.call(ctx, &result, "eth_gasPrice"); err != nil {
+		return nil, err
+	}
+	return result.ToInt(), nil
+}
+
+func (c *rpcClient) peerCount(ctx context.Context) (uint64, error) {
+	var result hexutil.Uint64
+	if err := c.call(ctx, &result, "net_peerCount"); err != nil {
+		return 0, err
+	}
+	return uint64(result), nil
+}
+
+func (c *rpcClient) pendingNonce(ctx context.Context, address common.Address) (uint64, error) {
+	var result hexutil.Uint64
+	if err := c.call(ctx, &result, "eth_getTransactionCount", address, "pending"); err != nil {
+		return 0, err
+	}
+	return uint64(result), nil
+}
+
+func (c *rpcClient) sendRawTransaction(ctx context.Context, raw []byte) (common.Hash, error) {
+	var hash common.Hash
+	if err := c.call(ctx, &hash, "eth_sendRawTransaction", hexutil.Bytes(raw)); err != nil {
+		return common.Hash{}, err
+	}
+	return hash, nil
+}
+
+// transactionReceipt returns nil without error while the transaction is
+// still pending
+func (c *rpcClient) transactionReceipt(ctx context.Context, hash common.Hash) (*txReceipt, error) {
+	var receipt *txReceipt
+	if err := c.call(ctx, &receipt, "eth_getTransactionReceipt", hash); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+func (c *rpcClient) chainID(ctx context.Context) (*big.Int, error) {
+	var result hexutil.Big
+	if err := c.call(ctx, &result, "eth_chainId"); err != nil {
+		return nil, err
+	}
+	return result.ToInt(), nil
+}
+
+func (c *rpcClient) getCode(ctx context.Context, address common.Address) ([]byte, error) {
+	var result hexutil.Bytes
+	if err := c.call(ctx, &result, "eth_getCode", address, "latest"); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
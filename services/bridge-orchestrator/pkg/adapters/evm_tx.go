@@ -0,0 +1,128 @@
+package adapters
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// dynamicFeeTxType is the EIP-2718 type byte of EIP-1559 transactions
+const dynamicFeeTxType = 0x02
+
+// evmTx is an unsigned EVM transaction. GasPrice is used by legacy
+// transactions and GasTipCap/GasFeeCap by EIP-1559 ones.
+type evmTx struct {
+	Nonce     uint64
+	GasPrice  *big.Int
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+	GasLimit  uint64
+	To        common.Address
+	Value     *big.Int
+	Data      []byte
+}
+
+// evmSigner signs transactions for a single account on a single chain
+type evmSigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+	chainID *big.Int
+}
+
+func newEVMSigner(privateKey string, chainID int64) (*evmSigner, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(privateKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	return &evmSigner{
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+		chainID: big.NewInt(chainID),
+	}, nil
+}
+
+// signLegacy signs tx as an EIP-155 legacy transaction and returns the raw
+// encoding and its hash
+func (s *evmSigner) signLegacy(tx *evmTx) ([]byte, common.Hash, error) {
+	value := tx.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	sigHash, err := rlpHash([]interface{}{
+		tx.Nonce, tx.GasPrice, tx.GasLimit, tx.To, value, tx.Data,
+		s.chainID, uint(0), uint(0),
+	})
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+
+	sig, err := crypto.Sign(sigHash.Bytes(), s.key)
+	if err != nil {
+		return nil, common.Hash{}, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	r := new(big.Int).SetBytes(sig[:32])
+	sv := new(big.Int).SetBytes(sig[32:64])
+	v := new(big.Int).Add(big.NewInt(int64(sig[64])+35), new(big.Int).Mul(s.chainID, big.NewInt(2)))
+
+	raw, err := rlp.EncodeToBytes([]interface{}{
+		tx.Nonce, tx.GasPrice, tx.GasLimit, tx.To, value, tx.Data, v, r, sv,
+	})
+	if err != nil {
+		return nil, common.Hash{}, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+
+	return raw, crypto.Keccak256Hash(raw), nil
+}
+
+// signDynamic signs tx as an EIP-1559 transaction with an empty access list
+// and returns the typed encoding and its hash
+func (s *evmSigner) signDynamic(tx *evmTx) ([]byte, common.Hash, error) {
+	value := tx.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	fields := []interface{}{
+		s.chainID, tx.Nonce, tx.GasTipCap, tx.GasFeeCap, tx.GasLimit, tx.To, value, tx.Data,
+		[]interface{}{}, // access list
+	}
+
+	payload, err := rlp.EncodeToBytes(fields)
+	if err != nil {
+		return nil, common.Hash{}, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	sigHash := crypto.Keccak256Hash(append([]byte{dynamicFeeTxType}, payload...))
+
+	sig, err := crypto.Sign(sigHash.Bytes(), s.key)
+	if err != nil {
+		return nil, common.Hash{}, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	fields = append(fields,
+		uint(sig[64]),
+		new(big.Int).SetBytes(sig[:32]),
+		new(big.Int).SetBytes(sig[32:64]),
+	)
+	payload, err = rlp.EncodeToBytes(fields)
+	if err != nil {
+		return nil, common.Hash{}, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+
+	raw := append([]byte{dynamicFeeTxType}, payload...)
+	return raw, crypto.Keccak256Hash(raw), nil
+}
+
+func rlpHash(v interface{}) (common.Hash, error) {
+	encoded, err := rlp.EncodeToBytes(v)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
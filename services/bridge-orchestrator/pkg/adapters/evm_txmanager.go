@@ -0,0 +1,331 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+)
+
+// ErrOperationInFlight is returned when an operation is submitted while an
+// earlier submission of it is still waiting to be mined
+var ErrOperationInFlight = errors.New("operation already has a transaction in flight")
+
+// managedTx is a logical operation and every transaction sent for it. All
+// versions share a nonce, so exactly one of them can be mined.
+type managedTx struct {
+	OperationID  string
+	Nonce        uint64
+	GasLimit     uint64
+	To           common.Address
+	Data         []byte
+	Fees         *txFees
+	Raw          []byte
+	Hash         common.Hash   // latest version
+	Sent         []common.Hash // every version broadcast, oldest first
+	SentAt       time.Time
+	Replacements int
+	Route        string
+
+	// BundleTarget is the block the latest relay bundle targeted, while the
+	// transaction has not been broadcast publicly. Until that block passes
+	// without a receipt the bundle may still land, so the nonce stays taken.
+	BundleTarget uint64
+
+	// waiting is set while a caller is waiting on the operation
+	waiting bool
+}
+
+// txManager assigns nonces, prices transactions from the fee market and
+// replaces transactions that stay pending past the replace timeout
+type txManager struct {
+	client       *rpcClient
+	signer       *evmSigner
+	fees         *feeEstimator
+	config       config.EthereumConfig
+	logger       *zap.Logger
+	pollInterval time.Duration
+
+	mutex     sync.Mutex
+	nextNonce uint64
+	hasNonce  bool
+	pending   map[string]*managedTx // by operation ID
+}
+
+func newTxManager(client *rpcClient, signer *evmSigner, cfg config.EthereumConfig, logger *zap.Logger) *txManager {
+	return &txManager{
+		client:       client,
+		signer:       signer,
+		fees:         &feeEstimator{client: client, config: cfg},
+		config:       cfg,
+		logger:       logger,
+		pollInterval: defaultPollInterval,
+		pending:      make(map[string]*managedTx),
+	}
+}
+
+// prepare signs a transaction for operationID. If an earlier transaction
+// for the operation is still pending it is returned with resumed set, so a
+// retried operation waits on it instead of spending a second nonce. A zero
+// gasLimit uses the configured limit.
+func (m *txManager) prepare(ctx context.Context, operationID string, to common.Address, data []byte, gasLimit uint64) (tx *managedTx, resumed bool, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if tx, ok := m.pending[operationID]; ok {
+		if tx.waiting {
+			return nil, false, fmt.Errorf("%w: %s", ErrOperationInFlight, operationID)
+		}
+		return tx, true, nil
+	}
+
+	m.reclaimBundles(ctx)
+
+	nonce, err := m.client.pendingNonce(ctx, m.signer.address)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	// The node may not have seen our latest broadcast yet
+	if m.hasNonce && m.nextNonce > nonce {
+		nonce = m.nextNonce
+	}
+
+	fees, err := m.fees.estimate(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to estimate fees: %w", err)
+	}
+
+	if gasLimit == 0 {
+		gasLimit = m.config.GasLimit
+	}
+
+	tx = &managedTx{
+		OperationID: operationID,
+		Nonce:       nonce,
+		GasLimit:    gasLimit,
+		To:          to,
+		Data:        data,
+		Fees:        fees,
+	}
+	if err := m.sign(tx); err != nil {
+		return nil, false, err
+	}
+
+	m.nextNonce = nonce + 1
+	m.hasNonce = true
+	m.pending[operationID] = tx
+	return tx, false, nil
+}
+
+// sign signs tx with its current fees and makes it the latest version
+func (m *txManager) sign(tx *managedTx) error {
+	unsigned := &evmTx{
+		Nonce:    tx.Nonce,
+		GasLimit: tx.GasLimit,
+		To:       tx.To,
+		Data:     tx.Data,
+	}
+
+	var raw []byte
+	var hash common.Hash
+	var err error
+	if tx.Fees.Legacy {
+		unsigned.GasPrice = tx.Fees.GasPrice
+		raw, hash, err = m.signer.signLegacy(unsigned)
+	} else {
+		unsigned.GasTipCap = tx.Fees.GasTipCap
+		unsigned.GasFeeCap = tx.Fees.GasFeeCap
+		raw, hash, err = m.signer.signDynamic(unsigned)
+	}
+	if err != nil {
+		return err
+	}
+
+	tx.Raw = raw
+	tx.Hash = hash
+	return nil
+}
+
+// release forgets an operation whose transaction never reached the public
+// mempool, freeing its nonce for the next operation
+func (m *txManager) release(tx *managedTx) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.releaseLocked(tx)
+}
+
+func (m *txManager) releaseLocked(tx *managedTx) {
+	delete(m.pending, tx.OperationID)
+	if m.hasNonce && m.nextNonce == tx.Nonce+1 {
+		m.nextNonce = tx.Nonce
+	} else {
+		// Later nonces are in flight; resync from the node next time
+		m.hasNonce = false
+	}
+}
+
+// setBundleTarget records the block a bundle carrying tx was sent for
+func (m *txManager) setBundleTarget(tx *managedTx, block uint64) {
+	m.mutex.Lock()
+	tx.BundleTarget = block
+	m.mutex.Unlock()
+}
+
+// reclaimBundles frees the nonces of privately sent transactions nobody is
+// waiting on once their last bundle's target block has passed without a
+// receipt. Bundles that landed stay pending so a retry of the operation
+// resumes them. Called with the mutex held; anything that cannot be checked
+// stays reserved.
+func (m *txManager) reclaimBundles(ctx context.Context) {
+	for _, tx := range m.pending {
+		if tx.waiting || tx.BundleTarget == 0 || len(tx.Sent) > 0 {
+			continue
+		}
+
+		// Height first, as in waitForBlock, so a receipt from the target
+		// block is not missed
+		current, err := m.client.blockNumber(ctx)
+		if err != nil || current < tx.BundleTarget {
+			continue
+		}
+		receipt, err := m.client.transactionReceipt(ctx, tx.Hash)
+		if err != nil || receipt != nil {
+			continue
+		}
+
+		m.logger.Info("Reclaiming nonce of expired bundle",
+			zap.String("operation", tx.OperationID),
+			zap.Uint64("nonce", tx.Nonce),
+			zap.Uint64("target_block", tx.BundleTarget))
+		m.releaseLocked(tx)
+	}
+}
+
+// broadcast sends the latest version of tx to the public mempool
+func (m *txManager) broadcast(ctx context.Context, tx *managedTx) error {
+	if _, err := m.client.sendRawTransaction(ctx, tx.Raw); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	tx.Sent = append(tx.Sent, tx.Hash)
+	tx.SentAt = time.Now()
+	m.mutex.Unlock()
+	return nil
+}
+
+// waitMined polls for a receipt of any version of tx, replacing it with
+// bumped fees each time it stays pending past the replace timeout
+func (m *txManager) waitMined(ctx context.Context, tx *managedTx) (*txReceipt, error) {
+	m.setWaiting(tx, true)
+	defer m.setWaiting(tx, false)
+
+	for {
+		for i := len(tx.Sent) - 1; i >= 0; i-- {
+			receipt, err := m.client.transactionReceipt(ctx, tx.Sent[i])
+			if err != nil {
+				return nil, fmt.Errorf("failed to get receipt: %w", err)
+			}
+			if receipt != nil {
+				m.finish(tx, receipt)
+				return receipt, nil
+			}
+		}
+
+		if time.Since(tx.SentAt) >= m.config.TxReplaceTimeout && tx.Replacements < m.config.MaxReplacements {
+			m.replace(ctx, tx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("transaction %s not mined: %w", tx.Hash.Hex(), ctx.Err())
+		case <-time.After(m.pollInterval):
+		}
+	}
+}
+
+func (m *txManager) setWaiting(tx *managedTx, waiting bool) {
+	m.mutex.Lock()
+	tx.waiting = waiting
+	m.mutex.Unlock()
+}
+
+// replace re-signs tx at the same nonce with bumped fees and broadcasts it.
+// Failures are logged; the earlier versions remain valid.
+func (m *txManager) replace(ctx context.Context, tx *managedTx) {
+	current, err := m.fees.estimate(ctx)
+	if err != nil {
+		current = nil
+	}
+
+	fees, ok := tx.Fees.bump(m.config.FeeBumpPercent, current, m.fees.maxFee())
+	if !ok {
+		m.logger.Warn("Pending transaction at fee cap, not replacing",
+			zap.String("operation", tx.OperationID),
+			zap.String("tx_hash", tx.Hash.Hex()))
+		tx.SentAt = time.Now()
+		return
+	}
+
+	previous := *tx
+	tx.Fees = fees
+	if err := m.sign(tx); err != nil {
+		m.logger.Error("Failed to sign replacement", zap.String("operation", tx.OperationID), zap.Error(err))
+		*tx = previous
+		return
+	}
+
+	if err := m.broadcast(ctx, tx); err != nil {
+		// A version may have been mined since the last receipt check, in
+		// which case the node rejects the nonce and the next poll finds it
+		level := m.logger.Warn
+		if isNonceTaken(err) {
+			level = m.logger.Debug
+		}
+		level("Replacement rejected",
+			zap.String("operation", tx.OperationID),
+			zap.String("tx_hash", tx.Hash.Hex()),
+			zap.Error(err))
+		*tx = previous
+		tx.SentAt = time.Now()
+		return
+	}
+
+	tx.Replacements++
+	m.logger.Info("Replaced stuck transaction",
+		zap.String("operation", tx.OperationID),
+		zap.Uint64("nonce", tx.Nonce),
+		zap.String("replaced", previous.Hash.Hex()),
+		zap.String("tx_hash", tx.Hash.Hex()),
+		zap.String("max_fee_per_gas", fees.maxPrice().String()),
+		zap.Int("replacements", tx.Replacements))
+}
+
+// finish records which version of tx was mined and stops tracking it
+func (m *txManager) finish(tx *managedTx, receipt *txReceipt) {
+	m.mutex.Lock()
+	delete(m.pending, tx.OperationID)
+	m.mutex.Unlock()
+
+	if receipt.TxHash != tx.Hash {
+		m.logger.Info("Earlier version of replaced transaction mined",
+			zap.String("operation", tx.OperationID),
+			zap.String("mined", receipt.TxHash.Hex()),
+			zap.String("latest", tx.Hash.Hex()))
+	}
+}
+
+// isNonceTaken reports whether a node rejected a transaction because its
+// nonce is already used or the same transaction is already known
+func isNonceTaken(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") || strings.Contains(msg, "already known")
+}
@@ -0,0 +1,198 @@
+package adapters
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// decodedDynamicTx is an EIP-1559 transaction as it appears on the wire
+type decodedDynamicTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	GasLimit   uint64
+	To         common.Address
+	Value      *big.Int
+	Data       []byte
+	AccessList rlp.RawValue
+	V          uint
+	R          *big.Int
+	S          *big.Int
+}
+
+// decodeDynamicTx decodes raw and recovers its sender
+func decodeDynamicTx(t *testing.T, raw []byte) (*decodedDynamicTx, common.Address) {
+	t.Helper()
+	if raw[0] != dynamicFeeTxType {
+		t.Fatalf("expected a type 2 transaction, got type byte %#x", raw[0])
+	}
+
+	var tx decodedDynamicTx
+	if err := rlp.DecodeBytes(raw[1:], &tx); err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+
+	unsigned, _ := rlp.EncodeToBytes([]interface{}{
+		tx.ChainID, tx.Nonce, tx.GasTipCap, tx.GasFeeCap, tx.GasLimit, tx.To, tx.Value, tx.Data, tx.AccessList,
+	})
+	sigHash := crypto.Keccak256(append([]byte{dynamicFeeTxType}, unsigned...))
+
+	sig := make([]byte, 65)
+	tx.R.FillBytes(sig[:32])
+	tx.S.FillBytes(sig[32:64])
+	sig[64] = byte(tx.V)
+
+	pub, err := crypto.SigToPub(sigHash, sig)
+	if err != nil {
+		t.Fatalf("failed to recover sender: %v", err)
+	}
+	return &tx, crypto.PubkeyToAddress(*pub)
+}
+
+func gwei(g int64) *big.Int { return new(big.Int).Mul(big.NewInt(g), big.NewInt(1e9)) }
+
+func TestSuggestFees(t *testing.T) {
+	history := &feeHistory{
+		BaseFeePerGas: []*hexutil.Big{(*hexutil.Big)(gwei(10)), (*hexutil.Big)(gwei(30))},
+		Reward:        [][]*hexutil.Big{{(*hexutil.Big)(gwei(2))}, {(*hexutil.Big)(gwei(4))}, {(*hexutil.Big)(gwei(3))}},
+	}
+
+	fees, err := suggestFees(history, gwei(1), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fees.GasTipCap.Cmp(gwei(3)) != 0 || fees.GasFeeCap.Cmp(gwei(63)) != 0 {
+		t.Fatalf("got tip %s cap %s, want median tip 3 gwei and cap 2*30+3 gwei", fees.GasTipCap, fees.GasFeeCap)
+	}
+
+	fees, _ = suggestFees(history, gwei(5), gwei(40))
+	if fees.GasTipCap.Cmp(gwei(5)) != 0 || fees.GasFeeCap.Cmp(gwei(40)) != 0 {
+		t.Fatalf("got tip %s cap %s, want the minimum tip and the configured cap", fees.GasTipCap, fees.GasFeeCap)
+	}
+
+	if _, err := suggestFees(&feeHistory{}, gwei(1), nil); err != errNoFeeMarket {
+		t.Fatalf("expected errNoFeeMarket, got %v", err)
+	}
+}
+
+func TestFeeBump(t *testing.T) {
+	fees := &txFees{GasTipCap: gwei(2), GasFeeCap: gwei(40)}
+
+	bumped, ok := fees.bump(15, nil, gwei(100))
+	if !ok || bumped.GasTipCap.Cmp(big.NewInt(2.3e9)) != 0 || bumped.GasFeeCap.Cmp(gwei(46)) != 0 {
+		t.Fatalf("unexpected bump %+v", bumped)
+	}
+
+	// A market that moved further than the bump sets the new fees
+	bumped, _ = fees.bump(15, &txFees{GasTipCap: gwei(5), GasFeeCap: gwei(80)}, gwei(100))
+	if bumped.GasTipCap.Cmp(gwei(5)) != 0 || bumped.GasFeeCap.Cmp(gwei(80)) != 0 {
+		t.Fatalf("bump ignored the current market: %+v", bumped)
+	}
+
+	if _, ok := fees.bump(15, nil, gwei(45)); ok {
+		t.Fatalf("bump past the fee cap should not be allowed")
+	}
+
+	legacy, ok := (&txFees{Legacy: true, GasPrice: big.NewInt(100)}).bump(10, nil, nil)
+	if !ok || legacy.GasPrice.Int64() != 110 {
+		t.Fatalf("unexpected legacy bump %+v", legacy)
+	}
+}
+
+func TestIntervalSignedWithFeeHistory(t *testing.T) {
+	stub := newChainStub()
+	adapter := newTestEthereumAdapter(t, stub, false, RelayFallbackNone)
+
+	result, err := adapter.ExecuteTWAPInterval(testIntervalParams(false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tx, sender := decodeDynamicTx(t, stub.publicTxs[0])
+	if sender != adapter.signer.address {
+		t.Fatalf("sender %s, want %s", sender.Hex(), adapter.signer.address.Hex())
+	}
+	if tx.ChainID.Int64() != 11155111 || tx.Nonce != 7 || tx.To != adapter.bridge {
+		t.Fatalf("unexpected transaction %+v", tx)
+	}
+	// Median tip of 1 and 3 gwei rewards, next base fee 12 gwei
+	if tx.GasTipCap.Cmp(gwei(3)) != 0 || tx.GasFeeCap.Cmp(gwei(27)) != 0 {
+		t.Fatalf("got tip %s cap %s", tx.GasTipCap, tx.GasFeeCap)
+	}
+	if result.GasPrice.BigInt().Cmp(gwei(27)) != 0 {
+		t.Fatalf("gas price %s should fall back to the fee cap without a receipt price", result.GasPrice)
+	}
+}
+
+func TestIntervalFallsBackToLegacyGas(t *testing.T) {
+	stub := newChainStub()
+	stub.noFeeMarket = true
+	adapter := newTestEthereumAdapter(t, stub, false, RelayFallbackNone)
+
+	if _, err := adapter.ExecuteTWAPInterval(testIntervalParams(false)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if raw := stub.publicTxs[0]; raw[0] < 0xc0 {
+		t.Fatalf("expected a legacy transaction, got type byte %#x", raw[0])
+	}
+}
+
+func TestStuckTransactionReplaced(t *testing.T) {
+	stub := newChainStub()
+	stub.stallPublic = 2
+	adapter := newTestEthereumAdapter(t, stub, false, RelayFallbackNone)
+	adapter.txs.config.TxReplaceTimeout = time.Millisecond
+
+	result, err := adapter.ExecuteTWAPInterval(testIntervalParams(false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(stub.publicTxs) != 3 || result.Replacements != 2 {
+		t.Fatalf("expected two replacements, got %d broadcasts and %d replacements", len(stub.publicTxs), result.Replacements)
+	}
+
+	first, _ := decodeDynamicTx(t, stub.publicTxs[0])
+	last, _ := decodeDynamicTx(t, stub.publicTxs[2])
+	if first.Nonce != last.Nonce {
+		t.Fatalf("replacement used nonce %d, original %d", last.Nonce, first.Nonce)
+	}
+	if last.GasFeeCap.Cmp(first.GasFeeCap) <= 0 || last.GasTipCap.Cmp(first.GasTipCap) <= 0 {
+		t.Fatalf("replacement fees were not bumped")
+	}
+
+	// The record points at what actually landed
+	if result.TxHash != crypto.Keccak256Hash(stub.publicTxs[2]).Hex() {
+		t.Fatalf("tx hash %s is not the mined replacement", result.TxHash)
+	}
+	if len(adapter.txs.pending) != 0 {
+		t.Fatalf("mined operation still tracked as pending")
+	}
+}
+
+func TestReleasedNonceIsReused(t *testing.T) {
+	stub := newChainStub()
+	adapter := newTestEthereumAdapter(t, stub, true, RelayFallbackNone)
+
+	if _, err := adapter.ExecuteTWAPInterval(testIntervalParams(true)); err == nil {
+		t.Fatalf("expected the bundle to go unincluded")
+	}
+
+	params := testIntervalParams(false)
+	params.IntervalNumber = 1
+	if _, err := adapter.ExecuteTWAPInterval(params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tx, _ := decodeDynamicTx(t, stub.publicTxs[0])
+	if tx.Nonce != 7 {
+		t.Fatalf("nonce %d, want the released nonce 7", tx.Nonce)
+	}
+}
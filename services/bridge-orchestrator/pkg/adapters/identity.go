@@ -0,0 +1,278 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+)
+
+// Network identity errors, returned by Connect
+var (
+	ErrNetworkMismatch   = errors.New("remote network does not match configuration")
+	ErrBridgeNotDeployed = errors.New("bridge not found on remote network")
+	ErrBridgeInterface   = errors.New("bridge does not expose the expected interface")
+)
+
+// expectedBridgeVersion is the bridge contract VERSION this adapter speaks
+const expectedBridgeVersion = 1
+
+// bridgeVersionSelector is the selector of the bridge's VERSION() getter
+var bridgeVersionSelector = abiSelector("VERSION()")
+
+// stellarPassphrases maps configured Stellar networks to their passphrases
+var stellarPassphrases = map[string]string{
+	"public":    "Public Global Stellar Network ; September 2015",
+	"mainnet":   "Public Global Stellar Network ; September 2015",
+	"testnet":   "Test SDF Network ; September 2015",
+	"futurenet": "Test SDF Future Network ; October 2022",
+}
+
+// bitcoinChains maps configured Bitcoin networks to the chain reported by
+// getblockchaininfo
+var bitcoinChains = map[string]string{
+	"mainnet":  "main",
+	"main":     "main",
+	"testnet":  "test",
+	"test":     "test",
+	"testnet4": "testnet4",
+	"signet":   "signet",
+	"regtest":  "regtest",
+}
+
+// ConnectResult is what an adapter verified about its remote network when
+// connecting
+type ConnectResult struct {
+	ChainID         string `json:"chain_id"`
+	Connected       bool   `json:"connected"`
+	Verified        bool   `json:"verified"` // false when identity checks were skipped
+	ExpectedNetwork string `json:"expected_network,omitempty"`
+	RemoteNetwork   string `json:"remote_network,omitempty"`
+	Bridge          string `json:"bridge,omitempty"`
+	BridgeVerified  bool   `json:"bridge_verified"`
+	Error           string `json:"error,omitempty"`
+}
+
+// identityCheck verifies an adapter's remote network and bridge, recording
+// what it found in result
+type identityCheck func(ctx context.Context, result *ConnectResult) error
+
+// evmIdentityCheck compares every endpoint's eth_chainId with chainID and,
+// when a bridge is configured, checks it has code of the expected version
+// exposing the functions the adapter calls
+func evmIdentityCheck(client *rpcClient, chainID int64, bridge string) identityCheck {
+	return func(ctx context.Context, result *ConnectResult) error {
+		result.ExpectedNetwork = fmt.Sprintf("%d", chainID)
+
+		// A fallback on another network would only be found on failover
+		for i, endpoint := range client.pool.endpoints {
+			remote, err := newRPCClient(endpoint.url, rpcTimeout).chainID(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get chain ID from %s: %w", redactURL(endpoint.url), err)
+			}
+			if i == 0 {
+				result.RemoteNetwork = remote.String()
+			}
+			if remote.Cmp(big.NewInt(chainID)) != 0 {
+				result.RemoteNetwork = remote.String()
+				return fmt.Errorf("%w: %s serves chain %s, configured for %d", ErrNetworkMismatch, redactURL(endpoint.url), remote, chainID)
+			}
+		}
+
+		if bridge == "" {
+			return nil
+		}
+		result.Bridge = bridge
+		if !common.IsHexAddress(bridge) {
+			return fmt.Errorf("invalid bridge address: %s", bridge)
+		}
+		address := common.HexToAddress(bridge)
+
+		code, err := client.getCode(ctx, address)
+		if err != nil {
+			return fmt.Errorf("failed to get bridge code: %w", err)
+		}
+		if len(code) == 0 {
+			return fmt.Errorf("%w: no code at %s", ErrBridgeNotDeployed, address.Hex())
+		}
+
+		raw, err := client.ethCall(ctx, address, bridgeVersionSelector)
+		if err != nil {
+			return fmt.Errorf("%w: VERSION() failed: %v", ErrBridgeInterface, err)
+		}
+		version, err := abiWord(raw, 0)
+		if err != nil || new(big.Int).SetBytes(version).Int64() != expectedBridgeVersion {
+			return fmt.Errorf("%w: VERSION() is not %d", ErrBridgeInterface, expectedBridgeVersion)
+		}
+
+		// Solidity dispatchers compare against each selector, so every
+		// external function's selector appears in the code
+		for name, selector := range map[string][]byte{
+			"executeTWAPInterval": executeTWAPIntervalSelector,
+			"createTWAPOrderFor":  createTWAPOrderForSelector,
+		} {
+			if !bytes.Contains(code, selector) {
+				return fmt.Errorf("%w: %s is missing", ErrBridgeInterface, name)
+			}
+		}
+
+		result.BridgeVerified = true
+		return nil
+	}
+}
+
+// cosmosIdentityCheck compares each CometBFT node's network with the
+// configured chain-id and checks the bridge contract exists
+func cosmosIdentityCheck(cfg config.CosmosConfig) identityCheck {
+	return func(ctx context.Context, result *ConnectResult) error {
+		result.ExpectedNetwork = cfg.ChainID
+
+		for _, endpoint := range endpointList(cfg.RPCURL, cfg.RPCURLs) {
+			var status struct {
+				Result struct {
+					NodeInfo struct {
+						Network string `json:"network"`
+					} `json:"node_info"`
+				} `json:"result"`
+			}
+			if _, err := getJSON(ctx, strings.TrimRight(endpoint, "/")+"/status", &status); err != nil {
+				return fmt.Errorf("failed to get node status from %s: %w", redactURL(endpoint), err)
+			}
+			result.RemoteNetwork = status.Result.NodeInfo.Network
+			if result.RemoteNetwork != cfg.ChainID {
+				return fmt.Errorf("%w: %s serves %q, configured for %q", ErrNetworkMismatch, redactURL(endpoint), result.RemoteNetwork, cfg.ChainID)
+			}
+		}
+
+		if cfg.BridgeAddress == "" || cfg.RestURL == "" {
+			return nil
+		}
+		result.Bridge = cfg.BridgeAddress
+
+		var contract struct {
+			ContractInfo struct {
+				CodeID string `json:"code_id"`
+			} `json:"contract_info"`
+		}
+		endpoint := strings.TrimRight(cfg.RestURL, "/") + "/cosmwasm/wasm/v1/contract/" + url.PathEscape(cfg.BridgeAddress)
+		found, err := getJSON(ctx, endpoint, &contract)
+		if err != nil {
+			return fmt.Errorf("failed to get bridge contract: %w", err)
+		}
+		if !found || contract.ContractInfo.CodeID == "" {
+			return fmt.Errorf("%w: no contract at %s", ErrBridgeNotDeployed, cfg.BridgeAddress)
+		}
+
+		result.BridgeVerified = true
+		return nil
+	}
+}
+
+// stellarIdentityCheck compares each Horizon's network passphrase with the one
+// for the configured network and checks the bridge account exists.
+// Contract (C...) bridges are not visible through Horizon and are skipped.
+func stellarIdentityCheck(cfg config.StellarConfig) identityCheck {
+	return func(ctx context.Context, result *ConnectResult) error {
+		expected, ok := stellarPassphrases[cfg.Network]
+		if !ok {
+			return fmt.Errorf("unknown stellar network: %s", cfg.Network)
+		}
+		result.ExpectedNetwork = expected
+
+		horizons := endpointList(cfg.HorizonURL, cfg.HorizonURLs)
+		for _, endpoint := range horizons {
+			var root struct {
+				NetworkPassphrase string `json:"network_passphrase"`
+			}
+			if _, err := getJSON(ctx, strings.TrimRight(endpoint, "/")+"/", &root); err != nil {
+				return fmt.Errorf("failed to get horizon root from %s: %w", redactURL(endpoint), err)
+			}
+			result.RemoteNetwork = root.NetworkPassphrase
+			if root.NetworkPassphrase != expected {
+				return fmt.Errorf("%w: %s serves %q, configured for %s", ErrNetworkMismatch, redactURL(endpoint), root.NetworkPassphrase, cfg.Network)
+			}
+		}
+		horizon := strings.TrimRight(horizons[0], "/")
+
+		if !strings.HasPrefix(cfg.BridgeAddress, "G") {
+			return nil
+		}
+		result.Bridge = cfg.BridgeAddress
+
+		var account struct {
+			AccountID string `json:"account_id"`
+		}
+		found, err := getJSON(ctx, horizon+"/accounts/"+url.PathEscape(cfg.BridgeAddress), &account)
+		if err != nil {
+			return fmt.Errorf("failed to get bridge account: %w", err)
+		}
+		if !found {
+			return fmt.Errorf("%w: account %s does not exist", ErrBridgeNotDeployed, cfg.BridgeAddress)
+		}
+
+		result.BridgeVerified = true
+		return nil
+	}
+}
+
+// bitcoinIdentityCheck compares each node's getblockchaininfo chain with
+// the configured network. Bitcoin HTLCs are scripts, so there is no bridge.
+func bitcoinIdentityCheck(cfg config.BitcoinConfig) identityCheck {
+	return func(ctx context.Context, result *ConnectResult) error {
+		expected, ok := bitcoinChains[cfg.Network]
+		if !ok {
+			return fmt.Errorf("unknown bitcoin network: %s", cfg.Network)
+		}
+		result.ExpectedNetwork = expected
+
+		for _, endpoint := range endpointList(cfg.RPCURL, cfg.RPCURLs) {
+			var info struct {
+				Chain string `json:"chain"`
+			}
+			if err := newRPCClient(endpoint, rpcTimeout).call(ctx, &info, "getblockchaininfo"); err != nil {
+				return fmt.Errorf("failed to get blockchain info from %s: %w", redactURL(endpoint), err)
+			}
+			result.RemoteNetwork = info.Chain
+			if info.Chain != expected {
+				return fmt.Errorf("%w: %s serves %q, configured for %s", ErrNetworkMismatch, redactURL(endpoint), info.Chain, cfg.Network)
+			}
+		}
+		return nil
+	}
+}
+
+// getJSON decodes a GET response into out. A 404 reports found as false
+// rather than an error.
+func getJSON(ctx context.Context, endpoint string, out interface{}) (found bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := (&http.Client{Timeout: rpcTimeout}).Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,133 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+)
+
+// bridgeCode stands in for deployed bytecode: a dispatcher holding the
+// selectors the adapter calls
+func bridgeCode(selectors ...[]byte) []byte {
+	code := []byte{0x60, 0x80, 0x60, 0x40}
+	for _, selector := range selectors {
+		code = append(code, 0x63) // PUSH4
+		code = append(code, selector...)
+	}
+	return code
+}
+
+func TestEVMIdentityCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		chainID int64
+		code    []byte
+		version int64
+		want    error
+	}{
+		{"matching network and bridge", 11155111, bridgeCode(executeTWAPIntervalSelector, createTWAPOrderForSelector), 1, nil},
+		{"RPC on another network", 1, bridgeCode(executeTWAPIntervalSelector, createTWAPOrderForSelector), 1, ErrNetworkMismatch},
+		{"no code at bridge address", 11155111, nil, 1, ErrBridgeNotDeployed},
+		{"unexpected bridge version", 11155111, bridgeCode(executeTWAPIntervalSelector, createTWAPOrderForSelector), 2, ErrBridgeInterface},
+		{"bridge missing a function", 11155111, bridgeCode(executeTWAPIntervalSelector), 1, ErrBridgeInterface},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newChainStub()
+			stub.chainID = tt.chainID
+			stub.code = tt.code
+			stub.contracts = func(_ common.Address, data []byte) []byte {
+				if !bytes.Equal(data, bridgeVersionSelector) {
+					return nil
+				}
+				return common.LeftPadBytes(big.NewInt(tt.version).Bytes(), 32)
+			}
+
+			adapter := newTestEthereumAdapter(t, stub, false, RelayFallbackNone)
+			err := adapter.Connect()
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+
+			result := adapter.ConnectResult()
+			if adapter.IsConnected() != (tt.want == nil) || result.Connected != (tt.want == nil) {
+				t.Fatalf("connected %v after %v", adapter.IsConnected(), err)
+			}
+			if tt.want == nil && (!result.Verified || !result.BridgeVerified) {
+				t.Fatalf("expected a verified result, got %+v", result)
+			}
+		})
+	}
+}
+
+func serveJSON(t *testing.T, routes map[string]interface{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := routes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestConnectAllReportsEveryChain(t *testing.T) {
+	cometbft := serveJSON(t, map[string]interface{}{
+		"/status": map[string]interface{}{
+			"result": map[string]interface{}{"node_info": map[string]string{"network": "theta-testnet-001"}},
+		},
+	})
+	horizon := serveJSON(t, map[string]interface{}{
+		"/": map[string]string{"network_passphrase": stellarPassphrases["public"]},
+	})
+
+	cosmos, _ := NewCosmosAdapter(config.CosmosConfig{ChainID: "theta-testnet-001", RPCURL: cometbft.URL}, nil)
+	stellar, _ := NewStellarAdapter(config.StellarConfig{Network: "testnet", HorizonURL: horizon.URL}, nil)
+	bitcoin, _ := NewBitcoinAdapter(config.BitcoinConfig{Network: "testnet"}, nil)
+
+	manager := &Manager{
+		adapters: map[string]ChainAdapter{"stellar": stellar, "cosmos": cosmos, "bitcoin": bitcoin},
+		logger:   zap.NewNop(),
+	}
+
+	err := manager.ConnectAll()
+	var connectErr *ConnectError
+	if !errors.As(err, &connectErr) {
+		t.Fatalf("expected a ConnectError, got %v", err)
+	}
+
+	report := connectErr.Report
+	if report.Failed != 1 || len(report.Results) != 3 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	byChain := map[string]*ConnectResult{}
+	for _, result := range report.Results {
+		byChain[result.ChainID] = result
+	}
+	if r := byChain["cosmos"]; !r.Connected || !r.Verified || r.RemoteNetwork != "theta-testnet-001" {
+		t.Fatalf("unexpected cosmos result %+v", r)
+	}
+	if r := byChain["stellar"]; r.Connected || r.RemoteNetwork != stellarPassphrases["public"] || r.Error == "" {
+		t.Fatalf("stellar on the public network should fail a testnet config: %+v", r)
+	}
+	// No RPC configured, nothing to verify
+	if r := byChain["bitcoin"]; !r.Connected || r.Verified {
+		t.Fatalf("unexpected bitcoin result %+v", r)
+	}
+	if manager.GetConnectReport() != report {
+		t.Fatalf("report not retained")
+	}
+}
@@ -2,6 +2,8 @@ package adapters
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"go.uber.org/zap"
@@ -25,9 +27,10 @@ type ChainAdapter interface {
 	GetBalance(tokenAddress string) (string, error)
 
 	// TWAP operations
-	CreateTWAPOrder(params CreateTWAPOrderParams) (string, error)
+	CreateTWAPOrder(params CreateTWAPOrderParams) (*CreateOrderResult, error)
 	ExecuteTWAPInterval(params ExecuteIntervalParams) (*ExecutionResult, error)
-	CancelOrder(orderID string) error
+	CancelOrder(params CancelOrderParams) (*CancelResult, error)
+	AmendOrder(params AmendOrderParams) (*AmendResult, error)
 	GetOrderStatus(orderID string) (*OrderStatus, error)
 
 	// HTLC operations
@@ -55,6 +58,35 @@ type Manager struct {
 	config   *config.Config
 	logger   *zap.Logger
 	mutex    sync.RWMutex
+
+	connectReport *ConnectReport
+}
+
+// ConnectReport summarises ConnectAll, one result per adapter
+type ConnectReport struct {
+	Results []*ConnectResult `json:"results"`
+	Failed  int              `json:"failed"`
+}
+
+// ConnectError is returned by ConnectAll when any adapter fails to connect
+type ConnectError struct {
+	Report *ConnectReport
+}
+
+func (e *ConnectError) Error() string {
+	var failures []string
+	for _, result := range e.Report.Results {
+		if !result.Connected {
+			failures = append(failures, result.ChainID+": "+result.Error)
+		}
+	}
+	return fmt.Sprintf("failed to connect %d adapters: %s", e.Report.Failed, strings.Join(failures, "; "))
+}
+
+// connectReporter is implemented by adapters that record what Connect
+// verified
+type connectReporter interface {
+	ConnectResult() *ConnectResult
 }
 
 // NewManager creates a new adapter manager
@@ -75,6 +107,12 @@ func NewManager(cfg *config.Config, logger *zap.Logger) (*Manager, error) {
 			continue
 		}
 
+		if !cfg.ChainIdentityChecks {
+			if checked, ok := adapter.(interface{ disableIdentityChecks() }); ok {
+				checked.disableIdentityChecks()
+			}
+		}
+
 		manager.adapters[chainID] = adapter
 		logger.Info("Adapter created", zap.String("chain_id", chainID))
 	}
@@ -135,30 +173,75 @@ func (m *Manager) GetAdapterCount() int {
 	return len(m.adapters)
 }
 
-// ConnectAll connects all adapters
+// ConnectAll connects all adapters concurrently, each verifying its remote
+// network, and fails with a *ConnectError carrying the full report if any
+// adapter could not connect
 func (m *Manager) ConnectAll() error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	var errors []error
-	for chainID, adapter := range m.adapters {
-		if err := adapter.Connect(); err != nil {
-			m.logger.Error("Failed to connect adapter", 
-				zap.String("chain_id", chainID), 
-				zap.Error(err))
-			errors = append(errors, fmt.Errorf("failed to connect %s: %w", chainID, err))
-		} else {
-			m.logger.Info("Adapter connected", zap.String("chain_id", chainID))
+	chainIDs := make([]string, 0, len(m.adapters))
+	for chainID := range m.adapters {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Strings(chainIDs)
+
+	report := &ConnectReport{Results: make([]*ConnectResult, len(chainIDs))}
+	var wg sync.WaitGroup
+	for i, chainID := range chainIDs {
+		wg.Add(1)
+		go func(i int, chainID string, adapter ChainAdapter) {
+			defer wg.Done()
+
+			err := adapter.Connect()
+
+			var result *ConnectResult
+			if reporter, ok := adapter.(connectReporter); ok && reporter.ConnectResult() != nil {
+				result = reporter.ConnectResult()
+			} else {
+				result = &ConnectResult{ChainID: chainID, Connected: err == nil}
+				if err != nil {
+					result.Error = err.Error()
+				}
+			}
+			report.Results[i] = result
+		}(i, chainID, m.adapters[chainID])
+	}
+	wg.Wait()
+
+	for _, result := range report.Results {
+		if !result.Connected {
+			report.Failed++
+			m.logger.Error("Failed to connect adapter",
+				zap.String("chain_id", result.ChainID),
+				zap.String("expected_network", result.ExpectedNetwork),
+				zap.String("remote_network", result.RemoteNetwork),
+				zap.String("error", result.Error))
+			continue
 		}
+		m.logger.Info("Adapter connected",
+			zap.String("chain_id", result.ChainID),
+			zap.Bool("verified", result.Verified),
+			zap.String("network", result.RemoteNetwork),
+			zap.Bool("bridge_verified", result.BridgeVerified))
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to connect %d adapters", len(errors))
+	m.connectReport = report
+	if report.Failed > 0 {
+		return &ConnectError{Report: report}
 	}
 
 	return nil
 }
 
+// GetConnectReport returns the report of the last ConnectAll, or nil
+func (m *Manager) GetConnectReport() *ConnectReport {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.connectReport
+}
+
 // DisconnectAll disconnects all adapters
 func (m *Manager) DisconnectAll() error {
 	m.mutex.Lock()
@@ -0,0 +1,258 @@
+package adapters
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+)
+
+// Relay fallback policies, applied when a protected transaction is not
+// included through the relay in time
+const (
+	// RelayFallbackNone fails the submission, keeping the transaction private
+	RelayFallbackNone = "none"
+	// RelayFallbackPublic broadcasts the same signed transaction publicly
+	RelayFallbackPublic = "public"
+)
+
+// Submission routes recorded per execution
+const (
+	SubmissionRoutePublic         = "public"
+	SubmissionRoutePrivate        = "private"
+	SubmissionRoutePublicFallback = "private_fallback_public"
+)
+
+// Relay errors
+var (
+	ErrBundleNotIncluded = errors.New("bundle not included by relay")
+	ErrNoRelayConfigured = errors.New("mev protection requested but no relay is configured")
+)
+
+// BundleRelay submits transaction bundles to a Flashbots-style relay via
+// eth_sendBundle, keeping them out of the public mempool
+type BundleRelay struct {
+	client  *rpcClient
+	authKey *ecdsa.PrivateKey
+}
+
+// NewBundleRelay creates a relay client. Requests are signed with authKey in
+// the X-Flashbots-Signature header; the key identifies the searcher to the
+// relay and does not need to hold funds. An empty key generates a throwaway
+// identity.
+func NewBundleRelay(url, authKey string, timeout time.Duration) (*BundleRelay, error) {
+	var key *ecdsa.PrivateKey
+	var err error
+	if authKey == "" {
+		key, err = crypto.GenerateKey()
+	} else {
+		key, err = crypto.HexToECDSA(strings.TrimPrefix(authKey, "0x"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid relay auth key: %w", err)
+	}
+
+	relay := &BundleRelay{
+		client:  newRPCClient(url, timeout),
+		authKey: key,
+	}
+	relay.client.headers = relay.signatureHeader
+
+	return relay, nil
+}
+
+// signatureHeader signs the keccak hash of the request body as an Ethereum
+// signed message
+func (r *BundleRelay) signatureHeader(body []byte) (map[string]string, error) {
+	digest := hexutil.Encode(crypto.Keccak256(body))
+	message := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(digest), digest)
+
+	sig, err := crypto.Sign(crypto.Keccak256([]byte(message)), r.authKey)
+	if err != nil {
+		return nil, err
+	}
+
+	address := crypto.PubkeyToAddress(r.authKey.PublicKey)
+	return map[string]string{
+		"X-Flashbots-Signature": address.Hex() + ":" + hexutil.Encode(sig),
+	}, nil
+}
+
+// sendBundleParams is the payload of eth_sendBundle
+type sendBundleParams struct {
+	Txs         []hexutil.Bytes `json:"txs"`
+	BlockNumber hexutil.Uint64  `json:"blockNumber"`
+}
+
+// SendBundle asks the relay to include rawTxs in blockNumber and returns the
+// bundle hash
+func (r *BundleRelay) SendBundle(ctx context.Context, rawTxs [][]byte, blockNumber uint64) (string, error) {
+	params := sendBundleParams{BlockNumber: hexutil.Uint64(blockNumber)}
+	for _, raw := range rawTxs {
+		params.Txs = append(params.Txs, raw)
+	}
+
+	var result struct {
+		BundleHash string `json:"bundleHash"`
+	}
+	if err := r.client.call(ctx, &result, "eth_sendBundle", params); err != nil {
+		return "", err
+	}
+
+	return result.BundleHash, nil
+}
+
+// txSubmission is the outcome of submitting an operation. TxHash is the
+// version that was mined.
+type txSubmission struct {
+	TxHash       common.Hash
+	Route        string
+	BundleHash   string
+	Attempts     int
+	Replacements int
+	Receipt      *txReceipt
+}
+
+// submitPrivate targets each of the next RelayMaxBlocks blocks with a
+// single-transaction bundle until it lands, then applies the fallback policy
+func (a *EthereumAdapter) submitPrivate(ctx context.Context, tx *managedTx) (*txSubmission, error) {
+	sub := &txSubmission{TxHash: tx.Hash, Route: SubmissionRoutePrivate}
+	tx.Route = SubmissionRoutePrivate
+
+	a.txs.setWaiting(tx, true)
+	defer a.txs.setWaiting(tx, false)
+
+	if tx.BundleTarget > 0 {
+		// An earlier attempt stopped watching its bundle before the target
+		// block passed, so it may have landed
+		receipt, err := a.waitForBlock(ctx, tx.Hash, tx.BundleTarget)
+		if err != nil {
+			return sub, err
+		}
+		if receipt != nil {
+			a.txs.finish(tx, receipt)
+			sub.Receipt = receipt
+			return sub, nil
+		}
+	}
+
+	for sub.Attempts < a.config.RelayMaxBlocks {
+		// Any earlier bundle's target block has passed without a receipt,
+		// so the nonce can be freed on failure from here until a new one
+		// is sent
+		current, err := a.client.blockNumber(ctx)
+		if err != nil {
+			a.txs.release(tx)
+			return sub, fmt.Errorf("failed to get block number: %w", err)
+		}
+		target := current + 1
+
+		bundleHash, err := a.relay.SendBundle(ctx, [][]byte{tx.Raw}, target)
+		if err != nil {
+			a.logger.Warn("Relay rejected bundle",
+				zap.String("tx_hash", tx.Hash.Hex()),
+				zap.Uint64("target_block", target),
+				zap.Error(err))
+			break
+		}
+		sub.Attempts++
+		sub.BundleHash = bundleHash
+		a.txs.setBundleTarget(tx, target)
+
+		receipt, err := a.waitForBlock(ctx, tx.Hash, target)
+		if err != nil {
+			// The bundle may still land, so the nonce stays reserved until
+			// its target block passes without a receipt
+			return sub, err
+		}
+		if receipt != nil {
+			a.txs.finish(tx, receipt)
+			sub.Receipt = receipt
+			return sub, nil
+		}
+
+		a.logger.Debug("Bundle not included, retargeting",
+			zap.String("tx_hash", tx.Hash.Hex()),
+			zap.Uint64("target_block", target),
+			zap.Int("attempt", sub.Attempts))
+	}
+
+	if a.config.RelayFallback != RelayFallbackPublic {
+		// Bundles expire with their target block, so the nonce is free again
+		a.txs.release(tx)
+		return sub, fmt.Errorf("%w after %d attempts", ErrBundleNotIncluded, sub.Attempts)
+	}
+
+	a.logger.Warn("Falling back to public mempool for protected transaction",
+		zap.String("tx_hash", tx.Hash.Hex()),
+		zap.Int("relay_attempts", sub.Attempts))
+
+	public, err := a.submitPublic(ctx, tx, SubmissionRoutePublicFallback)
+	public.BundleHash = sub.BundleHash
+	public.Attempts = sub.Attempts
+	return public, err
+}
+
+// submitPublic broadcasts the transaction and waits for it, or a
+// replacement, to be mined
+func (a *EthereumAdapter) submitPublic(ctx context.Context, tx *managedTx, route string) (*txSubmission, error) {
+	tx.Route = route
+	if err := a.txs.broadcast(ctx, tx); err != nil {
+		a.txs.release(tx)
+		return &txSubmission{TxHash: tx.Hash, Route: route}, fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+
+	return a.awaitPublic(ctx, tx, route)
+}
+
+// awaitPublic waits for a broadcast transaction through the tx manager
+func (a *EthereumAdapter) awaitPublic(ctx context.Context, tx *managedTx, route string) (*txSubmission, error) {
+	sub := &txSubmission{TxHash: tx.Hash, Route: route}
+
+	receipt, err := a.txs.waitMined(ctx, tx)
+	sub.Replacements = tx.Replacements
+	if err != nil {
+		return sub, err
+	}
+
+	sub.TxHash = receipt.TxHash
+	sub.Receipt = receipt
+	return sub, nil
+}
+
+// waitForBlock waits until the chain reaches block and returns the receipt
+// if the transaction was included by then
+func (a *EthereumAdapter) waitForBlock(ctx context.Context, hash common.Hash, block uint64) (*txReceipt, error) {
+	for {
+		// Read the height first so a receipt from the target block is not
+		// missed between the two calls
+		current, err := a.client.blockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block number: %w", err)
+		}
+
+		receipt, err := a.client.transactionReceipt(ctx, hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get receipt: %w", err)
+		}
+		if receipt != nil {
+			return receipt, nil
+		}
+		if current >= block {
+			return nil, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(a.txs.pollInterval):
+		}
+	}
+}
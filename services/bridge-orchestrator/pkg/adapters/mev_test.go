@@ -0,0 +1,410 @@
+package adapters
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+)
+
+// chainStub is a node and relay in one. The chain advances a block on every
+// eth_blockNumber call, and the relay includes a bundle on a chosen attempt.
+type chainStub struct {
+	mutex            sync.Mutex
+	height           uint64
+	receipts         map[common.Hash]uint64 // tx hash to inclusion block
+	includeOnAttempt int                    // 0 never includes
+	stallPublic      int                    // public broadcasts that never mine
+	failReceipts     int                    // receipt lookups that fail before any succeeds
+	noFeeMarket      bool
+	bundles          []sendBundleParams
+	publicTxs        [][]byte
+	relaySigners     []common.Address
+	// contracts answers eth_call
+	contracts func(to common.Address, data []byte) []byte
+	// onBroadcast runs a public transaction, returning its events and
+	// whether it succeeded
+	onBroadcast func(tx *decodedDynamicTx) ([]*txLog, bool)
+	logs        map[common.Hash][]*txLog
+	reverted    map[common.Hash]bool
+	chainID     int64
+	code        []byte
+}
+
+func newChainStub() *chainStub {
+	return &chainStub{
+		height:   100,
+		receipts: make(map[common.Hash]uint64),
+		logs:     make(map[common.Hash][]*txLog),
+		reverted: make(map[common.Hash]bool),
+		chainID:  11155111,
+	}
+}
+
+func (s *chainStub) serve(t *testing.T, handle func(method string, params []json.RawMessage, r *http.Request) interface{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("bad request: %v", err)
+			return
+		}
+
+		s.mutex.Lock()
+		result := handle(req.Method, req.Params, r)
+		s.mutex.Unlock()
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if rpcErr, ok := result.(*RPCError); ok {
+			resp["error"] = rpcErr
+		} else {
+			resp["result"] = result
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *chainStub) node(t *testing.T) *httptest.Server {
+	return s.serve(t, func(method string, params []json.RawMessage, _ *http.Request) interface{} {
+		switch method {
+		case "eth_blockNumber":
+			s.height++
+			return hexutil.Uint64(s.height)
+		case "eth_getTransactionCount":
+			return hexutil.Uint64(7)
+		case "eth_sendRawTransaction":
+			var raw hexutil.Bytes
+			json.Unmarshal(params[0], &raw)
+			s.publicTxs = append(s.publicTxs, raw)
+			hash := crypto.Keccak256Hash(raw)
+			if s.onBroadcast != nil {
+				tx, _ := decodeDynamicTx(t, raw)
+				logs, ok := s.onBroadcast(tx)
+				s.logs[hash] = logs
+				s.reverted[hash] = !ok
+			}
+			if len(s.publicTxs) > s.stallPublic {
+				s.receipts[hash] = s.height
+			}
+			return hash
+		case "eth_feeHistory":
+			if s.noFeeMarket {
+				return map[string]interface{}{"oldestBlock": hexutil.Uint64(s.height)}
+			}
+			gwei := func(g int64) *hexutil.Big { return (*hexutil.Big)(big.NewInt(g * 1e9)) }
+			return map[string]interface{}{
+				"oldestBlock":   hexutil.Uint64(s.height - 2),
+				"baseFeePerGas": []*hexutil.Big{gwei(10), gwei(11), gwei(12)},
+				"reward":        [][]*hexutil.Big{{gwei(1)}, {gwei(3)}},
+			}
+		case "eth_chainId":
+			return hexutil.Uint64(s.chainID)
+		case "eth_getCode":
+			return hexutil.Bytes(s.code)
+		case "eth_call":
+			var call struct {
+				To   common.Address `json:"to"`
+				Data hexutil.Bytes  `json:"data"`
+			}
+			json.Unmarshal(params[0], &call)
+			if s.contracts == nil {
+				t.Errorf("unexpected eth_call to %s", call.To.Hex())
+				return nil
+			}
+			return hexutil.Bytes(s.contracts(call.To, call.Data))
+		case "eth_getTransactionReceipt":
+			if s.failReceipts > 0 {
+				s.failReceipts--
+				return &RPCError{Code: -32000, Message: "upstream unavailable"}
+			}
+			var hash common.Hash
+			json.Unmarshal(params[0], &hash)
+			block, ok := s.receipts[hash]
+			if !ok || block > s.height {
+				return nil
+			}
+			status := hexutil.Uint64(1)
+			if s.reverted[hash] {
+				status = 0
+			}
+			return map[string]interface{}{
+				"transactionHash": hash,
+				"blockNumber":     hexutil.Uint64(block),
+				"status":          status,
+				"gasUsed":         hexutil.Uint64(120000),
+				"logs":            s.logs[hash],
+			}
+		default:
+			t.Errorf("unexpected node method %s", method)
+			return nil
+		}
+	})
+}
+
+func (s *chainStub) relay(t *testing.T) *httptest.Server {
+	return s.serve(t, func(method string, params []json.RawMessage, r *http.Request) interface{} {
+		if method != "eth_sendBundle" {
+			t.Errorf("unexpected relay method %s", method)
+			return nil
+		}
+
+		s.relaySigners = append(s.relaySigners, recoverRelaySigner(t, r))
+
+		var bundle sendBundleParams
+		json.Unmarshal(params[0], &bundle)
+		s.bundles = append(s.bundles, bundle)
+
+		if len(s.bundles) == s.includeOnAttempt {
+			s.receipts[crypto.Keccak256Hash(bundle.Txs[0])] = uint64(bundle.BlockNumber)
+		}
+		return map[string]string{"bundleHash": "0xbundle"}
+	})
+}
+
+// recoverRelaySigner checks the X-Flashbots-Signature header against the body
+func recoverRelaySigner(t *testing.T, r *http.Request) common.Address {
+	body, _ := io.ReadAll(r.Body)
+	parts := strings.Split(r.Header.Get("X-Flashbots-Signature"), ":")
+	if len(parts) != 2 {
+		t.Errorf("missing relay signature")
+		return common.Address{}
+	}
+
+	digest := hexutil.Encode(crypto.Keccak256(body))
+	message := "\x19Ethereum Signed Message:\n" + "66" + digest
+	sig := hexutil.MustDecode(parts[1])
+
+	pub, err := crypto.SigToPub(crypto.Keccak256([]byte(message)), sig)
+	if err != nil {
+		t.Errorf("bad relay signature: %v", err)
+		return common.Address{}
+	}
+	recovered := crypto.PubkeyToAddress(*pub)
+	if recovered != common.HexToAddress(parts[0]) {
+		t.Errorf("relay signature from %s, header claims %s", recovered.Hex(), parts[0])
+	}
+	return recovered
+}
+
+func newTestEthereumAdapter(t *testing.T, stub *chainStub, withRelay bool, fallback string) *EthereumAdapter {
+	key, _ := crypto.GenerateKey()
+	cfg := config.EthereumConfig{
+		RPCURL:         stub.node(t).URL,
+		PrivateKey:     hexutil.Encode(crypto.FromECDSA(key)),
+		BridgeAddress:  "0x742d35Cc6478354682b5dcB2b15c84F0B3B7b8d6",
+		ChainID:        11155111,
+		GasLimit:       300000,
+		GasPrice:       20,
+		RelayMaxBlocks: 3,
+		RelayFallback:  fallback,
+		ProtocolFeeBps: bridgeFeeRate,
+
+		FeeHistoryBlocks:   2,
+		FeePercentile:      50,
+		MinPriorityFeeGwei: 1,
+		MaxFeeGwei:         300,
+		TxReplaceTimeout:   time.Hour,
+		FeeBumpPercent:     15,
+		MaxReplacements:    3,
+	}
+	if withRelay {
+		cfg.RelayURL = stub.relay(t).URL
+	}
+
+	adapter, err := newEthereumAdapter(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to create adapter: %v", err)
+	}
+	adapter.txs.pollInterval = time.Millisecond
+	return adapter
+}
+
+func testIntervalParams(protected bool) ExecuteIntervalParams {
+	return ExecuteIntervalParams{
+		OrderID:        "order-1",
+		ChainOrderID:   common.HexToHash("0x01").Hex(),
+		IntervalNumber: 0,
+		Amount:         decimal.NewFromInt(1000),
+		PriceHint:      decimal.NewFromInt(2),
+		MinOutput:      decimal.NewFromInt(1900),
+		MEVProtection:  protected,
+	}
+}
+
+func TestProtectedIntervalIncludedByRelay(t *testing.T) {
+	stub := newChainStub()
+	stub.includeOnAttempt = 2
+	adapter := newTestEthereumAdapter(t, stub, true, RelayFallbackPublic)
+
+	result, err := adapter.ExecuteTWAPInterval(testIntervalParams(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.SubmissionRoute != SubmissionRoutePrivate {
+		t.Fatalf("route = %s, want %s", result.SubmissionRoute, SubmissionRoutePrivate)
+	}
+	if result.RelayAttempts != 2 || result.BundleHash != "0xbundle" {
+		t.Fatalf("unexpected relay result %+v", result)
+	}
+	if len(stub.publicTxs) != 0 {
+		t.Fatalf("protected transaction leaked to the public mempool")
+	}
+	if stub.bundles[1].BlockNumber <= stub.bundles[0].BlockNumber {
+		t.Fatalf("retry did not target a later block")
+	}
+	if uint64(stub.bundles[1].BlockNumber) != result.InclusionBlock {
+		t.Fatalf("inclusion block %d does not match target %d", result.InclusionBlock, stub.bundles[1].BlockNumber)
+	}
+	if result.TxHash != crypto.Keccak256Hash(stub.bundles[0].Txs[0]).Hex() {
+		t.Fatalf("tx hash does not match the submitted transaction")
+	}
+	if stub.relaySigners[0] != crypto.PubkeyToAddress(adapter.relay.authKey.PublicKey) {
+		t.Fatalf("relay requests not signed with the auth key")
+	}
+}
+
+func TestProtectedIntervalNotIncludedWithoutFallback(t *testing.T) {
+	stub := newChainStub()
+	adapter := newTestEthereumAdapter(t, stub, true, RelayFallbackNone)
+
+	_, err := adapter.ExecuteTWAPInterval(testIntervalParams(true))
+	if !errors.Is(err, ErrBundleNotIncluded) {
+		t.Fatalf("expected ErrBundleNotIncluded, got %v", err)
+	}
+	if len(stub.bundles) != 3 {
+		t.Fatalf("expected 3 relay attempts, got %d", len(stub.bundles))
+	}
+	if len(stub.publicTxs) != 0 {
+		t.Fatalf("transaction broadcast publicly despite fallback none")
+	}
+}
+
+func TestProtectedIntervalFallsBackToPublic(t *testing.T) {
+	stub := newChainStub()
+	adapter := newTestEthereumAdapter(t, stub, true, RelayFallbackPublic)
+
+	result, err := adapter.ExecuteTWAPInterval(testIntervalParams(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.SubmissionRoute != SubmissionRoutePublicFallback || result.RelayAttempts != 3 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if len(stub.publicTxs) != 1 || crypto.Keccak256Hash(stub.publicTxs[0]) != crypto.Keccak256Hash(stub.bundles[0].Txs[0]) {
+		t.Fatalf("fallback must broadcast the same signed transaction")
+	}
+}
+
+func TestProtectedIntervalWithoutRelay(t *testing.T) {
+	stub := newChainStub()
+	adapter := newTestEthereumAdapter(t, stub, false, RelayFallbackNone)
+
+	if _, err := adapter.ExecuteTWAPInterval(testIntervalParams(true)); !errors.Is(err, ErrNoRelayConfigured) {
+		t.Fatalf("expected ErrNoRelayConfigured, got %v", err)
+	}
+	if len(stub.publicTxs) != 0 {
+		t.Fatalf("transaction broadcast publicly without a relay")
+	}
+}
+
+func TestUnprotectedIntervalUsesPublicMempool(t *testing.T) {
+	stub := newChainStub()
+	adapter := newTestEthereumAdapter(t, stub, true, RelayFallbackNone)
+
+	result, err := adapter.ExecuteTWAPInterval(testIntervalParams(false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.SubmissionRoute != SubmissionRoutePublic || len(stub.bundles) != 0 || len(stub.publicTxs) != 1 {
+		t.Fatalf("unprotected order should go straight to the mempool: %+v", result)
+	}
+}
+
+func TestIntervalBelowMinOutputIsNotSigned(t *testing.T) {
+	stub := newChainStub()
+	adapter := newTestEthereumAdapter(t, stub, true, RelayFallbackPublic)
+
+	params := testIntervalParams(true)
+	params.MinOutput = decimal.NewFromInt(2001)
+
+	if _, err := adapter.ExecuteTWAPInterval(params); !errors.Is(err, ErrMinOutputNotMet) {
+		t.Fatalf("expected ErrMinOutputNotMet, got %v", err)
+	}
+	if len(stub.bundles) != 0 || len(stub.publicTxs) != 0 {
+		t.Fatalf("nothing should be submitted below the floor")
+	}
+}
+
+func TestBundleNonceHeldWhileInclusionUnknown(t *testing.T) {
+	stub := newChainStub()
+	stub.failReceipts = 1
+	adapter := newTestEthereumAdapter(t, stub, true, RelayFallbackNone)
+
+	if _, err := adapter.ExecuteTWAPInterval(testIntervalParams(true)); err == nil {
+		t.Fatalf("expected the receipt error")
+	}
+	if _, ok := adapter.txs.pending["order-1/0"]; !ok {
+		t.Fatalf("nonce released while the bundle could still land")
+	}
+
+	// The bundle's target block has passed without a receipt by the time
+	// the next interval is prepared, so its nonce is reused
+	params := testIntervalParams(false)
+	params.IntervalNumber = 1
+	if _, err := adapter.ExecuteTWAPInterval(params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := adapter.txs.pending["order-1/0"]; ok {
+		t.Fatalf("expired bundle still holds its nonce")
+	}
+	bundled, _ := decodeDynamicTx(t, stub.bundles[0].Txs[0])
+	public, _ := decodeDynamicTx(t, stub.publicTxs[0])
+	if public.Nonce != bundled.Nonce {
+		t.Fatalf("nonce %d not reclaimed, next transaction used %d", bundled.Nonce, public.Nonce)
+	}
+}
+
+func TestRetryResumesBundleThatLanded(t *testing.T) {
+	stub := newChainStub()
+	stub.includeOnAttempt = 1
+	stub.failReceipts = 1
+	adapter := newTestEthereumAdapter(t, stub, true, RelayFallbackNone)
+
+	if _, err := adapter.ExecuteTWAPInterval(testIntervalParams(true)); err == nil {
+		t.Fatalf("expected the receipt error")
+	}
+
+	result, err := adapter.ExecuteTWAPInterval(testIntervalParams(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stub.bundles) != 1 {
+		t.Fatalf("retry sent %d bundles for an interval that already landed", len(stub.bundles))
+	}
+	if result.TxHash != crypto.Keccak256Hash(stub.bundles[0].Txs[0]).Hex() {
+		t.Fatalf("retry did not report the landed transaction")
+	}
+}
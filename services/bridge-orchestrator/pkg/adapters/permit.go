@@ -0,0 +1,596 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+)
+
+// Permit kinds
+const (
+	// PermitKindEIP2612 is a permit signed against the token contract itself
+	PermitKindEIP2612 = "eip2612"
+	// PermitKindPermit2 is a Uniswap Permit2 PermitSingle allowance, for
+	// tokens without EIP-2612 support
+	PermitKindPermit2 = "permit2"
+)
+
+const (
+	// defaultPermitDeadline is how long a requested permit signature stays
+	// valid when the caller does not choose a deadline
+	defaultPermitDeadline = 30 * time.Minute
+	// permit2AllowanceLifetime is how long a Permit2 allowance to the bridge
+	// lasts; it must outlive the order's window
+	permit2AllowanceLifetime = 7 * 24 * time.Hour
+)
+
+// Permit2Address is the canonical Permit2 deployment, the same on every EVM
+// chain
+var Permit2Address = common.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3")
+
+// Approval errors
+var (
+	ErrInsufficientAllowance = errors.New("insufficient token allowance")
+	ErrInvalidPermit         = errors.New("invalid permit")
+	ErrPermitNotSupported    = errors.New("token does not support EIP-2612 permits")
+)
+
+// EIP-712 type strings
+const (
+	eip712DomainType          = "EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"
+	eip712DomainNoVersionType = "EIP712Domain(string name,uint256 chainId,address verifyingContract)"
+	permitType                = "Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"
+	permitDetailsType         = "PermitDetails(address token,uint160 amount,uint48 expiration,uint48 nonce)"
+	permitSingleType          = "PermitSingle(PermitDetails details,address spender,uint256 sigDeadline)" + permitDetailsType
+)
+
+var (
+	allowanceSelector        = abiSelector("allowance(address,address)")
+	noncesSelector           = abiSelector("nonces(address)")
+	nameSelector             = abiSelector("name()")
+	versionSelector          = abiSelector("version()")
+	domainSeparatorSelector  = abiSelector("DOMAIN_SEPARATOR()")
+	permitSelector           = abiSelector("permit(address,address,uint256,uint256,uint8,bytes32,bytes32)")
+	permit2AllowanceSelector = abiSelector("allowance(address,address,address)")
+	permit2PermitSelector    = abiSelector("permit(address,((address,uint160,uint48,uint48),address,uint256),bytes)")
+
+	maxUint160 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 160), big.NewInt(1))
+	maxUint48  = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 48), big.NewInt(1))
+)
+
+// TokenApprovalAdapter is implemented by adapters for chains where the bridge
+// pulls source tokens from the user with transferFrom
+type TokenApprovalAdapter interface {
+	// GetAllowance returns how much of token the bridge may pull from owner
+	// directly, in base units
+	GetAllowance(token, owner string) (decimal.Decimal, error)
+	// BuildPermit returns an unsigned permit and the typed data to sign it
+	BuildPermit(req PermitRequest) (*PermitTypedData, error)
+	// VerifyPermit checks a signed permit against the chain's current state
+	VerifyPermit(permit *Permit) error
+}
+
+// PermitRequest describes the approval a user is asked to sign
+type PermitRequest struct {
+	Kind     string
+	Token    string
+	Owner    string
+	Amount   decimal.Decimal // base units
+	Deadline int64           // unix seconds, zero for the default
+}
+
+// Permit is a signed approval letting the bridge pull Amount of Token from
+// Owner. Nonce and Deadline are the values that were signed; Expiration is
+// only used by Permit2.
+type Permit struct {
+	Kind       string          `json:"kind"`
+	Token      string          `json:"token"`
+	Owner      string          `json:"owner"`
+	Spender    string          `json:"spender"`
+	Amount     decimal.Decimal `json:"amount"`
+	Nonce      decimal.Decimal `json:"nonce"`
+	Deadline   int64           `json:"deadline"`
+	Expiration int64           `json:"expiration,omitempty"`
+	Signature  string          `json:"signature,omitempty"`
+}
+
+// TypedDataField is a member of an EIP-712 struct type
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedData is EIP-712 typed data as accepted by eth_signTypedData_v4
+type TypedData struct {
+	Types       map[string][]TypedDataField `json:"types"`
+	PrimaryType string                      `json:"primaryType"`
+	Domain      map[string]interface{}      `json:"domain"`
+	Message     map[string]interface{}      `json:"message"`
+}
+
+// PermitTypedData is what a user signs to approve the bridge. The signature
+// is returned in Permit.Signature when creating the order. Permit2 permits
+// need a one-time token approval of the Permit2 contract first, which
+// ApprovalRequired reports.
+type PermitTypedData struct {
+	Permit           Permit    `json:"permit"`
+	TypedData        TypedData `json:"typed_data"`
+	ApprovalRequired bool      `json:"approval_required,omitempty"`
+	ApprovalTarget   string    `json:"approval_target,omitempty"`
+}
+
+// eip712Domain is an EIP-712 domain. Permit2's domain has no version.
+type eip712Domain struct {
+	Name              string
+	Version           string
+	ChainID           int64
+	VerifyingContract common.Address
+}
+
+func (d eip712Domain) separator() common.Hash {
+	enc := &abiEncoder{}
+	if d.Version == "" {
+		enc.bytes32(crypto.Keccak256Hash([]byte(eip712DomainNoVersionType)))
+		enc.bytes32(crypto.Keccak256Hash([]byte(d.Name)))
+	} else {
+		enc.bytes32(crypto.Keccak256Hash([]byte(eip712DomainType)))
+		enc.bytes32(crypto.Keccak256Hash([]byte(d.Name)))
+		enc.bytes32(crypto.Keccak256Hash([]byte(d.Version)))
+	}
+	enc.uint(big.NewInt(d.ChainID)).address(d.VerifyingContract)
+	return crypto.Keccak256Hash(enc.head)
+}
+
+func (d eip712Domain) typedData() ([]TypedDataField, map[string]interface{}) {
+	fields := []TypedDataField{{Name: "name", Type: "string"}}
+	values := map[string]interface{}{"name": d.Name}
+	if d.Version != "" {
+		fields = append(fields, TypedDataField{Name: "version", Type: "string"})
+		values["version"] = d.Version
+	}
+	fields = append(fields,
+		TypedDataField{Name: "chainId", Type: "uint256"},
+		TypedDataField{Name: "verifyingContract", Type: "address"})
+	values["chainId"] = d.ChainID
+	values["verifyingContract"] = d.VerifyingContract.Hex()
+	return fields, values
+}
+
+// permit2Domain returns Permit2's domain on chainID
+func permit2Domain(chainID int64) eip712Domain {
+	return eip712Domain{Name: "Permit2", ChainID: chainID, VerifyingContract: Permit2Address}
+}
+
+// structHash returns the EIP-712 hash of the permit's message
+func (p *Permit) structHash() common.Hash {
+	owner := common.HexToAddress(p.Owner)
+	spender := common.HexToAddress(p.Spender)
+	deadline := big.NewInt(p.Deadline)
+
+	if p.Kind == PermitKindPermit2 {
+		details := (&abiEncoder{}).
+			bytes32(crypto.Keccak256Hash([]byte(permitDetailsType))).
+			address(common.HexToAddress(p.Token)).
+			uint(p.Amount.BigInt()).
+			uint(big.NewInt(p.Expiration)).
+			uint(p.Nonce.BigInt())
+		single := (&abiEncoder{}).
+			bytes32(crypto.Keccak256Hash([]byte(permitSingleType))).
+			bytes32(crypto.Keccak256Hash(details.head)).
+			address(spender).
+			uint(deadline)
+		return crypto.Keccak256Hash(single.head)
+	}
+
+	enc := (&abiEncoder{}).
+		bytes32(crypto.Keccak256Hash([]byte(permitType))).
+		address(owner).
+		address(spender).
+		uint(p.Amount.BigInt()).
+		uint(p.Nonce.BigInt()).
+		uint(deadline)
+	return crypto.Keccak256Hash(enc.head)
+}
+
+// typedData returns the permit as typed data in domain
+func (p *Permit) typedData(domain eip712Domain) TypedData {
+	domainFields, domainValues := domain.typedData()
+
+	if p.Kind == PermitKindPermit2 {
+		return TypedData{
+			Types: map[string][]TypedDataField{
+				"EIP712Domain": domainFields,
+				"PermitSingle": {
+					{Name: "details", Type: "PermitDetails"},
+					{Name: "spender", Type: "address"},
+					{Name: "sigDeadline", Type: "uint256"},
+				},
+				"PermitDetails": {
+					{Name: "token", Type: "address"},
+					{Name: "amount", Type: "uint160"},
+					{Name: "expiration", Type: "uint48"},
+					{Name: "nonce", Type: "uint48"},
+				},
+			},
+			PrimaryType: "PermitSingle",
+			Domain:      domainValues,
+			Message: map[string]interface{}{
+				"details": map[string]interface{}{
+					"token":      common.HexToAddress(p.Token).Hex(),
+					"amount":     p.Amount.String(),
+					"expiration": p.Expiration,
+					"nonce":      p.Nonce.String(),
+				},
+				"spender":     common.HexToAddress(p.Spender).Hex(),
+				"sigDeadline": p.Deadline,
+			},
+		}
+	}
+
+	return TypedData{
+		Types: map[string][]TypedDataField{
+			"EIP712Domain": domainFields,
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain:      domainValues,
+		Message: map[string]interface{}{
+			"owner":    common.HexToAddress(p.Owner).Hex(),
+			"spender":  common.HexToAddress(p.Spender).Hex(),
+			"value":    p.Amount.String(),
+			"nonce":    p.Nonce.String(),
+			"deadline": p.Deadline,
+		},
+	}
+}
+
+// typedDataDigest is the hash an EIP-712 signature signs
+func typedDataDigest(domain eip712Domain, structHash common.Hash) common.Hash {
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain.separator().Bytes(), structHash.Bytes())
+}
+
+// decodeSignature parses a 65-byte r||s||v signature, normalising v to 0 or 1
+// and rejecting the malleable high-s form contracts refuse
+func decodeSignature(signature string) ([]byte, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != 65 {
+		return nil, fmt.Errorf("%w: signature must be 65 hex-encoded bytes", ErrInvalidPermit)
+	}
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:64])
+	if !crypto.ValidateSignatureValues(sig[64], r, s, true) {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidPermit)
+	}
+	return sig, nil
+}
+
+// recoverSigner returns the address that signed digest
+func recoverSigner(digest common.Hash, signature string) (common.Address, error) {
+	sig, err := decodeSignature(signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	pub, err := crypto.SigToPub(digest.Bytes(), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidPermit, err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// encodePermitCall returns the contract and call data that apply permit
+// on-chain: the token for EIP-2612, Permit2 otherwise
+func encodePermitCall(p *Permit) (common.Address, []byte, error) {
+	sig, err := decodeSignature(p.Signature)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	owner := common.HexToAddress(p.Owner)
+	token := common.HexToAddress(p.Token)
+	spender := common.HexToAddress(p.Spender)
+
+	if p.Kind == PermitKindPermit2 {
+		// Permit2 takes the signature as bytes with v as 27 or 28
+		sig[64] += 27
+		data := (&abiEncoder{}).
+			address(owner).
+			address(token).
+			uint(p.Amount.BigInt()).
+			uint(big.NewInt(p.Expiration)).
+			uint(p.Nonce.BigInt()).
+			address(spender).
+			uint(big.NewInt(p.Deadline)).
+			bytes(sig).
+			encode(permit2PermitSelector)
+		return Permit2Address, data, nil
+	}
+
+	data := (&abiEncoder{}).
+		address(owner).
+		address(spender).
+		uint(p.Amount.BigInt()).
+		uint(big.NewInt(p.Deadline)).
+		uint64(uint64(sig[64]) + 27).
+		word(sig[:32]).
+		word(sig[32:64]).
+		encode(permitSelector)
+	return token, data, nil
+}
+
+// validatePermitFields checks a permit without touching the chain
+func validatePermitFields(p *Permit, spender common.Address, now time.Time) error {
+	if p.Kind != PermitKindEIP2612 && p.Kind != PermitKindPermit2 {
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidPermit, p.Kind)
+	}
+	if !common.IsHexAddress(p.Owner) || !common.IsHexAddress(p.Token) {
+		return fmt.Errorf("%w: owner and token must be addresses", ErrInvalidPermit)
+	}
+	if !common.IsHexAddress(p.Spender) || common.HexToAddress(p.Spender) != spender {
+		return fmt.Errorf("%w: spender must be the bridge %s", ErrInvalidPermit, spender.Hex())
+	}
+	if !p.Amount.IsPositive() || p.Nonce.IsNegative() {
+		return fmt.Errorf("%w: amount must be positive and nonce not negative", ErrInvalidPermit)
+	}
+	if p.Deadline <= now.Unix() {
+		return fmt.Errorf("%w: deadline has passed", ErrInvalidPermit)
+	}
+
+	if p.Kind == PermitKindPermit2 {
+		if p.Amount.BigInt().Cmp(maxUint160) > 0 || p.Nonce.BigInt().Cmp(maxUint48) > 0 {
+			return fmt.Errorf("%w: amount or nonce out of range for Permit2", ErrInvalidPermit)
+		}
+		if p.Expiration <= now.Unix() {
+			return fmt.Errorf("%w: allowance expiration has passed", ErrInvalidPermit)
+		}
+	}
+	return nil
+}
+
+// GetAllowance reads token.allowance(owner, bridge)
+func (a *EthereumAdapter) GetAllowance(token, owner string) (decimal.Decimal, error) {
+	if !common.IsHexAddress(token) || !common.IsHexAddress(owner) {
+		return decimal.Zero, fmt.Errorf("invalid token or owner address")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+	defer cancel()
+
+	allowance, err := a.tokenAllowance(ctx, common.HexToAddress(token), common.HexToAddress(owner), a.bridge)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return decimal.NewFromBigInt(allowance, 0), nil
+}
+
+// BuildPermit reads the nonce and domain the user must sign over
+func (a *EthereumAdapter) BuildPermit(req PermitRequest) (*PermitTypedData, error) {
+	now := time.Now()
+	deadline := req.Deadline
+	if deadline == 0 {
+		deadline = now.Add(defaultPermitDeadline).Unix()
+	}
+
+	permit := Permit{
+		Kind:     req.Kind,
+		Token:    req.Token,
+		Owner:    req.Owner,
+		Spender:  a.bridge.Hex(),
+		Amount:   req.Amount,
+		Deadline: deadline,
+	}
+	if req.Kind == PermitKindPermit2 {
+		permit.Expiration = now.Add(permit2AllowanceLifetime).Unix()
+	}
+	if err := validatePermitFields(&permit, a.bridge, now); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+	defer cancel()
+
+	domain, nonce, err := a.permitState(ctx, &permit)
+	if err != nil {
+		return nil, err
+	}
+	permit.Nonce = decimal.NewFromBigInt(nonce, 0)
+
+	result := &PermitTypedData{
+		Permit:    permit,
+		TypedData: permit.typedData(domain),
+	}
+
+	if req.Kind == PermitKindPermit2 {
+		approved, err := a.tokenAllowance(ctx, common.HexToAddress(permit.Token), common.HexToAddress(permit.Owner), Permit2Address)
+		if err != nil {
+			return nil, err
+		}
+		if approved.Cmp(permit.Amount.BigInt()) < 0 {
+			result.ApprovalRequired = true
+			result.ApprovalTarget = Permit2Address.Hex()
+		}
+	}
+
+	return result, nil
+}
+
+// VerifyPermit checks the permit is signed by its owner over the current
+// nonce and can still be applied
+func (a *EthereumAdapter) VerifyPermit(permit *Permit) error {
+	ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+	defer cancel()
+
+	return a.verifyPermit(ctx, permit)
+}
+
+func (a *EthereumAdapter) verifyPermit(ctx context.Context, permit *Permit) error {
+	if err := validatePermitFields(permit, a.bridge, time.Now()); err != nil {
+		return err
+	}
+	if permit.Signature == "" {
+		return fmt.Errorf("%w: missing signature", ErrInvalidPermit)
+	}
+
+	domain, nonce, err := a.permitState(ctx, permit)
+	if err != nil {
+		return err
+	}
+	if nonce.Cmp(permit.Nonce.BigInt()) != 0 {
+		return fmt.Errorf("%w: nonce %s is not the current nonce %s", ErrInvalidPermit, permit.Nonce.String(), nonce.String())
+	}
+
+	signer, err := recoverSigner(typedDataDigest(domain, permit.structHash()), permit.Signature)
+	if err != nil {
+		return err
+	}
+	if signer != common.HexToAddress(permit.Owner) {
+		return fmt.Errorf("%w: signed by %s, not the owner", ErrInvalidPermit, signer.Hex())
+	}
+
+	if permit.Kind == PermitKindPermit2 {
+		approved, err := a.tokenAllowance(ctx, common.HexToAddress(permit.Token), common.HexToAddress(permit.Owner), Permit2Address)
+		if err != nil {
+			return err
+		}
+		if approved.Cmp(permit.Amount.BigInt()) < 0 {
+			return fmt.Errorf("%w: token not approved for Permit2", ErrInsufficientAllowance)
+		}
+	}
+	return nil
+}
+
+// permitGranted reports whether the allowance permit grants is already in
+// place, as it is once an earlier submission of it has been mined
+func (a *EthereumAdapter) permitGranted(ctx context.Context, permit *Permit) (bool, error) {
+	owner := common.HexToAddress(permit.Owner)
+	token := common.HexToAddress(permit.Token)
+
+	if permit.Kind == PermitKindPermit2 {
+		amount, expiration, _, err := a.permit2Allowance(ctx, owner, token, a.bridge)
+		if err != nil {
+			return false, err
+		}
+		return amount.Cmp(permit.Amount.BigInt()) >= 0 && expiration > time.Now().Unix(), nil
+	}
+
+	allowance, err := a.tokenAllowance(ctx, token, owner, a.bridge)
+	if err != nil {
+		return false, err
+	}
+	return allowance.Cmp(permit.Amount.BigInt()) >= 0, nil
+}
+
+// permitState returns the domain and current nonce a permit is signed over
+func (a *EthereumAdapter) permitState(ctx context.Context, permit *Permit) (eip712Domain, *big.Int, error) {
+	owner := common.HexToAddress(permit.Owner)
+	token := common.HexToAddress(permit.Token)
+
+	if permit.Kind == PermitKindPermit2 {
+		_, _, nonce, err := a.permit2Allowance(ctx, owner, token, a.bridge)
+		if err != nil {
+			return eip712Domain{}, nil, err
+		}
+		return permit2Domain(a.config.ChainID), nonce, nil
+	}
+
+	domain, err := a.tokenPermitDomain(ctx, token)
+	if err != nil {
+		return eip712Domain{}, nil, err
+	}
+	nonce, err := a.callUint(ctx, token, (&abiEncoder{}).address(owner).encode(noncesSelector))
+	if err != nil {
+		return eip712Domain{}, nil, fmt.Errorf("failed to read permit nonce: %w", err)
+	}
+	return domain, nonce, nil
+}
+
+// tokenPermitDomain reconstructs a token's EIP-712 domain. Not every token
+// exposes version(), so candidate versions are checked against the token's
+// DOMAIN_SEPARATOR.
+func (a *EthereumAdapter) tokenPermitDomain(ctx context.Context, token common.Address) (eip712Domain, error) {
+	separator, err := a.client.ethCall(ctx, token, domainSeparatorSelector)
+	if err != nil || len(separator) != 32 {
+		return eip712Domain{}, ErrPermitNotSupported
+	}
+
+	raw, err := a.client.ethCall(ctx, token, nameSelector)
+	if err != nil {
+		return eip712Domain{}, fmt.Errorf("failed to read token name: %w", err)
+	}
+	name, err := abiDecodeString(raw)
+	if err != nil {
+		return eip712Domain{}, fmt.Errorf("failed to read token name: %w", err)
+	}
+
+	candidates := []string{"1", "2"}
+	if raw, err := a.client.ethCall(ctx, token, versionSelector); err == nil {
+		if version, err := abiDecodeString(raw); err == nil && version != "" {
+			candidates = append([]string{version}, candidates...)
+		}
+	}
+
+	for _, version := range candidates {
+		domain := eip712Domain{Name: name, Version: version, ChainID: a.config.ChainID, VerifyingContract: token}
+		if domain.separator() == common.BytesToHash(separator) {
+			return domain, nil
+		}
+	}
+	return eip712Domain{}, fmt.Errorf("%w: unrecognised domain separator", ErrPermitNotSupported)
+}
+
+func (a *EthereumAdapter) tokenAllowance(ctx context.Context, token, owner, spender common.Address) (*big.Int, error) {
+	allowance, err := a.callUint(ctx, token, (&abiEncoder{}).address(owner).address(spender).encode(allowanceSelector))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read allowance: %w", err)
+	}
+	return allowance, nil
+}
+
+// permit2Allowance reads Permit2's allowance(owner, token, spender)
+func (a *EthereumAdapter) permit2Allowance(ctx context.Context, owner, token, spender common.Address) (amount *big.Int, expiration int64, nonce *big.Int, err error) {
+	data := (&abiEncoder{}).address(owner).address(token).address(spender).encode(permit2AllowanceSelector)
+	result, err := a.client.ethCall(ctx, Permit2Address, data)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to read Permit2 allowance: %w", err)
+	}
+
+	words := make([]*big.Int, 3)
+	for i := range words {
+		word, err := abiWord(result, i)
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("failed to read Permit2 allowance: %w", err)
+		}
+		words[i] = new(big.Int).SetBytes(word)
+	}
+	return words[0], words[1].Int64(), words[2], nil
+}
+
+// callUint calls a function returning a single uint
+func (a *EthereumAdapter) callUint(ctx context.Context, to common.Address, data []byte) (*big.Int, error) {
+	result, err := a.client.ethCall(ctx, to, data)
+	if err != nil {
+		return nil, err
+	}
+	word, err := abiWord(result, 0)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(word), nil
+}
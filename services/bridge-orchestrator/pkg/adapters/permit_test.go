@@ -0,0 +1,257 @@
+package adapters
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+)
+
+var testToken = common.HexToAddress("0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238")
+
+// tokenStub is an ERC-20 with EIP-2612 permits and the Permit2 contract
+type tokenStub struct {
+	name            string
+	domainSeparator common.Hash
+	nonce           int64
+	allowances      map[common.Address]*big.Int // by spender
+	permit2Nonce    int64
+}
+
+func (ts *tokenStub) handle(to common.Address, data []byte) []byte {
+	word := func(v *big.Int) []byte { return common.LeftPadBytes(v.Bytes(), 32) }
+	selector := data[:4]
+
+	switch {
+	case to == Permit2Address && bytes.Equal(selector, permit2AllowanceSelector):
+		out := append(word(big.NewInt(0)), word(big.NewInt(0))...)
+		return append(out, word(big.NewInt(ts.permit2Nonce))...)
+	case bytes.Equal(selector, domainSeparatorSelector):
+		return ts.domainSeparator.Bytes()
+	case bytes.Equal(selector, nameSelector):
+		return (&abiEncoder{}).string(ts.name).encode(nil)
+	case bytes.Equal(selector, noncesSelector):
+		return word(big.NewInt(ts.nonce))
+	case bytes.Equal(selector, allowanceSelector):
+		spender := common.BytesToAddress(data[4+32 : 4+64])
+		if allowance, ok := ts.allowances[spender]; ok {
+			return word(allowance)
+		}
+		return word(big.NewInt(0))
+	default:
+		// version() and anything else revert
+		return nil
+	}
+}
+
+// newPermitTestAdapter returns an adapter on a chain with an EIP-2612 token
+// at version "1" that exposes no version()
+func newPermitTestAdapter(t *testing.T) (*EthereumAdapter, *chainStub, *tokenStub) {
+	stub := newChainStub()
+	token := &tokenStub{name: "Test Token", nonce: 3, allowances: map[common.Address]*big.Int{}}
+	stub.contracts = token.handle
+	adapter := newTestEthereumAdapter(t, stub, false, RelayFallbackNone)
+	stub.onBroadcast = newBridgeStub(t, adapter.bridge).handle
+
+	// Computed by hand rather than through eip712Domain
+	token.domainSeparator = crypto.Keccak256Hash(
+		crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)")),
+		crypto.Keccak256([]byte("Test Token")),
+		crypto.Keccak256([]byte("1")),
+		common.LeftPadBytes(big.NewInt(11155111).Bytes(), 32),
+		common.LeftPadBytes(testToken.Bytes(), 32),
+	)
+	return adapter, stub, token
+}
+
+func signDigest(t *testing.T, key *ecdsa.PrivateKey, digest common.Hash) string {
+	t.Helper()
+	sig, err := crypto.Sign(digest.Bytes(), key)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	sig[64] += 27
+	return hexutil.Encode(sig)
+}
+
+func TestABIEncoderDynamicArguments(t *testing.T) {
+	data := (&abiEncoder{}).uint64(1).string("abc").uint64(2).encode([]byte{0xaa})
+
+	want := "aa" +
+		"0000000000000000000000000000000000000000000000000000000000000001" +
+		"0000000000000000000000000000000000000000000000000000000000000060" +
+		"0000000000000000000000000000000000000000000000000000000000000002" +
+		"0000000000000000000000000000000000000000000000000000000000000003" +
+		"6162630000000000000000000000000000000000000000000000000000000000"
+	if got := common.Bytes2Hex(data); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestEIP2612PermitVerification(t *testing.T) {
+	adapter, _, token := newPermitTestAdapter(t)
+	key, _ := crypto.GenerateKey()
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+
+	typed, err := adapter.BuildPermit(PermitRequest{
+		Kind:   PermitKindEIP2612,
+		Token:  testToken.Hex(),
+		Owner:  owner.Hex(),
+		Amount: decimal.NewFromInt(5000),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if typed.TypedData.Domain["version"] != "1" || typed.Permit.Nonce.IntPart() != 3 {
+		t.Fatalf("unexpected typed data %+v", typed.TypedData)
+	}
+	if typed.Permit.Spender != adapter.bridge.Hex() {
+		t.Fatalf("spender %s, want the bridge", typed.Permit.Spender)
+	}
+
+	permit := typed.Permit
+	structHash := crypto.Keccak256Hash(
+		crypto.Keccak256([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)")),
+		common.LeftPadBytes(owner.Bytes(), 32),
+		common.LeftPadBytes(adapter.bridge.Bytes(), 32),
+		common.LeftPadBytes(big.NewInt(5000).Bytes(), 32),
+		common.LeftPadBytes(big.NewInt(3).Bytes(), 32),
+		common.LeftPadBytes(big.NewInt(permit.Deadline).Bytes(), 32),
+	)
+	digest := crypto.Keccak256Hash([]byte{0x19, 0x01}, token.domainSeparator.Bytes(), structHash.Bytes())
+	permit.Signature = signDigest(t, key, digest)
+
+	if err := adapter.VerifyPermit(&permit); err != nil {
+		t.Fatalf("valid permit rejected: %v", err)
+	}
+
+	other, _ := crypto.GenerateKey()
+	forged := permit
+	forged.Signature = signDigest(t, other, digest)
+	if err := adapter.VerifyPermit(&forged); !errors.Is(err, ErrInvalidPermit) {
+		t.Fatalf("expected a permit signed by someone else to be rejected, got %v", err)
+	}
+
+	expired := permit
+	expired.Deadline = time.Now().Add(-time.Minute).Unix()
+	if err := adapter.VerifyPermit(&expired); !errors.Is(err, ErrInvalidPermit) {
+		t.Fatalf("expected an expired permit to be rejected, got %v", err)
+	}
+
+	token.nonce = 4
+	if err := adapter.VerifyPermit(&permit); !errors.Is(err, ErrInvalidPermit) {
+		t.Fatalf("expected a used nonce to be rejected, got %v", err)
+	}
+}
+
+func TestPermitNotSupported(t *testing.T) {
+	adapter, _, token := newPermitTestAdapter(t)
+	token.domainSeparator = common.Hash{0x01}
+
+	_, err := adapter.BuildPermit(PermitRequest{
+		Kind:   PermitKindEIP2612,
+		Token:  testToken.Hex(),
+		Owner:  testToken.Hex(),
+		Amount: decimal.NewFromInt(1),
+	})
+	if !errors.Is(err, ErrPermitNotSupported) {
+		t.Fatalf("expected ErrPermitNotSupported, got %v", err)
+	}
+}
+
+func testCreateParams(owner common.Address) CreateTWAPOrderParams {
+	return CreateTWAPOrderParams{
+		OrderID:         "order-1",
+		UserAddress:     owner.Hex(),
+		SourceToken:     testToken.Hex(),
+		TargetToken:     "uatom",
+		Amount:          decimal.NewFromInt(5000),
+		WindowMinutes:   60,
+		Intervals:       6,
+		MaxSlippage:     100,
+		HashedSecret:    crypto.Keccak256Hash([]byte("secret")).Hex(),
+		TimeoutHeight:   10000,
+		TargetChain:     "cosmos",
+		TargetRecipient: "cosmos1recipient",
+		MinFillSize:     decimal.NewFromInt(500),
+	}
+}
+
+func TestCreateOrderSubmitsPermit2First(t *testing.T) {
+	adapter, stub, token := newPermitTestAdapter(t)
+	token.permit2Nonce = 5
+	key, _ := crypto.GenerateKey()
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	token.allowances[Permit2Address] = new(big.Int).Lsh(big.NewInt(1), 200)
+
+	typed, err := adapter.BuildPermit(PermitRequest{
+		Kind:   PermitKindPermit2,
+		Token:  testToken.Hex(),
+		Owner:  owner.Hex(),
+		Amount: decimal.NewFromInt(5000),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if typed.ApprovalRequired || typed.TypedData.PrimaryType != "PermitSingle" || typed.Permit.Nonce.IntPart() != 5 {
+		t.Fatalf("unexpected Permit2 typed data %+v", typed)
+	}
+
+	permit := typed.Permit
+	permit.Signature = signDigest(t, key, typedDataDigest(permit2Domain(11155111), permit.structHash()))
+
+	params := testCreateParams(owner)
+	params.Permit = &permit
+	result, err := adapter.CreateTWAPOrder(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(stub.publicTxs) != 2 {
+		t.Fatalf("expected a permit and an order transaction, got %d", len(stub.publicTxs))
+	}
+	permitTx, _ := decodeDynamicTx(t, stub.publicTxs[0])
+	orderTx, _ := decodeDynamicTx(t, stub.publicTxs[1])
+	if permitTx.To != Permit2Address || !bytes.Equal(permitTx.Data[:4], permit2PermitSelector) {
+		t.Fatalf("first transaction is not a Permit2 permit")
+	}
+	if orderTx.To != adapter.bridge || !bytes.Equal(orderTx.Data[:4], createTWAPOrderForSelector) {
+		t.Fatalf("second transaction is not createTWAPOrderFor")
+	}
+	if common.BytesToAddress(orderTx.Data[4:36]) != owner || orderTx.Nonce != permitTx.Nonce+1 {
+		t.Fatalf("order not created for the permit owner in nonce order")
+	}
+	if result.TxHash != crypto.Keccak256Hash(stub.publicTxs[1]).Hex() {
+		t.Fatalf("returned %s, want the order transaction", result.TxHash)
+	}
+}
+
+func TestCreateOrderSkipsAppliedPermit(t *testing.T) {
+	adapter, stub, token := newPermitTestAdapter(t)
+	owner := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	token.allowances[adapter.bridge] = big.NewInt(5000)
+
+	params := testCreateParams(owner)
+	params.Permit = &Permit{
+		Kind:     PermitKindEIP2612,
+		Token:    testToken.Hex(),
+		Owner:    owner.Hex(),
+		Spender:  adapter.bridge.Hex(),
+		Amount:   decimal.NewFromInt(5000),
+		Deadline: time.Now().Add(-time.Hour).Unix(), // already used
+	}
+
+	if _, err := adapter.CreateTWAPOrder(params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stub.publicTxs) != 1 {
+		t.Fatalf("expected only the order transaction, got %d", len(stub.publicTxs))
+	}
+}
@@ -0,0 +1,350 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+)
+
+// ErrNoHealthyEndpoint is returned when every endpoint's circuit is open
+var ErrNoHealthyEndpoint = errors.New("no healthy RPC endpoint")
+
+// Endpoint circuit breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+const (
+	// healthAlpha weighs the latest request in an endpoint's score and latency
+	healthAlpha = 0.2
+	// degradedScore is the score below which an endpoint is tried after
+	// healthier ones
+	degradedScore = 0.5
+)
+
+// stickyMethods go to one endpoint at a time, preferring the one that last
+// served them, so nonces and pending transactions stay consistent
+var stickyMethods = map[string]bool{
+	"eth_getTransactionCount": true,
+	"eth_sendRawTransaction":  true,
+	"eth_sendBundle":          true,
+	"sendrawtransaction":      true,
+}
+
+// EndpointHealth is the health of one RPC endpoint. URL has any path,
+// query or credentials removed since providers embed API keys in them.
+type EndpointHealth struct {
+	URL                 string    `json:"url"`
+	State               string    `json:"state"`
+	Score               float64   `json:"score"`
+	LatencyMs           int64     `json:"latency_ms"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Sticky              bool      `json:"sticky,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastSuccess         time.Time `json:"last_success,omitempty"`
+}
+
+// rpcEndpoint is one endpoint's health and breaker, guarded by the pool
+type rpcEndpoint struct {
+	url         string
+	state       string
+	score       float64
+	latency     time.Duration
+	failures    int
+	openedAt    time.Time
+	trial       bool // a half-open trial request is in flight
+	lastError   string
+	lastSuccess time.Time
+}
+
+// endpointPool tracks an ordered list of endpoints for one chain. Only
+// transport failures count against an endpoint; a JSON-RPC error is a
+// healthy node answering.
+type endpointPool struct {
+	policy    config.RPCFailoverConfig
+	endpoints []*rpcEndpoint
+
+	mutex  sync.Mutex
+	sticky *rpcEndpoint
+}
+
+// newEndpointPool creates a pool over urls in preference order. A zero
+// BreakerThreshold disables the breakers and a zero HedgeDelay disables
+// hedging.
+func newEndpointPool(urls []string, policy config.RPCFailoverConfig) *endpointPool {
+	pool := &endpointPool{policy: policy}
+	for _, u := range urls {
+		pool.endpoints = append(pool.endpoints, &rpcEndpoint{url: u, state: BreakerClosed, score: 1})
+	}
+	return pool
+}
+
+// endpointList returns urls, or primary alone when no list is configured
+func endpointList(primary string, urls []string) []string {
+	if len(urls) == 0 {
+		return []string{primary}
+	}
+	return urls
+}
+
+// rank orders endpoints: healthy, then degraded, then those with a tripped
+// breaker
+func (e *rpcEndpoint) rank() int {
+	switch {
+	case e.state != BreakerClosed:
+		return 2
+	case e.score < degradedScore:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// candidates returns the endpoints best first, keeping configured order
+// within each rank
+func (p *endpointPool) candidates() []*rpcEndpoint {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	ranked := append([]*rpcEndpoint(nil), p.endpoints...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].rank() < ranked[j].rank() })
+	return ranked
+}
+
+// writeCandidates returns the candidates with the sticky endpoint first
+func (p *endpointPool) writeCandidates() []*rpcEndpoint {
+	ranked := p.candidates()
+
+	p.mutex.Lock()
+	sticky := p.sticky
+	p.mutex.Unlock()
+	if sticky == nil {
+		return ranked
+	}
+
+	ordered := []*rpcEndpoint{sticky}
+	for _, e := range ranked {
+		if e != sticky {
+			ordered = append(ordered, e)
+		}
+	}
+	return ordered
+}
+
+// acquire reports whether a request may be sent to e. An open breaker lets
+// a single trial request through once its cooldown has passed.
+func (p *endpointPool) acquire(e *rpcEndpoint) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.policy.BreakerThreshold <= 0 {
+		return true
+	}
+
+	switch e.state {
+	case BreakerOpen:
+		if time.Since(e.openedAt) < p.policy.BreakerCooldown {
+			return false
+		}
+		e.state = BreakerHalfOpen
+		e.trial = true
+		return true
+	case BreakerHalfOpen:
+		if e.trial {
+			return false
+		}
+		e.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// release gives back a request that was abandoned before it had an outcome
+func (p *endpointPool) release(e *rpcEndpoint) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	e.trial = false
+}
+
+// record updates e with the outcome of a request
+func (p *endpointPool) record(e *rpcEndpoint, err error, latency time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	e.trial = false
+	if err == nil {
+		e.score = e.score*(1-healthAlpha) + healthAlpha
+		if e.latency == 0 {
+			e.latency = latency
+		} else {
+			e.latency = time.Duration(float64(e.latency)*(1-healthAlpha) + float64(latency)*healthAlpha)
+		}
+		e.failures = 0
+		e.state = BreakerClosed
+		e.lastError = ""
+		e.lastSuccess = time.Now()
+		return
+	}
+
+	e.score *= 1 - healthAlpha
+	e.failures++
+	e.lastError = strings.ReplaceAll(err.Error(), e.url, redactURL(e.url))
+	if p.sticky == e {
+		p.sticky = nil
+	}
+
+	if p.policy.BreakerThreshold > 0 && (e.state == BreakerHalfOpen || e.failures >= p.policy.BreakerThreshold) {
+		e.state = BreakerOpen
+		e.openedAt = time.Now()
+	}
+}
+
+// stick routes later sticky requests to e
+func (p *endpointPool) stick(e *rpcEndpoint) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.sticky = e
+}
+
+// health returns a snapshot of every endpoint in configured order
+func (p *endpointPool) health() []EndpointHealth {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	health := make([]EndpointHealth, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		health = append(health, EndpointHealth{
+			URL:                 redactURL(e.url),
+			State:               e.state,
+			Score:               e.score,
+			LatencyMs:           e.latency.Milliseconds(),
+			ConsecutiveFailures: e.failures,
+			Sticky:              p.sticky == e,
+			LastError:           e.lastError,
+			LastSuccess:         e.lastSuccess,
+		})
+	}
+	return health
+}
+
+// available reports whether any endpoint's breaker is closed
+func (p *endpointPool) available() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, e := range p.endpoints {
+		if e.state == BreakerClosed {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL reduces an endpoint URL to its scheme and host
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "invalid-url"
+	}
+	redacted := u.Scheme + "://" + u.Host
+	if strings.Trim(u.Path, "/") != "" || u.RawQuery != "" {
+		redacted += "/***"
+	}
+	return redacted
+}
+
+// sendHedged sends a read to the best endpoint and, if it has not answered
+// within the hedge delay, races it against the next. A failed attempt fails
+// over to the next endpoint immediately.
+func (c *rpcClient) sendHedged(ctx context.Context, method string, body []byte, headers map[string]string) (*rpcResponse, error) {
+	candidates := c.pool.candidates()
+
+	// Losing attempts are cancelled once one succeeds
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type attempt struct {
+		resp *rpcResponse
+		err  error
+	}
+	attempts := make(chan attempt, len(candidates))
+	inFlight := 0
+
+	launch := func() bool {
+		for len(candidates) > 0 {
+			e := candidates[0]
+			candidates = candidates[1:]
+			if !c.pool.acquire(e) {
+				continue
+			}
+			inFlight++
+			go func() {
+				resp, err := c.post(ctx, e, method, body, headers)
+				attempts <- attempt{resp, err}
+			}()
+			return true
+		}
+		return false
+	}
+
+	if !launch() {
+		return nil, fmt.Errorf("%s: %w", method, ErrNoHealthyEndpoint)
+	}
+
+	var hedge <-chan time.Time
+	if delay := c.pool.policy.HedgeDelay; delay > 0 && len(candidates) > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		hedge = timer.C
+	}
+
+	var lastErr error
+	for inFlight > 0 {
+		select {
+		case a := <-attempts:
+			inFlight--
+			if a.err == nil {
+				return a.resp, nil
+			}
+			lastErr = a.err
+			if inFlight == 0 && ctx.Err() == nil {
+				launch()
+			}
+		case <-hedge:
+			hedge = nil
+			launch()
+		}
+	}
+	return nil, lastErr
+}
+
+// sendSticky sends a nonce-sensitive request to one endpoint at a time,
+// starting with the one that last served such a request
+func (c *rpcClient) sendSticky(ctx context.Context, method string, body []byte, headers map[string]string) (*rpcResponse, error) {
+	lastErr := fmt.Errorf("%s: %w", method, ErrNoHealthyEndpoint)
+	for _, e := range c.pool.writeCandidates() {
+		if !c.pool.acquire(e) {
+			continue
+		}
+		resp, err := c.post(ctx, e, method, body, headers)
+		if err == nil {
+			c.pool.stick(e)
+			return resp, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
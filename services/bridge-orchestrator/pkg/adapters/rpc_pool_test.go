@@ -0,0 +1,172 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+)
+
+// endpointStub is a JSON-RPC endpoint answering every method with block 1
+type endpointStub struct {
+	*httptest.Server
+	hits   int64
+	down   atomic.Bool
+	delay  time.Duration
+	rpcErr bool
+}
+
+func newEndpointStub(t *testing.T) *endpointStub {
+	stub := &endpointStub{}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&stub.hits, 1)
+		if stub.down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var req rpcRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		select {
+		case <-time.After(stub.delay):
+		case <-r.Context().Done():
+			return
+		}
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": hexutil.Uint64(1)}
+		if stub.rpcErr {
+			resp = map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32000, "message": "execution reverted"}}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+func (s *endpointStub) count() int64 {
+	return atomic.LoadInt64(&s.hits)
+}
+
+func TestBreakerSkipsFailingEndpoint(t *testing.T) {
+	primary, fallback := newEndpointStub(t), newEndpointStub(t)
+	primary.down.Store(true)
+
+	policy := config.RPCFailoverConfig{BreakerThreshold: 2, BreakerCooldown: time.Hour}
+	client := newPooledRPCClient([]string{primary.URL + "/v3/secret-key", fallback.URL}, policy, time.Second)
+
+	for i := 0; i < 4; i++ {
+		if _, err := client.blockNumber(context.Background()); err != nil {
+			t.Fatalf("request %d not failed over: %v", i, err)
+		}
+	}
+	if primary.count() != 2 || fallback.count() != 4 {
+		t.Fatalf("primary served %d, fallback %d; want the breaker open after 2", primary.count(), fallback.count())
+	}
+
+	health := client.pool.health()
+	if health[0].State != BreakerOpen || health[1].State != BreakerClosed {
+		t.Fatalf("unexpected health %+v", health)
+	}
+	if strings.Contains(health[0].URL, "secret-key") || strings.Contains(health[0].LastError, "secret-key") {
+		t.Fatalf("endpoint key exposed in %+v", health[0])
+	}
+
+	// Once the cooldown passes a single trial closes the breaker again
+	primary.down.Store(false)
+	client.pool.endpoints[0].openedAt = time.Now().Add(-2 * time.Hour)
+	client.pool.endpoints[1].state = BreakerOpen
+	client.pool.endpoints[1].openedAt = time.Now()
+	if _, err := client.blockNumber(context.Background()); err != nil {
+		t.Fatalf("trial request failed: %v", err)
+	}
+	if health := client.pool.health(); health[0].State != BreakerClosed {
+		t.Fatalf("breaker not closed after a successful trial: %+v", health[0])
+	}
+
+	client.pool.endpoints[0].state = BreakerOpen
+	client.pool.endpoints[0].openedAt = time.Now()
+	if _, err := client.blockNumber(context.Background()); !errors.Is(err, ErrNoHealthyEndpoint) {
+		t.Fatalf("expected ErrNoHealthyEndpoint with every breaker open, got %v", err)
+	}
+}
+
+func TestRPCErrorsDoNotTripBreaker(t *testing.T) {
+	stub := newEndpointStub(t)
+	stub.rpcErr = true
+	client := newPooledRPCClient([]string{stub.URL}, config.RPCFailoverConfig{BreakerThreshold: 1, BreakerCooldown: time.Hour}, time.Second)
+
+	for i := 0; i < 3; i++ {
+		var rpcErr *RPCError
+		if _, err := client.blockNumber(context.Background()); !errors.As(err, &rpcErr) {
+			t.Fatalf("expected the node's error, got %v", err)
+		}
+	}
+	if health := client.pool.health()[0]; health.State != BreakerClosed || health.ConsecutiveFailures != 0 {
+		t.Fatalf("a node answering with an error counted as unhealthy: %+v", health)
+	}
+}
+
+func TestHedgedReadUsesFasterEndpoint(t *testing.T) {
+	slow, fast := newEndpointStub(t), newEndpointStub(t)
+	slow.delay = 5 * time.Second
+
+	policy := config.RPCFailoverConfig{BreakerThreshold: 3, BreakerCooldown: time.Hour, HedgeDelay: 20 * time.Millisecond}
+	client := newPooledRPCClient([]string{slow.URL, fast.URL}, policy, 10*time.Second)
+
+	start := time.Now()
+	if _, err := client.blockNumber(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("hedged read took %s", elapsed)
+	}
+	if fast.count() != 1 {
+		t.Fatalf("hedge not sent")
+	}
+
+	// The abandoned request says nothing about the slow endpoint
+	if health := client.pool.health()[0]; health.ConsecutiveFailures != 0 {
+		t.Fatalf("cancelled hedge counted as a failure: %+v", health)
+	}
+}
+
+func TestWritesStickToOneEndpoint(t *testing.T) {
+	primary, fallback := newEndpointStub(t), newEndpointStub(t)
+	policy := config.RPCFailoverConfig{BreakerThreshold: 3, BreakerCooldown: time.Hour, HedgeDelay: time.Second}
+	client := newPooledRPCClient([]string{primary.URL, fallback.URL}, policy, time.Second)
+
+	// A failed nonce read moves writes to the fallback
+	primary.down.Store(true)
+	if _, err := client.pendingNonce(context.Background(), testToken); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	primary.down.Store(false)
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.pendingNonce(context.Background(), testToken); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if primary.count() != 1 || fallback.count() != 4 {
+		t.Fatalf("primary served %d writes, fallback %d; want them kept on the fallback", primary.count(), fallback.count())
+	}
+	if !client.pool.health()[1].Sticky {
+		t.Fatalf("fallback not marked sticky")
+	}
+
+	// Reads go back to the preferred endpoint
+	if _, err := client.blockNumber(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if primary.count() != 2 {
+		t.Fatalf("read not sent to the primary")
+	}
+}
@@ -1,14 +1,31 @@
 package adapters
 
 import (
+	"context"
+	"errors"
 	"time"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
 	"flowfusion/bridge-orchestrator/internal/config"
+	"flowfusion/bridge-orchestrator/internal/database"
 )
 
+// ErrMinOutputNotMet is returned when an interval would deliver less than
+// its MinOutput; on-chain the swap reverts
+var ErrMinOutputNotMet = errors.New("execution output below minimum")
+
+// ErrNoChainOrderID is returned for an order the bridge has not assigned an
+// id yet, because its creation has not been mined
+var ErrNoChainOrderID = errors.New("order has no bridge order id")
+
+// ErrNotImplemented is returned by a live adapter for operations it cannot
+// perform on-chain yet
+var ErrNotImplemented = errors.New("operation not implemented for this chain")
+
 // CreateTWAPOrderParams contains parameters for creating a TWAP order
 type CreateTWAPOrderParams struct {
 	OrderID          string          `json:"order_id"`
@@ -23,15 +40,84 @@ type CreateTWAPOrderParams struct {
 	HashedSecret     string          `json:"hashed_secret"`
 	TimeoutHeight    int64           `json:"timeout_height"`
 	TimeoutTimestamp int64           `json:"timeout_timestamp"`
+
+	TargetChain         string          `json:"target_chain"`
+	TargetRecipient     string          `json:"target_recipient"`
+	MinFillSize         decimal.Decimal `json:"min_fill_size"`
+	EnableMEVProtection bool            `json:"enable_mev_protection"`
+
+	// Permit is the user's signed approval, submitted before the order
+	Permit *Permit `json:"permit,omitempty"`
+}
+
+// CreateOrderResult is an order created on-chain. ChainOrderID is the id
+// the chain assigned the order, empty where the chain keeps none.
+type CreateOrderResult struct {
+	TxHash       string `json:"tx_hash"`
+	ChainOrderID string `json:"chain_order_id,omitempty"`
+}
+
+// CancelOrderParams identifies an order to cancel on-chain. The unexecuted
+// remainder is refunded to UserAddress.
+type CancelOrderParams struct {
+	OrderID         string          `json:"order_id"`
+	ChainOrderID    string          `json:"chain_order_id"`
+	UserAddress     string          `json:"user_address"`
+	SourceToken     string          `json:"source_token"`
+	RemainingAmount decimal.Decimal `json:"remaining_amount"`
+}
+
+// CancelResult is an on-chain cancellation and the refund it paid
+type CancelResult struct {
+	OrderID      string          `json:"order_id"`
+	TxHash       string          `json:"tx_hash"`
+	RefundAmount decimal.Decimal `json:"refund_amount"`
+	Recipient    string          `json:"recipient"`
+	BlockNumber  uint64          `json:"block_number,omitempty"`
+}
+
+// AmendOrderParams carries a live order's amended terms. SourceAmount is in
+// the order's terms, before any protocol fee the chain takes. Any reduction
+// of the source amount is refunded to UserAddress; RefundAmount is the
+// refund expected, and the chain's result reports what was paid.
+type AmendOrderParams struct {
+	OrderID             string          `json:"order_id"`
+	ChainOrderID        string          `json:"chain_order_id"`
+	UserAddress         string          `json:"user_address"`
+	SourceAmount        decimal.Decimal `json:"source_amount"`
+	RefundAmount        decimal.Decimal `json:"refund_amount"`
+	WindowMinutes       int             `json:"window_minutes"`
+	Intervals           int             `json:"intervals"`
+	MaxSlippage         int             `json:"max_slippage"`
+	MinFillSize         decimal.Decimal `json:"min_fill_size"`
+	EnableMEVProtection bool            `json:"enable_mev_protection"`
+}
+
+// AmendResult is an on-chain amendment and the refund it paid
+type AmendResult struct {
+	OrderID      string          `json:"order_id"`
+	TxHash       string          `json:"tx_hash"`
+	RefundAmount decimal.Decimal `json:"refund_amount"`
 }
 
 // ExecuteIntervalParams contains parameters for executing a TWAP interval
 type ExecuteIntervalParams struct {
 	OrderID        string          `json:"order_id"`
+	ChainOrderID   string          `json:"chain_order_id"`
 	IntervalNumber int             `json:"interval_number"`
 	Amount         decimal.Decimal `json:"amount"`
 	MaxSlippage    int             `json:"max_slippage"`
-	PriceHint      decimal.Decimal `json:"price_hint"`
+	PriceHint      decimal.Decimal `json:"price_hint"`  // whole target tokens per whole source token
+	PriceScale     int32           `json:"price_scale"` // target less source token decimals
+	MinOutput      decimal.Decimal `json:"min_output"`  // hard limit on target token base units received
+	MEVProtection  bool            `json:"mev_protection"`
+}
+
+// Output values amount of the source token, in base units, at price in
+// whole target tokens per whole source token. The value is in target token
+// base units.
+func (p ExecuteIntervalParams) Output(amount, price decimal.Decimal) decimal.Decimal {
+	return amount.Mul(price).Shift(p.PriceScale)
 }
 
 // ExecutionResult contains the result of a TWAP interval execution
@@ -41,8 +127,17 @@ type ExecutionResult struct {
 	ExecutedAmount decimal.Decimal `json:"executed_amount"`
 	ExecutionPrice decimal.Decimal `json:"execution_price"`
 	GasUsed        uint64          `json:"gas_used"`
+	GasPrice       decimal.Decimal `json:"gas_price"` // smallest native unit per gas
+	GasCost        decimal.Decimal `json:"gas_cost"`  // in native tokens
 	Slippage       int             `json:"slippage"`
 	Error          string          `json:"error,omitempty"`
+
+	// How the transaction reached the chain
+	SubmissionRoute string `json:"submission_route,omitempty"`
+	BundleHash      string `json:"bundle_hash,omitempty"`
+	RelayAttempts   int    `json:"relay_attempts,omitempty"`
+	InclusionBlock  uint64 `json:"inclusion_block,omitempty"`
+	Replacements    int    `json:"replacements,omitempty"` // fee bumps before TxHash was mined
 }
 
 // CreateHTLCParams contains parameters for creating an HTLC
@@ -84,17 +179,18 @@ type HTLCStatus struct {
 
 // ChainStatus represents the status of a blockchain
 type ChainStatus struct {
-	ChainID         string    `json:"chain_id"`
-	Name            string    `json:"name"`
-	IsHealthy       bool      `json:"is_healthy"`
-	LastBlockHeight int64     `json:"last_block_height"`
-	LastBlockTime   time.Time `json:"last_block_time"`
-	AvgBlockTime    string    `json:"avg_block_time"`
-	GasPrice        string    `json:"gas_price"`
-	NetworkVersion  string    `json:"network_version"`
-	PeerCount       int       `json:"peer_count"`
-	ErrorMessage    string    `json:"error_message,omitempty"`
-	LastChecked     time.Time `json:"last_checked"`
+	ChainID         string           `json:"chain_id"`
+	Name            string           `json:"name"`
+	IsHealthy       bool             `json:"is_healthy"`
+	LastBlockHeight int64            `json:"last_block_height"`
+	LastBlockTime   time.Time        `json:"last_block_time"`
+	AvgBlockTime    string           `json:"avg_block_time"`
+	GasPrice        string           `json:"gas_price"`
+	NetworkVersion  string           `json:"network_version"`
+	PeerCount       int              `json:"peer_count"`
+	ErrorMessage    string           `json:"error_message,omitempty"`
+	LastChecked     time.Time        `json:"last_checked"`
+	Endpoints       []EndpointHealth `json:"endpoints,omitempty"`
 }
 
 // ChainEvent represents an event from a blockchain
@@ -150,15 +246,17 @@ const (
 	EventBlockCreated   = "block_created"
 )
 
-// Order statuses
+// Order statuses, shared with the order lifecycle in the database package
 const (
-	OrderStatusPending         = "pending"
-	OrderStatusExecuting       = "executing"
-	OrderStatusPartiallyFilled = "partially_filled"
-	OrderStatusCompleted       = "completed"
-	OrderStatusCancelled       = "cancelled"
-	OrderStatusExpired         = "expired"
-	OrderStatusFailed          = "failed"
+	OrderStatusPending         = string(database.OrderStatusPending)
+	OrderStatusExecuting       = string(database.OrderStatusExecuting)
+	OrderStatusPartiallyFilled = string(database.OrderStatusPartiallyFilled)
+	OrderStatusCompleted       = string(database.OrderStatusCompleted)
+	OrderStatusCancelled       = string(database.OrderStatusCancelled)
+	OrderStatusExpired         = string(database.OrderStatusExpired)
+	OrderStatusRefunded        = string(database.OrderStatusRefunded)
+	OrderStatusPaused          = string(database.OrderStatusPaused)
+	OrderStatusCancelling      = string(database.OrderStatusCancelling)
 )
 
 // HTLC statuses
@@ -173,63 +271,124 @@ const (
 
 // NewEthereumAdapter creates a new Ethereum adapter
 func NewEthereumAdapter(config config.EthereumConfig, logger interface{}) (ChainAdapter, error) {
-	// For now, return a mock adapter
-	return &MockAdapter{
-		chainID: "ethereum",
-		name:    "Ethereum",
-		config:  config,
-	}, nil
+	// Submit real transactions once a signer and bridge contract are configured
+	if config.PrivateKey != "" && config.BridgeAddress != "" {
+		zapLogger, ok := logger.(*zap.Logger)
+		if !ok {
+			zapLogger = zap.NewNop()
+		}
+		return newEthereumAdapter(config, zapLogger)
+	}
+
+	adapter := &MockAdapter{
+		adapterBase: adapterBase{chainID: "ethereum", name: "Ethereum"},
+		config:      config,
+	}
+	if config.RPCURL != "" {
+		client := newPooledRPCClient(endpointList(config.RPCURL, config.RPCURLs), config.RPCFailover, rpcTimeout)
+		adapter.identity = evmIdentityCheck(client, config.ChainID, config.BridgeAddress)
+	}
+	return adapter, nil
 }
 
 // NewCosmosAdapter creates a new Cosmos adapter
 func NewCosmosAdapter(config config.CosmosConfig, logger interface{}) (ChainAdapter, error) {
-	return &MockAdapter{
-		chainID: "cosmos",
-		name:    "Cosmos",
-		config:  config,
-	}, nil
+	adapter := &MockAdapter{
+		adapterBase: adapterBase{chainID: "cosmos", name: "Cosmos"},
+		config:      config,
+	}
+	if config.RPCURL != "" {
+		adapter.identity = cosmosIdentityCheck(config)
+	}
+	return adapter, nil
 }
 
 // NewStellarAdapter creates a new Stellar adapter
 func NewStellarAdapter(config config.StellarConfig, logger interface{}) (ChainAdapter, error) {
-	return &MockAdapter{
-		chainID: "stellar",
-		name:    "Stellar",
-		config:  config,
-	}, nil
+	adapter := &MockAdapter{
+		adapterBase: adapterBase{chainID: "stellar", name: "Stellar"},
+		config:      config,
+	}
+	if config.HorizonURL != "" {
+		adapter.identity = stellarIdentityCheck(config)
+	}
+	return adapter, nil
 }
 
 // NewBitcoinAdapter creates a new Bitcoin adapter
 func NewBitcoinAdapter(config config.BitcoinConfig, logger interface{}) (ChainAdapter, error) {
-	return &MockAdapter{
-		chainID: "bitcoin",
-		name:    "Bitcoin",
-		config:  config,
-	}, nil
+	adapter := &MockAdapter{
+		adapterBase: adapterBase{chainID: "bitcoin", name: "Bitcoin"},
+		config:      config,
+	}
+	if config.RPCURL != "" {
+		adapter.identity = bitcoinIdentityCheck(config)
+	}
+	return adapter, nil
 }
 
-// MockAdapter is a mock implementation for development/testing
-type MockAdapter struct {
+// mockGasPrice is the gas price the mock adapter reports, 20 gwei
+const mockGasPrice = 20000000000
+
+// adapterBase identifies an adapter and manages its connection; mock and
+// live adapters share it
+type adapterBase struct {
 	chainID   string
 	name      string
-	config    interface{}
 	connected bool
+
+	// identity verifies the configured network on Connect, when set
+	identity      identityCheck
+	skipIdentity  bool
+	connectResult *ConnectResult
 }
 
-func (m *MockAdapter) ChainID() string { return m.chainID }
-func (m *MockAdapter) Name() string    { return m.name }
+// MockAdapter is a mock implementation for development/testing
+type MockAdapter struct {
+	adapterBase
+	config interface{}
+}
+
+func (m *adapterBase) ChainID() string { return m.chainID }
+func (m *adapterBase) Name() string    { return m.name }
+
+// Connect verifies the remote network matches the configuration before
+// marking the adapter connected
+func (m *adapterBase) Connect() error {
+	result := &ConnectResult{ChainID: m.chainID}
+	m.connectResult = result
+
+	if m.identity != nil && !m.skipIdentity {
+		ctx, cancel := context.WithTimeout(context.Background(), rpcTimeout)
+		defer cancel()
 
-func (m *MockAdapter) Connect() error {
+		if err := m.identity(ctx, result); err != nil {
+			result.Error = err.Error()
+			return err
+		}
+		result.Verified = true
+	}
+
+	result.Connected = true
 	m.connected = true
 	return nil
 }
 
-func (m *MockAdapter) Disconnect() error {
+// ConnectResult returns what the last Connect verified
+func (m *adapterBase) ConnectResult() *ConnectResult {
+	return m.connectResult
+}
+
+func (m *adapterBase) disableIdentityChecks() {
+	m.skipIdentity = true
+}
+
+func (m *adapterBase) Disconnect() error {
 	m.connected = false
 	return nil
 }
 
-func (m *MockAdapter) IsConnected() bool {
+func (m *adapterBase) IsConnected() bool {
 	return m.connected
 }
 
@@ -252,23 +411,61 @@ func (m *MockAdapter) GetBalance(tokenAddress string) (string, error) {
 	return "1000000000000000000", nil // 1 token with 18 decimals
 }
 
-func (m *MockAdapter) CreateTWAPOrder(params CreateTWAPOrderParams) (string, error) {
-	return "mock_order_" + params.OrderID, nil
+func (m *MockAdapter) CreateTWAPOrder(params CreateTWAPOrderParams) (*CreateOrderResult, error) {
+	return &CreateOrderResult{TxHash: "mock_order_" + params.OrderID}, nil
 }
 
 func (m *MockAdapter) ExecuteTWAPInterval(params ExecuteIntervalParams) (*ExecutionResult, error) {
+	// Simulate some variance in execution
+	variance := decimal.NewFromFloat(0.99 + 0.02*float64(time.Now().Unix()%100)/100)
+	executedAmount := params.Amount.Mul(variance)
+
+	// Simulate price with small slippage around the hint
+	priceVariance := decimal.NewFromFloat(0.995 + 0.01*float64(time.Now().Unix()%100)/100)
+	executionPrice := params.PriceHint.Mul(priceVariance)
+
+	// Mirror the bridge, which reverts an interval delivering less than minOutput
+	output := params.Output(executedAmount, executionPrice)
+	if params.MinOutput.IsPositive() && output.LessThan(params.MinOutput) {
+		return nil, fmt.Errorf("%w: got %s, need %s", ErrMinOutputNotMet, output.String(), params.MinOutput.String())
+	}
+
+	slippage := 0
+	if params.PriceHint.IsPositive() {
+		bps, _ := executionPrice.Sub(params.PriceHint).Abs().Div(params.PriceHint).Mul(decimal.NewFromInt(10000)).Float64()
+		slippage = int(bps)
+	}
+
+	gasUsed := uint64(150000 + time.Now().Unix()%50000)
+	gasPrice := decimal.NewFromInt(mockGasPrice)
+
 	return &ExecutionResult{
 		Success:        true,
-		TxHash:         "0xmocktxhash" + params.OrderID,
-		ExecutedAmount: params.Amount,
-		ExecutionPrice: decimal.NewFromFloat(2000), // Mock price
-		GasUsed:        150000,
-		Slippage:       25, // 0.25%
+		TxHash:         fmt.Sprintf("0x%x", time.Now().UnixNano()),
+		ExecutedAmount: executedAmount,
+		ExecutionPrice: executionPrice,
+		GasUsed:        gasUsed,
+		GasPrice:       gasPrice,
+		GasCost:        GasCostInNative(m.chainID, gasUsed, gasPrice),
+		Slippage:       slippage,
 	}, nil
 }
 
-func (m *MockAdapter) CancelOrder(orderID string) error {
-	return nil
+func (m *MockAdapter) CancelOrder(params CancelOrderParams) (*CancelResult, error) {
+	return &CancelResult{
+		OrderID:      params.OrderID,
+		TxHash:       fmt.Sprintf("0x%x", time.Now().UnixNano()),
+		RefundAmount: params.RemainingAmount,
+		Recipient:    params.UserAddress,
+	}, nil
+}
+
+func (m *MockAdapter) AmendOrder(params AmendOrderParams) (*AmendResult, error) {
+	return &AmendResult{
+		OrderID:      params.OrderID,
+		TxHash:       fmt.Sprintf("0x%x", time.Now().UnixNano()),
+		RefundAmount: params.RefundAmount,
+	}, nil
 }
 
 func (m *MockAdapter) GetOrderStatus(orderID string) (*OrderStatus, error) {
@@ -338,17 +535,17 @@ func (m *MockAdapter) GetChainStatus() (*ChainStatus, error) {
 		ChainID:         m.chainID,
 		Name:            m.name,
 		IsHealthy:       m.connected,
-		LastBlockHeight: 1000000,
+		LastBlockHeight: time.Now().Unix() / 12, // advances every 12s like a live chain
 		LastBlockTime:   time.Now(),
 		AvgBlockTime:    "12s",
-		GasPrice:        "20000000000", // 20 gwei
+		GasPrice:        decimal.NewFromInt(mockGasPrice).String(),
 		NetworkVersion:  "1.0.0",
 		PeerCount:       25,
 		LastChecked:     time.Now(),
 	}, nil
 }
 
-func (m *MockAdapter) Health() error {
+func (m *adapterBase) Health() error {
 	if !m.connected {
 		return fmt.Errorf("adapter not connected")
 	}
@@ -406,6 +603,74 @@ func GetDefaultTokenDecimals(chainID string) int {
 	}
 }
 
+// knownTokenDecimals are the decimals of tokens orders name by symbol
+var knownTokenDecimals = map[string]int{
+	"ETH":   18,
+	"WETH":  18,
+	"DAI":   18,
+	"MATIC": 18,
+	"AVAX":  18,
+	"USDC":  6,
+	"USDT":  6,
+	"ATOM":  6,
+	"XLM":   7,
+	"BTC":   8,
+	"WBTC":  8,
+	"SOL":   9,
+	"NEAR":  24,
+}
+
+// GetTokenDecimals returns the decimals of a token on a chain, falling back
+// to the chain's native decimals for tokens it does not know
+func GetTokenDecimals(chainID, token string) int {
+	if decimals, ok := knownTokenDecimals[strings.ToUpper(token)]; ok {
+		return decimals
+	}
+	return GetDefaultTokenDecimals(chainID)
+}
+
+// GetNativeTokenSymbol returns the symbol of the token a chain charges gas in
+func GetNativeTokenSymbol(chainID string) string {
+	switch chainID {
+	case "ethereum", "arbitrum", "optimism":
+		return "ETH"
+	case "polygon":
+		return "MATIC"
+	case "avalanche":
+		return "AVAX"
+	case "cosmos":
+		return "ATOM"
+	case "stellar":
+		return "XLM"
+	case "bitcoin":
+		return "BTC"
+	case "near":
+		return "NEAR"
+	case "solana":
+		return "SOL"
+	default:
+		return ""
+	}
+}
+
+// GasCostInNative converts gas used at a price in the smallest native unit
+// to whole native tokens
+func GasCostInNative(chainID string, gasUsed uint64, gasPrice decimal.Decimal) decimal.Decimal {
+	return gasPrice.Mul(decimal.NewFromInt(int64(gasUsed))).Shift(-int32(GetDefaultTokenDecimals(chainID)))
+}
+
+// OrderCreationGas is the gas limit the adapter creates an order on the
+// source chain with, or zero where the orchestrator does not send it
+func OrderCreationGas(chainID string, permit bool) uint64 {
+	if chainID != "ethereum" {
+		return 0
+	}
+	if permit {
+		return createOrderGasLimit + permitGasLimit
+	}
+	return createOrderGasLimit
+}
+
 // FormatTokenAmount formats a token amount for display
 func FormatTokenAmount(amount decimal.Decimal, decimals int) string {
 	divisor := decimal.NewFromBigInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil), 0)
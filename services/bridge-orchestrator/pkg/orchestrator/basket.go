@@ -0,0 +1,112 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+// BasketProgress aggregates a basket's orders. Completion rates are in
+// percent; the orders may sell different tokens, so the basket's rate is
+// the mean of theirs rather than an amount-weighted one.
+type BasketProgress struct {
+	Orders            int     `json:"orders"`
+	ActiveOrders      int     `json:"active_orders"`
+	PausedOrders      int     `json:"paused_orders"`
+	CompletedOrders   int     `json:"completed_orders"`
+	UnfilledOrders    int     `json:"unfilled_orders"` // ended without filling in full
+	CompletionRate    float64 `json:"completion_rate"`
+	MinCompletionRate float64 `json:"min_completion_rate"`
+	MaxCompletionRate float64 `json:"max_completion_rate"`
+}
+
+// BasketCancelOutcome reports the cancellation of a basket's live orders
+type BasketCancelOutcome struct {
+	BasketID    string            `json:"basket_id"`
+	Orders      []*CancelOutcome  `json:"orders"`
+	OrderErrors map[string]string `json:"order_errors,omitempty"`
+}
+
+// NewBasketProgress reports on a basket's orders
+func NewBasketProgress(orders []*database.Order) BasketProgress {
+	progress := BasketProgress{Orders: len(orders)}
+
+	for i, order := range orders {
+		switch database.OrderStatus(order.Status) {
+		case database.OrderStatusCompleted, database.OrderStatusClaimed:
+			progress.CompletedOrders++
+		case database.OrderStatusPartiallyFilled, database.OrderStatusCancelled,
+			database.OrderStatusExpired, database.OrderStatusRefunded:
+			progress.UnfilledOrders++
+		case database.OrderStatusPaused:
+			progress.PausedOrders++
+		default:
+			progress.ActiveOrders++
+		}
+
+		rate := order.CalculateCompletionRate()
+		progress.CompletionRate += rate
+		if i == 0 || rate < progress.MinCompletionRate {
+			progress.MinCompletionRate = rate
+		}
+		if rate > progress.MaxCompletionRate {
+			progress.MaxCompletionRate = rate
+		}
+	}
+
+	if len(orders) > 0 {
+		progress.CompletionRate /= float64(len(orders))
+	}
+	return progress
+}
+
+// CancelBasket cancels and refunds every live order in the basket. An order
+// that fails to cancel is reported and can be cancelled on its own.
+func (o *Orchestrator) CancelBasket(ctx context.Context, id string) (*BasketCancelOutcome, error) {
+	if _, err := o.db.GetBasket(id); err != nil {
+		return nil, err
+	}
+
+	orders, err := o.db.GetOrdersByBasket(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get basket orders: %w", err)
+	}
+
+	outcome := &BasketCancelOutcome{BasketID: id}
+	outcome.Orders, outcome.OrderErrors = o.cancelLiveOrders(ctx, orders, "basket cancelled")
+
+	o.logger.Info("Basket cancelled",
+		zap.String("basket_id", id),
+		zap.Int("cancelled", len(outcome.Orders)),
+		zap.Int("failed", len(outcome.OrderErrors)))
+	return outcome, nil
+}
+
+// cancelLiveOrders cancels those of orders that are still live, returning
+// the outcomes and, keyed by order ID, the errors of any that failed
+func (o *Orchestrator) cancelLiveOrders(ctx context.Context, orders []*database.Order, reason string) ([]*CancelOutcome, map[string]string) {
+	outcomes := []*CancelOutcome{}
+	var failures map[string]string
+
+	for _, order := range orders {
+		if !database.CanTransition(database.OrderStatus(order.Status), database.OrderStatusCancelling) &&
+			order.Status != string(database.OrderStatusCancelling) {
+			continue
+		}
+
+		result, err := o.CancelOrder(ctx, order.ID, reason)
+		if err != nil {
+			if failures == nil {
+				failures = make(map[string]string)
+			}
+			failures[order.ID] = err.Error()
+			continue
+		}
+		outcomes = append(outcomes, result)
+	}
+
+	return outcomes, failures
+}
@@ -0,0 +1,38 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+func TestNewBasketProgress(t *testing.T) {
+	order := func(status string, executed int64) *database.Order {
+		return &database.Order{
+			Status:         status,
+			SourceAmount:   decimal.NewFromInt(200),
+			ExecutedAmount: decimal.NewFromInt(executed),
+		}
+	}
+
+	progress := NewBasketProgress([]*database.Order{
+		order(string(database.OrderStatusCompleted), 200),
+		order(string(database.OrderStatusExecuting), 100),
+		order(string(database.OrderStatusPaused), 40),
+		order(string(database.OrderStatusCancelled), 20),
+	})
+
+	if progress.Orders != 4 || progress.CompletedOrders != 1 || progress.ActiveOrders != 1 ||
+		progress.PausedOrders != 1 || progress.UnfilledOrders != 1 {
+		t.Fatalf("unexpected order counts: %+v", progress)
+	}
+	if progress.CompletionRate != 45 || progress.MinCompletionRate != 10 || progress.MaxCompletionRate != 100 {
+		t.Fatalf("unexpected completion rates: %+v", progress)
+	}
+
+	if empty := NewBasketProgress(nil); empty.CompletionRate != 0 || empty.Orders != 0 {
+		t.Fatalf("empty basket should report no progress: %+v", empty)
+	}
+}
@@ -0,0 +1,174 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+	"flowfusion/bridge-orchestrator/pkg/adapters"
+)
+
+// CancelOutcome reports a completed cancellation and its refunds
+type CancelOutcome struct {
+	OrderID         string          `json:"order_id"`
+	Status          string          `json:"status"`
+	ExecutedAmount  decimal.Decimal `json:"executed_amount"`
+	RefundAmount    decimal.Decimal `json:"refund_amount"`
+	RefundRecipient string          `json:"refund_recipient"`
+	RefundTxHash    string          `json:"refund_tx_hash,omitempty"`
+	HTLCRefunds     []HTLCRefund    `json:"htlc_refunds,omitempty"`
+}
+
+// HTLCRefund is the outcome of unwinding one of the order's HTLCs. An HTLC
+// that cannot be refunded yet, typically because its timelock has not
+// passed, is left active and reported with Error set.
+type HTLCRefund struct {
+	Address string `json:"address"`
+	ChainID string `json:"chain_id"`
+	TxHash  string `json:"tx_hash,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// CancelOrder cancels an order: it stops scheduling, waits for any interval
+// in flight, cancels the order on its source chain so the unexecuted
+// remainder is refunded to the user, and unwinds the order's HTLCs. The
+// order stays cancelling if a step fails; cancelling it again resumes.
+func (o *Orchestrator) CancelOrder(ctx context.Context, orderID, cause string) (*CancelOutcome, error) {
+	order, err := o.db.GetOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stop scheduling before anything touches the chain
+	if err := order.Transition(database.OrderStatusCancelling, database.ActorUser, cause); err != nil {
+		return nil, err
+	}
+	if len(order.PendingEvents()) > 0 {
+		if err := o.db.UpdateOrder(order); err != nil {
+			return nil, fmt.Errorf("failed to mark order cancelling: %w", err)
+		}
+	}
+
+	if err := o.twapEngine.SettleInterval(ctx, orderID); err != nil {
+		return nil, fmt.Errorf("in-flight interval did not settle: %w", err)
+	}
+
+	// Reread so the refund reflects the settled fill
+	order, err = o.db.GetOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := &CancelOutcome{
+		OrderID:         orderID,
+		ExecutedAmount:  order.ExecutedAmount,
+		RefundAmount:    decimal.Zero,
+		RefundRecipient: order.UserAddress,
+	}
+
+	var result *adapters.CancelResult
+	if remaining := order.GetRemainingAmount(); remaining.IsPositive() {
+		adapter, err := o.adapterManager.GetAdapter(order.SourceChain)
+		if err != nil {
+			return nil, err
+		}
+		result, err = adapter.CancelOrder(adapters.CancelOrderParams{
+			OrderID:         orderID,
+			ChainOrderID:    order.GetChainOrderID(),
+			UserAddress:     order.UserAddress,
+			SourceToken:     order.SourceToken,
+			RemainingAmount: remaining,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel order on %s: %w", order.SourceChain, err)
+		}
+		outcome.RefundAmount = result.RefundAmount
+		outcome.RefundRecipient = result.Recipient
+		outcome.RefundTxHash = result.TxHash
+	}
+
+	outcome.HTLCRefunds = o.refundOrderHTLCs(orderID)
+
+	cancelCause := "cancelled with nothing left to refund"
+	if result != nil {
+		cancelCause = fmt.Sprintf("cancelled on %s in %s", order.SourceChain, result.TxHash)
+	}
+	if err := order.Transition(database.OrderStatusCancelled, database.ActorChain, cancelCause); err != nil {
+		return nil, err
+	}
+	if result != nil && result.RefundAmount.IsPositive() {
+		order.RefundTxHash = &result.TxHash
+		order.RefundedAmount = result.RefundAmount
+		refundCause := fmt.Sprintf("refunded %s to %s", result.RefundAmount.String(), result.Recipient)
+		if err := order.Transition(database.OrderStatusRefunded, database.ActorChain, refundCause); err != nil {
+			return nil, err
+		}
+	}
+	if err := o.db.UpdateOrder(order); err != nil {
+		return nil, fmt.Errorf("failed to record cancellation: %w", err)
+	}
+	outcome.Status = order.Status
+
+	o.stats.mutex.Lock()
+	o.stats.ActiveOrders--
+	o.stats.mutex.Unlock()
+
+	o.logger.Info("Order cancelled",
+		zap.String("order_id", orderID),
+		zap.String("status", order.Status),
+		zap.String("refund_amount", outcome.RefundAmount.String()),
+		zap.String("refund_tx_hash", outcome.RefundTxHash))
+
+	return outcome, nil
+}
+
+// refundOrderHTLCs refunds the order's active HTLCs
+func (o *Orchestrator) refundOrderHTLCs(orderID string) []HTLCRefund {
+	htlcs, err := o.db.GetHTLCsByOrder(orderID)
+	if err != nil {
+		o.logger.Error("Failed to get order HTLCs", zap.String("order_id", orderID), zap.Error(err))
+		return nil
+	}
+
+	var refunds []HTLCRefund
+	for _, htlc := range htlcs {
+		if htlc.Status != string(database.HTLCStatusActive) {
+			continue
+		}
+
+		refund := HTLCRefund{Address: htlc.Address, ChainID: htlc.ChainID}
+		txHash, err := o.refundHTLC(htlc)
+		if err != nil {
+			o.logger.Warn("HTLC not refunded",
+				zap.String("order_id", orderID),
+				zap.String("htlc", htlc.Address),
+				zap.Error(err))
+			refund.Error = err.Error()
+		} else {
+			refund.TxHash = txHash
+		}
+		refunds = append(refunds, refund)
+	}
+	return refunds
+}
+
+// refundHTLC refunds one HTLC and records it as refunded
+func (o *Orchestrator) refundHTLC(htlc *database.HTLC) (string, error) {
+	adapter, err := o.adapterManager.GetAdapter(htlc.ChainID)
+	if err != nil {
+		return "", err
+	}
+	txHash, err := adapter.RefundHTLC(htlc.Address)
+	if err != nil {
+		return "", err
+	}
+
+	htlc.Status = string(database.HTLCStatusRefunded)
+	if err := o.db.UpdateHTLC(htlc); err != nil {
+		return txHash, fmt.Errorf("refunded in %s but not recorded: %w", txHash, err)
+	}
+	return txHash, nil
+}
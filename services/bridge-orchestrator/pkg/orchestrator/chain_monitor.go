@@ -0,0 +1,260 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+	"flowfusion/bridge-orchestrator/internal/database"
+	"flowfusion/bridge-orchestrator/pkg/adapters"
+)
+
+// blockTimeAlpha weighs the latest observed block time in the average
+const blockTimeAlpha = 0.2
+
+// ChainHealth is the latest polled status of a chain
+type ChainHealth struct {
+	ChainID      string                    `json:"chain_id"`
+	Name         string                    `json:"name"`
+	Status       database.HealthStatus     `json:"status"`
+	StatusSince  time.Time                 `json:"status_since"`
+	BlockHeight  int64                     `json:"block_height"`
+	BlockTime    time.Duration             `json:"block_time"`
+	LastAdvance  time.Time                 `json:"last_advance"` // when the head last moved
+	Lag          time.Duration             `json:"lag"`
+	GasPrice     decimal.Decimal           `json:"gas_price"`
+	PeerCount    int                       `json:"peer_count"`
+	ErrorRate    float64                   `json:"error_rate"`
+	LastError    string                    `json:"last_error,omitempty"`
+	Endpoints    []adapters.EndpointHealth `json:"endpoints,omitempty"`
+	LastChecked  time.Time                 `json:"last_checked"`
+	pollFailures []bool                    // recent poll outcomes, oldest first
+}
+
+// chainMonitor polls every adapter's status and health on an interval
+func (o *Orchestrator) chainMonitor(ctx context.Context) {
+	defer o.wg.Done()
+
+	o.logger.Info("Starting chain monitor")
+
+	ticker := time.NewTicker(o.config.ChainMonitor.Interval)
+	defer ticker.Stop()
+
+	o.pollChains()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.stopChan:
+			return
+		case <-ticker.C:
+			o.pollChains()
+		}
+	}
+}
+
+// pollChains polls each adapter concurrently and persists what it finds
+func (o *Orchestrator) pollChains() {
+	var wg sync.WaitGroup
+	for chainID, adapter := range o.adapterManager.GetAllAdapters() {
+		wg.Add(1)
+		go func(chainID string, adapter adapters.ChainAdapter) {
+			defer wg.Done()
+
+			status, err := adapter.GetChainStatus()
+			if err == nil {
+				err = adapter.Health()
+			}
+			if err == nil && !status.IsHealthy {
+				err = fmt.Errorf("chain reported unhealthy: %s", status.ErrorMessage)
+			}
+
+			health := o.observeChain(chainID, status, err, time.Now())
+			if err := o.db.UpdateChainStatus(chainStatusRecord(health)); err != nil {
+				o.logger.Error("Failed to record chain status",
+					zap.String("chain_id", chainID),
+					zap.Error(err))
+			}
+		}(chainID, adapter)
+	}
+	wg.Wait()
+}
+
+// observeChain folds a poll into the chain's health and logs transitions
+func (o *Orchestrator) observeChain(chainID string, status *adapters.ChainStatus, pollErr error, now time.Time) ChainHealth {
+	o.healthMutex.Lock()
+	defer o.healthMutex.Unlock()
+
+	health, ok := o.chainHealth[chainID]
+	if !ok {
+		health = &ChainHealth{ChainID: chainID, Status: database.HealthStatusUnknown, StatusSince: now}
+		o.chainHealth[chainID] = health
+	}
+
+	previous := health.Status
+	updateChainHealth(health, status, pollErr, now, o.config.ChainMonitor)
+
+	if health.Status != previous {
+		fields := []zap.Field{
+			zap.String("chain_id", chainID),
+			zap.String("from", string(previous)),
+			zap.String("to", string(health.Status)),
+			zap.Duration("lag", health.Lag),
+			zap.Float64("error_rate", health.ErrorRate),
+		}
+		if health.Status == database.HealthStatusHealthy {
+			o.logger.Info("Chain health changed", fields...)
+		} else {
+			o.logger.Warn("Chain health changed", append(fields, zap.String("last_error", health.LastError))...)
+		}
+	}
+
+	return health.snapshot()
+}
+
+// updateChainHealth applies a poll to health and reclassifies it
+func updateChainHealth(health *ChainHealth, status *adapters.ChainStatus, pollErr error, now time.Time, cfg config.ChainMonitorConfig) {
+	health.LastChecked = now
+	health.pollFailures = append(health.pollFailures, pollErr != nil)
+	if len(health.pollFailures) > cfg.ErrorWindow {
+		health.pollFailures = health.pollFailures[len(health.pollFailures)-cfg.ErrorWindow:]
+	}
+
+	failures := 0
+	for _, failed := range health.pollFailures {
+		if failed {
+			failures++
+		}
+	}
+	health.ErrorRate = float64(failures) / float64(len(health.pollFailures))
+
+	if status != nil {
+		health.Name = status.Name
+		health.Endpoints = status.Endpoints
+	}
+
+	if pollErr != nil {
+		health.LastError = pollErr.Error()
+	} else {
+		health.LastError = ""
+		health.PeerCount = status.PeerCount
+		if gasPrice, err := decimal.NewFromString(status.GasPrice); err == nil {
+			health.GasPrice = gasPrice
+		}
+
+		if health.BlockTime == 0 {
+			health.BlockTime, _ = time.ParseDuration(status.AvgBlockTime)
+		}
+		if status.LastBlockHeight > health.BlockHeight {
+			if health.BlockHeight > 0 && !health.LastAdvance.IsZero() {
+				observed := now.Sub(health.LastAdvance) / time.Duration(status.LastBlockHeight-health.BlockHeight)
+				if health.BlockTime == 0 {
+					health.BlockTime = observed
+				} else {
+					health.BlockTime = time.Duration(float64(health.BlockTime)*(1-blockTimeAlpha) + float64(observed)*blockTimeAlpha)
+				}
+			}
+			health.BlockHeight = status.LastBlockHeight
+			health.LastAdvance = now
+		}
+	}
+
+	if !health.LastAdvance.IsZero() {
+		health.Lag = now.Sub(health.LastAdvance)
+	}
+
+	next := classifyChainHealth(health, cfg)
+	if next != health.Status {
+		health.Status = next
+		health.StatusSince = now
+	}
+}
+
+// classifyChainHealth grades a chain by the worse of its error rate and how
+// many block times its head has been stalled
+func classifyChainHealth(health *ChainHealth, cfg config.ChainMonitorConfig) database.HealthStatus {
+	if health.BlockHeight == 0 && health.ErrorRate == 1 {
+		return database.HealthStatusUnhealthy
+	}
+	if health.BlockHeight == 0 {
+		return database.HealthStatusUnknown
+	}
+
+	var lagBlocks float64
+	if health.BlockTime > 0 {
+		lagBlocks = float64(health.Lag) / float64(health.BlockTime)
+	}
+
+	switch {
+	case health.ErrorRate >= cfg.UnhealthyErrorRate || lagBlocks >= float64(cfg.UnhealthyLagBlocks):
+		return database.HealthStatusUnhealthy
+	case health.ErrorRate >= cfg.DegradedErrorRate || lagBlocks >= float64(cfg.DegradedLagBlocks):
+		return database.HealthStatusDegraded
+	default:
+		return database.HealthStatusHealthy
+	}
+}
+
+// snapshot returns a copy safe to hand out
+func (h *ChainHealth) snapshot() ChainHealth {
+	snapshot := *h
+	snapshot.pollFailures = nil
+	snapshot.Endpoints = append([]adapters.EndpointHealth(nil), h.Endpoints...)
+	return snapshot
+}
+
+// chainStatusRecord converts health into a chain_status row
+func chainStatusRecord(health ChainHealth) *database.ChainStatus {
+	record := &database.ChainStatus{
+		ChainID:         health.ChainID,
+		Name:            health.Name,
+		HealthStatus:    string(health.Status),
+		LastHealthCheck: health.LastChecked,
+	}
+	if record.Name == "" {
+		record.Name = health.ChainID
+	}
+	if health.LastError != "" {
+		record.ErrorMessage = &health.LastError
+	}
+	if health.BlockHeight > 0 {
+		record.LastBlockHeight = &health.BlockHeight
+		record.LastBlockTime = &health.LastAdvance
+		record.GasPrice = &health.GasPrice
+		record.PeerCount = &health.PeerCount
+	}
+	if health.BlockTime > 0 {
+		avgBlockTime := fmt.Sprintf("%.3f seconds", health.BlockTime.Seconds())
+		record.AvgBlockTime = &avgBlockTime
+	}
+	return record
+}
+
+// GetChainHealth returns the latest polled health of a chain
+func (o *Orchestrator) GetChainHealth(chainID string) (ChainHealth, bool) {
+	o.healthMutex.RLock()
+	defer o.healthMutex.RUnlock()
+
+	health, ok := o.chainHealth[chainID]
+	if !ok {
+		return ChainHealth{}, false
+	}
+	return health.snapshot(), true
+}
+
+// GetAllChainHealth returns the latest polled health of every chain
+func (o *Orchestrator) GetAllChainHealth() map[string]ChainHealth {
+	o.healthMutex.RLock()
+	defer o.healthMutex.RUnlock()
+
+	all := make(map[string]ChainHealth, len(o.chainHealth))
+	for chainID, health := range o.chainHealth {
+		all[chainID] = health.snapshot()
+	}
+	return all
+}
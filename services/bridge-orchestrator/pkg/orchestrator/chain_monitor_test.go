@@ -0,0 +1,90 @@
+package orchestrator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+	"flowfusion/bridge-orchestrator/internal/database"
+	"flowfusion/bridge-orchestrator/pkg/adapters"
+)
+
+var testMonitorConfig = config.ChainMonitorConfig{
+	Interval:           30 * time.Second,
+	DegradedLagBlocks:  5,
+	UnhealthyLagBlocks: 20,
+	ErrorWindow:        10,
+	DegradedErrorRate:  0.2,
+	UnhealthyErrorRate: 0.5,
+}
+
+func TestChainHealthFollowsHeadLag(t *testing.T) {
+	health := &ChainHealth{ChainID: "ethereum", Status: database.HealthStatusUnknown}
+	start := time.Now()
+	poll := func(at time.Duration, height int64) database.HealthStatus {
+		status := &adapters.ChainStatus{Name: "Ethereum", IsHealthy: true, LastBlockHeight: height, AvgBlockTime: "12s", GasPrice: "1000"}
+		updateChainHealth(health, status, nil, start.Add(at), testMonitorConfig)
+		return health.Status
+	}
+
+	if got := poll(0, 100); got != database.HealthStatusHealthy {
+		t.Fatalf("first poll: got %s", got)
+	}
+	if got := poll(36*time.Second, 103); got != database.HealthStatusHealthy || health.BlockTime != 12*time.Second {
+		t.Fatalf("advancing head: got %s with block time %s", got, health.BlockTime)
+	}
+
+	// The head stalls for 6, then 21 block times
+	if got := poll(108*time.Second, 103); got != database.HealthStatusDegraded {
+		t.Fatalf("after 6 blocks of lag: got %s", got)
+	}
+	if got := poll(288*time.Second, 103); got != database.HealthStatusUnhealthy {
+		t.Fatalf("after 21 blocks of lag: got %s", got)
+	}
+
+	since := health.StatusSince
+	if got := poll(300*time.Second, 104); got != database.HealthStatusHealthy || health.StatusSince == since {
+		t.Fatalf("recovered head: got %s since %s", got, health.StatusSince)
+	}
+}
+
+func TestChainHealthFollowsErrorRate(t *testing.T) {
+	health := &ChainHealth{ChainID: "ethereum", Status: database.HealthStatusUnknown}
+	start := time.Now()
+	height := int64(100)
+	poll := func(i int, err error) database.HealthStatus {
+		status := &adapters.ChainStatus{IsHealthy: err == nil, LastBlockHeight: height, AvgBlockTime: "12s"}
+		height++
+		updateChainHealth(health, status, err, start.Add(time.Duration(i)*12*time.Second), testMonitorConfig)
+		return health.Status
+	}
+
+	for i := 0; i < 8; i++ {
+		poll(i, nil)
+	}
+	rpcDown := errors.New("connection refused")
+	if got := poll(8, rpcDown); got != database.HealthStatusHealthy {
+		t.Fatalf("one failed poll in ten: got %s", got)
+	}
+	if got := poll(9, rpcDown); got != database.HealthStatusDegraded || health.LastError == "" {
+		t.Fatalf("two failed polls in ten: got %s", got)
+	}
+	for i := 10; i < 13; i++ {
+		poll(i, rpcDown)
+	}
+	if health.Status != database.HealthStatusUnhealthy || health.ErrorRate != 0.5 {
+		t.Fatalf("five failed polls in ten: got %s at %.1f", health.Status, health.ErrorRate)
+	}
+}
+
+func TestUnreachableChainIsUnhealthy(t *testing.T) {
+	health := &ChainHealth{ChainID: "cosmos", Status: database.HealthStatusUnknown}
+	updateChainHealth(health, nil, errors.New("adapter not connected"), time.Now(), testMonitorConfig)
+	if health.Status != database.HealthStatusUnhealthy {
+		t.Fatalf("got %s", health.Status)
+	}
+	if record := chainStatusRecord(health.snapshot()); record.LastBlockHeight != nil || record.ErrorMessage == nil || record.Name != "cosmos" {
+		t.Fatalf("unexpected record %+v", record)
+	}
+}
@@ -28,6 +28,10 @@ type Orchestrator struct {
 	wg            sync.WaitGroup
 	mutex         sync.RWMutex
 
+	// Latest polled health per chain
+	chainHealth map[string]*ChainHealth
+	healthMutex sync.RWMutex
+
 	// Statistics
 	stats *Statistics
 }
@@ -41,7 +45,6 @@ type Statistics struct {
 	ActiveOrders       int64     `json:"active_orders"`
 	CompletedOrders    int64     `json:"completed_orders"`
 	FailedOrders       int64     `json:"failed_orders"`
-	TotalVolume        string    `json:"total_volume"`
 	CrossChainSwaps    int64     `json:"cross_chain_swaps"`
 	SuccessfulSwaps    int64     `json:"successful_swaps"`
 	AverageProcessTime string    `json:"average_process_time"`
@@ -67,6 +70,7 @@ func New(
 		logger:         logger,
 		eventHandlers:  make(map[string]EventHandler),
 		stopChan:       make(chan struct{}),
+		chainHealth:    make(map[string]*ChainHealth),
 		stats: &Statistics{
 			startTime: time.Now(),
 		},
@@ -100,6 +104,14 @@ func (o *Orchestrator) Start(ctx context.Context) error {
 	o.wg.Add(1)
 	go o.statisticsUpdater(ctx)
 
+	// Start chain status poller
+	o.wg.Add(1)
+	go o.chainMonitor(ctx)
+
+	// Start recurring order scheduler
+	o.wg.Add(1)
+	go o.recurringScheduler(ctx)
+
 	// Wait for context cancellation
 	<-ctx.Done()
 
@@ -156,7 +168,8 @@ func (o *Orchestrator) eventSubscriber(ctx context.Context) {
 	}
 }
 
-// orderMonitor monitors order statuses and handles timeouts
+// orderMonitor monitors order statuses and handles timeouts, and drops
+// expired idempotency keys
 func (o *Orchestrator) orderMonitor(ctx context.Context) {
 	defer o.wg.Done()
 
@@ -175,6 +188,9 @@ func (o *Orchestrator) orderMonitor(ctx context.Context) {
 			if err := o.checkOrderTimeouts(); err != nil {
 				o.logger.Error("Failed to check order timeouts", zap.Error(err))
 			}
+			if err := o.db.CleanupExpiredIdempotencyKeys(time.Now()); err != nil {
+				o.logger.Error("Failed to clean up idempotency keys", zap.Error(err))
+			}
 		}
 	}
 }
@@ -255,9 +271,19 @@ func (o *Orchestrator) handleOrderExecuted(event *adapters.ChainEvent) error {
 		return fmt.Errorf("failed to get order: %w", err)
 	}
 
-	// Update order status based on event
-	order.Status = string(database.OrderStatusExecuting)
-	order.UpdatedAt = time.Now()
+	// Update order status based on event. A late event for an order that
+	// has since finished or been cancelled is ignored.
+	cause := fmt.Sprintf("executed on %s in %s", event.ChainID, event.TxHash)
+	if err := order.Transition(database.OrderStatusExecuting, database.ActorChain, cause); err != nil {
+		o.logger.Warn("Ignoring order executed event",
+			zap.String("order_id", orderID),
+			zap.String("status", order.Status),
+			zap.Error(err))
+		return nil
+	}
+	if len(order.PendingEvents()) == 0 {
+		return nil
+	}
 
 	if err := o.db.UpdateOrder(order); err != nil {
 		return fmt.Errorf("failed to update order: %w", err)
@@ -355,18 +381,36 @@ func (o *Orchestrator) checkOrderTimeouts() error {
 		return fmt.Errorf("failed to get orders: %w", err)
 	}
 
+	// An outage can outlast an order's timeout while it is paused
+	paused, err := o.db.GetPausedOrders()
+	if err != nil {
+		return fmt.Errorf("failed to get paused orders: %w", err)
+	}
+	orders = append(orders, paused...)
+
 	currentTime := time.Now().Unix()
 	
 	for _, order := range orders {
 		// Check if order has timed out
 		if currentTime >= order.TimeoutTimestamp {
-			o.logger.Info("Order timed out",
+			fields := []zap.Field{
 				zap.String("order_id", order.ID),
-				zap.Int64("timeout_timestamp", order.TimeoutTimestamp))
+				zap.Int64("timeout_timestamp", order.TimeoutTimestamp),
+			}
+			if order.IsPaused() && order.PauseReason != nil {
+				fields = append(fields, zap.String("pause_reason", *order.PauseReason))
+			}
+			o.logger.Info("Order timed out", fields...)
 
-			// Update order status to expired
-			order.Status = string(database.OrderStatusExpired)
-			order.UpdatedAt = time.Now()
+			// Update order status to expired. The pause reason is kept to
+			// show why it never finished.
+			if err := order.Transition(database.OrderStatusExpired, database.ActorSystem, "timed out"); err != nil {
+				o.logger.Error("Failed to expire order",
+					zap.String("order_id", order.ID),
+					zap.Error(err))
+				continue
+			}
+			order.PausedAt = nil
 
 			if err := o.db.UpdateOrder(order); err != nil {
 				o.logger.Error("Failed to update expired order",
@@ -413,7 +457,6 @@ func (o *Orchestrator) GetStatistics() *Statistics {
 		ActiveOrders:       o.stats.ActiveOrders,
 		CompletedOrders:    o.stats.CompletedOrders,
 		FailedOrders:       o.stats.FailedOrders,
-		TotalVolume:        o.stats.TotalVolume,
 		CrossChainSwaps:    o.stats.CrossChainSwaps,
 		SuccessfulSwaps:    o.stats.SuccessfulSwaps,
 		AverageProcessTime: o.stats.AverageProcessTime,
@@ -465,6 +508,14 @@ func (o *Orchestrator) HealthCheck() map[string]interface{} {
 	}
 	health["adapters"] = adapters
 
+	// Network identity verified at connect
+	if report := o.adapterManager.GetConnectReport(); report != nil {
+		health["adapter_connections"] = report
+	}
+
+	// Polled chain health
+	health["chains"] = o.GetAllChainHealth()
+
 	// Add statistics
 	health["statistics"] = o.GetStatistics()
 
@@ -502,6 +553,96 @@ func (o *Orchestrator) ProcessOrder(orderID string) error {
 	return nil
 }
 
+// SubmitPermitOrder creates a permit-funded order on its source chain in the
+// background. The permit is submitted ahead of the order so the bridge can
+// pull the user's tokens; if either fails the order is cancelled.
+func (o *Orchestrator) SubmitPermitOrder(order *database.Order, permit *adapters.Permit) error {
+	if _, err := o.adapterManager.GetAdapter(order.SourceChain); err != nil {
+		return fmt.Errorf("failed to get adapter for %s: %w", order.SourceChain, err)
+	}
+
+	o.submitSourceOrder(order, permit)
+	return nil
+}
+
+// submitSourceOrder creates the order on its source chain in the
+// background, pulling the user's tokens under the permit if there is one or
+// an existing approval otherwise. If creation fails the order is cancelled.
+func (o *Orchestrator) submitSourceOrder(order *database.Order, permit *adapters.Permit) {
+	params := adapters.CreateTWAPOrderParams{
+		OrderID:             order.ID,
+		UserAddress:         order.UserAddress,
+		SourceToken:         order.SourceToken,
+		TargetToken:         order.TargetToken,
+		Amount:              order.SourceAmount,
+		MinReceived:         order.MinReceived,
+		WindowMinutes:       order.WindowMinutes,
+		Intervals:           order.ExecutionIntervals,
+		MaxSlippage:         order.MaxSlippage,
+		HashedSecret:        order.HTLCHash,
+		TimeoutHeight:       order.TimeoutHeight,
+		TimeoutTimestamp:    order.TimeoutTimestamp,
+		TargetChain:         order.TargetChain,
+		TargetRecipient:     order.TargetRecipient,
+		MinFillSize:         order.MinFillSize,
+		EnableMEVProtection: order.EnableMEVProtection,
+		Permit:              permit,
+	}
+
+	// The caller keeps using order, so the goroutine works from copies of
+	// its fields and reloads the order to cancel it
+	orderID, sourceChain := order.ID, order.SourceChain
+
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+
+		adapter, err := o.adapterManager.GetAdapter(sourceChain)
+		if err == nil {
+			var result *adapters.CreateOrderResult
+			if result, err = adapter.CreateTWAPOrder(params); err == nil {
+				o.logger.Info("Order created on source chain",
+					zap.String("order_id", orderID),
+					zap.String("chain", sourceChain),
+					zap.String("tx_hash", result.TxHash),
+					zap.String("chain_order_id", result.ChainOrderID),
+					zap.Bool("with_permit", permit != nil))
+				if result.ChainOrderID == "" {
+					return
+				}
+				if err := o.db.SetChainOrderID(orderID, result.ChainOrderID); err != nil {
+					// The bridge cannot be told about the order without
+					// its id, so it cannot be executed or cancelled there
+					o.logger.Error("Failed to record bridge order id",
+						zap.String("order_id", orderID),
+						zap.String("chain_order_id", result.ChainOrderID),
+						zap.Error(err))
+				}
+				return
+			}
+		}
+
+		o.logger.Error("Failed to create order on source chain, cancelling",
+			zap.String("order_id", orderID),
+			zap.String("chain", sourceChain),
+			zap.Error(err))
+
+		current, loadErr := o.db.GetOrder(orderID)
+		if loadErr != nil {
+			o.logger.Error("Failed to cancel order", zap.String("order_id", orderID), zap.Error(loadErr))
+			return
+		}
+		if err := current.Transition(database.OrderStatusCancelled, database.ActorSystem,
+			fmt.Sprintf("source chain order creation failed: %v", err)); err != nil {
+			o.logger.Error("Failed to cancel order", zap.String("order_id", orderID), zap.Error(err))
+			return
+		}
+		if err := o.db.UpdateOrder(current); err != nil {
+			o.logger.Error("Failed to cancel order", zap.String("order_id", orderID), zap.Error(err))
+		}
+	}()
+}
+
 // AddEventHandler adds a custom event handler
 func (o *Orchestrator) AddEventHandler(eventType string, handler EventHandler) {
 	o.mutex.Lock()
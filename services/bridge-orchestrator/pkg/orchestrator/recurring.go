@@ -0,0 +1,229 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+	"flowfusion/bridge-orchestrator/pkg/recurring"
+)
+
+// errNoChainHeight is returned when a run's timeout height cannot be set
+// because the source chain has not been polled yet
+var errNoChainHeight = errors.New("source chain height unknown")
+
+// RecurringCancelOutcome reports a cancelled recurring order and, when its
+// live runs were cancelled with it, their outcomes
+type RecurringCancelOutcome struct {
+	RecurringOrder *database.RecurringOrder `json:"recurring_order"`
+	Runs           []*CancelOutcome         `json:"runs,omitempty"`
+	RunErrors      map[string]string        `json:"run_errors,omitempty"`
+}
+
+// recurringScheduler spawns the runs of recurring orders as they fall due
+func (o *Orchestrator) recurringScheduler(ctx context.Context) {
+	defer o.wg.Done()
+
+	o.logger.Info("Starting recurring order scheduler")
+
+	ticker := time.NewTicker(o.config.Recurring.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.stopChan:
+			return
+		case <-ticker.C:
+			o.runDueRecurringOrders(time.Now())
+		}
+	}
+}
+
+// runDueRecurringOrders spawns a run for every recurring order that is due.
+// A run that cannot be spawned is retried on the next tick.
+func (o *Orchestrator) runDueRecurringOrders(now time.Time) {
+	due, err := o.db.GetDueRecurringOrders(now)
+	if err != nil {
+		o.logger.Error("Failed to get due recurring orders", zap.Error(err))
+		return
+	}
+
+	for _, series := range due {
+		if err := o.spawnRun(series, now); err != nil {
+			o.logger.Warn("Recurring run not spawned",
+				zap.String("recurring_order_id", series.ID),
+				zap.Error(err))
+		}
+	}
+}
+
+// spawnRun creates the series' next run and submits it to the source chain.
+// Runs missed while the orchestrator was down are not caught up: the next
+// run is scheduled after now.
+func (o *Orchestrator) spawnRun(series *database.RecurringOrder, now time.Time) error {
+	if series.Exhausted(now) {
+		series.Reschedule(now, nil)
+		return o.db.UpdateRecurringOrder(series)
+	}
+
+	price, err := o.twapEngine.GetCurrentPrice(fmt.Sprintf("%s_%s", series.SourceToken, series.TargetToken))
+	if err != nil {
+		return fmt.Errorf("no price for the run's minimum received: %w", err)
+	}
+
+	lifetime := time.Duration(series.WindowMinutes)*time.Minute + o.config.Recurring.TimeoutGrace
+	timeoutHeight, err := o.timeoutHeight(series.SourceChain, lifetime)
+	if err != nil {
+		return err
+	}
+
+	run, err := recurring.NewRun(series, recurring.RunParams{
+		Price:            price,
+		TimeoutHeight:    timeoutHeight,
+		TimeoutTimestamp: now.Add(lifetime).Unix(),
+	}, now)
+	if err != nil {
+		return err
+	}
+
+	next, err := recurring.NextRun(series.Schedule, now)
+	if err != nil {
+		return err
+	}
+	series.RecordRun(now, next)
+	if err := o.db.CreateRecurringRun(series, run); err != nil {
+		return fmt.Errorf("failed to record run: %w", err)
+	}
+
+	o.stats.mutex.Lock()
+	o.stats.TotalOrders++
+	o.stats.ActiveOrders++
+	o.stats.mutex.Unlock()
+
+	o.logger.Info("Recurring run spawned",
+		zap.String("recurring_order_id", series.ID),
+		zap.String("order_id", run.ID),
+		zap.Int("run", series.RunCount),
+		zap.String("min_received", run.MinReceived.String()),
+		zap.String("status", series.Status))
+
+	o.submitSourceOrder(run, nil)
+	return nil
+}
+
+// timeoutHeight estimates the source chain's height lifetime from now,
+// from its latest polled height and average block time
+func (o *Orchestrator) timeoutHeight(chainID string, lifetime time.Duration) (int64, error) {
+	o.healthMutex.RLock()
+	health, ok := o.chainHealth[chainID]
+	o.healthMutex.RUnlock()
+	if !ok || health.BlockHeight <= 0 || health.BlockTime <= 0 {
+		return 0, fmt.Errorf("%w: %s", errNoChainHeight, chainID)
+	}
+
+	blocks := int64(math.Ceil(float64(lifetime) / float64(health.BlockTime)))
+	return health.BlockHeight + blocks, nil
+}
+
+// CreateRecurringOrder validates the series' schedule and stores it, due at
+// its first scheduled run
+func (o *Orchestrator) CreateRecurringOrder(series *database.RecurringOrder) error {
+	now := time.Now()
+	next, err := recurring.NextRun(series.Schedule, now)
+	if err != nil {
+		return err
+	}
+	if next == nil || series.Exhausted(*next) {
+		return fmt.Errorf("%w: the schedule has no run before the series ends", recurring.ErrInvalidSchedule)
+	}
+
+	series.Status = string(database.RecurringStatusActive)
+	series.NextRunAt = next
+	series.CreatedAt = now
+	series.UpdatedAt = now
+	return o.db.CreateRecurringOrder(series)
+}
+
+// PauseRecurringOrder stops a series spawning runs. Runs already spawned
+// carry on.
+func (o *Orchestrator) PauseRecurringOrder(id string) (*database.RecurringOrder, error) {
+	series, err := o.db.GetRecurringOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := series.Pause(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := o.db.UpdateRecurringOrder(series); err != nil {
+		return nil, err
+	}
+
+	o.logger.Info("Recurring order paused", zap.String("recurring_order_id", id))
+	return series, nil
+}
+
+// ResumeRecurringOrder restarts a paused series at its next scheduled run.
+// Runs that fell due while it was paused are skipped.
+func (o *Orchestrator) ResumeRecurringOrder(id string) (*database.RecurringOrder, error) {
+	series, err := o.db.GetRecurringOrder(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	next, err := recurring.NextRun(series.Schedule, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := series.Resume(now, next); err != nil {
+		return nil, err
+	}
+	if err := o.db.UpdateRecurringOrder(series); err != nil {
+		return nil, err
+	}
+
+	o.logger.Info("Recurring order resumed",
+		zap.String("recurring_order_id", id),
+		zap.String("status", series.Status))
+	return series, nil
+}
+
+// CancelRecurringOrder ends a series. With cancelRuns set its live runs are
+// cancelled and refunded too; a run that fails to cancel is reported and
+// can be cancelled on its own.
+func (o *Orchestrator) CancelRecurringOrder(ctx context.Context, id string, cancelRuns bool) (*RecurringCancelOutcome, error) {
+	series, err := o.db.GetRecurringOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := series.Cancel(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := o.db.UpdateRecurringOrder(series); err != nil {
+		return nil, err
+	}
+
+	o.logger.Info("Recurring order cancelled",
+		zap.String("recurring_order_id", id),
+		zap.Bool("cancel_runs", cancelRuns))
+
+	outcome := &RecurringCancelOutcome{RecurringOrder: series}
+	if !cancelRuns {
+		return outcome, nil
+	}
+
+	runs, err := o.db.GetOrdersByParent(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runs: %w", err)
+	}
+	outcome.Runs, outcome.RunErrors = o.cancelLiveOrders(ctx, runs, "recurring order cancelled")
+
+	return outcome, nil
+}
@@ -0,0 +1,65 @@
+package recurring
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+	"flowfusion/bridge-orchestrator/pkg/twap"
+)
+
+// Report aggregates a recurring order's runs
+type Report struct {
+	Runs            int             `json:"runs"`
+	ActiveRuns      int             `json:"active_runs"`
+	CompletedRuns   int             `json:"completed_runs"`
+	UnfilledRuns    int             `json:"unfilled_runs"` // ended without filling in full
+	CommittedAmount decimal.Decimal `json:"committed_amount"`
+	ExecutedAmount  decimal.Decimal `json:"executed_amount"`
+	ReceivedAmount  decimal.Decimal `json:"received_amount"` // in target token base units
+	RefundedAmount  decimal.Decimal `json:"refunded_amount"`
+	AveragePrice    decimal.Decimal `json:"average_price"` // weighted by executed amount
+	RemainingBudget decimal.Decimal `json:"remaining_budget"`
+	NextRunAt       *time.Time      `json:"next_run_at,omitempty"`
+}
+
+// Summarize reports on the runs a recurring order has spawned
+func Summarize(series *database.RecurringOrder, runs []*database.Order) Report {
+	report := Report{
+		Runs:            len(runs),
+		CommittedAmount: decimal.Zero,
+		ExecutedAmount:  decimal.Zero,
+		ReceivedAmount:  decimal.Zero,
+		RefundedAmount:  decimal.Zero,
+		AveragePrice:    decimal.Zero,
+		RemainingBudget: series.RemainingBudget(),
+		NextRunAt:       series.NextRunAt,
+	}
+
+	// Runs' executed amounts at their average prices, for the overall
+	// average price
+	priced := decimal.Zero
+	for _, run := range runs {
+		switch database.OrderStatus(run.Status) {
+		case database.OrderStatusCompleted, database.OrderStatusClaimed:
+			report.CompletedRuns++
+		case database.OrderStatusPartiallyFilled, database.OrderStatusCancelled,
+			database.OrderStatusExpired, database.OrderStatusRefunded:
+			report.UnfilledRuns++
+		default:
+			report.ActiveRuns++
+		}
+
+		report.CommittedAmount = report.CommittedAmount.Add(run.SourceAmount)
+		report.ExecutedAmount = report.ExecutedAmount.Add(run.ExecutedAmount)
+		report.ReceivedAmount = report.ReceivedAmount.Add(twap.ReceivedValue(run))
+		priced = priced.Add(run.GetReceivedAmount())
+		report.RefundedAmount = report.RefundedAmount.Add(run.RefundedAmount)
+	}
+
+	if report.ExecutedAmount.IsPositive() {
+		report.AveragePrice = priced.Div(report.ExecutedAmount)
+	}
+	return report
+}
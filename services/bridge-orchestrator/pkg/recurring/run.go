@@ -0,0 +1,68 @@
+package recurring
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+	"flowfusion/bridge-orchestrator/pkg/twap"
+)
+
+// ErrNoMinReceived is returned when a run's minimum output rounds to zero
+var ErrNoMinReceived = errors.New("run's minimum received rounds to zero")
+
+// RunID is the order ID of a recurring order's nth run
+func RunID(seriesID string, run int) string {
+	return fmt.Sprintf("%s-%d", seriesID, run)
+}
+
+// RunParams are what a run needs beyond the series' terms: the pair's price
+// now, which sets the run's MinReceived after slippage, and its timeouts
+type RunParams struct {
+	Price            decimal.Decimal
+	TimeoutHeight    int64
+	TimeoutTimestamp int64
+}
+
+// NewRun builds the order for the series' next run. Its MinReceived is
+// the run's amount valued at params.Price, in target token base units, less
+// the series' slippage.
+func NewRun(series *database.RecurringOrder, params RunParams, now time.Time) (*database.Order, error) {
+	run := series.RunCount + 1
+	parentID := series.ID
+	order := &database.Order{
+		ID:                  RunID(series.ID, run),
+		UserAddress:         series.UserAddress,
+		SourceChain:         series.SourceChain,
+		TargetChain:         series.TargetChain,
+		SourceToken:         series.SourceToken,
+		SourceAmount:        series.AmountPerRun,
+		TargetToken:         series.TargetToken,
+		TargetRecipient:     series.TargetRecipient,
+		WindowMinutes:       series.WindowMinutes,
+		ExecutionIntervals:  series.ExecutionIntervals,
+		MaxSlippage:         series.MaxSlippage,
+		MinFillSize:         series.MinFillSize,
+		EnableMEVProtection: series.EnableMEVProtection,
+		HTLCHash:            series.HTLCHash,
+		TimeoutHeight:       params.TimeoutHeight,
+		TimeoutTimestamp:    params.TimeoutTimestamp,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+		Status:              string(database.OrderStatusPending),
+		ExecutedAmount:      decimal.Zero,
+		AveragePrice:        decimal.Zero,
+		Metadata:            database.Metadata{"recurring_order_id": series.ID, "run": run},
+		ParentID:            &parentID,
+	}
+
+	slippage := decimal.NewFromInt(int64(10000 - series.MaxSlippage)).Div(decimal.NewFromInt(10000))
+	order.MinReceived = twap.TargetValue(order, series.AmountPerRun, params.Price).Mul(slippage).Truncate(0)
+	if !order.MinReceived.IsPositive() {
+		return nil, ErrNoMinReceived
+	}
+	return order, nil
+}
@@ -0,0 +1,112 @@
+package recurring
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+func testSeries() *database.RecurringOrder {
+	maxRuns := 3
+	return &database.RecurringOrder{
+		ID:           "dca-1",
+		SourceToken:  "ETH",
+		TargetToken:  "USDC",
+		Schedule:     "@daily",
+		AmountPerRun: decimal.NewFromInt(1000),
+		MaxSlippage:  100,
+		Budget:       decimal.NewFromInt(2500),
+		MaxRuns:      &maxRuns,
+		SpentAmount:  decimal.Zero,
+		Status:       string(database.RecurringStatusActive),
+	}
+}
+
+func TestNewRun(t *testing.T) {
+	series := testSeries()
+	now := time.Now()
+
+	// 1 ETH a run, which has 18 decimals to USDC's 6
+	series.AmountPerRun = decimal.NewFromInt(1).Shift(18)
+	run, err := NewRun(series, RunParams{Price: decimal.NewFromInt(2500), TimeoutHeight: 100}, now)
+	if err != nil {
+		t.Fatalf("NewRun failed: %v", err)
+	}
+	if run.ID != "dca-1-1" || run.ParentID == nil || *run.ParentID != series.ID {
+		t.Fatalf("run not linked to its series: id %s, parent %v", run.ID, run.ParentID)
+	}
+	// 1 ETH at 2500 less 1% slippage, in USDC base units
+	if !run.SourceAmount.Equal(series.AmountPerRun) || !run.MinReceived.Equal(decimal.NewFromInt(2475).Shift(6)) {
+		t.Fatalf("unexpected amounts: source %s, min received %s", run.SourceAmount, run.MinReceived)
+	}
+
+	// $500 of ATOM a run, both with 6 decimals
+	series.SourceToken, series.TargetToken = "USDC", "ATOM"
+	series.AmountPerRun = decimal.NewFromInt(500).Shift(6)
+	run, err = NewRun(series, RunParams{Price: decimal.RequireFromString("0.1")}, now)
+	if err != nil || !run.MinReceived.Equal(decimal.RequireFromString("49.5").Shift(6)) {
+		t.Fatalf("min received %v, want 49.5 ATOM in base units: %v", run, err)
+	}
+
+	if _, err := NewRun(series, RunParams{Price: decimal.RequireFromString("0.000000001")}, now); !errors.Is(err, ErrNoMinReceived) {
+		t.Fatalf("expected ErrNoMinReceived, got %v", err)
+	}
+}
+
+func TestSeriesCompletesOnBudget(t *testing.T) {
+	series := testSeries()
+	now := time.Now()
+
+	next := now.Add(24 * time.Hour)
+	series.RecordRun(now, &next)
+	if series.Status != string(database.RecurringStatusActive) || series.NextRunAt == nil {
+		t.Fatalf("series should stay active with budget for another run")
+	}
+
+	// The second run leaves 500, less than a run
+	series.RecordRun(next, &next)
+	if series.Status != string(database.RecurringStatusCompleted) || series.NextRunAt != nil {
+		t.Fatalf("series should complete once the budget cannot fund a run, got %s", series.Status)
+	}
+	if !series.RemainingBudget().Equal(decimal.NewFromInt(500)) || series.RunCount != 2 {
+		t.Fatalf("unexpected bookkeeping: %d runs, %s left", series.RunCount, series.RemainingBudget())
+	}
+
+	if err := series.Pause(now); !errors.Is(err, database.ErrInvalidTransition) {
+		t.Fatalf("completed series should not pause, got %v", err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	series := testSeries()
+	series.SpentAmount = decimal.NewFromInt(2000)
+
+	completed := &database.Order{
+		Status:         string(database.OrderStatusCompleted),
+		SourceAmount:   decimal.NewFromInt(1000),
+		ExecutedAmount: decimal.NewFromInt(1000),
+		AveragePrice:   decimal.NewFromInt(2),
+	}
+	cancelled := &database.Order{
+		Status:         string(database.OrderStatusCancelled),
+		SourceAmount:   decimal.NewFromInt(1000),
+		ExecutedAmount: decimal.NewFromInt(500),
+		AveragePrice:   decimal.NewFromInt(4),
+		RefundedAmount: decimal.NewFromInt(500),
+	}
+
+	report := Summarize(series, []*database.Order{completed, cancelled})
+	if report.Runs != 2 || report.CompletedRuns != 1 || report.UnfilledRuns != 1 || report.ActiveRuns != 0 {
+		t.Fatalf("unexpected run counts: %+v", report)
+	}
+	if !report.ExecutedAmount.Equal(decimal.NewFromInt(1500)) || !report.RefundedAmount.Equal(decimal.NewFromInt(500)) {
+		t.Fatalf("unexpected amounts: executed %s, refunded %s", report.ExecutedAmount, report.RefundedAmount)
+	}
+	if !report.RemainingBudget.Equal(decimal.NewFromInt(500)) {
+		t.Fatalf("expected 500 budget left, got %s", report.RemainingBudget)
+	}
+}
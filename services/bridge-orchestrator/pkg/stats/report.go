@@ -0,0 +1,283 @@
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+// PairVolume is the volume executed in one pair. Volume is in source token
+// base units and Received in target token base units, so volumes are only
+// ever summed within a pair.
+type PairVolume struct {
+	TokenPair    string           `json:"token_pair"`
+	Executions   int64            `json:"executions"`
+	Volume       decimal.Decimal  `json:"volume"`
+	Received     decimal.Decimal  `json:"received"`
+	AveragePrice *decimal.Decimal `json:"average_price,omitempty"`
+	GasCostQuote decimal.Decimal  `json:"gas_cost_quote"`
+}
+
+// VolumeBucket is the volume executed in one time bucket
+type VolumeBucket struct {
+	Start      time.Time     `json:"start"`
+	Executions int64         `json:"executions"`
+	Pairs      []*PairVolume `json:"pairs"`
+}
+
+// ChainVolume is the volume executed on one chain
+type ChainVolume struct {
+	ChainID    string        `json:"chain_id"`
+	Executions int64         `json:"executions"`
+	Pairs      []*PairVolume `json:"pairs"`
+}
+
+// VolumeReport is the volume executed since a time, by time bucket, chain
+// and pair
+type VolumeReport struct {
+	Bucket     database.StatsBucket `json:"bucket"`
+	Since      time.Time            `json:"since"`
+	Executions int64                `json:"executions"`
+	Buckets    []*VolumeBucket      `json:"buckets"`
+	ByChain    []*ChainVolume       `json:"by_chain"`
+	ByPair     []*PairVolume        `json:"by_pair"`
+}
+
+// SummarizeVolume builds a volume report from execution rollups
+func SummarizeVolume(bucket database.StatsBucket, since time.Time, rollups []*database.ExecutionRollup) *VolumeReport {
+	report := &VolumeReport{
+		Bucket:  bucket,
+		Since:   since,
+		Buckets: []*VolumeBucket{},
+		ByChain: []*ChainVolume{},
+	}
+
+	buckets := make(map[time.Time]*pairVolumes)
+	chains := make(map[string]*pairVolumes)
+	pairs := newPairVolumes()
+	var bucketStarts []time.Time
+	var chainIDs []string
+
+	for _, rollup := range rollups {
+		report.Executions += rollup.Executions
+
+		start := rollup.Bucket.UTC()
+		if _, ok := buckets[start]; !ok {
+			buckets[start] = newPairVolumes()
+			bucketStarts = append(bucketStarts, start)
+		}
+		if _, ok := chains[rollup.ChainID]; !ok {
+			chains[rollup.ChainID] = newPairVolumes()
+			chainIDs = append(chainIDs, rollup.ChainID)
+		}
+
+		buckets[start].add(rollup)
+		chains[rollup.ChainID].add(rollup)
+		pairs.add(rollup)
+	}
+
+	sort.Slice(bucketStarts, func(i, j int) bool { return bucketStarts[i].Before(bucketStarts[j]) })
+	for _, start := range bucketStarts {
+		volumes := buckets[start]
+		report.Buckets = append(report.Buckets, &VolumeBucket{
+			Start:      start,
+			Executions: volumes.executions,
+			Pairs:      volumes.result(),
+		})
+	}
+
+	sort.Strings(chainIDs)
+	for _, chainID := range chainIDs {
+		volumes := chains[chainID]
+		report.ByChain = append(report.ByChain, &ChainVolume{
+			ChainID:    chainID,
+			Executions: volumes.executions,
+			Pairs:      volumes.result(),
+		})
+	}
+
+	report.ByPair = pairs.result()
+	return report
+}
+
+// pairVolumes sums rollups by pair
+type pairVolumes struct {
+	executions int64
+	byPair     map[string]*PairVolume
+}
+
+func newPairVolumes() *pairVolumes {
+	return &pairVolumes{byPair: make(map[string]*PairVolume)}
+}
+
+func (p *pairVolumes) add(rollup *database.ExecutionRollup) {
+	p.executions += rollup.Executions
+
+	volume, ok := p.byPair[rollup.TokenPair]
+	if !ok {
+		volume = &PairVolume{TokenPair: rollup.TokenPair}
+		p.byPair[rollup.TokenPair] = volume
+	}
+	volume.Executions += rollup.Executions
+	volume.Volume = volume.Volume.Add(rollup.Volume)
+	volume.Received = volume.Received.Add(rollup.Received)
+	volume.GasCostQuote = volume.GasCostQuote.Add(rollup.GasCostQuote)
+}
+
+// result returns the pairs sorted by name with their average prices
+func (p *pairVolumes) result() []*PairVolume {
+	result := make([]*PairVolume, 0, len(p.byPair))
+	for _, volume := range p.byPair {
+		if volume.Volume.IsPositive() {
+			price := volume.Received.Div(volume.Volume)
+			volume.AveragePrice = &price
+		}
+		result = append(result, volume)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].TokenPair < result[j].TokenPair })
+	return result
+}
+
+// OrderOutcomes counts orders by how they ended. Orders that filled in full
+// succeeded; those that ran out of intervals below MinReceived or expired
+// failed. Cancellations are the user's choice and count as neither, so
+// refunds, which mostly follow them, are counted with them.
+type OrderOutcomes struct {
+	Total       int64    `json:"total"`
+	Active      int64    `json:"active"`
+	Succeeded   int64    `json:"succeeded"`
+	Failed      int64    `json:"failed"`
+	Cancelled   int64    `json:"cancelled"`
+	SuccessRate *float64 `json:"success_rate,omitempty"` // percent of succeeded and failed orders
+}
+
+func (o *OrderOutcomes) add(status string, orders int64) {
+	o.Total += orders
+	switch database.OrderStatus(status) {
+	case database.OrderStatusCompleted, database.OrderStatusClaimed:
+		o.Succeeded += orders
+	case database.OrderStatusPartiallyFilled, database.OrderStatusExpired:
+		o.Failed += orders
+	case database.OrderStatusCancelling, database.OrderStatusCancelled, database.OrderStatusRefunded:
+		o.Cancelled += orders
+	default:
+		o.Active += orders
+	}
+}
+
+func (o *OrderOutcomes) finish() {
+	if finished := o.Succeeded + o.Failed; finished > 0 {
+		rate := float64(o.Succeeded) / float64(finished) * 100
+		o.SuccessRate = &rate
+	}
+}
+
+// ChainPerformance is the performance of the orders and executions
+// targeting one chain
+type ChainPerformance struct {
+	ChainID            string           `json:"chain_id"`
+	Orders             OrderOutcomes    `json:"orders"`
+	Executions         int64            `json:"executions"`
+	AverageSlippageBps *decimal.Decimal `json:"average_slippage_bps,omitempty"`
+}
+
+// PerformanceReport is how the orders created since a time have fared and
+// how the intervals executed since then slipped. Latencies are averages in
+// seconds from an order's activation to its first fill and, for completed
+// orders, to its last.
+type PerformanceReport struct {
+	Since                 time.Time           `json:"since"`
+	Orders                OrderOutcomes       `json:"orders"`
+	Executions            int64               `json:"executions"`
+	AverageSlippageBps    *decimal.Decimal    `json:"average_slippage_bps,omitempty"`
+	AverageFillLatency    *float64            `json:"average_fill_latency_seconds,omitempty"`
+	AverageCompletionTime *float64            `json:"average_completion_time_seconds,omitempty"`
+	ByChain               []*ChainPerformance `json:"by_chain"`
+}
+
+// SummarizePerformance builds a performance report from order and
+// execution rollups. Orders are attributed to their target chain, where
+// their intervals execute.
+func SummarizePerformance(since time.Time, orders []*database.OrderRollup, executions []*database.ExecutionRollup) *PerformanceReport {
+	report := &PerformanceReport{Since: since, ByChain: []*ChainPerformance{}}
+
+	chains := make(map[string]*chainAccumulator)
+	chain := func(chainID string) *chainAccumulator {
+		acc, ok := chains[chainID]
+		if !ok {
+			acc = &chainAccumulator{ChainPerformance: ChainPerformance{ChainID: chainID}}
+			chains[chainID] = acc
+		}
+		return acc
+	}
+
+	var filled, completed int64
+	var fillLatency, completionTime float64
+	for _, rollup := range orders {
+		report.Orders.add(rollup.Status, rollup.Orders)
+		chain(rollup.TargetChain).Orders.add(rollup.Status, rollup.Orders)
+
+		filled += rollup.FilledOrders
+		fillLatency += rollup.FillLatencySeconds
+		completed += rollup.CompletedOrders
+		completionTime += rollup.CompletionTimeSeconds
+	}
+
+	var slippage slippageAverage
+	for _, rollup := range executions {
+		report.Executions += rollup.Executions
+		slippage.add(rollup)
+
+		acc := chain(rollup.ChainID)
+		acc.Executions += rollup.Executions
+		acc.slippage.add(rollup)
+	}
+
+	report.Orders.finish()
+	report.AverageSlippageBps = slippage.value()
+	if filled > 0 {
+		average := fillLatency / float64(filled)
+		report.AverageFillLatency = &average
+	}
+	if completed > 0 {
+		average := completionTime / float64(completed)
+		report.AverageCompletionTime = &average
+	}
+
+	for _, acc := range chains {
+		acc.Orders.finish()
+		acc.AverageSlippageBps = acc.slippage.value()
+		performance := acc.ChainPerformance
+		report.ByChain = append(report.ByChain, &performance)
+	}
+	sort.Slice(report.ByChain, func(i, j int) bool { return report.ByChain[i].ChainID < report.ByChain[j].ChainID })
+
+	return report
+}
+
+// chainAccumulator sums one chain's performance
+type chainAccumulator struct {
+	ChainPerformance
+	slippage slippageAverage
+}
+
+// slippageAverage averages the slippage of the executions that recorded it
+type slippageAverage struct {
+	sum, count int64
+}
+
+func (s *slippageAverage) add(rollup *database.ExecutionRollup) {
+	s.sum += rollup.SlippageSum
+	s.count += rollup.SlippageCount
+}
+
+func (s *slippageAverage) value() *decimal.Decimal {
+	if s.count == 0 {
+		return nil
+	}
+	average := decimal.NewFromInt(s.sum).Div(decimal.NewFromInt(s.count)).Round(2)
+	return &average
+}
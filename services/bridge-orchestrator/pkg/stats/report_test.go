@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+func TestSummarizeVolume(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rollups := []*database.ExecutionRollup{
+		{Bucket: day.AddDate(0, 0, 1), ChainID: "ethereum", TokenPair: "WETH_USDC", Executions: 2,
+			Volume: decimal.NewFromInt(200), Received: decimal.NewFromInt(400), GasCostQuote: decimal.NewFromInt(3)},
+		{Bucket: day, ChainID: "ethereum", TokenPair: "WETH_USDC", Executions: 1,
+			Volume: decimal.NewFromInt(100), Received: decimal.NewFromInt(230), GasCostQuote: decimal.NewFromInt(1)},
+		{Bucket: day, ChainID: "cosmos", TokenPair: "ATOM_USDC", Executions: 4,
+			Volume: decimal.NewFromInt(40), Received: decimal.NewFromInt(400)},
+	}
+
+	report := SummarizeVolume(database.StatsBucketDay, day, rollups)
+	if report.Executions != 7 || len(report.Buckets) != 2 || len(report.ByChain) != 2 || len(report.ByPair) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if !report.Buckets[0].Start.Equal(day) || report.Buckets[0].Executions != 5 || len(report.Buckets[0].Pairs) != 2 {
+		t.Fatalf("buckets should be in order: %+v", report.Buckets[0])
+	}
+	if report.ByChain[0].ChainID != "cosmos" || report.ByChain[1].Executions != 3 {
+		t.Fatalf("unexpected chains: %+v %+v", report.ByChain[0], report.ByChain[1])
+	}
+
+	weth := report.ByPair[1]
+	if weth.TokenPair != "WETH_USDC" || !weth.Volume.Equal(decimal.NewFromInt(300)) ||
+		!weth.GasCostQuote.Equal(decimal.NewFromInt(4)) {
+		t.Fatalf("unexpected pair volume: %+v", weth)
+	}
+	if weth.AveragePrice == nil || !weth.AveragePrice.Equal(decimal.RequireFromString("2.1")) {
+		t.Fatalf("average price = %v, want 2.1", weth.AveragePrice)
+	}
+
+	empty := SummarizeVolume(database.StatsBucketDay, day, nil)
+	if empty.Buckets == nil || empty.ByChain == nil || len(empty.ByPair) != 0 {
+		t.Fatalf("an empty report should have empty lists: %+v", empty)
+	}
+}
+
+func TestSummarizePerformance(t *testing.T) {
+	orders := []*database.OrderRollup{
+		{TargetChain: "ethereum", Status: string(database.OrderStatusCompleted), Orders: 3,
+			FilledOrders: 3, FillLatencySeconds: 30, CompletedOrders: 3, CompletionTimeSeconds: 3600},
+		{TargetChain: "ethereum", Status: string(database.OrderStatusClaimed), Orders: 1,
+			FilledOrders: 1, FillLatencySeconds: 10, CompletedOrders: 1, CompletionTimeSeconds: 1200},
+		{TargetChain: "cosmos", Status: string(database.OrderStatusExpired), Orders: 1,
+			FilledOrders: 1, FillLatencySeconds: 60},
+		{TargetChain: "cosmos", Status: string(database.OrderStatusCancelled), Orders: 2},
+		{TargetChain: "cosmos", Status: string(database.OrderStatusExecuting), Orders: 1},
+	}
+	executions := []*database.ExecutionRollup{
+		{ChainID: "ethereum", Executions: 10, SlippageSum: 150, SlippageCount: 10},
+		{ChainID: "cosmos", Executions: 3, SlippageSum: 40, SlippageCount: 2},
+	}
+
+	report := SummarizePerformance(time.Time{}, orders, executions)
+
+	outcomes := report.Orders
+	if outcomes.Total != 8 || outcomes.Succeeded != 4 || outcomes.Failed != 1 ||
+		outcomes.Cancelled != 2 || outcomes.Active != 1 {
+		t.Fatalf("unexpected outcomes: %+v", outcomes)
+	}
+	// Cancellations count as neither success nor failure
+	if outcomes.SuccessRate == nil || *outcomes.SuccessRate != 80 {
+		t.Fatalf("success rate = %v, want 80", outcomes.SuccessRate)
+	}
+	if report.Executions != 13 || report.AverageSlippageBps == nil ||
+		!report.AverageSlippageBps.Equal(decimal.RequireFromString("15.83")) {
+		t.Fatalf("average slippage = %v, want 15.83", report.AverageSlippageBps)
+	}
+	if report.AverageFillLatency == nil || *report.AverageFillLatency != 20 {
+		t.Fatalf("average fill latency = %v, want 20", report.AverageFillLatency)
+	}
+	if report.AverageCompletionTime == nil || *report.AverageCompletionTime != 1200 {
+		t.Fatalf("average completion time = %v, want 1200", report.AverageCompletionTime)
+	}
+
+	if len(report.ByChain) != 2 {
+		t.Fatalf("expected two chains, got %d", len(report.ByChain))
+	}
+	cosmos := report.ByChain[0]
+	if cosmos.ChainID != "cosmos" || cosmos.Orders.Total != 4 || cosmos.Executions != 3 {
+		t.Fatalf("unexpected chain performance: %+v", cosmos)
+	}
+	if cosmos.Orders.SuccessRate == nil || *cosmos.Orders.SuccessRate != 0 {
+		t.Fatalf("cosmos success rate = %v, want 0", cosmos.Orders.SuccessRate)
+	}
+	if cosmos.AverageSlippageBps == nil || !cosmos.AverageSlippageBps.Equal(decimal.NewFromInt(20)) {
+		t.Fatalf("cosmos slippage = %v, want 20", cosmos.AverageSlippageBps)
+	}
+
+	empty := SummarizePerformance(time.Time{}, nil, nil)
+	if empty.Orders.SuccessRate != nil || empty.AverageSlippageBps != nil || empty.AverageFillLatency != nil {
+		t.Fatalf("an empty report should leave averages unset: %+v", empty)
+	}
+}
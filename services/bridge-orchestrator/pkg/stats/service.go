@@ -0,0 +1,134 @@
+package stats
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+// Service computes volume and performance statistics from rollups of the
+// orders and execution history, which it refreshes in the background
+type Service struct {
+	config config.StatsConfig
+	db     database.DB
+	logger *zap.Logger
+
+	refreshedAt time.Time
+	mutex       sync.RWMutex
+}
+
+// Overview summarizes every order and execution
+type Overview struct {
+	Orders             OrderOutcomes    `json:"orders"`
+	Executions         int64            `json:"executions"`
+	AverageSlippageBps *decimal.Decimal `json:"average_slippage_bps,omitempty"`
+	VolumeByPair       []*PairVolume    `json:"volume_by_pair"`
+	RefreshedAt        *time.Time       `json:"refreshed_at,omitempty"`
+}
+
+// NewService creates a stats service
+func NewService(cfg config.StatsConfig, db database.DB, logger *zap.Logger) *Service {
+	return &Service{
+		config: cfg,
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Start refreshes the rollups every RefreshInterval until ctx is done
+func (s *Service) Start(ctx context.Context) error {
+	s.logger.Info("Starting stats service", zap.Duration("refresh_interval", s.config.RefreshInterval))
+
+	s.refresh()
+
+	ticker := time.NewTicker(s.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			s.refresh()
+		}
+	}
+}
+
+// refresh recomputes the rollups, logging failures so the next tick retries
+func (s *Service) refresh() {
+	start := time.Now()
+	if err := s.db.RefreshStatsRollups(); err != nil {
+		s.logger.Error("Failed to refresh stats rollups", zap.Error(err))
+		return
+	}
+
+	s.mutex.Lock()
+	s.refreshedAt = start
+	s.mutex.Unlock()
+
+	s.logger.Debug("Stats rollups refreshed", zap.Duration("duration", time.Since(start)))
+}
+
+// RefreshedAt returns when the rollups were last refreshed by this
+// process, or nil before the first refresh
+func (s *Service) RefreshedAt() *time.Time {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.refreshedAt.IsZero() {
+		return nil
+	}
+	refreshedAt := s.refreshedAt
+	return &refreshedAt
+}
+
+// Volume reports the volume executed since a time in buckets of the given
+// width
+func (s *Service) Volume(bucket database.StatsBucket, since time.Time) (*VolumeReport, error) {
+	rollups, err := s.db.GetExecutionRollups(bucket, since)
+	if err != nil {
+		return nil, err
+	}
+	return SummarizeVolume(bucket, since, rollups), nil
+}
+
+// Performance reports on the orders created and intervals executed since
+// a time
+func (s *Service) Performance(since time.Time) (*PerformanceReport, error) {
+	orders, err := s.db.GetOrderRollups(database.StatsBucketMonth, since)
+	if err != nil {
+		return nil, err
+	}
+	executions, err := s.db.GetExecutionRollups(database.StatsBucketMonth, since)
+	if err != nil {
+		return nil, err
+	}
+	return SummarizePerformance(since, orders, executions), nil
+}
+
+// Overview summarizes every order and execution
+func (s *Service) Overview() (*Overview, error) {
+	orders, err := s.db.GetOrderRollups(database.StatsBucketMonth, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	executions, err := s.db.GetExecutionRollups(database.StatsBucketMonth, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	performance := SummarizePerformance(time.Time{}, orders, executions)
+	return &Overview{
+		Orders:             performance.Orders,
+		Executions:         performance.Executions,
+		AverageSlippageBps: performance.AverageSlippageBps,
+		VolumeByPair:       SummarizeVolume(database.StatsBucketMonth, time.Time{}, executions).ByPair,
+		RefreshedAt:        s.RefreshedAt(),
+	}, nil
+}
@@ -0,0 +1,247 @@
+package twap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+	"flowfusion/bridge-orchestrator/pkg/adapters"
+)
+
+// Amendment limits, matching the bridge contract's TWAP config validation
+const (
+	minWindowMinutes      = 5
+	maxWindowMinutes      = 1440
+	minExecutionIntervals = 2
+	maxExecutionIntervals = 20
+	maxSlippageBps        = 1000
+)
+
+// Amendment errors
+var (
+	ErrInvalidAmendment  = errors.New("invalid order amendment")
+	ErrOrderNotAmendable = errors.New("order cannot be amended in its current status")
+	ErrOrderBusy         = errors.New("order is being amended")
+)
+
+// Amendment is a requested change to a live order. Nil fields are left
+// unchanged. SourceAmount may only shrink and WindowMinutes only grow;
+// shrinking SourceAmount scales MinReceived with it.
+type Amendment struct {
+	SourceAmount       *decimal.Decimal
+	WindowMinutes      *int
+	ExecutionIntervals *int
+	MaxSlippage        *int
+	Actor              string
+	Reason             string
+}
+
+// Schedule is what remains of an order's execution plan
+type Schedule struct {
+	RemainingAmount    decimal.Decimal `json:"remaining_amount"`
+	RemainingIntervals int             `json:"remaining_intervals"`
+	IntervalAmount     decimal.Decimal `json:"interval_amount"`
+	IntervalMinutes    int             `json:"interval_minutes"`
+	NextExecution      time.Time       `json:"next_execution"`
+	WindowEnd          time.Time       `json:"window_end"`
+}
+
+// AmendmentResult is an applied amendment and the order's new schedule
+type AmendmentResult struct {
+	Order     *database.Order          `json:"order"`
+	Amendment *database.OrderAmendment `json:"amendment"`
+	Schedule  Schedule                 `json:"schedule"`
+}
+
+// AmendOrder applies an amendment to a live order. New intervals are held
+// and any interval in flight settles first, so the amendment is validated
+// against the order's latest fills; the amended terms are then written to
+// the source chain and the amendment recorded.
+func (e *Engine) AmendOrder(ctx context.Context, orderID string, amendment Amendment) (*AmendmentResult, error) {
+	release, err := e.holdOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := e.SettleInterval(ctx, orderID); err != nil {
+		return nil, fmt.Errorf("in-flight interval did not settle: %w", err)
+	}
+
+	order, err := e.db.GetOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := e.db.GetExecutionHistory(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get execution history: %w", err)
+	}
+
+	now := time.Now()
+	previousAmount := order.SourceAmount
+	changes, err := ApplyAmendment(order, len(history), amendment, now)
+	if err != nil {
+		return nil, err
+	}
+	refund := previousAmount.Sub(order.SourceAmount)
+
+	adapter, err := e.adapterManager.GetAdapter(order.SourceChain)
+	if err != nil {
+		return nil, err
+	}
+	result, err := adapter.AmendOrder(adapters.AmendOrderParams{
+		OrderID:             order.ID,
+		ChainOrderID:        order.GetChainOrderID(),
+		UserAddress:         order.UserAddress,
+		SourceAmount:        order.SourceAmount,
+		RefundAmount:        refund,
+		WindowMinutes:       order.WindowMinutes,
+		Intervals:           order.ExecutionIntervals,
+		MaxSlippage:         order.MaxSlippage,
+		MinFillSize:         order.MinFillSize,
+		EnableMEVProtection: order.EnableMEVProtection,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to amend order on %s: %w", order.SourceChain, err)
+	}
+
+	record := &database.OrderAmendment{
+		OrderID:      order.ID,
+		Changes:      changes,
+		Actor:        amendment.Actor,
+		Reason:       amendment.Reason,
+		TxHash:       &result.TxHash,
+		RefundAmount: result.RefundAmount,
+		CreatedAt:    now,
+	}
+	order.RefundedAmount = order.RefundedAmount.Add(result.RefundAmount)
+	if err := e.db.AmendOrder(order, record); err != nil {
+		// The chain already has the new terms; the next amendment or a
+		// manual fix must bring the database in line
+		e.logger.Error("Order amended on-chain but not recorded",
+			zap.String("order_id", order.ID),
+			zap.String("tx_hash", result.TxHash),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to record amendment: %w", err)
+	}
+
+	e.logger.Info("Order amended",
+		zap.String("order_id", order.ID),
+		zap.Any("changes", changes),
+		zap.String("refund_amount", result.RefundAmount.String()),
+		zap.String("tx_hash", result.TxHash))
+
+	return &AmendmentResult{
+		Order:     order,
+		Amendment: record,
+		Schedule:  RemainingSchedule(order, len(history), now),
+	}, nil
+}
+
+// ApplyAmendment validates an amendment against an order that has run
+// executedIntervals intervals and applies it, returning the changed fields
+func ApplyAmendment(order *database.Order, executedIntervals int, amendment Amendment, now time.Time) (database.Metadata, error) {
+	if !order.IsExecutable() && !order.IsPaused() {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotAmendable, order.Status)
+	}
+
+	changes := database.Metadata{}
+	change := func(field string, from, to interface{}) {
+		changes[field] = map[string]interface{}{"from": from, "to": to}
+	}
+
+	if a := amendment.SourceAmount; a != nil && !a.Equal(order.SourceAmount) {
+		switch {
+		case !a.Equal(a.Truncate(0)):
+			return nil, fmt.Errorf("%w: source amount must be an integer in base units", ErrInvalidAmendment)
+		case a.GreaterThan(order.SourceAmount):
+			return nil, fmt.Errorf("%w: source amount can only be reduced", ErrInvalidAmendment)
+		case a.LessThanOrEqual(order.ExecutedAmount):
+			return nil, fmt.Errorf("%w: source amount must exceed the %s already executed", ErrInvalidAmendment, order.ExecutedAmount.String())
+		}
+		change("source_amount", order.SourceAmount.String(), a.String())
+		if order.MinReceived.IsPositive() {
+			// Keep the order's floor price; rounding up never lets the
+			// smaller order accept a worse one
+			minReceived := order.MinReceived.Mul(*a).Div(order.SourceAmount).Ceil()
+			change("min_received", order.MinReceived.String(), minReceived.String())
+			order.MinReceived = minReceived
+		}
+		order.SourceAmount = *a
+	}
+
+	if w := amendment.WindowMinutes; w != nil && *w != order.WindowMinutes {
+		switch {
+		case *w < order.WindowMinutes:
+			return nil, fmt.Errorf("%w: window can only be extended", ErrInvalidAmendment)
+		case *w > maxWindowMinutes:
+			return nil, fmt.Errorf("%w: window minutes must be between %d and %d", ErrInvalidAmendment, minWindowMinutes, maxWindowMinutes)
+		}
+		change("window_minutes", order.WindowMinutes, *w)
+		order.WindowMinutes = *w
+	}
+
+	if n := amendment.ExecutionIntervals; n != nil && *n != order.ExecutionIntervals {
+		switch {
+		case *n < minExecutionIntervals || *n > maxExecutionIntervals:
+			return nil, fmt.Errorf("%w: execution intervals must be between %d and %d", ErrInvalidAmendment, minExecutionIntervals, maxExecutionIntervals)
+		case *n <= executedIntervals+order.SkippedIntervals:
+			return nil, fmt.Errorf("%w: %d intervals have already run", ErrInvalidAmendment, executedIntervals+order.SkippedIntervals)
+		}
+		change("execution_intervals", order.ExecutionIntervals, *n)
+		order.ExecutionIntervals = *n
+	}
+
+	if s := amendment.MaxSlippage; s != nil && *s != order.MaxSlippage {
+		if *s < 1 || *s > maxSlippageBps {
+			return nil, fmt.Errorf("%w: max slippage must be between 1 and %d basis points", ErrInvalidAmendment, maxSlippageBps)
+		}
+		change("max_slippage", order.MaxSlippage, *s)
+		order.MaxSlippage = *s
+	}
+
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("%w: nothing to change", ErrInvalidAmendment)
+	}
+
+	// The remaining schedule must still be executable
+	if order.WindowMinutes/order.ExecutionIntervals < 1 {
+		return nil, fmt.Errorf("%w: execution intervals too frequent for the window", ErrInvalidAmendment)
+	}
+	schedule := RemainingSchedule(order, executedIntervals, now)
+	if schedule.RemainingIntervals > 1 && schedule.IntervalAmount.LessThan(order.MinFillSize) {
+		return nil, fmt.Errorf("%w: intervals of %s would be below the minimum fill size", ErrInvalidAmendment, schedule.IntervalAmount.String())
+	}
+	if remaining := order.ExecutionIntervals - executedIntervals - order.SkippedIntervals; schedule.RemainingIntervals < remaining {
+		return nil, fmt.Errorf("%w: the remaining %d intervals do not fit before the window ends at %s",
+			ErrInvalidAmendment, remaining, schedule.WindowEnd.UTC().Format(time.RFC3339))
+	}
+
+	return changes, nil
+}
+
+// RemainingSchedule computes the order's remaining execution plan the way
+// the engine schedules it: the remainder split evenly over the intervals
+// neither executed nor skipped that fit in the window, spaced WindowMinutes/ExecutionIntervals
+// apart
+func RemainingSchedule(order *database.Order, executedIntervals int, now time.Time) Schedule {
+	schedule := Schedule{
+		RemainingAmount:    order.GetRemainingAmount(),
+		RemainingIntervals: PlannedIntervals(order, order.ExecutionIntervals-executedIntervals-order.SkippedIntervals, now),
+		IntervalMinutes:    order.WindowMinutes / order.ExecutionIntervals,
+		NextExecution:      order.GetNextExecutionTime(),
+		WindowEnd:          order.WindowEnd(),
+	}
+	if schedule.RemainingIntervals > 0 {
+		schedule.IntervalAmount = schedule.RemainingAmount.Div(decimal.NewFromInt(int64(schedule.RemainingIntervals)))
+	}
+	if schedule.NextExecution.Before(now) {
+		schedule.NextExecution = now
+	}
+	return schedule
+}
@@ -0,0 +1,115 @@
+package twap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+func amendableOrder(now time.Time) *database.Order {
+	lastExecution := now.Add(-5 * time.Minute)
+	return &database.Order{
+		Status:             string(database.OrderStatusExecuting),
+		CreatedAt:          now.Add(-25 * time.Minute),
+		SourceAmount:       decimal.NewFromInt(6000),
+		ExecutedAmount:     decimal.NewFromInt(2000),
+		MinReceived:        decimal.NewFromInt(12001),
+		MinFillSize:        decimal.NewFromInt(100),
+		WindowMinutes:      60,
+		ExecutionIntervals: 6,
+		MaxSlippage:        50,
+		LastExecution:      &lastExecution,
+	}
+}
+
+func TestApplyAmendment(t *testing.T) {
+	now := time.Now()
+	ptr := func(v int) *int { return &v }
+	amount := func(v int64) *decimal.Decimal { d := decimal.NewFromInt(v); return &d }
+
+	tests := []struct {
+		name      string
+		amendment Amendment
+		wantErr   error
+		check     func(t *testing.T, order *database.Order, schedule Schedule)
+	}{
+		{
+			name:      "resize and add intervals",
+			amendment: Amendment{SourceAmount: amount(4000), ExecutionIntervals: ptr(4), WindowMinutes: ptr(100)},
+			check: func(t *testing.T, order *database.Order, schedule Schedule) {
+				// 2000 left over the 2 intervals not yet run, 25 minutes apart
+				if !schedule.IntervalAmount.Equal(decimal.NewFromInt(1000)) || schedule.IntervalMinutes != 25 {
+					t.Fatalf("unexpected schedule %+v", schedule)
+				}
+			},
+		},
+		{
+			name:      "reducing the amount scales min received",
+			amendment: Amendment{SourceAmount: amount(4000)},
+			check: func(t *testing.T, order *database.Order, schedule Schedule) {
+				// 12001 * 4000/6000 rounded up
+				if !order.MinReceived.Equal(decimal.NewFromInt(8001)) {
+					t.Fatalf("min received = %s, want 8001", order.MinReceived)
+				}
+			},
+		},
+		{
+			name:      "widen slippage",
+			amendment: Amendment{MaxSlippage: ptr(200)},
+			check: func(t *testing.T, order *database.Order, schedule Schedule) {
+				if order.MaxSlippage != 200 {
+					t.Fatalf("slippage not amended")
+				}
+			},
+		},
+		{name: "increase amount", amendment: Amendment{SourceAmount: amount(7000)}, wantErr: ErrInvalidAmendment},
+		{name: "below executed", amendment: Amendment{SourceAmount: amount(2000)}, wantErr: ErrInvalidAmendment},
+		{name: "shorten window", amendment: Amendment{WindowMinutes: ptr(30)}, wantErr: ErrInvalidAmendment},
+		{name: "fewer intervals than executed", amendment: Amendment{ExecutionIntervals: ptr(2)}, wantErr: ErrInvalidAmendment},
+		{name: "slippage out of range", amendment: Amendment{MaxSlippage: ptr(5000)}, wantErr: ErrInvalidAmendment},
+		{name: "nothing changed", amendment: Amendment{MaxSlippage: ptr(50)}, wantErr: ErrInvalidAmendment},
+		{name: "intervals below min fill", amendment: Amendment{SourceAmount: amount(2300)}, wantErr: ErrInvalidAmendment},
+		{
+			// 18 intervals 3 minutes apart from now overrun the window
+			name:      "schedule overruns window",
+			amendment: Amendment{ExecutionIntervals: ptr(20)},
+			wantErr:   ErrInvalidAmendment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := amendableOrder(now)
+			changes, err := ApplyAmendment(order, 2, tt.amendment, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(changes) == 0 {
+				t.Fatalf("no changes recorded")
+			}
+			if _, scaled := changes["min_received"]; scaled != (tt.amendment.SourceAmount != nil) {
+				t.Fatalf("min received change recorded = %v for %+v", scaled, tt.amendment)
+			}
+			tt.check(t, order, RemainingSchedule(order, 2, now))
+		})
+	}
+}
+
+func TestApplyAmendmentRequiresLiveOrder(t *testing.T) {
+	order := amendableOrder(time.Now())
+	order.Status = string(database.OrderStatusCompleted)
+	slippage := 100
+	if _, err := ApplyAmendment(order, 2, Amendment{MaxSlippage: &slippage}, time.Now()); !errors.Is(err, ErrOrderNotAmendable) {
+		t.Fatalf("expected ErrOrderNotAmendable, got %v", err)
+	}
+}
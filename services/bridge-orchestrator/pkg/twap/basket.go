@@ -0,0 +1,75 @@
+package twap
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+// holdForBasket checks if the order should wait for the rest of a basket
+// that keeps its orders in step. An order is never held once its window
+// has closed, so it can still fill.
+func (e *Engine) holdForBasket(order *database.Order, now time.Time) bool {
+	if order.BasketID == nil || !now.Before(order.WindowEnd()) {
+		return false
+	}
+
+	basket, err := e.db.GetBasket(*order.BasketID)
+	if err != nil {
+		e.logger.Warn("Failed to get order's basket",
+			zap.String("order_id", order.ID),
+			zap.Error(err))
+		return false
+	}
+	if !basket.SyncProgress {
+		return false
+	}
+
+	members, err := e.db.GetOrdersByBasket(basket.ID)
+	if err != nil {
+		e.logger.Warn("Failed to get basket orders",
+			zap.String("basket_id", basket.ID),
+			zap.Error(err))
+		return false
+	}
+
+	laggard := aheadOfBasket(order, members, basket.MaxProgressDrift, now)
+	if laggard == nil {
+		return false
+	}
+
+	e.logger.Debug("Deferring interval to keep basket in step",
+		zap.String("order_id", order.ID),
+		zap.String("basket_id", basket.ID),
+		zap.String("laggard_id", laggard.ID),
+		zap.Float64("completion", order.CalculateCompletionRate()),
+		zap.Float64("laggard_completion", laggard.CalculateCompletionRate()))
+	return true
+}
+
+// aheadOfBasket returns the basket's slowest live order if order's
+// completion leads it by more than maxDrift percentage points, or nil.
+// Orders that are paused, ended or not yet activated do not hold the rest.
+func aheadOfBasket(order *database.Order, members []*database.Order, maxDrift decimal.Decimal, now time.Time) *database.Order {
+	var laggard *database.Order
+	for _, member := range members {
+		if member.ID == order.ID || !member.IsExecutable() || !member.IsActivated(now) {
+			continue
+		}
+		if laggard == nil || member.CalculateCompletionRate() < laggard.CalculateCompletionRate() {
+			laggard = member
+		}
+	}
+	if laggard == nil {
+		return nil
+	}
+
+	drift, _ := maxDrift.Float64()
+	if order.CalculateCompletionRate()-laggard.CalculateCompletionRate() <= drift {
+		return nil
+	}
+	return laggard
+}
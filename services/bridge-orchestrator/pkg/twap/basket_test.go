@@ -0,0 +1,52 @@
+package twap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+func TestAheadOfBasket(t *testing.T) {
+	now := time.Now()
+	member := func(id, status string, executed int64) *database.Order {
+		return &database.Order{
+			ID:             id,
+			Status:         status,
+			SourceAmount:   decimal.NewFromInt(100),
+			ExecutedAmount: decimal.NewFromInt(executed),
+			CreatedAt:      now.Add(-time.Hour),
+		}
+	}
+
+	leader := member("a", string(database.OrderStatusExecuting), 50)
+	laggard := member("b", string(database.OrderStatusExecuting), 20)
+	paused := member("c", string(database.OrderStatusPaused), 0)
+	drift := decimal.NewFromInt(10)
+
+	if got := aheadOfBasket(leader, []*database.Order{leader, laggard, paused}, drift, now); got != laggard {
+		t.Fatalf("leader 30 points ahead should wait for the laggard, got %v", got)
+	}
+	if got := aheadOfBasket(laggard, []*database.Order{leader, laggard, paused}, drift, now); got != nil {
+		t.Fatalf("the slowest order should never wait, got %s", got.ID)
+	}
+
+	laggard.ExecutedAmount = decimal.NewFromInt(40)
+	if got := aheadOfBasket(leader, []*database.Order{leader, laggard}, drift, now); got != nil {
+		t.Fatalf("leader within the drift should not wait, got %s", got.ID)
+	}
+
+	// Orders that cannot progress do not hold the rest
+	laggard.Status = string(database.OrderStatusCancelled)
+	if got := aheadOfBasket(leader, []*database.Order{leader, laggard, paused}, drift, now); got != nil {
+		t.Fatalf("ended and paused orders should not hold the leader, got %s", got.ID)
+	}
+	startAt := now.Add(time.Hour)
+	pending := member("d", string(database.OrderStatusPending), 0)
+	pending.StartAt = &startAt
+	if got := aheadOfBasket(leader, []*database.Order{leader, pending}, drift, now); got != nil {
+		t.Fatalf("an order not yet started should not hold the leader, got %s", got.ID)
+	}
+}
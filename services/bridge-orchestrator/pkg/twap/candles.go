@@ -0,0 +1,200 @@
+package twap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+// candleAggregationInterval is how often raw points are rolled into candles
+const candleAggregationInterval = time.Minute
+
+// AggregateCandles buckets raw price points into OHLCV candles of the given
+// resolution. Buckets are aligned to UTC and returned in time order; buckets
+// without any points are omitted.
+func AggregateCandles(tokenPair string, points []*PricePoint, resolution database.CandleResolution) []*database.Candle {
+	width := resolution.Duration()
+	if width <= 0 {
+		return nil
+	}
+
+	var candles []*database.Candle
+	var current *database.Candle
+	for _, p := range sortedPoints(points) {
+		bucket := p.Timestamp.UTC().Truncate(width)
+		if current == nil || !current.BucketStart.Equal(bucket) {
+			current = &database.Candle{
+				TokenPair:   tokenPair,
+				Resolution:  resolution,
+				BucketStart: bucket,
+				Open:        p.Price,
+				High:        p.Price,
+				Low:         p.Price,
+			}
+			candles = append(candles, current)
+		}
+
+		if p.Price.GreaterThan(current.High) {
+			current.High = p.Price
+		}
+		if p.Price.LessThan(current.Low) {
+			current.Low = p.Price
+		}
+		current.Close = p.Price
+		current.Volume = current.Volume.Add(p.Volume)
+		current.NumPoints++
+	}
+
+	return candles
+}
+
+// RollupCandles merges finer candles into candles of a coarser resolution.
+// The input must be sorted by bucket start, as returned by the database.
+func RollupCandles(candles []*database.Candle, resolution database.CandleResolution) []*database.Candle {
+	width := resolution.Duration()
+	if width <= 0 {
+		return nil
+	}
+
+	var rolled []*database.Candle
+	var current *database.Candle
+	for _, c := range candles {
+		bucket := c.BucketStart.UTC().Truncate(width)
+		if current == nil || !current.BucketStart.Equal(bucket) {
+			current = &database.Candle{
+				TokenPair:   c.TokenPair,
+				Resolution:  resolution,
+				BucketStart: bucket,
+				Open:        c.Open,
+				High:        c.High,
+				Low:         c.Low,
+				Volume:      decimal.Zero,
+			}
+			rolled = append(rolled, current)
+		}
+
+		if c.High.GreaterThan(current.High) {
+			current.High = c.High
+		}
+		if c.Low.LessThan(current.Low) {
+			current.Low = c.Low
+		}
+		current.Close = c.Close
+		current.Volume = current.Volume.Add(c.Volume)
+		current.NumPoints += c.NumPoints
+	}
+
+	return rolled
+}
+
+// candleAggregator periodically rolls price points into candles and applies
+// the retention policy
+func (e *Engine) candleAggregator(ctx context.Context) {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(candleAggregationInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Time{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.stopChan:
+			return
+		case <-ticker.C:
+			now := time.Now()
+			for _, pair := range e.trackedPairs() {
+				if err := e.updateCandles(pair, now); err != nil {
+					e.logger.Error("Failed to update candles",
+						zap.String("token_pair", pair),
+						zap.Error(err))
+				}
+			}
+			if now.Sub(lastCleanup) >= time.Hour {
+				e.applyPriceRetention(now)
+				lastCleanup = now
+			}
+		}
+	}
+}
+
+// updateCandles rebuilds the current and previous bucket of every resolution
+// for a pair. The previous bucket is included so points that arrive late
+// are still counted once the bucket has closed.
+func (e *Engine) updateCandles(tokenPair string, now time.Time) error {
+	minute := database.CandleResolution1m.Duration()
+	since := now.UTC().Truncate(minute).Add(-minute)
+
+	dbPoints, err := e.db.GetPricePoints(tokenPair, since)
+	if err != nil {
+		return fmt.Errorf("failed to load price points: %w", err)
+	}
+
+	points := make([]*PricePoint, 0, len(dbPoints))
+	for _, p := range dbPoints {
+		volume := decimal.Zero
+		if p.Volume != nil {
+			volume = *p.Volume
+		}
+		points = append(points, &PricePoint{Timestamp: p.Timestamp, Price: p.Price, Volume: volume})
+	}
+
+	if candles := AggregateCandles(tokenPair, points, database.CandleResolution1m); len(candles) > 0 {
+		if err := e.db.UpsertCandles(candles); err != nil {
+			return fmt.Errorf("failed to store 1m candles: %w", err)
+		}
+	}
+
+	// Coarser resolutions are rolled up from the stored 1m candles
+	for _, resolution := range database.CandleResolutions[1:] {
+		width := resolution.Duration()
+		since := now.UTC().Truncate(width).Add(-width)
+
+		minutes, err := e.db.GetCandles(tokenPair, database.CandleResolution1m, since)
+		if err != nil {
+			return fmt.Errorf("failed to load 1m candles: %w", err)
+		}
+
+		if candles := RollupCandles(minutes, resolution); len(candles) > 0 {
+			if err := e.db.UpsertCandles(candles); err != nil {
+				return fmt.Errorf("failed to store %s candles: %w", resolution, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyPriceRetention deletes raw points and candles older than their
+// configured retention. A zero retention keeps data forever.
+func (e *Engine) applyPriceRetention(now time.Time) {
+	retention := e.config.PriceRetention
+
+	if retention.RawPoints > 0 {
+		if err := e.db.CleanupOldPricePoints(now.Add(-retention.RawPoints)); err != nil {
+			e.logger.Error("Failed to clean up old price points", zap.Error(err))
+		}
+	}
+
+	for resolution, keep := range map[database.CandleResolution]time.Duration{
+		database.CandleResolution1m: retention.Candles1m,
+		database.CandleResolution5m: retention.Candles5m,
+		database.CandleResolution1h: retention.Candles1h,
+		database.CandleResolution1d: retention.Candles1d,
+	} {
+		if keep <= 0 {
+			continue
+		}
+		if err := e.db.CleanupOldCandles(resolution, now.Add(-keep)); err != nil {
+			e.logger.Error("Failed to clean up old candles",
+				zap.String("resolution", string(resolution)),
+				zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package twap
+
+import (
+	"testing"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+func TestAggregateCandles(t *testing.T) {
+	points := []*PricePoint{
+		point(0, 100, 1),
+		point(2, 120, 2),
+		point(1, 90, 1),
+		point(4, 110, 1),
+		point(5, 130, 3),
+		point(9, 125, 0),
+	}
+
+	candles := AggregateCandles("ETH_USDC", points, database.CandleResolution5m)
+	if len(candles) != 2 {
+		t.Fatalf("expected 2 candles, got %d", len(candles))
+	}
+
+	first := candles[0]
+	if !first.BucketStart.Equal(at(0)) {
+		t.Fatalf("unexpected bucket start %v", first.BucketStart)
+	}
+	assertDecimal(t, first.Open, point(0, 100, 0).Price)
+	assertDecimal(t, first.High, point(0, 120, 0).Price)
+	assertDecimal(t, first.Low, point(0, 90, 0).Price)
+	assertDecimal(t, first.Close, point(0, 110, 0).Price)
+	assertDecimal(t, first.Volume, point(0, 5, 0).Price)
+	if first.NumPoints != 4 {
+		t.Fatalf("expected 4 points, got %d", first.NumPoints)
+	}
+
+	second := candles[1]
+	if !second.BucketStart.Equal(at(5)) {
+		t.Fatalf("unexpected bucket start %v", second.BucketStart)
+	}
+	assertDecimal(t, second.Open, point(0, 130, 0).Price)
+	assertDecimal(t, second.Close, point(0, 125, 0).Price)
+}
+
+func TestRollupCandlesMatchesDirectAggregation(t *testing.T) {
+	var points []*PricePoint
+	for i := 0; i < 180; i += 3 {
+		points = append(points, point(i, float64(100+(i*7)%23), float64(i%4)))
+	}
+
+	minutes := AggregateCandles("ETH_USDC", points, database.CandleResolution1m)
+
+	for _, resolution := range []database.CandleResolution{database.CandleResolution5m, database.CandleResolution1h} {
+		rolled := RollupCandles(minutes, resolution)
+		direct := AggregateCandles("ETH_USDC", points, resolution)
+
+		if len(rolled) != len(direct) {
+			t.Fatalf("%s: expected %d candles, got %d", resolution, len(direct), len(rolled))
+		}
+		for i := range direct {
+			if !rolled[i].BucketStart.Equal(direct[i].BucketStart) || rolled[i].NumPoints != direct[i].NumPoints {
+				t.Fatalf("%s: bucket %d mismatch", resolution, i)
+			}
+			assertDecimal(t, rolled[i].Open, direct[i].Open)
+			assertDecimal(t, rolled[i].High, direct[i].High)
+			assertDecimal(t, rolled[i].Low, direct[i].Low)
+			assertDecimal(t, rolled[i].Close, direct[i].Close)
+			assertDecimal(t, rolled[i].Volume, direct[i].Volume)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package twap
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+// chainHealthView answers whether chains are fit for execution from the
+// chain_status rows the orchestrator's poller writes. Each row is read once
+// per pass. A chain that was never polled, or whose row is older than
+// maxAge, is not held against its orders.
+type chainHealthView struct {
+	lookup func(chainID string) (*database.ChainStatus, error)
+	maxAge time.Duration
+	now    time.Time
+	cache  map[string]*database.ChainStatus
+}
+
+func newChainHealthView(lookup func(string) (*database.ChainStatus, error), maxAge time.Duration, now time.Time) *chainHealthView {
+	return &chainHealthView{
+		lookup: lookup,
+		maxAge: maxAge,
+		now:    now,
+		cache:  make(map[string]*database.ChainStatus),
+	}
+}
+
+// impaired returns why the first degraded or unhealthy chain among chains
+// cannot be used
+func (v *chainHealthView) impaired(chains ...string) (string, bool) {
+	for _, chainID := range chains {
+		status, ok := v.cache[chainID]
+		if !ok {
+			status, _ = v.lookup(chainID)
+			v.cache[chainID] = status
+		}
+		if status == nil || v.now.Sub(status.LastHealthCheck) > v.maxAge {
+			continue
+		}
+
+		switch database.HealthStatus(status.HealthStatus) {
+		case database.HealthStatusDegraded, database.HealthStatusUnhealthy:
+			reason := fmt.Sprintf("%s is %s", chainID, status.HealthStatus)
+			if status.ErrorMessage != nil && *status.ErrorMessage != "" {
+				reason += ": " + *status.ErrorMessage
+			}
+			return reason, true
+		}
+	}
+	return "", false
+}
+
+// chainHealth returns a view of chain health for one pass over the orders
+func (e *Engine) chainHealth() *chainHealthView {
+	return newChainHealthView(e.db.GetChainStatus, 3*e.config.ChainMonitor.Interval, time.Now())
+}
+
+// pauseForChainHealth pauses an order touching an impaired chain rather
+// than queue an interval that would fail
+func (e *Engine) pauseForChainHealth(order *database.Order, health *chainHealthView) bool {
+	reason, impaired := health.impaired(order.SourceChain, order.TargetChain)
+	if !impaired {
+		return false
+	}
+
+	if err := order.Pause(database.PausedBySystem, reason, time.Now()); err != nil {
+		e.logger.Error("Failed to pause order",
+			zap.String("order_id", order.ID),
+			zap.Error(err))
+		return true
+	}
+	if err := e.db.UpdateOrder(order); err != nil {
+		e.logger.Error("Failed to pause order",
+			zap.String("order_id", order.ID),
+			zap.Error(err))
+		return true
+	}
+
+	e.logger.Warn("Paused order on impaired chain",
+		zap.String("order_id", order.ID),
+		zap.String("reason", reason))
+	return true
+}
+
+// resumeRecoveredOrders resumes the orders the engine paused once every
+// chain they touch has recovered. Orders that time out while paused are
+// left for the orchestrator to expire.
+func (e *Engine) resumeRecoveredOrders(health *chainHealthView) {
+	orders, err := e.db.GetPausedOrders()
+	if err != nil {
+		e.logger.Error("Failed to get paused orders", zap.Error(err))
+		return
+	}
+
+	for _, order := range orders {
+		if order.PausedBy == nil || *order.PausedBy != database.PausedBySystem || order.IsTimedOut() {
+			continue
+		}
+		if _, impaired := health.impaired(order.SourceChain, order.TargetChain); impaired {
+			continue
+		}
+
+		pausedAt := order.PausedAt
+		if err := order.Resume(database.ResumeExtend, database.ActorSystem, "chains recovered", health.now); err != nil {
+			e.logger.Error("Failed to resume order",
+				zap.String("order_id", order.ID),
+				zap.Error(err))
+			continue
+		}
+		if err := e.db.UpdateOrder(order); err != nil {
+			e.logger.Error("Failed to resume order",
+				zap.String("order_id", order.ID),
+				zap.Error(err))
+			continue
+		}
+
+		fields := []zap.Field{zap.String("order_id", order.ID)}
+		if pausedAt != nil {
+			fields = append(fields, zap.Duration("paused_for", health.now.Sub(*pausedAt)))
+		}
+		e.logger.Info("Resumed order after chain recovered", fields...)
+	}
+}
@@ -0,0 +1,82 @@
+package twap
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+func TestChainHealthView(t *testing.T) {
+	now := time.Now()
+	rpcDown := "connection refused"
+	statuses := map[string]*database.ChainStatus{
+		"ethereum": {ChainID: "ethereum", HealthStatus: string(database.HealthStatusHealthy), LastHealthCheck: now},
+		"cosmos":   {ChainID: "cosmos", HealthStatus: string(database.HealthStatusDegraded), LastHealthCheck: now, ErrorMessage: &rpcDown},
+		"stellar":  {ChainID: "stellar", HealthStatus: string(database.HealthStatusUnhealthy), LastHealthCheck: now.Add(-time.Hour)},
+	}
+	lookups := 0
+	view := newChainHealthView(func(chainID string) (*database.ChainStatus, error) {
+		lookups++
+		if status, ok := statuses[chainID]; ok {
+			return status, nil
+		}
+		return nil, database.ErrChainNotFound
+	}, 90*time.Second, now)
+
+	if _, impaired := view.impaired("ethereum", "bitcoin"); impaired {
+		t.Fatalf("healthy and unpolled chains should not hold an order back")
+	}
+	if _, impaired := view.impaired("ethereum", "stellar"); impaired {
+		t.Fatalf("a stale row should not hold an order back")
+	}
+	reason, impaired := view.impaired("ethereum", "cosmos")
+	if !impaired || !strings.Contains(reason, "cosmos is degraded") || !strings.Contains(reason, rpcDown) {
+		t.Fatalf("got %q, %v", reason, impaired)
+	}
+	if lookups != 4 {
+		t.Fatalf("expected each chain read once per pass, got %d reads", lookups)
+	}
+}
+
+func TestResumeShiftsSchedule(t *testing.T) {
+	created := time.Now().Add(-50 * time.Minute)
+	lastExecution := created.Add(40 * time.Minute)
+	order := &database.Order{
+		Status:             string(database.OrderStatusExecuting),
+		CreatedAt:          created,
+		WindowMinutes:      60,
+		ExecutionIntervals: 6,
+		ExecutedAmount:     decimal.NewFromInt(400),
+		LastExecution:      &lastExecution,
+	}
+
+	pausedAt := created.Add(45 * time.Minute)
+	if err := order.Pause(database.PausedBySystem, "cosmos is unhealthy", pausedAt); err != nil {
+		t.Fatalf("pause rejected: %v", err)
+	}
+	if !order.IsPaused() || *order.PauseReason != "cosmos is unhealthy" {
+		t.Fatalf("order not paused: %+v", order)
+	}
+
+	// Two intervals remain, so without the pause the last chance to defer
+	// would have passed at minute 50
+	if err := order.Resume(database.ResumeExtend, database.ActorSystem, "cosmos recovered", pausedAt.Add(30*time.Minute)); err != nil {
+		t.Fatalf("resume rejected: %v", err)
+	}
+	if order.Status != string(database.OrderStatusExecuting) || order.PausedAt != nil || order.PauseReason != nil {
+		t.Fatalf("order not resumed: %+v", order)
+	}
+	if order.PausedSeconds != 1800 || !order.LastExecution.Equal(lastExecution.Add(30*time.Minute)) {
+		t.Fatalf("schedule not shifted: paused %ds, last execution %s", order.PausedSeconds, order.LastExecution)
+	}
+	if !CanDeferInterval(order, 2, created.Add(75*time.Minute)) {
+		t.Fatalf("window not extended by the pause")
+	}
+	if CanDeferInterval(order, 2, created.Add(81*time.Minute)) {
+		t.Fatalf("window extended too far")
+	}
+}
@@ -0,0 +1,234 @@
+package twap
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+)
+
+// ErrCircuitOpen is returned when execution for a token pair is halted
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState is the execution state of a token pair
+type BreakerState string
+
+const (
+	// BreakerClosed allows execution
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen halts execution until the market calms down
+	BreakerOpen BreakerState = "open"
+)
+
+// MarketStats summarizes recent price movement for a token pair
+type MarketStats struct {
+	Volatility   float64 `json:"volatility"`
+	MaxJump      float64 `json:"max_jump"`
+	Observations int     `json:"observations"`
+}
+
+// PairBreaker is the circuit breaker state of a single token pair
+type PairBreaker struct {
+	TokenPair   string       `json:"token_pair"`
+	State       BreakerState `json:"state"`
+	Reason      string       `json:"reason,omitempty"`
+	Stats       MarketStats  `json:"stats"`
+	TrippedAt   *time.Time   `json:"tripped_at,omitempty"`
+	ResumeAfter *time.Time   `json:"resume_after,omitempty"`
+	LastChecked time.Time    `json:"last_checked"`
+}
+
+// CircuitBreaker halts execution for token pairs whose prices move too fast
+// to trade through safely, and resumes them once the market has been calm
+// for the cooldown period
+type CircuitBreaker struct {
+	config config.CircuitBreakerConfig
+	logger *zap.Logger
+	pairs  map[string]*PairBreaker
+	mutex  sync.RWMutex
+}
+
+// NewCircuitBreaker creates a circuit breaker with every pair closed
+func NewCircuitBreaker(cfg config.CircuitBreakerConfig, logger *zap.Logger) *CircuitBreaker {
+	return &CircuitBreaker{
+		config: cfg,
+		logger: logger,
+		pairs:  make(map[string]*PairBreaker),
+	}
+}
+
+// ComputeMarketStats measures volatility and the largest jump among points
+// in (now-window, now]. Returns are taken between consecutive points from the
+// same source, so disagreement between sources is not mistaken for movement.
+func ComputeMarketStats(points []*PricePoint, now time.Time, window time.Duration) MarketStats {
+	start := now.Add(-window)
+
+	bySource := make(map[string][]*PricePoint)
+	for _, p := range sortedPoints(points) {
+		if p.Timestamp.After(start) && !p.Timestamp.After(now) && p.Price.IsPositive() {
+			bySource[p.Source] = append(bySource[p.Source], p)
+		}
+	}
+
+	stats := MarketStats{}
+	for _, series := range bySource {
+		stats.Observations += len(series)
+
+		var sumSquares float64
+		for i := 1; i < len(series); i++ {
+			prev, _ := series[i-1].Price.Float64()
+			curr, _ := series[i].Price.Float64()
+
+			logReturn := math.Log(curr / prev)
+			sumSquares += logReturn * logReturn
+
+			if jump := math.Abs(curr/prev - 1); jump > stats.MaxJump {
+				stats.MaxJump = jump
+			}
+		}
+
+		if volatility := math.Sqrt(sumSquares); volatility > stats.Volatility {
+			stats.Volatility = volatility
+		}
+	}
+
+	return stats
+}
+
+// Evaluate updates a pair's state from its recent price points
+func (cb *CircuitBreaker) Evaluate(tokenPair string, points []*PricePoint, now time.Time) PairBreaker {
+	stats := ComputeMarketStats(points, now, cb.config.Window)
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	pair, exists := cb.pairs[tokenPair]
+	if !exists {
+		pair = &PairBreaker{TokenPair: tokenPair, State: BreakerClosed}
+		cb.pairs[tokenPair] = pair
+	}
+	pair.Stats = stats
+	pair.LastChecked = now
+
+	reason := ""
+	switch {
+	case stats.MaxJump > cb.config.MaxJump:
+		reason = fmt.Sprintf("price jump of %.2f%% exceeds %.2f%%", stats.MaxJump*100, cb.config.MaxJump*100)
+	case stats.Volatility > cb.config.MaxVolatility:
+		reason = fmt.Sprintf("volatility of %.2f%% exceeds %.2f%%", stats.Volatility*100, cb.config.MaxVolatility*100)
+	}
+
+	if reason != "" {
+		// Keep pushing the resume time out while the market is still moving
+		resumeAfter := now.Add(cb.config.Cooldown)
+		pair.ResumeAfter = &resumeAfter
+		pair.Reason = reason
+		if pair.State != BreakerOpen {
+			trippedAt := now
+			pair.TrippedAt = &trippedAt
+			pair.State = BreakerOpen
+			cb.logger.Warn("Circuit breaker tripped, halting execution",
+				zap.String("token_pair", tokenPair),
+				zap.String("reason", reason),
+				zap.Float64("volatility", stats.Volatility),
+				zap.Float64("max_jump", stats.MaxJump))
+		}
+	} else if pair.State == BreakerOpen && pair.ResumeAfter != nil && !now.Before(*pair.ResumeAfter) {
+		cb.logger.Info("Circuit breaker reset, resuming execution",
+			zap.String("token_pair", tokenPair),
+			zap.Duration("halted_for", now.Sub(*pair.TrippedAt)))
+		pair.close()
+	}
+
+	return *pair
+}
+
+// Allow returns ErrCircuitOpen if execution for the pair is halted
+func (cb *CircuitBreaker) Allow(tokenPair string) error {
+	if !cb.config.Enabled {
+		return nil
+	}
+
+	cb.mutex.RLock()
+	defer cb.mutex.RUnlock()
+
+	pair, exists := cb.pairs[tokenPair]
+	if !exists || pair.State != BreakerOpen {
+		return nil
+	}
+
+	return fmt.Errorf("%w for %s: %s", ErrCircuitOpen, tokenPair, pair.Reason)
+}
+
+// Status returns a snapshot of every pair the breaker has evaluated,
+// sorted by pair
+func (cb *CircuitBreaker) Status() []PairBreaker {
+	cb.mutex.RLock()
+	defer cb.mutex.RUnlock()
+
+	status := make([]PairBreaker, 0, len(cb.pairs))
+	for _, pair := range cb.pairs {
+		status = append(status, *pair)
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].TokenPair < status[j].TokenPair })
+
+	return status
+}
+
+// Reset manually closes the breaker for a pair. The next evaluation trips it
+// again if the market is still moving.
+func (cb *CircuitBreaker) Reset(tokenPair string) bool {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	pair, exists := cb.pairs[tokenPair]
+	if !exists {
+		return false
+	}
+
+	cb.logger.Info("Circuit breaker manually reset", zap.String("token_pair", tokenPair))
+	pair.close()
+	return true
+}
+
+func (p *PairBreaker) close() {
+	p.State = BreakerClosed
+	p.Reason = ""
+	p.TrippedAt = nil
+	p.ResumeAfter = nil
+}
+
+// evaluateCircuitBreakers re-evaluates every pair held in the price cache
+func (e *Engine) evaluateCircuitBreakers() {
+	if !e.config.CircuitBreaker.Enabled {
+		return
+	}
+
+	e.priceCache.mutex.RLock()
+	pairs := make([]string, 0, len(e.priceCache.data))
+	for pair := range e.priceCache.data {
+		pairs = append(pairs, pair)
+	}
+	e.priceCache.mutex.RUnlock()
+
+	now := time.Now()
+	for _, pair := range pairs {
+		e.circuitBreaker.Evaluate(pair, e.getPricePoints(pair, e.config.CircuitBreaker.Window), now)
+	}
+}
+
+// GetCircuitBreakers returns the circuit breaker state of every tracked pair
+func (e *Engine) GetCircuitBreakers() []PairBreaker {
+	return e.circuitBreaker.Status()
+}
+
+// ResetCircuitBreaker manually resumes execution for a pair
+func (e *Engine) ResetCircuitBreaker(tokenPair string) bool {
+	return e.circuitBreaker.Reset(tokenPair)
+}
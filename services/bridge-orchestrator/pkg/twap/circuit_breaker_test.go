@@ -0,0 +1,221 @@
+package twap
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+)
+
+var testBreakerConfig = config.CircuitBreakerConfig{
+	Enabled:       true,
+	Window:        15 * time.Minute,
+	MaxVolatility: 0.05,
+	MaxJump:       0.08,
+	Cooldown:      10 * time.Minute,
+}
+
+// series builds one point per minute from source, starting at minute start
+func series(source string, start int, prices ...float64) []*PricePoint {
+	points := make([]*PricePoint, len(prices))
+	for i, price := range prices {
+		points[i] = &PricePoint{
+			Timestamp: at(start + i),
+			Price:     decimal.NewFromFloat(price),
+			Source:    source,
+		}
+	}
+	return points
+}
+
+// flat returns n copies of price
+func flat(price float64, n int) []float64 {
+	prices := make([]float64, n)
+	for i := range prices {
+		prices[i] = price
+	}
+	return prices
+}
+
+func TestComputeMarketStats(t *testing.T) {
+	tests := []struct {
+		name           string
+		points         []*PricePoint
+		wantVolatility float64
+		wantJump       float64
+	}{
+		{
+			name:   "flat market",
+			points: series("feed", 0, flat(100, 15)...),
+		},
+		{
+			name:           "single jump",
+			points:         series("feed", 0, 100, 100, 90, 90),
+			wantVolatility: math.Abs(math.Log(0.9)),
+			wantJump:       0.1,
+		},
+		{
+			name:   "sources disagreeing is not movement",
+			points: append(series("a", 0, flat(100, 10)...), series("b", 0, flat(120, 10)...)...),
+		},
+		{
+			name:   "points outside window are ignored",
+			points: append(series("feed", -30, 100, 50), series("feed", 1, flat(50, 10)...)...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := ComputeMarketStats(tt.points, at(14), 15*time.Minute)
+			if math.Abs(stats.Volatility-tt.wantVolatility) > 1e-9 {
+				t.Fatalf("volatility = %v, want %v", stats.Volatility, tt.wantVolatility)
+			}
+			if math.Abs(stats.MaxJump-tt.wantJump) > 1e-9 {
+				t.Fatalf("max jump = %v, want %v", stats.MaxJump, tt.wantJump)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerTrips(t *testing.T) {
+	// Small oscillations that each stay under the jump limit but add up to
+	// more than the volatility limit
+	choppy := make([]float64, 15)
+	for i := range choppy {
+		choppy[i] = 100
+		if i%2 == 1 {
+			choppy[i] = 103
+		}
+	}
+
+	tests := []struct {
+		name     string
+		points   []*PricePoint
+		wantOpen bool
+	}{
+		{
+			name:   "calm market",
+			points: series("feed", 0, 100, 100.2, 100.1, 100.3, 100.2, 100.4, 100.3),
+		},
+		{
+			name:     "flash crash",
+			points:   series("feed", 0, 100, 100, 100, 70, 95, 100),
+			wantOpen: true,
+		},
+		{
+			name:     "oracle spike",
+			points:   series("feed", 0, 100, 100, 1000, 100),
+			wantOpen: true,
+		},
+		{
+			name:     "sustained volatility",
+			points:   series("feed", 0, choppy...),
+			wantOpen: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := NewCircuitBreaker(testBreakerConfig, zap.NewNop())
+			state := cb.Evaluate("ETH_USDC", tt.points, at(14))
+
+			if open := state.State == BreakerOpen; open != tt.wantOpen {
+				t.Fatalf("open = %v, want %v (stats %+v)", open, tt.wantOpen, state.Stats)
+			}
+
+			err := cb.Allow("ETH_USDC")
+			if tt.wantOpen && !errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("expected ErrCircuitOpen, got %v", err)
+			}
+			if !tt.wantOpen && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := cb.Allow("BTC_USDC"); err != nil {
+				t.Fatalf("other pairs must not be halted: %v", err)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerResumesAfterCooldown(t *testing.T) {
+	cb := NewCircuitBreaker(testBreakerConfig, zap.NewNop())
+
+	// Crash at minute 3, then the price settles at the new level
+	points := append(series("feed", 0, 100, 100, 100), series("feed", 3, flat(80, 60)...)...)
+
+	if state := cb.Evaluate("ETH_USDC", points, at(5)); state.State != BreakerOpen {
+		t.Fatalf("expected breaker to trip on the crash")
+	}
+
+	// The crash is still inside the volatility window, so the cooldown keeps
+	// being extended
+	if state := cb.Evaluate("ETH_USDC", points, at(16)); state.State != BreakerOpen {
+		t.Fatalf("expected breaker to stay open while the crash is in the window")
+	}
+
+	// Crash has left the window but the cooldown from the last trip has not
+	// elapsed
+	if state := cb.Evaluate("ETH_USDC", points, at(20)); state.State != BreakerOpen {
+		t.Fatalf("expected breaker to stay open during cooldown")
+	}
+
+	state := cb.Evaluate("ETH_USDC", points, at(28))
+	if state.State != BreakerClosed {
+		t.Fatalf("expected breaker to close after cooldown, got %+v", state)
+	}
+	if state.TrippedAt != nil || state.ResumeAfter != nil || state.Reason != "" {
+		t.Fatalf("expected trip details to be cleared, got %+v", state)
+	}
+	if err := cb.Allow("ETH_USDC"); err != nil {
+		t.Fatalf("unexpected error after resume: %v", err)
+	}
+}
+
+func TestCircuitBreakerManualReset(t *testing.T) {
+	cb := NewCircuitBreaker(testBreakerConfig, zap.NewNop())
+
+	if cb.Reset("ETH_USDC") {
+		t.Fatalf("reset of unknown pair should report false")
+	}
+
+	cb.Evaluate("ETH_USDC", series("feed", 0, 100, 50), at(2))
+	if !cb.Reset("ETH_USDC") {
+		t.Fatalf("expected reset to succeed")
+	}
+	if err := cb.Allow("ETH_USDC"); err != nil {
+		t.Fatalf("unexpected error after reset: %v", err)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	cfg := testBreakerConfig
+	cfg.Enabled = false
+	cb := NewCircuitBreaker(cfg, zap.NewNop())
+
+	cb.Evaluate("ETH_USDC", series("feed", 0, 100, 50), at(2))
+	if err := cb.Allow("ETH_USDC"); err != nil {
+		t.Fatalf("disabled breaker must not halt execution: %v", err)
+	}
+}
+
+func TestCircuitBreakerStatusSortedByPair(t *testing.T) {
+	cb := NewCircuitBreaker(testBreakerConfig, zap.NewNop())
+	for _, pair := range []string{"WBTC_USDC", "ATOM_USDC", "ETH_USDC", "DAI_USDC"} {
+		cb.Evaluate(pair, series("feed", 0, 100, 50), at(2))
+	}
+
+	status := cb.Status()
+	if len(status) != 4 {
+		t.Fatalf("expected 4 pairs, got %d", len(status))
+	}
+	for i := 1; i < len(status); i++ {
+		if status[i-1].TokenPair >= status[i].TokenPair {
+			t.Fatalf("status not sorted: %s before %s", status[i-1].TokenPair, status[i].TokenPair)
+		}
+	}
+}
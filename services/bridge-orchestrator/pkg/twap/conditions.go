@@ -0,0 +1,78 @@
+package twap
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+// activateOrder reports whether the order's start time and trigger allow it
+// to run. A trigger is evaluated against the latest cached price for the
+// order's pair and, once crossed, fires for good: the order's window starts
+// then.
+func (e *Engine) activateOrder(order *database.Order, now time.Time) (bool, error) {
+	if order.IsActivated(now) {
+		return true, nil
+	}
+	if order.StartAt != nil && now.Before(*order.StartAt) {
+		return false, nil
+	}
+
+	price, err := e.getCurrentPrice(orderTokenPair(order))
+	if err != nil {
+		e.logger.Debug("Trigger not evaluated without a price",
+			zap.String("order_id", order.ID),
+			zap.Error(err))
+		return false, nil
+	}
+	if !order.TriggerMet(price) {
+		return false, nil
+	}
+
+	order.TriggeredAt = &now
+	order.UpdatedAt = now
+	if err := e.db.UpdateOrder(order); err != nil {
+		return false, fmt.Errorf("failed to record trigger: %w", err)
+	}
+
+	e.logger.Info("Order triggered",
+		zap.String("order_id", order.ID),
+		zap.String("price", price.String()),
+		zap.String("trigger_price", order.TriggerPrice.String()))
+	return true, nil
+}
+
+// skipInterval gives up an interval priced below the order's limit. The
+// remainder is spread over the intervals left, and the order finalizes
+// once its intervals run out.
+func (e *Engine) skipInterval(order *database.Order, price decimal.Decimal) error {
+	order.SkipInterval(time.Now())
+	if err := e.db.UpdateOrder(order); err != nil {
+		return fmt.Errorf("failed to record skipped interval: %w", err)
+	}
+
+	e.logger.Info("Skipping interval priced below the order's limit",
+		zap.String("order_id", order.ID),
+		zap.String("price", price.String()),
+		zap.String("limit_price", order.LimitPrice.String()),
+		zap.Int("skipped_intervals", order.SkippedIntervals))
+	return nil
+}
+
+// TriggerCondition returns the direction in which the order's pair must
+// cross triggerPrice: up to it from below, or down to it from above,
+// judged from the current cached price
+func (e *Engine) TriggerCondition(order *database.Order, triggerPrice decimal.Decimal) (string, error) {
+	price, err := e.getCurrentPrice(orderTokenPair(order))
+	if err != nil {
+		return "", err
+	}
+	if price.LessThan(triggerPrice) {
+		return database.TriggerAbove, nil
+	}
+	return database.TriggerBelow, nil
+}
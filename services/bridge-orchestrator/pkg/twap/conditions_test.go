@@ -0,0 +1,106 @@
+package twap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+func TestConditionalOrderSchedule(t *testing.T) {
+	created := time.Now().Add(-time.Hour)
+	startAt := created.Add(30 * time.Minute)
+	order := &database.Order{
+		Status:             string(database.OrderStatusPending),
+		CreatedAt:          created,
+		StartAt:            &startAt,
+		WindowMinutes:      60,
+		ExecutionIntervals: 6,
+	}
+
+	if order.IsActivated(created.Add(10 * time.Minute)) {
+		t.Fatalf("order activated before its start time")
+	}
+	if !order.IsActivated(startAt) {
+		t.Fatalf("order not activated at its start time")
+	}
+	if !order.GetNextExecutionTime().Equal(startAt) || !order.WindowEnd().Equal(startAt.Add(time.Hour)) {
+		t.Fatalf("window should start at the start time: next %s, end %s", order.GetNextExecutionTime(), order.WindowEnd())
+	}
+
+	// A trigger holds the order past its start time and moves the window
+	// to when it fires
+	trigger := decimal.NewFromInt(2000)
+	condition := database.TriggerBelow
+	order.TriggerPrice, order.TriggerCondition = &trigger, &condition
+	if order.IsActivated(startAt.Add(time.Minute)) {
+		t.Fatalf("order activated before its trigger fired")
+	}
+	if order.TriggerMet(decimal.NewFromInt(2001)) || !order.TriggerMet(decimal.NewFromInt(2000)) {
+		t.Fatalf("below trigger evaluated wrongly")
+	}
+	triggered := startAt.Add(5 * time.Minute)
+	order.TriggeredAt = &triggered
+	if !order.IsActivated(triggered) || !order.WindowEnd().Equal(triggered.Add(time.Hour)) {
+		t.Fatalf("window should start when the trigger fires: end %s", order.WindowEnd())
+	}
+
+	// A skipped interval takes its slot and leaves one fewer interval
+	order.SkipInterval(triggered)
+	if order.SkippedIntervals != 1 || !order.GetNextExecutionTime().Equal(triggered.Add(10*time.Minute)) {
+		t.Fatalf("skipped interval not booked: %d skipped, next %s", order.SkippedIntervals, order.GetNextExecutionTime())
+	}
+	if remaining := order.GetRemainingIntervals(nil); remaining != 5 {
+		t.Fatalf("expected 5 intervals left, got %d", remaining)
+	}
+}
+
+func TestLimitPrice(t *testing.T) {
+	order := &database.Order{}
+	if !order.WithinLimit(decimal.NewFromInt(1)) {
+		t.Fatalf("order without a limit should fill at any price")
+	}
+
+	limit := decimal.NewFromInt(1800)
+	order.LimitPrice = &limit
+	if order.WithinLimit(decimal.NewFromInt(1799)) {
+		t.Fatalf("interval priced below the limit should be skipped")
+	}
+	if !order.WithinLimit(decimal.NewFromInt(1800)) || !order.WithinLimit(decimal.NewFromInt(1900)) {
+		t.Fatalf("interval at or above the limit should fill")
+	}
+}
+
+func TestActivateOrder(t *testing.T) {
+	now := time.Now()
+	engine := &Engine{
+		logger: zap.NewNop(),
+		priceCache: &PriceCache{data: map[string][]*PricePoint{
+			"ETH_USDC": {{Timestamp: now.Add(-time.Minute), Price: decimal.NewFromInt(1900)}},
+		}},
+	}
+
+	startAt := now.Add(time.Hour)
+	order := &database.Order{SourceToken: "ETH", TargetToken: "USDC", CreatedAt: now, StartAt: &startAt}
+	if active, err := engine.activateOrder(order, now); active || err != nil {
+		t.Fatalf("order activated before its start time: %v", err)
+	}
+
+	trigger := decimal.NewFromInt(2000)
+	order = &database.Order{SourceToken: "ETH", TargetToken: "USDC", CreatedAt: now, TriggerPrice: &trigger}
+	condition, err := engine.TriggerCondition(order, trigger)
+	if err != nil || condition != database.TriggerAbove {
+		t.Fatalf("expected to trigger above 1900, got %q, %v", condition, err)
+	}
+	order.TriggerCondition = &condition
+	if active, err := engine.activateOrder(order, now); active || err != nil || order.TriggeredAt != nil {
+		t.Fatalf("order triggered below its trigger price: %v", err)
+	}
+
+	if _, err := engine.TriggerCondition(&database.Order{SourceToken: "ETH", TargetToken: "DAI"}, trigger); err == nil {
+		t.Fatalf("expected an error without a price")
+	}
+}
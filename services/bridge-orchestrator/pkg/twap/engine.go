@@ -23,10 +23,13 @@ type Engine struct {
 
 	// Internal state
 	priceCache     *PriceCache
+	circuitBreaker *CircuitBreaker
 	executionQueue chan *ExecutionRequest
 	stopChan       chan struct{}
 	wg             sync.WaitGroup
 	mutex          sync.RWMutex
+	inFlight       map[string]chan struct{} // closed when the order's interval finishes
+	held           map[string]bool          // orders being amended
 
 	// Metrics
 	metrics *Metrics
@@ -96,8 +99,11 @@ func NewEngine(
 			data:   make(map[string][]*PricePoint),
 			maxAge: 24 * time.Hour,
 		},
+		circuitBreaker: NewCircuitBreaker(config.CircuitBreaker, logger),
 		executionQueue: make(chan *ExecutionRequest, 100),
 		stopChan:       make(chan struct{}),
+		inFlight:       make(map[string]chan struct{}),
+		held:           make(map[string]bool),
 		metrics:        &Metrics{},
 	}
 
@@ -108,6 +114,9 @@ func NewEngine(
 func (e *Engine) Start(ctx context.Context) error {
 	e.logger.Info("Starting TWAP engine")
 
+	// Warm the price cache so TWAPs are available before the first feed update
+	e.hydratePriceCache()
+
 	// Start price feed updater
 	e.wg.Add(1)
 	go e.priceFeedUpdater(ctx)
@@ -124,6 +133,10 @@ func (e *Engine) Start(ctx context.Context) error {
 	e.wg.Add(1)
 	go e.metricsUpdater(ctx)
 
+	// Start candle aggregator
+	e.wg.Add(1)
+	go e.candleAggregator(ctx)
+
 	// Wait for context cancellation
 	<-ctx.Done()
 	
@@ -148,9 +161,19 @@ func (e *Engine) priceFeedUpdater(ctx context.Context) {
 		case <-e.stopChan:
 			return
 		case <-ticker.C:
+			stale := e.stalePairs(e.trackedPairs())
 			if err := e.updatePriceFeeds(); err != nil {
 				e.logger.Error("Failed to update price feeds", zap.Error(err))
 			}
+			// Backfill anything other writers recorded while we were behind
+			for _, pair := range stale {
+				if err := e.hydratePair(pair); err != nil {
+					e.logger.Error("Failed to rehydrate price cache after gap",
+						zap.String("token_pair", pair),
+						zap.Error(err))
+				}
+			}
+			e.evaluateCircuitBreakers()
 		}
 	}
 }
@@ -216,6 +239,12 @@ func (e *Engine) metricsUpdater(ctx context.Context) {
 
 // processExecutableOrders finds orders ready for execution
 func (e *Engine) processExecutableOrders() error {
+	var health *chainHealthView
+	if e.config.ChainMonitor.PauseOrders {
+		health = e.chainHealth()
+		e.resumeRecoveredOrders(health)
+	}
+
 	orders, err := e.db.GetExecutableOrders()
 	if err != nil {
 		return fmt.Errorf("failed to get executable orders: %w", err)
@@ -224,6 +253,9 @@ func (e *Engine) processExecutableOrders() error {
 	e.logger.Debug("Processing executable orders", zap.Int("count", len(orders)))
 
 	for _, order := range orders {
+		if health != nil && e.pauseForChainHealth(order, health) {
+			continue
+		}
 		if err := e.processOrder(order); err != nil {
 			e.logger.Error("Failed to process order",
 				zap.String("order_id", order.ID),
@@ -236,6 +268,10 @@ func (e *Engine) processExecutableOrders() error {
 
 // processOrder determines if an order is ready for execution and queues it
 func (e *Engine) processOrder(order *database.Order) error {
+	if active, err := e.activateOrder(order, time.Now()); !active {
+		return err
+	}
+
 	if !order.CanExecuteInterval() {
 		return nil
 	}
@@ -245,8 +281,8 @@ func (e *Engine) processOrder(order *database.Order) error {
 		return fmt.Errorf("failed to get execution history: %w", err)
 	}
 
-	if len(history) >= order.ExecutionIntervals {
-		order.Status = string(database.OrderStatusCompleted)
+	if order.GetRemainingIntervals(history) <= 0 {
+		e.finalizeOrder(order)
 		return e.db.UpdateOrder(order)
 	}
 
@@ -257,6 +293,7 @@ func (e *Engine) processOrder(order *database.Order) error {
 	if remainingIntervals <= 0 {
 		return nil
 	}
+	remainingIntervals = PlannedIntervals(order, remainingIntervals, time.Now())
 
 	targetAmount := remainingAmount.Div(decimal.NewFromInt(int64(remainingIntervals)))
 
@@ -269,15 +306,51 @@ func (e *Engine) processOrder(order *database.Order) error {
 		return nil
 	}
 
-	// Calculate TWAP price for validation
-	tokenPair := fmt.Sprintf("%s_%s", order.SourceToken, order.TargetToken)
-	twapPrice, err := e.calculateTWAP(tokenPair, order.WindowMinutes)
+	// Let the rest of a synced basket catch up
+	if e.holdForBasket(order, time.Now()) {
+		return nil
+	}
+
+	// Hold every order on the pair while the market is dislocated
+	tokenPair := orderTokenPair(order)
+	if err := e.circuitBreaker.Allow(tokenPair); err != nil {
+		e.logger.Debug("Deferring interval while circuit breaker is open",
+			zap.String("order_id", order.ID),
+			zap.Error(err))
+		return nil
+	}
+
+	// Calculate TWAP price for validation. Without a trustworthy TWAP the
+	// slippage check cannot run, so the interval waits for more data.
+	twapPrice, err := e.validatedTWAP(tokenPair, order.WindowMinutes)
 	if err != nil {
-		e.logger.Warn("Failed to calculate TWAP price",
+		e.logger.Warn("Deferring interval without a reliable TWAP price",
 			zap.String("order_id", order.ID),
 			zap.String("token_pair", tokenPair),
 			zap.Error(err))
-		twapPrice = decimal.Zero // Use zero as fallback
+		return nil
+	}
+
+	// Defer rather than submit a fill the on-chain limit would reject
+	minOutput := IntervalMinOutput(order, targetAmount, twapPrice)
+	quotePrice, err := e.getCurrentPrice(tokenPair)
+	if err != nil {
+		quotePrice = twapPrice
+	}
+	if !order.WithinLimit(quotePrice) {
+		return e.skipInterval(order, quotePrice)
+	}
+	if expected := TargetValue(order, targetAmount, quotePrice); expected.LessThan(minOutput) {
+		e.logger.Info("Deferring interval that cannot meet its minimum output",
+			zap.String("order_id", order.ID),
+			zap.String("expected_output", expected.String()),
+			zap.String("min_output", minOutput.String()))
+		return nil
+	}
+
+	// Wait out gas spikes while the window still has room
+	if e.deferForGas(order, history, targetAmount, twapPrice, remainingIntervals) {
+		return nil
 	}
 
 	// Create execution request
@@ -312,6 +385,17 @@ func (e *Engine) executeInterval(request *ExecutionRequest) *ExecutionResponse {
 		zap.Int("interval", request.IntervalNumber),
 		zap.String("target_amount", request.TargetAmount.String()))
 
+	// Mark the interval in flight before reading the order, so a
+	// cancellation either sees it or this read sees the cancellation
+	finish, err := e.beginInterval(request.OrderID)
+	if err != nil {
+		return &ExecutionResponse{
+			Success: false,
+			Error:   err,
+		}
+	}
+	defer finish()
+
 	// Get order details
 	order, err := e.db.GetOrder(request.OrderID)
 	if err != nil {
@@ -321,6 +405,22 @@ func (e *Engine) executeInterval(request *ExecutionRequest) *ExecutionResponse {
 		}
 	}
 
+	// The order may have been paused or cancelled while the request sat in
+	// the queue
+	if !order.IsExecutable() {
+		return &ExecutionResponse{
+			Success: false,
+			Error:   fmt.Errorf("order %s is %s", request.OrderID, order.Status),
+		}
+	}
+
+	if request.PriceHint.IsZero() {
+		return &ExecutionResponse{
+			Success: false,
+			Error:   fmt.Errorf("no TWAP price hint for order %s", request.OrderID),
+		}
+	}
+
 	// Get chain adapter for target chain
 	adapter, err := e.adapterManager.GetAdapter(order.TargetChain)
 	if err != nil {
@@ -330,8 +430,16 @@ func (e *Engine) executeInterval(request *ExecutionRequest) *ExecutionResponse {
 		}
 	}
 
+	// The breaker may have tripped while the request sat in the queue
+	tokenPair := orderTokenPair(order)
+	if err := e.circuitBreaker.Allow(tokenPair); err != nil {
+		return &ExecutionResponse{
+			Success: false,
+			Error:   err,
+		}
+	}
+
 	// Calculate current market price
-	tokenPair := fmt.Sprintf("%s_%s", order.SourceToken, order.TargetToken)
 	marketPrice, err := e.getCurrentPrice(tokenPair)
 	if err != nil {
 		e.logger.Warn("Failed to get current market price, using TWAP",
@@ -341,23 +449,32 @@ func (e *Engine) executeInterval(request *ExecutionRequest) *ExecutionResponse {
 	}
 
 	// Validate slippage
-	if !request.PriceHint.IsZero() {
-		slippage := e.calculateSlippage(request.PriceHint, marketPrice)
-		if slippage > request.MaxSlippage {
-			return &ExecutionResponse{
-				Success: false,
-				Error:   fmt.Errorf("slippage %d exceeds maximum %d", slippage, request.MaxSlippage),
-			}
+	slippage := e.calculateSlippage(request.PriceHint, marketPrice)
+	if slippage > request.MaxSlippage {
+		return &ExecutionResponse{
+			Success: false,
+			Error:   fmt.Errorf("slippage %d exceeds maximum %d", slippage, request.MaxSlippage),
+		}
+	}
+
+	// Recompute the floor from the order as it stands now, since other
+	// intervals may have filled while this request was queued
+	minOutput := IntervalMinOutput(order, request.TargetAmount, request.PriceHint)
+	if expected := TargetValue(order, request.TargetAmount, marketPrice); expected.LessThan(minOutput) {
+		return &ExecutionResponse{
+			Success: false,
+			Error: fmt.Errorf("%w: expected %s, need %s",
+				ErrBelowMinOutput, expected.String(), minOutput.String()),
 		}
 	}
 
 	// Execute the swap
-	executedAmount, executionPrice, txHash, gasUsed, err := e.executeSwap(
+	result, err := e.executeSwap(
 		adapter,
-		order.SourceToken,
-		order.TargetToken,
-		request.TargetAmount,
+		order,
+		request,
 		marketPrice,
+		minOutput,
 	)
 	if err != nil {
 		e.updateMetricsOnFailure()
@@ -367,11 +484,13 @@ func (e *Engine) executeInterval(request *ExecutionRequest) *ExecutionResponse {
 		}
 	}
 
+	executedAmount := result.ExecutedAmount
+	executionPrice := result.ExecutionPrice
+	txHash := result.TxHash
+	gasUsed := result.GasUsed
+
 	// Calculate actual slippage
-	actualSlippage := 0
-	if !request.PriceHint.IsZero() {
-		actualSlippage = e.calculateSlippage(request.PriceHint, executionPrice)
-	}
+	actualSlippage := e.calculateSlippage(request.PriceHint, executionPrice)
 
 	// Convert uint64 to *int64 for database storage
 	gasUsedInt64 := int64(gasUsed)
@@ -388,6 +507,14 @@ func (e *Engine) executeInterval(request *ExecutionRequest) *ExecutionResponse {
 		TxHash:         &txHash,
 		ChainID:        order.TargetChain,
 	}
+	if result.SubmissionRoute != "" {
+		inclusionBlock := int64(result.InclusionBlock)
+		executionRecord.SubmissionRoute = &result.SubmissionRoute
+		executionRecord.BundleHash = &result.BundleHash
+		executionRecord.RelayAttempts = &result.RelayAttempts
+		executionRecord.InclusionBlock = &inclusionBlock
+	}
+	e.recordGasSpent(order, executionRecord, result)
 
 	if err := e.db.CreateExecutionRecord(executionRecord); err != nil {
 		e.logger.Error("Failed to record execution", zap.Error(err))
@@ -397,14 +524,19 @@ func (e *Engine) executeInterval(request *ExecutionRequest) *ExecutionResponse {
 	order.ExecutedAmount = order.ExecutedAmount.Add(executedAmount)
 	order.LastExecution = &executionRecord.Timestamp
 	order.UpdateAveragePrice(executedAmount, executionPrice)
-	order.Status = string(database.OrderStatusExecuting)
 
 	// Check if order is complete
-	if order.ExecutedAmount.GreaterThanOrEqual(order.SourceAmount) {
-		order.Status = string(database.OrderStatusCompleted)
+	if order.ExecutedAmount.GreaterThanOrEqual(order.SourceAmount) ||
+		request.IntervalNumber+1+order.SkippedIntervals >= order.ExecutionIntervals {
+		e.finalizeOrder(order)
+	} else if err := order.Transition(database.OrderStatusExecuting, database.ActorSystem,
+		fmt.Sprintf("interval %d filled", request.IntervalNumber)); err != nil {
+		e.logger.Warn("Order status not updated after interval",
+			zap.String("order_id", order.ID),
+			zap.Error(err))
 	}
 
-	if err := e.db.UpdateOrder(order); err != nil {
+	if err := e.saveFill(order); err != nil {
 		e.logger.Error("Failed to update order", zap.Error(err))
 	}
 
@@ -428,86 +560,130 @@ func (e *Engine) executeInterval(request *ExecutionRequest) *ExecutionResponse {
 	}
 }
 
-// executeSwap performs the actual token swap (mock implementation)
+// executeSwap submits the interval to the chain adapter with minOutput as a
+// hard limit on the target tokens received
 func (e *Engine) executeSwap(
 	adapter adapters.ChainAdapter,
-	sourceToken, targetToken string,
-	amount, priceHint decimal.Decimal,
-) (executedAmount, executionPrice decimal.Decimal, txHash string, gasUsed uint64, err error) {
-	// Mock implementation - in reality this would call the appropriate adapter
-	// to execute the swap on the target chain
-	
-	// Simulate some variance in execution
-	variance := decimal.NewFromFloat(0.99 + 0.02*float64(time.Now().Unix()%100)/100)
-	executedAmount = amount.Mul(variance)
-	
-	// Simulate price with small slippage
-	priceVariance := decimal.NewFromFloat(0.995 + 0.01*float64(time.Now().Unix()%100)/100)
-	executionPrice = priceHint.Mul(priceVariance)
-	
-	// Mock transaction hash
-	txHash = fmt.Sprintf("0x%x", time.Now().UnixNano())
-	
-	// Mock gas usage - cast to uint64
-	gasUsed = uint64(150000 + time.Now().Unix()%50000)
+	order *database.Order,
+	request *ExecutionRequest,
+	marketPrice, minOutput decimal.Decimal,
+) (*adapters.ExecutionResult, error) {
+	result, err := adapter.ExecuteTWAPInterval(adapters.ExecuteIntervalParams{
+		OrderID:        order.ID,
+		ChainOrderID:   order.GetChainOrderID(),
+		IntervalNumber: request.IntervalNumber,
+		Amount:         request.TargetAmount,
+		MaxSlippage:    request.MaxSlippage,
+		PriceHint:      marketPrice,
+		PriceScale:     priceScale(order),
+		MinOutput:      minOutput,
+		MEVProtection:  order.EnableMEVProtection,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		return nil, fmt.Errorf("adapter reported failure: %s", result.Error)
+	}
 
-	e.logger.Debug("Mock swap executed",
-		zap.String("source_token", sourceToken),
-		zap.String("target_token", targetToken),
-		zap.String("amount", amount.String()),
-		zap.String("executed_amount", executedAmount.String()),
-		zap.String("execution_price", executionPrice.String()),
-		zap.String("tx_hash", txHash))
+	e.logger.Debug("Swap executed",
+		zap.String("source_token", order.SourceToken),
+		zap.String("target_token", order.TargetToken),
+		zap.String("amount", request.TargetAmount.String()),
+		zap.String("min_output", minOutput.String()),
+		zap.String("executed_amount", result.ExecutedAmount.String()),
+		zap.String("execution_price", result.ExecutionPrice.String()),
+		zap.String("tx_hash", result.TxHash),
+		zap.String("submission_route", result.SubmissionRoute))
 
-	return executedAmount, executionPrice, txHash, gasUsed, nil
+	return result, nil
 }
 
 // calculateTWAP calculates the Time-Weighted Average Price
 func (e *Engine) calculateTWAP(tokenPair string, windowMinutes int) (decimal.Decimal, error) {
-	pricePoints := e.getPricePoints(tokenPair, time.Duration(windowMinutes)*time.Minute)
-	
+	end := time.Now()
+	start := end.Add(-time.Duration(windowMinutes) * time.Minute)
+	pricePoints := e.getPricePointsInWindow(tokenPair, start)
+
 	if len(pricePoints) == 0 {
 		return decimal.Zero, fmt.Errorf("no price data available for %s", tokenPair)
 	}
 
-	if len(pricePoints) == 1 {
-		return pricePoints[0].Price, nil
+	twap, err := TimeWeightedAverage(pricePoints, start, end)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to calculate TWAP for %s: %w", tokenPair, err)
 	}
 
-	// Calculate TWAP using time-weighted average
-	var totalValue decimal.Decimal
-	var totalWeight decimal.Decimal
-	
-	windowStart := time.Now().Add(-time.Duration(windowMinutes) * time.Minute)
+	e.logger.Debug("TWAP calculated",
+		zap.String("token_pair", tokenPair),
+		zap.Int("window_minutes", windowMinutes),
+		zap.Int("data_points", len(pricePoints)),
+		zap.String("twap_price", twap.String()))
 
-	for i, point := range pricePoints {
-		if point.Timestamp.Before(windowStart) {
-			continue
-		}
+	return twap, nil
+}
 
-		weight := decimal.NewFromInt(1)
-		if i > 0 {
-			duration := point.Timestamp.Sub(pricePoints[i-1].Timestamp)
-			weight = decimal.NewFromInt(int64(duration.Seconds()))
-		}
+// calculateTWAPData calculates arithmetic TWAP, geometric TWAP and VWAP
+// together with summary statistics for a token pair
+func (e *Engine) calculateTWAPData(tokenPair string, windowMinutes int) (*database.TWAPData, error) {
+	end := time.Now()
+	start := end.Add(-time.Duration(windowMinutes) * time.Minute)
+	pricePoints := e.getPricePointsInWindow(tokenPair, start)
 
-		totalValue = totalValue.Add(point.Price.Mul(weight))
-		totalWeight = totalWeight.Add(weight)
+	if len(pricePoints) == 0 {
+		return nil, fmt.Errorf("no price data available for %s", tokenPair)
 	}
 
-	if totalWeight.IsZero() {
-		return decimal.Zero, fmt.Errorf("insufficient data for TWAP calculation")
+	twap, err := TimeWeightedAverage(pricePoints, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate TWAP for %s: %w", tokenPair, err)
 	}
 
-	twap := totalValue.Div(totalWeight)
-	
-	e.logger.Debug("TWAP calculated",
-		zap.String("token_pair", tokenPair),
-		zap.Int("window_minutes", windowMinutes),
-		zap.Int("data_points", len(pricePoints)),
-		zap.String("twap_price", twap.String()))
+	geometric, err := GeometricTimeWeightedAverage(pricePoints, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate geometric TWAP for %s: %w", tokenPair, err)
+	}
 
-	return twap, nil
+	// Most feeds do not report volume, so a missing VWAP is not an error
+	vwap, err := VolumeWeightedAverage(pricePoints, start, end)
+	if err != nil && err != ErrNoVolumeData {
+		return nil, fmt.Errorf("failed to calculate VWAP for %s: %w", tokenPair, err)
+	}
+
+	data := &database.TWAPData{
+		TokenPair:          tokenPair,
+		WindowMinutes:      windowMinutes,
+		TWAPPrice:          twap,
+		GeometricTWAPPrice: geometric,
+		VWAPPrice:          vwap,
+		CalculatedAt:       end,
+	}
+
+	// The change is measured from the first observation in the window, not
+	// the price carried in from before it; with none it is zero
+	last := pricePoints[len(pricePoints)-1]
+	first := last
+	for _, point := range pricePoints {
+		if !point.Timestamp.Before(start) {
+			first = point
+			break
+		}
+	}
+	data.LastPrice = last.Price
+	data.PriceChange = last.Price.Sub(first.Price)
+	if !first.Price.IsZero() {
+		data.PriceChangePercent = data.PriceChange.Div(first.Price).Mul(decimal.NewFromInt(100))
+	}
+
+	for _, point := range pricePoints {
+		if point.Timestamp.Before(start) {
+			continue
+		}
+		data.Volume = data.Volume.Add(point.Volume)
+		data.NumDataPoints++
+	}
+
+	return data, nil
 }
 
 // getCurrentPrice gets the most recent price for a token pair
@@ -558,6 +734,31 @@ func (e *Engine) addPricePoint(tokenPair string, point *PricePoint) {
 	e.priceCache.data[tokenPair] = filtered
 }
 
+// getPricePointsInWindow returns the points at or after start, preceded by
+// the latest point before start so its price can carry into the window
+func (e *Engine) getPricePointsInWindow(tokenPair string, start time.Time) []*PricePoint {
+	e.priceCache.mutex.RLock()
+	defer e.priceCache.mutex.RUnlock()
+
+	points := sortedPoints(e.priceCache.data[tokenPair])
+
+	var carry *PricePoint
+	var filtered []*PricePoint
+	for _, point := range points {
+		if point.Timestamp.Before(start) {
+			carry = point
+			continue
+		}
+		filtered = append(filtered, point)
+	}
+
+	if carry != nil {
+		filtered = append([]*PricePoint{carry}, filtered...)
+	}
+
+	return filtered
+}
+
 func (e *Engine) getPricePoints(tokenPair string, window time.Duration) []*PricePoint {
 	e.priceCache.mutex.RLock()
 	defer e.priceCache.mutex.RUnlock()
@@ -633,6 +834,11 @@ func (e *Engine) GetTWAPPrice(tokenPair string, windowMinutes int) (decimal.Deci
 	return e.calculateTWAP(tokenPair, windowMinutes)
 }
 
+// GetTWAPData calculates arithmetic TWAP, geometric TWAP and VWAP for a token pair
+func (e *Engine) GetTWAPData(tokenPair string, windowMinutes int) (*database.TWAPData, error) {
+	return e.calculateTWAPData(tokenPair, windowMinutes)
+}
+
 // GetCurrentPrice gets the latest price for a token pair
 func (e *Engine) GetCurrentPrice(tokenPair string) (decimal.Decimal, error) {
 	return e.getCurrentPrice(tokenPair)
@@ -667,19 +873,29 @@ func (e *Engine) ExecuteOrderManually(orderID string) (*ExecutionResponse, error
 		return nil, fmt.Errorf("failed to get execution history: %w", err)
 	}
 
-	if len(history) >= order.ExecutionIntervals {
+	if order.GetRemainingIntervals(history) <= 0 {
 		return nil, fmt.Errorf("order already fully executed")
 	}
 
 	remainingAmount := order.GetRemainingAmount()
-	remainingIntervals := order.GetRemainingIntervals(history)
+	remainingIntervals := PlannedIntervals(order, order.GetRemainingIntervals(history), time.Now())
 	targetAmount := remainingAmount.Div(decimal.NewFromInt(int64(remainingIntervals)))
 
+	if err := e.circuitBreaker.Allow(orderTokenPair(order)); err != nil {
+		return nil, err
+	}
+
+	twapPrice, err := e.validatedTWAP(orderTokenPair(order), order.WindowMinutes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot execute without a reliable TWAP price: %w", err)
+	}
+
 	request := &ExecutionRequest{
 		OrderID:         orderID,
 		IntervalNumber:  len(history),
 		TargetAmount:    targetAmount,
 		MaxSlippage:     order.MaxSlippage,
+		PriceHint:       twapPrice,
 		ResponseChannel: make(chan *ExecutionResponse, 1),
 	}
 
@@ -0,0 +1,239 @@
+package twap
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+	"flowfusion/bridge-orchestrator/internal/database"
+	"flowfusion/bridge-orchestrator/pkg/adapters"
+)
+
+// Gas policy errors
+var (
+	ErrGasPriceTooHigh = errors.New("gas price above ceiling")
+	ErrGasCostTooHigh  = errors.New("gas cost too high relative to interval value")
+)
+
+// defaultIntervalGas is the gas an interval is assumed to use before the
+// order has history and when no gas limit is configured
+const defaultIntervalGas = 200000
+
+// GasQuote is the expected gas cost of an interval
+type GasQuote struct {
+	ChainID    string
+	GasPrice   decimal.Decimal // smallest native unit per gas
+	GasUnits   uint64
+	CostNative decimal.Decimal // whole native tokens
+	CostQuote  decimal.Decimal // target token base units, zero when the native token has no price
+}
+
+// TargetValue values amount of the order's source token, in its base units,
+// at a price in whole target tokens per whole source token. The value is in
+// target token base units.
+func TargetValue(order *database.Order, amount, price decimal.Decimal) decimal.Decimal {
+	return amount.Mul(price).Shift(priceScale(order))
+}
+
+// priceScale is the target token's decimals less the source token's: the
+// shift from a price in whole tokens to one in base units
+func priceScale(order *database.Order) int32 {
+	sourceDecimals := adapters.GetTokenDecimals(order.SourceChain, order.SourceToken)
+	targetDecimals := adapters.GetTokenDecimals(order.TargetChain, order.TargetToken)
+	return int32(targetDecimals - sourceDecimals)
+}
+
+// gasCostInTarget converts a cost in whole native tokens, at nativePrice in
+// whole target tokens, to target token base units
+func gasCostInTarget(order *database.Order, costNative, nativePrice decimal.Decimal) decimal.Decimal {
+	targetDecimals := adapters.GetTokenDecimals(order.TargetChain, order.TargetToken)
+	return costNative.Mul(nativePrice).Shift(int32(targetDecimals))
+}
+
+// CheckGasPolicy returns an error when an interval worth sliceValue, in
+// target token base units, should wait for cheaper gas
+func CheckGasPolicy(policy config.GasPolicyConfig, quote *GasQuote, sliceValue decimal.Decimal) error {
+	if !policy.Enabled || quote == nil {
+		return nil
+	}
+
+	if policy.MaxGasPriceGwei > 0 && adapters.IsEVMChain(quote.ChainID) {
+		gwei := quote.GasPrice.Shift(-9)
+		if gwei.GreaterThan(decimal.NewFromFloat(policy.MaxGasPriceGwei)) {
+			return fmt.Errorf("%w: %s gwei exceeds %.2f gwei",
+				ErrGasPriceTooHigh, gwei.StringFixed(2), policy.MaxGasPriceGwei)
+		}
+	}
+
+	if quote.CostQuote.IsPositive() && sliceValue.IsPositive() {
+		ratio := quote.CostQuote.Div(sliceValue)
+		if ratio.GreaterThan(decimal.NewFromFloat(policy.MaxGasCostRatio)) {
+			return fmt.Errorf("%w: %s of slice value exceeds %.4f",
+				ErrGasCostTooHigh, ratio.StringFixed(4), policy.MaxGasCostRatio)
+		}
+	}
+
+	return nil
+}
+
+// CanDeferInterval reports whether the next interval can wait and still
+// leave room for the intervals after it before the order's window closes
+func CanDeferInterval(order *database.Order, remainingIntervals int, now time.Time) bool {
+	if order.ExecutionIntervals <= 0 || remainingIntervals <= 0 {
+		return false
+	}
+
+	window := time.Duration(order.WindowMinutes) * time.Minute
+	interval := window / time.Duration(order.ExecutionIntervals)
+	latest := order.WindowEnd().Add(-time.Duration(remainingIntervals-1) * interval)
+
+	return now.Before(latest)
+}
+
+// deferForGas reports whether an interval should wait for cheaper gas. Gas
+// that cannot be quoted never holds an order back, and nothing is deferred
+// once the window has no room left.
+func (e *Engine) deferForGas(order *database.Order, history []*database.ExecutionRecord, targetAmount, twapPrice decimal.Decimal, remainingIntervals int) bool {
+	if !e.config.GasPolicy.Enabled {
+		return false
+	}
+
+	quote, err := e.quoteGas(order, history, twapPrice)
+	if err != nil {
+		e.logger.Debug("Gas quote unavailable, not deferring",
+			zap.String("order_id", order.ID),
+			zap.Error(err))
+		return false
+	}
+
+	policyErr := CheckGasPolicy(e.config.GasPolicy, quote, TargetValue(order, targetAmount, twapPrice))
+	if policyErr == nil {
+		return false
+	}
+
+	if !CanDeferInterval(order, remainingIntervals, time.Now()) {
+		e.logger.Warn("Executing despite expensive gas, order window has no room to defer",
+			zap.String("order_id", order.ID),
+			zap.Error(policyErr))
+		return false
+	}
+
+	e.logger.Info("Deferring interval for cheaper gas",
+		zap.String("order_id", order.ID),
+		zap.String("gas_cost_native", quote.CostNative.String()),
+		zap.String("gas_cost_quote", quote.CostQuote.String()),
+		zap.Error(policyErr))
+	return true
+}
+
+// quoteGas estimates an interval's gas cost from the target chain's current
+// gas price and the gas the order's past intervals used
+func (e *Engine) quoteGas(order *database.Order, history []*database.ExecutionRecord, twapPrice decimal.Decimal) (*GasQuote, error) {
+	return e.quoteChainGas(order, order.TargetChain, e.expectedIntervalGas(order, history), twapPrice)
+}
+
+// quoteChainGas prices gasUnits at chainID's current gas price, converting
+// the cost to target token base units when the native token has a price
+func (e *Engine) quoteChainGas(order *database.Order, chainID string, gasUnits uint64, sourcePrice decimal.Decimal) (*GasQuote, error) {
+	adapter, err := e.adapterManager.GetAdapter(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := adapter.GetChainStatus()
+	if err != nil {
+		return nil, err
+	}
+	gasPrice, err := decimal.NewFromString(status.GasPrice)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gas price %q: %w", status.GasPrice, err)
+	}
+
+	quote := &GasQuote{
+		ChainID:  chainID,
+		GasPrice: gasPrice,
+		GasUnits: gasUnits,
+	}
+	quote.CostNative = adapters.GasCostInNative(chainID, quote.GasUnits, gasPrice)
+
+	if price, ok := e.nativePriceOn(chainID, order, sourcePrice); ok {
+		quote.CostQuote = gasCostInTarget(order, quote.CostNative, price)
+	}
+
+	return quote, nil
+}
+
+// expectedIntervalGas averages the gas used by the order's past intervals
+func (e *Engine) expectedIntervalGas(order *database.Order, history []*database.ExecutionRecord) uint64 {
+	var total, count int64
+	for _, record := range history {
+		if record.GasUsed != nil && *record.GasUsed > 0 {
+			total += *record.GasUsed
+			count++
+		}
+	}
+	if count > 0 {
+		return uint64(total / count)
+	}
+
+	if order.TargetChain == "ethereum" && e.config.EthereumConfig.GasLimit > 0 {
+		return e.config.EthereumConfig.GasLimit
+	}
+	return defaultIntervalGas
+}
+
+// nativePrice returns the price of the target chain's native token in the
+// order's target token. sourcePrice is the price of the source token.
+func (e *Engine) nativePrice(order *database.Order, sourcePrice decimal.Decimal) (decimal.Decimal, bool) {
+	return e.nativePriceOn(order.TargetChain, order, sourcePrice)
+}
+
+// nativePriceOn returns the price of chainID's native token in the order's
+// target token
+func (e *Engine) nativePriceOn(chainID string, order *database.Order, sourcePrice decimal.Decimal) (decimal.Decimal, bool) {
+	native := adapters.GetNativeTokenSymbol(chainID)
+	switch {
+	case native == "":
+		return decimal.Zero, false
+	case order.TargetToken == native:
+		return decimal.NewFromInt(1), true
+	case order.SourceToken == native:
+		return sourcePrice, sourcePrice.IsPositive()
+	}
+
+	price, err := e.getCurrentPrice(fmt.Sprintf("%s_%s", native, order.TargetToken))
+	if err != nil {
+		return decimal.Zero, false
+	}
+	return price, true
+}
+
+// recordGasSpent adds an interval's gas to the execution record and the
+// order's running totals
+func (e *Engine) recordGasSpent(order *database.Order, record *database.ExecutionRecord, result *adapters.ExecutionResult) {
+	if result.GasCost.IsZero() {
+		return
+	}
+
+	gasPrice := result.GasPrice
+	costNative := result.GasCost
+	record.GasPrice = &gasPrice
+	record.GasCostNative = &costNative
+	order.GasSpentNative = order.GasSpentNative.Add(costNative)
+
+	price, ok := e.nativePrice(order, result.ExecutionPrice)
+	if !ok {
+		e.logger.Debug("No native token price, gas cost not converted",
+			zap.String("order_id", order.ID),
+			zap.String("chain", order.TargetChain))
+		return
+	}
+
+	costQuote := gasCostInTarget(order, costNative, price)
+	record.GasCostQuote = &costQuote
+	order.GasSpentQuote = order.GasSpentQuote.Add(costQuote)
+}
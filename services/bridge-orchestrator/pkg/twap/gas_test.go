@@ -0,0 +1,139 @@
+package twap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+	"flowfusion/bridge-orchestrator/internal/database"
+	"flowfusion/bridge-orchestrator/pkg/adapters"
+)
+
+func TestCheckGasPolicy(t *testing.T) {
+	policy := config.GasPolicyConfig{
+		Enabled:         true,
+		MaxGasPriceGwei: 100,
+		MaxGasCostRatio: 0.02,
+	}
+	gwei := func(g int64) decimal.Decimal { return decimal.NewFromInt(g).Shift(9) }
+
+	tests := []struct {
+		name       string
+		quote      *GasQuote
+		sliceValue float64
+		want       error
+	}{
+		{
+			name:       "cheap gas",
+			quote:      &GasQuote{ChainID: "ethereum", GasPrice: gwei(20), CostQuote: decimal.NewFromInt(5)},
+			sliceValue: 1000,
+		},
+		{
+			name:       "price above ceiling",
+			quote:      &GasQuote{ChainID: "ethereum", GasPrice: gwei(150), CostQuote: decimal.NewFromInt(5)},
+			sliceValue: 1000,
+			want:       ErrGasPriceTooHigh,
+		},
+		{
+			name:       "ceiling only applies to EVM chains",
+			quote:      &GasQuote{ChainID: "cosmos", GasPrice: gwei(150)},
+			sliceValue: 1000,
+		},
+		{
+			name:       "cost too large a share of a small slice",
+			quote:      &GasQuote{ChainID: "ethereum", GasPrice: gwei(20), CostQuote: decimal.NewFromInt(5)},
+			sliceValue: 100,
+			want:       ErrGasCostTooHigh,
+		},
+		{
+			name:       "unpriced native token skips the ratio",
+			quote:      &GasQuote{ChainID: "ethereum", GasPrice: gwei(20)},
+			sliceValue: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckGasPolicy(policy, tt.quote, decimal.NewFromFloat(tt.sliceValue))
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	policy.Enabled = false
+	expensive := &GasQuote{ChainID: "ethereum", GasPrice: gwei(500)}
+	if err := CheckGasPolicy(policy, expensive, decimal.NewFromInt(1)); err != nil {
+		t.Fatalf("disabled policy deferred: %v", err)
+	}
+}
+
+func TestGasPolicyComparesInTargetBaseUnits(t *testing.T) {
+	policy := config.GasPolicyConfig{Enabled: true, MaxGasCostRatio: 0.02}
+	order := &database.Order{
+		SourceChain: "ethereum",
+		SourceToken: "WETH", // 18 decimals
+		TargetChain: "ethereum",
+		TargetToken: "USDC", // 6 decimals
+	}
+	price := decimal.NewFromInt(2500)
+
+	// 200k gas at 20 gwei is 0.004 ETH, 10 USDC
+	costNative := adapters.GasCostInNative("ethereum", 200000, decimal.NewFromInt(20).Shift(9))
+	quote := &GasQuote{ChainID: "ethereum", CostNative: costNative, CostQuote: gasCostInTarget(order, costNative, price)}
+	if !quote.CostQuote.Equal(decimal.NewFromInt(10).Shift(6)) {
+		t.Fatalf("gas cost = %s, want 10 USDC in base units", quote.CostQuote)
+	}
+
+	// 0.1 WETH is worth 250 USDC, so 10 USDC of gas is 4% of it
+	small := TargetValue(order, decimal.RequireFromString("0.1").Shift(18), price)
+	if !small.Equal(decimal.NewFromInt(250).Shift(6)) {
+		t.Fatalf("slice value = %s, want 250 USDC in base units", small)
+	}
+	if err := CheckGasPolicy(policy, quote, small); !errors.Is(err, ErrGasCostTooHigh) {
+		t.Fatalf("got %v, want %v", err, ErrGasCostTooHigh)
+	}
+
+	// 1 WETH is worth 2500 USDC, and the same gas is 0.4% of it
+	large := TargetValue(order, decimal.NewFromInt(1).Shift(18), price)
+	if err := CheckGasPolicy(policy, quote, large); err != nil {
+		t.Fatalf("deferred a slice worth %s: %v", large, err)
+	}
+}
+
+func TestCanDeferInterval(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	order := &database.Order{
+		CreatedAt:          created,
+		WindowMinutes:      60,
+		ExecutionIntervals: 6, // 10 minute intervals
+	}
+	at := func(minutes int) time.Time { return created.Add(time.Duration(minutes) * time.Minute) }
+
+	tests := []struct {
+		name      string
+		remaining int
+		now       time.Time
+		want      bool
+	}{
+		{"first interval on schedule", 6, at(0), true},
+		{"first interval uses up the slack", 6, at(10), false},
+		{"later interval on schedule", 3, at(30), true},
+		{"later interval already behind", 3, at(40), false},
+		{"last interval before the window closes", 1, at(55), true},
+		{"last interval at the close", 1, at(60), false},
+		{"nothing left", 0, at(0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanDeferInterval(order, tt.remaining, tt.now); got != tt.want {
+				t.Fatalf("CanDeferInterval(%d, %s) = %v, want %v",
+					tt.remaining, tt.now.Sub(created), got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package twap
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+// ErrInsufficientCoverage is returned when the price cache does not cover
+// enough of a TWAP window to trust the result
+var ErrInsufficientCoverage = errors.New("insufficient price coverage for TWAP window")
+
+// hydratePriceCache loads recent price points from the database into the
+// in-memory cache for every active token pair, so TWAPs are available
+// immediately after a restart
+func (e *Engine) hydratePriceCache() {
+	for _, pair := range e.trackedPairs() {
+		if err := e.hydratePair(pair); err != nil {
+			e.logger.Error("Failed to hydrate price cache",
+				zap.String("token_pair", pair),
+				zap.Error(err))
+		}
+	}
+}
+
+// hydratePair loads up to MaxWindowMinutes of price history for a pair and
+// merges it into the cache
+func (e *Engine) hydratePair(tokenPair string) error {
+	now := time.Now()
+	since := now.Add(-time.Duration(e.config.TWAPConfig.MaxWindowMinutes) * time.Minute)
+
+	dbPoints, err := e.db.GetPricePoints(tokenPair, since)
+	if err != nil {
+		return fmt.Errorf("failed to load price points: %w", err)
+	}
+
+	points := make([]*PricePoint, 0, len(dbPoints))
+	for _, p := range dbPoints {
+		volume := decimal.Zero
+		if p.Volume != nil {
+			volume = *p.Volume
+		}
+		points = append(points, &PricePoint{
+			Timestamp: p.Timestamp,
+			Price:     p.Price,
+			Volume:    volume,
+			Source:    p.Source,
+		})
+	}
+
+	e.mergePricePoints(tokenPair, points)
+
+	gaps := FindGaps(e.getPricePointsInWindow(tokenPair, since), since, now, e.config.TWAPConfig.MaxPriceGap)
+	for _, gap := range gaps {
+		e.logger.Warn("Gap in price history",
+			zap.String("token_pair", tokenPair),
+			zap.Time("gap_start", gap.Start),
+			zap.Time("gap_end", gap.End),
+			zap.Duration("duration", gap.Duration()))
+	}
+
+	e.logger.Info("Price cache hydrated",
+		zap.String("token_pair", tokenPair),
+		zap.Int("points_loaded", len(points)),
+		zap.Int("gaps", len(gaps)))
+
+	return nil
+}
+
+// trackedPairs returns the feed pairs plus the pairs of all active orders
+func (e *Engine) trackedPairs() []string {
+	orders, err := e.db.GetExecutableOrders()
+	if err != nil {
+		e.logger.Warn("Failed to load active orders for price hydration", zap.Error(err))
+	}
+	return pairsToTrack(orders)
+}
+
+// pairsToTrack returns the feed pairs plus the pairs of orders
+func pairsToTrack(orders []*database.Order) []string {
+	seen := make(map[string]bool)
+	var pairs []string
+	for _, pair := range feedTokenPairs {
+		if !seen[pair] {
+			seen[pair] = true
+			pairs = append(pairs, pair)
+		}
+	}
+
+	for _, order := range orders {
+		pair := orderTokenPair(order)
+		if !seen[pair] {
+			seen[pair] = true
+			pairs = append(pairs, pair)
+		}
+	}
+
+	return pairs
+}
+
+// stalePairs returns those of pairs whose latest cached point is older than
+// MaxPriceGap
+func (e *Engine) stalePairs(pairs []string) []string {
+	e.priceCache.mutex.RLock()
+	defer e.priceCache.mutex.RUnlock()
+
+	cutoff := time.Now().Add(-e.config.TWAPConfig.MaxPriceGap)
+
+	var stale []string
+	for _, pair := range pairs {
+		latest := time.Time{}
+		for _, p := range e.priceCache.data[pair] {
+			if p.Timestamp.After(latest) {
+				latest = p.Timestamp
+			}
+		}
+		if latest.Before(cutoff) {
+			stale = append(stale, pair)
+		}
+	}
+
+	return stale
+}
+
+// mergePricePoints adds points to the cache, skipping duplicates of points
+// already held and dropping anything older than the cache's max age
+func (e *Engine) mergePricePoints(tokenPair string, points []*PricePoint) {
+	e.priceCache.mutex.Lock()
+	defer e.priceCache.mutex.Unlock()
+
+	type pointKey struct {
+		timestamp int64
+		source    string
+	}
+
+	existing := e.priceCache.data[tokenPair]
+	seen := make(map[pointKey]bool, len(existing)+len(points))
+	merged := make([]*PricePoint, 0, len(existing)+len(points))
+
+	cutoff := time.Now().Add(-e.priceCache.maxAge)
+	for _, batch := range [][]*PricePoint{existing, points} {
+		for _, p := range batch {
+			key := pointKey{timestamp: p.Timestamp.UnixNano(), source: p.Source}
+			if seen[key] || !p.Timestamp.After(cutoff) {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, p)
+		}
+	}
+
+	e.priceCache.data[tokenPair] = sortedPoints(merged)
+}
+
+// validatedTWAP calculates the TWAP for a window, refusing when the cache
+// does not cover enough of it
+func (e *Engine) validatedTWAP(tokenPair string, windowMinutes int) (decimal.Decimal, error) {
+	end := time.Now()
+	start := end.Add(-time.Duration(windowMinutes) * time.Minute)
+
+	coverage := WindowCoverage(e.getPricePointsInWindow(tokenPair, start), start, end, e.config.TWAPConfig.MaxPriceGap)
+	if coverage < e.config.TWAPConfig.MinWindowCoverage {
+		return decimal.Zero, fmt.Errorf("%w: %s covered %.0f%% of %d minute window, need %.0f%%",
+			ErrInsufficientCoverage, tokenPair, coverage*100, windowMinutes,
+			e.config.TWAPConfig.MinWindowCoverage*100)
+	}
+
+	return e.calculateTWAP(tokenPair, windowMinutes)
+}
+
+// orderTokenPair returns the price cache key for an order
+func orderTokenPair(order *database.Order) string {
+	return fmt.Sprintf("%s_%s", order.SourceToken, order.TargetToken)
+}
@@ -0,0 +1,39 @@
+package twap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+func TestStalePairsIncludeOrderPairs(t *testing.T) {
+	now := time.Now()
+	engine := &Engine{
+		config:     config.Config{TWAPConfig: config.TWAPConfig{MaxPriceGap: 5 * time.Minute}},
+		logger:     zap.NewNop(),
+		priceCache: &PriceCache{data: make(map[string][]*PricePoint)},
+	}
+	for _, pair := range feedTokenPairs {
+		engine.priceCache.data[pair] = []*PricePoint{{Timestamp: now.Add(-time.Minute), Price: decimal.NewFromInt(1)}}
+	}
+	// Only live orders reference WETH_DAI, and its prices stopped 10
+	// minutes ago
+	engine.priceCache.data["WETH_DAI"] = []*PricePoint{{Timestamp: now.Add(-10 * time.Minute), Price: decimal.NewFromInt(2500)}}
+	orders := []*database.Order{
+		{SourceToken: "ETH", TargetToken: "USDC"},
+		{SourceToken: "WETH", TargetToken: "DAI"},
+	}
+
+	pairs := pairsToTrack(orders)
+	if len(pairs) != len(feedTokenPairs)+1 {
+		t.Fatalf("tracked %v, want the feed pairs and WETH_DAI once each", pairs)
+	}
+	if stale := engine.stalePairs(pairs); len(stale) != 1 || stale[0] != "WETH_DAI" {
+		t.Fatalf("stale pairs = %v, want the order's WETH_DAI", stale)
+	}
+}
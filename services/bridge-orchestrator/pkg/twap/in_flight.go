@@ -0,0 +1,98 @@
+package twap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+// beginInterval marks an interval of the order as executing and returns
+// the func that marks it finished. It fails while the order is held for an
+// amendment.
+func (e *Engine) beginInterval(orderID string) (func(), error) {
+	done := make(chan struct{})
+
+	e.mutex.Lock()
+	if e.held[orderID] {
+		e.mutex.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrOrderBusy, orderID)
+	}
+	e.inFlight[orderID] = done
+	e.mutex.Unlock()
+
+	return func() {
+		e.mutex.Lock()
+		if e.inFlight[orderID] == done {
+			delete(e.inFlight, orderID)
+		}
+		e.mutex.Unlock()
+		close(done)
+	}, nil
+}
+
+// holdOrder stops new intervals of the order from starting until the
+// returned func is called
+func (e *Engine) holdOrder(orderID string) (func(), error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.held[orderID] {
+		return nil, fmt.Errorf("%w: %s", ErrOrderBusy, orderID)
+	}
+	e.held[orderID] = true
+
+	return func() {
+		e.mutex.Lock()
+		delete(e.held, orderID)
+		e.mutex.Unlock()
+	}, nil
+}
+
+// SettleInterval waits for an interval of the order that is executing to
+// finish, so its fill is recorded before the order is cancelled. Intervals
+// that start afterwards see the order's new status and do not execute.
+func (e *Engine) SettleInterval(ctx context.Context, orderID string) error {
+	e.mutex.RLock()
+	done, ok := e.inFlight[orderID]
+	e.mutex.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	e.logger.Info("Waiting for in-flight interval to settle", zap.String("order_id", orderID))
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// saveFill persists an interval's fill. If the order's status changed while
+// the interval was executing, the fill is applied to the current order and
+// the interval's own status change is dropped.
+func (e *Engine) saveFill(order *database.Order) error {
+	err := e.db.UpdateOrder(order)
+	if !errors.Is(err, database.ErrStatusConflict) {
+		return err
+	}
+
+	current, err := e.db.GetOrder(order.ID)
+	if err != nil {
+		return err
+	}
+	e.logger.Info("Order status changed during interval, recording fill only",
+		zap.String("order_id", order.ID),
+		zap.String("status", current.Status))
+
+	current.ExecutedAmount = order.ExecutedAmount
+	current.LastExecution = order.LastExecution
+	current.AveragePrice = order.AveragePrice
+	current.GasSpentNative = order.GasSpentNative
+	current.GasSpentQuote = order.GasSpentQuote
+	return e.db.UpdateOrder(current)
+}
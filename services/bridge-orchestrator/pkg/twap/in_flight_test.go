@@ -0,0 +1,69 @@
+package twap
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSettleIntervalWaitsForInFlight(t *testing.T) {
+	engine := &Engine{logger: zap.NewNop(), inFlight: make(map[string]chan struct{}), held: make(map[string]bool)}
+
+	if err := engine.SettleInterval(context.Background(), "order-1"); err != nil {
+		t.Fatalf("nothing in flight, got %v", err)
+	}
+
+	finish, err := engine.beginInterval("order-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	settled := make(chan error, 1)
+	go func() { settled <- engine.SettleInterval(context.Background(), "order-1") }()
+
+	select {
+	case err := <-settled:
+		t.Fatalf("settled while the interval was in flight: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	finish()
+	select {
+	case err := <-settled:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("not settled after the interval finished")
+	}
+
+	// A caller that gives up is not held by a stuck interval
+	engine.beginInterval("order-2")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := engine.SettleInterval(ctx, "order-2"); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestHeldOrderDoesNotStartIntervals(t *testing.T) {
+	engine := &Engine{logger: zap.NewNop(), inFlight: make(map[string]chan struct{}), held: make(map[string]bool)}
+
+	release, err := engine.holdOrder("order-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := engine.holdOrder("order-1"); !errors.Is(err, ErrOrderBusy) {
+		t.Fatalf("expected a second hold to fail, got %v", err)
+	}
+	if _, err := engine.beginInterval("order-1"); !errors.Is(err, ErrOrderBusy) {
+		t.Fatalf("expected ErrOrderBusy, got %v", err)
+	}
+
+	release()
+	if _, err := engine.beginInterval("order-1"); err != nil {
+		t.Fatalf("interval blocked after release: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package twap
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+// ErrBelowMinOutput is returned when an interval cannot be filled at or above
+// its minimum output at the current market price
+var ErrBelowMinOutput = errors.New("interval cannot meet minimum output")
+
+// IntervalMinOutput returns the least target output an interval of amount
+// may accept. It is the stricter of two floors:
+//
+//   - the interval's pro rata share of what the order still needs to reach
+//     MinReceived, so earlier surplus relaxes later intervals and the order
+//     as a whole cannot finish below its floor
+//   - the amount valued at the TWAP less the order's slippage tolerance
+//
+// Like MinReceived, it is in target token base units.
+func IntervalMinOutput(order *database.Order, amount, twapPrice decimal.Decimal) decimal.Decimal {
+	floor := decimal.Zero
+
+	outstanding := order.MinReceived.Sub(ReceivedValue(order))
+	remaining := order.GetRemainingAmount()
+	if outstanding.IsPositive() {
+		if remaining.GreaterThan(amount) {
+			floor = outstanding.Mul(amount).Div(remaining)
+		} else {
+			floor = outstanding
+		}
+	}
+
+	tolerance := decimal.NewFromInt(int64(10000 - order.MaxSlippage)).Div(decimal.NewFromInt(10000))
+	if slippageFloor := TargetValue(order, amount, twapPrice).Mul(tolerance); slippageFloor.GreaterThan(floor) {
+		floor = slippageFloor
+	}
+
+	return floor
+}
+
+// ReceivedValue estimates the target token base units an order has
+// received from its executed amount and average price
+func ReceivedValue(order *database.Order) decimal.Decimal {
+	return TargetValue(order, order.ExecutedAmount, order.AveragePrice)
+}
+
+// finalizeOrder marks an order whose intervals are exhausted as completed,
+// unless it received less than MinReceived, in which case it is left
+// partially filled so the remainder can be cancelled and refunded
+func (e *Engine) finalizeOrder(order *database.Order) {
+	next, cause := database.OrderStatusCompleted, "all intervals filled"
+	if received := ReceivedValue(order); received.LessThan(order.MinReceived) {
+		e.logger.Warn("Order finished below its minimum received, not completing",
+			zap.String("order_id", order.ID),
+			zap.String("received", received.String()),
+			zap.String("min_received", order.MinReceived.String()))
+		next, cause = database.OrderStatusPartiallyFilled, "intervals exhausted below minimum received"
+	}
+
+	if err := order.Transition(next, database.ActorSystem, cause); err != nil {
+		e.logger.Error("Failed to finalize order",
+			zap.String("order_id", order.ID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,166 @@
+package twap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+func TestIntervalMinOutput(t *testing.T) {
+	d := decimal.NewFromFloat
+
+	tests := []struct {
+		name   string
+		order  *database.Order
+		amount float64
+		twap   float64
+		want   float64
+	}{
+		{
+			name: "pro rata share of min received",
+			order: &database.Order{
+				SourceAmount: d(10),
+				MinReceived:  d(20000),
+				MaxSlippage:  100,
+			},
+			amount: 2,
+			twap:   1500,
+			want:   4000, // 2/10 of 20000, above 2*1500*0.99
+		},
+		{
+			name: "slippage floor when it is stricter",
+			order: &database.Order{
+				SourceAmount: d(10),
+				MinReceived:  d(10000),
+				MaxSlippage:  100,
+			},
+			amount: 2,
+			twap:   2000,
+			want:   3960, // 2*2000*0.99
+		},
+		{
+			name: "earlier surplus relaxes the floor",
+			order: &database.Order{
+				SourceAmount:   d(10),
+				MinReceived:    d(20000),
+				ExecutedAmount: d(5),
+				AveragePrice:   d(3500),
+				MaxSlippage:    100,
+			},
+			amount: 1,
+			twap:   1000,
+			want:   990, // 2500 outstanding over 5 remaining is below 1*1000*0.99
+		},
+		{
+			name: "earlier shortfall raises the floor",
+			order: &database.Order{
+				SourceAmount:   d(10),
+				MinReceived:    d(20000),
+				ExecutedAmount: d(5),
+				AveragePrice:   d(1800),
+				MaxSlippage:    100,
+			},
+			amount: 1,
+			twap:   1000,
+			want:   2200, // 9000 received, 11000 outstanding over 5 remaining
+		},
+		{
+			name: "last interval owes the whole shortfall",
+			order: &database.Order{
+				SourceAmount:   d(10),
+				MinReceived:    d(20000),
+				ExecutedAmount: d(9),
+				AveragePrice:   d(2000),
+				MaxSlippage:    100,
+			},
+			amount: 1,
+			twap:   1000,
+			want:   2000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IntervalMinOutput(tt.order, d(tt.amount), d(tt.twap))
+			assertDecimal(t, got, d(tt.want))
+		})
+	}
+}
+
+func TestIntervalMinOutputAcrossDecimals(t *testing.T) {
+	units := func(v string, decimals int32) decimal.Decimal { return decimal.RequireFromString(v).Shift(decimals) }
+
+	// WETH has 18 decimals and USDC 6; MinReceived and the floor are in
+	// the target token's base units
+	sell := &database.Order{
+		SourceChain:    "ethereum",
+		SourceToken:    "WETH",
+		TargetChain:    "ethereum",
+		TargetToken:    "USDC",
+		SourceAmount:   units("10", 18),
+		MinReceived:    units("20000", 6),
+		ExecutedAmount: units("5", 18),
+		AveragePrice:   decimal.NewFromInt(1800),
+		MaxSlippage:    100,
+	}
+	if received := ReceivedValue(sell); !received.Equal(units("9000", 6)) {
+		t.Fatalf("received %s, want 9000 USDC in base units", received)
+	}
+	// 11000 USDC outstanding over 5 WETH remaining
+	assertDecimal(t, IntervalMinOutput(sell, units("1", 18), decimal.NewFromInt(1000)), units("2200", 6))
+	// 2 WETH at 3000 less 1%, above their 4400 share
+	assertDecimal(t, IntervalMinOutput(sell, units("2", 18), decimal.NewFromInt(3000)), units("5940", 6))
+
+	buy := &database.Order{
+		SourceChain:  "ethereum",
+		SourceToken:  "USDC",
+		TargetChain:  "ethereum",
+		TargetToken:  "WETH",
+		SourceAmount: units("10000", 6),
+		MaxSlippage:  100,
+	}
+	// 2000 USDC at 0.0004 WETH less 1%
+	assertDecimal(t, IntervalMinOutput(buy, units("2000", 6), decimal.RequireFromString("0.0004")), units("0.792", 18))
+}
+
+func TestFinalizeOrderTransitions(t *testing.T) {
+	d := decimal.NewFromFloat
+	engine := &Engine{logger: zap.NewNop()}
+
+	order := &database.Order{
+		Status:         string(database.OrderStatusExecuting),
+		ExecutedAmount: d(10),
+		AveragePrice:   d(2000),
+		MinReceived:    d(25000),
+	}
+	engine.finalizeOrder(order)
+	if order.Status != string(database.OrderStatusPartiallyFilled) {
+		t.Fatalf("order below min received finalized as %s", order.Status)
+	}
+	events := order.PendingEvents()
+	if len(events) != 1 || events[0].FromStatus != string(database.OrderStatusExecuting) ||
+		events[0].Actor != database.ActorSystem {
+		t.Fatalf("transition not recorded: %+v", events)
+	}
+
+	// A partially filled order can only be cancelled, expired or refunded
+	if err := order.Transition(database.OrderStatusExecuting, database.ActorChain, "late event"); !errors.Is(err, database.ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+
+	// A cancelled order stays cancelled when its last interval lands
+	cancelled := &database.Order{
+		Status:         string(database.OrderStatusCancelled),
+		ExecutedAmount: d(10),
+		AveragePrice:   d(2000),
+		MinReceived:    d(15000),
+	}
+	engine.finalizeOrder(cancelled)
+	if cancelled.Status != string(database.OrderStatusCancelled) || len(cancelled.PendingEvents()) != 0 {
+		t.Fatalf("cancelled order finalized: %+v", cancelled)
+	}
+}
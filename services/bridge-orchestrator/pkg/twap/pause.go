@@ -0,0 +1,129 @@
+package twap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+// Pause errors
+var (
+	ErrOrderNotPausable  = errors.New("order cannot be paused in its current status")
+	ErrOrderNotResumable = errors.New("order is not paused by its user")
+)
+
+// PauseOrder pauses a live order at its user's request. Any interval in
+// flight settles first. An order the engine paused for chain health is
+// handed over to the user, so it stays paused after the chains recover.
+func (e *Engine) PauseOrder(ctx context.Context, orderID, reason string) (*database.Order, error) {
+	release, err := e.holdOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := e.SettleInterval(ctx, orderID); err != nil {
+		return nil, fmt.Errorf("in-flight interval did not settle: %w", err)
+	}
+
+	order, err := e.db.GetOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	switch {
+	case order.IsPaused():
+		if order.PausedBy != nil && *order.PausedBy == database.PausedByUser {
+			return order, nil
+		}
+		by := database.PausedByUser
+		order.PausedBy = &by
+		order.PauseReason = &reason
+		order.UpdatedAt = now
+	case order.IsExecutable():
+		if err := order.Pause(database.PausedByUser, reason, now); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotPausable, order.Status)
+	}
+
+	if err := e.db.UpdateOrder(order); err != nil {
+		return nil, fmt.Errorf("failed to pause order: %w", err)
+	}
+
+	e.logger.Info("Order paused by user",
+		zap.String("order_id", order.ID),
+		zap.String("reason", reason))
+
+	return order, nil
+}
+
+// ResumeOrder resumes an order its user paused and re-plans the remaining
+// intervals over the remaining window according to mode
+func (e *Engine) ResumeOrder(orderID string, mode database.ResumeMode) (*database.Order, Schedule, error) {
+	order, err := e.db.GetOrder(orderID)
+	if err != nil {
+		return nil, Schedule{}, err
+	}
+	if !order.IsPaused() || order.PausedBy == nil || *order.PausedBy != database.PausedByUser {
+		return nil, Schedule{}, fmt.Errorf("%w: %s", ErrOrderNotResumable, order.Status)
+	}
+	if order.IsTimedOut() {
+		return nil, Schedule{}, fmt.Errorf("%w: order has expired", ErrOrderNotResumable)
+	}
+
+	history, err := e.db.GetExecutionHistory(orderID)
+	if err != nil {
+		return nil, Schedule{}, fmt.Errorf("failed to get execution history: %w", err)
+	}
+
+	now := time.Now()
+	if err := order.Resume(mode, database.ActorUser, fmt.Sprintf("resumed by user (%s)", mode), now); err != nil {
+		return nil, Schedule{}, err
+	}
+	if err := e.db.UpdateOrder(order); err != nil {
+		return nil, Schedule{}, fmt.Errorf("failed to resume order: %w", err)
+	}
+
+	schedule := RemainingSchedule(order, len(history), now)
+	e.logger.Info("Order resumed by user",
+		zap.String("order_id", order.ID),
+		zap.String("mode", string(mode)),
+		zap.Int("remaining_intervals", schedule.RemainingIntervals),
+		zap.Time("window_end", schedule.WindowEnd))
+
+	return order, schedule, nil
+}
+
+// PlannedIntervals is how many of the order's remaining intervals still fit
+// before its window ends. Intervals keep their spacing, which the bridge
+// contract enforces, so a window shortened by a compressed resume is
+// covered by fewer, larger intervals. At least one interval is planned.
+func PlannedIntervals(order *database.Order, remainingIntervals int, now time.Time) int {
+	if remainingIntervals <= 1 || order.ExecutionIntervals <= 0 {
+		return remainingIntervals
+	}
+
+	next := order.GetNextExecutionTime()
+	if next.Before(now) {
+		next = now
+	}
+	spacing := time.Duration(order.WindowMinutes/order.ExecutionIntervals) * time.Minute
+	left := order.WindowEnd().Sub(next)
+	if left < 0 || spacing <= 0 {
+		return 1
+	}
+
+	fit := 1 + int(left/spacing)
+	if fit < remainingIntervals {
+		return fit
+	}
+	return remainingIntervals
+}
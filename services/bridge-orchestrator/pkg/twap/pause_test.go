@@ -0,0 +1,96 @@
+package twap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+func TestResumeReplansSchedule(t *testing.T) {
+	created := time.Now().Add(-time.Hour)
+	lastExecution := created.Add(20 * time.Minute)
+	pausedAt := created.Add(25 * time.Minute)
+	resumedAt := created.Add(60 * time.Minute)
+
+	// 6 intervals of 10 minutes over an hour-long window; 2 have run, then
+	// the user paused for 35 minutes
+	pausedOrder := func() *database.Order {
+		last := lastExecution
+		order := &database.Order{
+			Status:             string(database.OrderStatusExecuting),
+			CreatedAt:          created,
+			SourceAmount:       decimal.NewFromInt(600),
+			ExecutedAmount:     decimal.NewFromInt(200),
+			WindowMinutes:      60,
+			ExecutionIntervals: 6,
+			LastExecution:      &last,
+		}
+		if err := order.Pause(database.PausedByUser, "waiting for a better market", pausedAt); err != nil {
+			t.Fatalf("pause rejected: %v", err)
+		}
+		return order
+	}
+
+	extended := pausedOrder()
+	if err := extended.Resume(database.ResumeExtend, database.ActorUser, "resumed", resumedAt); err != nil {
+		t.Fatalf("resume rejected: %v", err)
+	}
+	schedule := RemainingSchedule(extended, 2, resumedAt)
+	if schedule.RemainingIntervals != 4 || !schedule.IntervalAmount.Equal(decimal.NewFromInt(100)) {
+		t.Fatalf("extend should keep all 4 intervals: %+v", schedule)
+	}
+	if !schedule.WindowEnd.Equal(created.Add(95 * time.Minute)) {
+		t.Fatalf("extend should push the window end out by the pause: %s", schedule.WindowEnd)
+	}
+
+	compressed := pausedOrder()
+	if err := compressed.Resume(database.ResumeCompress, database.ActorUser, "resumed", resumedAt); err != nil {
+		t.Fatalf("resume rejected: %v", err)
+	}
+	if compressed.PausedSeconds != 0 || compressed.IsPaused() {
+		t.Fatalf("compress should not extend the window: %+v", compressed)
+	}
+	// Nothing fits after the window closes but one final interval
+	if schedule := RemainingSchedule(compressed, 2, resumedAt); schedule.RemainingIntervals != 1 ||
+		!schedule.IntervalAmount.Equal(decimal.NewFromInt(400)) {
+		t.Fatalf("compress past the window end should plan a single interval: %+v", schedule)
+	}
+	// Resumed with 25 minutes left, 3 intervals fit 10 minutes apart
+	if schedule := RemainingSchedule(compressed, 2, created.Add(35*time.Minute)); schedule.RemainingIntervals != 3 {
+		t.Fatalf("compress should plan the intervals that fit: %+v", schedule)
+	}
+
+	if err := pausedOrder().Resume("shuffle", database.ActorUser, "resumed", resumedAt); err == nil {
+		t.Fatalf("unknown resume mode accepted")
+	}
+}
+
+func TestPlannedIntervals(t *testing.T) {
+	created := time.Now().Add(-30 * time.Minute)
+	order := &database.Order{
+		CreatedAt:          created,
+		WindowMinutes:      60,
+		ExecutionIntervals: 6,
+	}
+
+	tests := []struct {
+		name      string
+		remaining int
+		now       time.Time
+		want      int
+	}{
+		{"all fit", 3, created.Add(30 * time.Minute), 3},
+		{"capped by window", 6, created.Add(30 * time.Minute), 4},
+		{"last moment", 4, created.Add(60 * time.Minute), 1},
+		{"past window", 4, created.Add(90 * time.Minute), 1},
+		{"none left", 0, created.Add(30 * time.Minute), 0},
+	}
+	for _, tt := range tests {
+		if got := PlannedIntervals(order, tt.remaining, tt.now); got != tt.want {
+			t.Errorf("%s: got %d intervals, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -14,6 +14,9 @@ import (
 	"flowfusion/bridge-orchestrator/internal/database"
 )
 
+// feedTokenPairs are the token pairs polled by updatePriceFeeds
+var feedTokenPairs = []string{"ETH_USDC", "ATOM_USDC", "XLM_USDC", "BTC_USDC"}
+
 // PriceFeedConfig holds configuration for price feed sources
 type PriceFeedConfig struct {
 	CoinGeckoAPIKey string
@@ -264,7 +267,7 @@ func (e *Engine) updatePriceFeeds() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
-	tokenPairs := []string{"ETH_USDC", "ATOM_USDC", "XLM_USDC", "BTC_USDC"}
+	tokenPairs := feedTokenPairs
 	
 	var lastError error
 	successCount := 0
@@ -0,0 +1,202 @@
+package twap
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Pricing errors
+var (
+	ErrNoPriceData      = errors.New("no price data in window")
+	ErrNoVolumeData     = errors.New("no volume data in window")
+	ErrNonPositivePrice = errors.New("price must be positive for geometric mean")
+)
+
+// weightedPrice is a price together with the time it was in effect
+type weightedPrice struct {
+	price  decimal.Decimal
+	weight time.Duration
+}
+
+// TimeWeightedAverage calculates the arithmetic TWAP over [start, end].
+//
+// Prices are treated as a step function: each point holds from its own
+// timestamp until the next point, and the last point holds until end. The
+// most recent point before start carries its price into the window, so a
+// window is fully covered as long as one observation precedes it.
+func TimeWeightedAverage(points []*PricePoint, start, end time.Time) (decimal.Decimal, error) {
+	segments, err := stepSegments(points, start, end)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	var totalValue, totalWeight decimal.Decimal
+	for _, s := range segments {
+		weight := decimal.NewFromInt(int64(s.weight))
+		totalValue = totalValue.Add(s.price.Mul(weight))
+		totalWeight = totalWeight.Add(weight)
+	}
+
+	return totalValue.Div(totalWeight), nil
+}
+
+// GeometricTimeWeightedAverage calculates the time-weighted geometric mean
+// price over [start, end], the same way on-chain oracles accumulate
+// log-prices. It is less sensitive to short-lived spikes than the
+// arithmetic TWAP.
+func GeometricTimeWeightedAverage(points []*PricePoint, start, end time.Time) (decimal.Decimal, error) {
+	segments, err := stepSegments(points, start, end)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	var logSum, totalWeight float64
+	for _, s := range segments {
+		if !s.price.IsPositive() {
+			return decimal.Zero, ErrNonPositivePrice
+		}
+		price, _ := s.price.Float64()
+		weight := s.weight.Seconds()
+		logSum += math.Log(price) * weight
+		totalWeight += weight
+	}
+
+	return decimal.NewFromFloat(math.Exp(logSum / totalWeight)), nil
+}
+
+// VolumeWeightedAverage calculates the VWAP of the points observed within
+// [start, end]. Points without volume do not contribute.
+func VolumeWeightedAverage(points []*PricePoint, start, end time.Time) (decimal.Decimal, error) {
+	var totalValue, totalVolume decimal.Decimal
+	for _, p := range points {
+		if p.Timestamp.Before(start) || p.Timestamp.After(end) {
+			continue
+		}
+		if !p.Volume.IsPositive() {
+			continue
+		}
+		totalValue = totalValue.Add(p.Price.Mul(p.Volume))
+		totalVolume = totalVolume.Add(p.Volume)
+	}
+
+	if totalVolume.IsZero() {
+		return decimal.Zero, ErrNoVolumeData
+	}
+
+	return totalValue.Div(totalVolume), nil
+}
+
+// stepSegments splits [start, end] into the intervals during which each
+// price point was the latest observation. Time before the first observation
+// is left uncovered rather than back-filled.
+func stepSegments(points []*PricePoint, start, end time.Time) ([]weightedPrice, error) {
+	sorted := sortedPoints(points)
+
+	var segments []weightedPrice
+	var lastInWindow *PricePoint
+	for i, p := range sorted {
+		if p.Timestamp.After(end) {
+			break
+		}
+		if !p.Timestamp.Before(start) {
+			lastInWindow = p
+		}
+
+		segStart := p.Timestamp
+		if segStart.Before(start) {
+			segStart = start
+		}
+		segEnd := end
+		if i+1 < len(sorted) && sorted[i+1].Timestamp.Before(end) {
+			segEnd = sorted[i+1].Timestamp
+		}
+
+		if segEnd.After(segStart) {
+			segments = append(segments, weightedPrice{price: p.Price, weight: segEnd.Sub(segStart)})
+		}
+	}
+
+	if len(segments) == 0 {
+		// A single observation exactly at the end of the window has no
+		// duration; report it rather than failing.
+		if lastInWindow != nil {
+			return []weightedPrice{{price: lastInWindow.Price, weight: time.Nanosecond}}, nil
+		}
+		return nil, ErrNoPriceData
+	}
+
+	return segments, nil
+}
+
+// sortedPoints returns the points ordered by timestamp without modifying
+// the caller's slice
+func sortedPoints(points []*PricePoint) []*PricePoint {
+	sorted := make([]*PricePoint, 0, len(points))
+	for _, p := range points {
+		if p != nil {
+			sorted = append(sorted, p)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+	return sorted
+}
+
+// PriceGap is a stretch of time in which no fresh observation was available
+type PriceGap struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Duration returns the length of the gap
+func (g PriceGap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+// FindGaps returns the parts of [start, end] in which the latest observation
+// was older than maxGap. A point before start counts toward coverage of the
+// beginning of the window, as it does for TimeWeightedAverage.
+func FindGaps(points []*PricePoint, start, end time.Time, maxGap time.Duration) []PriceGap {
+	sorted := sortedPoints(points)
+
+	var gaps []PriceGap
+	coveredUntil := start
+	for _, p := range sorted {
+		if p.Timestamp.After(end) {
+			break
+		}
+		if p.Timestamp.After(coveredUntil) {
+			gaps = append(gaps, PriceGap{Start: coveredUntil, End: p.Timestamp})
+		}
+		if fresh := p.Timestamp.Add(maxGap); fresh.After(coveredUntil) {
+			coveredUntil = fresh
+		}
+	}
+
+	if coveredUntil.Before(end) {
+		gaps = append(gaps, PriceGap{Start: coveredUntil, End: end})
+	}
+
+	return gaps
+}
+
+// WindowCoverage returns the fraction of [start, end] covered by fresh
+// observations, between 0 and 1
+func WindowCoverage(points []*PricePoint, start, end time.Time, maxGap time.Duration) float64 {
+	window := end.Sub(start)
+	if window <= 0 {
+		return 0
+	}
+
+	uncovered := time.Duration(0)
+	for _, gap := range FindGaps(points, start, end, maxGap) {
+		uncovered += gap.Duration()
+	}
+
+	return 1 - uncovered.Seconds()/window.Seconds()
+}
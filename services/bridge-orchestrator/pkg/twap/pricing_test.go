@@ -0,0 +1,316 @@
+package twap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var testEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func at(minutes int) time.Time {
+	return testEpoch.Add(time.Duration(minutes) * time.Minute)
+}
+
+func point(minutes int, price, volume float64) *PricePoint {
+	return &PricePoint{
+		Timestamp: at(minutes),
+		Price:     decimal.NewFromFloat(price),
+		Volume:    decimal.NewFromFloat(volume),
+	}
+}
+
+func assertDecimal(t *testing.T, got, want decimal.Decimal) {
+	t.Helper()
+	if got.Sub(want).Abs().GreaterThan(decimal.NewFromFloat(1e-9)) {
+		t.Fatalf("got %s, want %s", got.String(), want.String())
+	}
+}
+
+func TestTimeWeightedAverage(t *testing.T) {
+	tests := []struct {
+		name    string
+		points  []*PricePoint
+		start   time.Time
+		end     time.Time
+		want    float64
+		wantErr error
+	}{
+		{
+			name:   "single point covering whole window",
+			points: []*PricePoint{point(0, 100, 0)},
+			start:  at(0),
+			end:    at(10),
+			want:   100,
+		},
+		{
+			name:   "each price weighted by the time it held",
+			points: []*PricePoint{point(0, 100, 0), point(2, 200, 0)},
+			start:  at(0),
+			end:    at(10),
+			want:   180, // 100 for 2m, 200 for 8m
+		},
+		{
+			name:   "last point holds until end of window",
+			points: []*PricePoint{point(0, 100, 0), point(9, 200, 0)},
+			start:  at(0),
+			end:    at(10),
+			want:   110,
+		},
+		{
+			name:   "point before window carries into it",
+			points: []*PricePoint{point(-5, 100, 0), point(5, 200, 0)},
+			start:  at(0),
+			end:    at(10),
+			want:   150,
+		},
+		{
+			name:   "older points are superseded by the carry-in",
+			points: []*PricePoint{point(-10, 50, 0), point(-5, 100, 0), point(5, 200, 0)},
+			start:  at(0),
+			end:    at(10),
+			want:   150,
+		},
+		{
+			name:   "uncovered start of window is not back-filled",
+			points: []*PricePoint{point(5, 100, 0), point(8, 200, 0)},
+			start:  at(0),
+			end:    at(10),
+			want:   140, // 100 for 3m, 200 for 2m
+		},
+		{
+			name:   "points after end are ignored",
+			points: []*PricePoint{point(0, 100, 0), point(11, 500, 0)},
+			start:  at(0),
+			end:    at(10),
+			want:   100,
+		},
+		{
+			name:   "unsorted input",
+			points: []*PricePoint{point(2, 200, 0), point(0, 100, 0)},
+			start:  at(0),
+			end:    at(10),
+			want:   180,
+		},
+		{
+			name:   "single point exactly at end",
+			points: []*PricePoint{point(10, 100, 0)},
+			start:  at(0),
+			end:    at(10),
+			want:   100,
+		},
+		{
+			name:    "no points",
+			points:  nil,
+			start:   at(0),
+			end:     at(10),
+			wantErr: ErrNoPriceData,
+		},
+		{
+			name:    "only points after end",
+			points:  []*PricePoint{point(11, 100, 0)},
+			start:   at(0),
+			end:     at(10),
+			wantErr: ErrNoPriceData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TimeWeightedAverage(tt.points, tt.start, tt.end)
+			if tt.wantErr != nil {
+				if err != tt.wantErr {
+					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertDecimal(t, got, decimal.NewFromFloat(tt.want))
+		})
+	}
+}
+
+func TestGeometricTimeWeightedAverage(t *testing.T) {
+	tests := []struct {
+		name    string
+		points  []*PricePoint
+		end     time.Time
+		want    float64
+		wantErr error
+	}{
+		{
+			name:   "constant price",
+			points: []*PricePoint{point(0, 100, 0), point(5, 100, 0)},
+			end:    at(10),
+			want:   100,
+		},
+		{
+			name:   "equal time at two prices",
+			points: []*PricePoint{point(0, 100, 0), point(5, 400, 0)},
+			end:    at(10),
+			want:   200, // sqrt(100 * 400)
+		},
+		{
+			name:   "weighted by duration",
+			points: []*PricePoint{point(0, 1, 0), point(10, 1000, 0)},
+			end:    at(15),
+			want:   10, // exp((ln(1)*10 + ln(1000)*5) / 15)
+		},
+		{
+			name:    "non-positive price",
+			points:  []*PricePoint{point(0, 0, 0)},
+			end:     at(10),
+			wantErr: ErrNonPositivePrice,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GeometricTimeWeightedAverage(tt.points, at(0), tt.end)
+			if tt.wantErr != nil {
+				if err != tt.wantErr {
+					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Sub(decimal.NewFromFloat(tt.want)).Abs().GreaterThan(decimal.NewFromFloat(1e-6)) {
+				t.Fatalf("got %s, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestVolumeWeightedAverage(t *testing.T) {
+	tests := []struct {
+		name    string
+		points  []*PricePoint
+		want    float64
+		wantErr error
+	}{
+		{
+			name:   "weighted by volume",
+			points: []*PricePoint{point(1, 100, 1), point(2, 200, 3)},
+			want:   175,
+		},
+		{
+			name:   "points without volume are skipped",
+			points: []*PricePoint{point(1, 100, 2), point(2, 1000, 0)},
+			want:   100,
+		},
+		{
+			name:   "points outside window are skipped",
+			points: []*PricePoint{point(-1, 1000, 5), point(1, 100, 1), point(11, 1000, 5)},
+			want:   100,
+		},
+		{
+			name:    "no volume",
+			points:  []*PricePoint{point(1, 100, 0)},
+			wantErr: ErrNoVolumeData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VolumeWeightedAverage(tt.points, at(0), at(10))
+			if tt.wantErr != nil {
+				if err != tt.wantErr {
+					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertDecimal(t, got, decimal.NewFromFloat(tt.want))
+		})
+	}
+}
+
+func TestWindowCoverage(t *testing.T) {
+	tests := []struct {
+		name     string
+		points   []*PricePoint
+		wantGaps int
+		want     float64
+	}{
+		{
+			name:     "regular updates",
+			points:   []*PricePoint{point(0, 1, 0), point(2, 1, 0), point(4, 1, 0), point(6, 1, 0), point(8, 1, 0)},
+			wantGaps: 0,
+			want:     1,
+		},
+		{
+			name:     "carry-in covers start of window",
+			points:   []*PricePoint{point(-1, 1, 0), point(2, 1, 0), point(4, 1, 0), point(6, 1, 0), point(8, 1, 0)},
+			wantGaps: 0,
+			want:     1,
+		},
+		{
+			name:     "gap in the middle",
+			points:   []*PricePoint{point(0, 1, 0), point(7, 1, 0), point(9, 1, 0)},
+			wantGaps: 1,
+			want:     0.6, // 3m to 7m uncovered
+		},
+		{
+			name:     "feed stopped",
+			points:   []*PricePoint{point(0, 1, 0), point(2, 1, 0)},
+			wantGaps: 1,
+			want:     0.5,
+		},
+		{
+			name:     "no data",
+			points:   nil,
+			wantGaps: 1,
+			want:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gaps := FindGaps(tt.points, at(0), at(10), 3*time.Minute)
+			if len(gaps) != tt.wantGaps {
+				t.Fatalf("expected %d gaps, got %d: %v", tt.wantGaps, len(gaps), gaps)
+			}
+			got := WindowCoverage(tt.points, at(0), at(10), 3*time.Minute)
+			if got < tt.want-1e-9 || got > tt.want+1e-9 {
+				t.Fatalf("got coverage %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTWAPDataPriceChangeWithinWindow(t *testing.T) {
+	now := time.Now()
+	ago := func(minutes int) time.Time { return now.Add(-time.Duration(minutes) * time.Minute) }
+	engine := &Engine{priceCache: &PriceCache{data: map[string][]*PricePoint{
+		"ETH_USDC": {
+			// Carried into the window's TWAP, but outside the window
+			{Timestamp: ago(70), Price: decimal.NewFromInt(1000)},
+			{Timestamp: ago(50), Price: decimal.NewFromInt(2000)},
+			{Timestamp: ago(10), Price: decimal.NewFromInt(2200)},
+		},
+	}}}
+
+	data, err := engine.calculateTWAPData("ETH_USDC", 60)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertDecimal(t, data.PriceChange, decimal.NewFromInt(200))
+	assertDecimal(t, data.PriceChangePercent, decimal.NewFromInt(10))
+	if data.NumDataPoints != 2 {
+		t.Fatalf("counted %d points, want the 2 in the window", data.NumDataPoints)
+	}
+
+	// With only the carried price there is no change across the window
+	engine.priceCache.data["ETH_USDC"] = engine.priceCache.data["ETH_USDC"][:1]
+	if data, err = engine.calculateTWAPData("ETH_USDC", 60); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertDecimal(t, data.PriceChange, decimal.Zero)
+}
@@ -0,0 +1,234 @@
+package twap
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/database"
+	"flowfusion/bridge-orchestrator/pkg/adapters"
+)
+
+// PlannedInterval is one interval of an order's execution plan
+type PlannedInterval struct {
+	Number    int              `json:"number"`
+	At        time.Time        `json:"at"`
+	Amount    decimal.Decimal  `json:"amount"`
+	MinOutput *decimal.Decimal `json:"min_output,omitempty"` // unset without a price
+}
+
+// GasEstimate is the expected gas cost of an order's transactions on one
+// chain. CostQuote is in target token base units.
+type GasEstimate struct {
+	ChainID      string           `json:"chain_id"`
+	Purpose      string           `json:"purpose"`
+	Transactions int              `json:"transactions"`
+	GasUnits     uint64           `json:"gas_units"` // per transaction
+	GasPrice     decimal.Decimal  `json:"gas_price"`
+	NativeToken  string           `json:"native_token"`
+	CostNative   decimal.Decimal  `json:"cost_native"`
+	CostQuote    *decimal.Decimal `json:"cost_quote,omitempty"`
+}
+
+// Quote previews what the engine would do with an order submitted now.
+// Outputs are in target token base units. ExpectedOutput values the plan
+// at the spot price; NetExpectedOutput takes off the protocol fee and the
+// gas that could be priced. WorstCaseOutput is the least the intervals'
+// minimum outputs let the order receive.
+type Quote struct {
+	TokenPair         string            `json:"token_pair"`
+	SpotPrice         *decimal.Decimal  `json:"spot_price,omitempty"`
+	TWAPPrice         *decimal.Decimal  `json:"twap_price,omitempty"`
+	IntervalMinutes   int               `json:"interval_minutes"`
+	WindowStart       time.Time         `json:"window_start"`
+	WindowEnd         time.Time         `json:"window_end"`
+	Intervals         []PlannedInterval `json:"intervals"`
+	Gas               []GasEstimate     `json:"gas"`
+	ProtocolFeeBps    int               `json:"protocol_fee_bps"`
+	ProtocolFee       decimal.Decimal   `json:"protocol_fee"` // in source token base units
+	MinReceived       decimal.Decimal   `json:"min_received"`
+	ExpectedOutput    *decimal.Decimal  `json:"expected_output,omitempty"`
+	NetExpectedOutput *decimal.Decimal  `json:"net_expected_output,omitempty"`
+	WorstCaseOutput   *decimal.Decimal  `json:"worst_case_output,omitempty"`
+	Warnings          []string          `json:"warnings"`
+}
+
+// PlanIntervals slices an order the way processOrder does: what remains
+// split evenly over the intervals left that fit in the window, one every
+// WindowMinutes/ExecutionIntervals from activation. Each interval's minimum
+// output is its IntervalMinOutput at price, in target token base units,
+// assuming the intervals before it filled at their minimum; a zero price
+// leaves them unset.
+func PlanIntervals(order *database.Order, price decimal.Decimal, now time.Time) []PlannedInterval {
+	sim := *order
+	var plan []PlannedInterval
+
+	for executed := 0; sim.GetRemainingAmount().IsPositive(); executed++ {
+		remaining := sim.ExecutionIntervals - executed - sim.SkippedIntervals
+		if remaining <= 0 {
+			break
+		}
+
+		at := sim.GetNextExecutionTime()
+		if at.Before(now) {
+			at = now
+		}
+		amount := sim.GetRemainingAmount().Div(decimal.NewFromInt(int64(PlannedIntervals(&sim, remaining, at))))
+
+		interval := PlannedInterval{Number: executed, At: at, Amount: amount}
+		fillPrice := price
+		if price.IsPositive() {
+			minOutput := IntervalMinOutput(&sim, amount, price)
+			interval.MinOutput = &minOutput
+			fillPrice = minOutput.Div(amount).Shift(-priceScale(order))
+		}
+		plan = append(plan, interval)
+
+		sim.UpdateAveragePrice(amount, fillPrice)
+		sim.ExecutedAmount = sim.ExecutedAmount.Add(amount)
+		sim.LastExecution = &at
+	}
+
+	return plan
+}
+
+// QuoteOrder previews an order without submitting it: its interval plan,
+// prices, gas and fees, expected and worst-case output, and warnings about
+// anything that would hold its intervals back. permit is whether the order
+// is funded by a permit, which costs gas on the source chain.
+func (e *Engine) QuoteOrder(order *database.Order, permit bool, now time.Time) *Quote {
+	pair := orderTokenPair(order)
+	quote := &Quote{
+		TokenPair:       pair,
+		IntervalMinutes: order.WindowMinutes / order.ExecutionIntervals,
+		WindowStart:     order.ActivatedAt(),
+		WindowEnd:       order.WindowEnd(),
+		MinReceived:     order.MinReceived,
+		Gas:             []GasEstimate{},
+		Warnings:        []string{},
+	}
+	warn := func(format string, args ...interface{}) {
+		quote.Warnings = append(quote.Warnings, fmt.Sprintf(format, args...))
+	}
+
+	spot, err := e.getCurrentPrice(pair)
+	if err == nil {
+		quote.SpotPrice = &spot
+	} else {
+		warn("no spot price: %v", err)
+	}
+	twapPrice, err := e.validatedTWAP(pair, order.WindowMinutes)
+	if err == nil {
+		quote.TWAPPrice = &twapPrice
+	} else {
+		warn("no reliable TWAP, intervals are deferred until there is one: %v", err)
+	}
+
+	// Minimum outputs are set against the TWAP, falling back to spot
+	price := twapPrice
+	if quote.TWAPPrice == nil && quote.SpotPrice != nil {
+		price = spot
+	}
+	quote.Intervals = PlanIntervals(order, price, now)
+
+	if order.TriggerPrice != nil {
+		warn("the window starts when the price crosses the trigger; times assume it fires at %s", quote.WindowStart.UTC().Format(time.RFC3339))
+	}
+	if len(quote.Intervals) < order.ExecutionIntervals {
+		warn("only %d of %d intervals fit before the window ends", len(quote.Intervals), order.ExecutionIntervals)
+	}
+	if err := e.circuitBreaker.Allow(pair); err != nil {
+		warn("intervals are held while the circuit breaker is open: %v", err)
+	}
+	if order.LimitPrice != nil && quote.SpotPrice != nil && !order.WithinLimit(spot) {
+		warn("spot price %s is below the limit price %s, intervals would be skipped", spot, order.LimitPrice)
+	}
+
+	var expected, worstCase decimal.Decimal
+	for i, interval := range quote.Intervals {
+		if interval.Amount.LessThan(order.MinFillSize) && i < len(quote.Intervals)-1 {
+			warn("interval %d of %s is below the minimum fill size %s and would not execute",
+				interval.Number, interval.Amount, order.MinFillSize)
+		}
+		if quote.SpotPrice != nil {
+			output := TargetValue(order, interval.Amount, spot)
+			expected = expected.Add(output)
+			if interval.MinOutput != nil && output.LessThan(*interval.MinOutput) {
+				warn("at the spot price interval %d cannot meet its minimum output %s and would be deferred",
+					interval.Number, interval.MinOutput)
+			}
+		}
+		if interval.MinOutput != nil {
+			worstCase = worstCase.Add(*interval.MinOutput)
+		}
+	}
+	if price.IsPositive() {
+		quote.WorstCaseOutput = &worstCase
+	}
+
+	if order.SourceChain == "ethereum" {
+		quote.ProtocolFeeBps = e.config.EthereumConfig.ProtocolFeeBps
+	}
+	feeRate := decimal.NewFromInt(int64(quote.ProtocolFeeBps)).Div(decimal.NewFromInt(10000))
+	quote.ProtocolFee = order.SourceAmount.Mul(feeRate).Truncate(0)
+
+	gasQuote := e.quoteOrderGas(quote, order, permit, price)
+	if quote.SpotPrice != nil {
+		quote.ExpectedOutput = &expected
+		net := expected.Sub(TargetValue(order, quote.ProtocolFee, spot)).Sub(gasQuote)
+		quote.NetExpectedOutput = &net
+		if expected.LessThan(order.MinReceived) {
+			warn("expected output %s at the spot price is below min received %s", expected.Truncate(0), order.MinReceived)
+		}
+	}
+
+	return quote
+}
+
+// quoteOrderGas adds the gas estimates for creating the order on its source
+// chain and executing its intervals on the target chain, returning their
+// total cost in target token base units where it could be priced
+func (e *Engine) quoteOrderGas(quote *Quote, order *database.Order, permit bool, price decimal.Decimal) decimal.Decimal {
+	total := decimal.Zero
+	estimate := func(chainID, purpose string, transactions int, gasUnits uint64) *GasQuote {
+		gas, err := e.quoteChainGas(order, chainID, gasUnits, price)
+		if err != nil {
+			quote.Warnings = append(quote.Warnings, fmt.Sprintf("no gas estimate for %s on %s: %v", purpose, chainID, err))
+			return nil
+		}
+
+		count := decimal.NewFromInt(int64(transactions))
+		entry := GasEstimate{
+			ChainID:      chainID,
+			Purpose:      purpose,
+			Transactions: transactions,
+			GasUnits:     gasUnits,
+			GasPrice:     gas.GasPrice,
+			NativeToken:  adapters.GetNativeTokenSymbol(chainID),
+			CostNative:   gas.CostNative.Mul(count),
+		}
+		if gas.CostQuote.IsPositive() {
+			cost := gas.CostQuote.Mul(count)
+			entry.CostQuote = &cost
+			total = total.Add(cost)
+		}
+		quote.Gas = append(quote.Gas, entry)
+		return gas
+	}
+
+	if gasUnits := adapters.OrderCreationGas(order.SourceChain, permit); gasUnits > 0 {
+		estimate(order.SourceChain, "order creation", 1, gasUnits)
+	}
+
+	intervals := len(quote.Intervals)
+	gas := estimate(order.TargetChain, "interval execution", intervals, e.expectedIntervalGas(order, nil))
+	if gas != nil && intervals > 0 && price.IsPositive() {
+		sliceValue := TargetValue(order, quote.Intervals[0].Amount, price)
+		if err := CheckGasPolicy(e.config.GasPolicy, gas, sliceValue); err != nil {
+			quote.Warnings = append(quote.Warnings, fmt.Sprintf("intervals may be deferred for cheaper gas: %v", err))
+		}
+	}
+
+	return total
+}
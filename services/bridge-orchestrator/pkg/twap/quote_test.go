@@ -0,0 +1,165 @@
+package twap
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+	"flowfusion/bridge-orchestrator/internal/database"
+	"flowfusion/bridge-orchestrator/pkg/adapters"
+)
+
+func TestPlanIntervals(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	order := &database.Order{
+		CreatedAt:          created,
+		SourceAmount:       decimal.NewFromInt(600),
+		MinReceived:        decimal.NewFromInt(1140),
+		WindowMinutes:      60,
+		ExecutionIntervals: 6,
+		MaxSlippage:        100, // 1%
+	}
+
+	plan := PlanIntervals(order, decimal.NewFromInt(2), created)
+	if len(plan) != 6 {
+		t.Fatalf("planned %d intervals, want 6", len(plan))
+	}
+	for i, interval := range plan {
+		if want := created.Add(time.Duration(i*10) * time.Minute); !interval.At.Equal(want) {
+			t.Fatalf("interval %d at %s, want %s", i, interval.At, want)
+		}
+		if !interval.Amount.Equal(decimal.NewFromInt(100)) {
+			t.Fatalf("interval %d sized %s, want 100", i, interval.Amount)
+		}
+		// The slippage floor of 198 is above the 190 share of MinReceived
+		if interval.MinOutput == nil || !interval.MinOutput.Equal(decimal.NewFromInt(198)) {
+			t.Fatalf("interval %d min output %v, want 198", i, interval.MinOutput)
+		}
+	}
+	if order.ExecutedAmount.IsPositive() || order.LastExecution != nil {
+		t.Fatalf("planning modified the order: %+v", order)
+	}
+
+	// Half way through the window only 4 intervals fit, the last at its
+	// end, so the order is split between them
+	late := PlanIntervals(order, decimal.Zero, created.Add(30*time.Minute))
+	if len(late) != 4 || !late[0].Amount.Equal(decimal.NewFromInt(150)) ||
+		!late[3].At.Equal(created.Add(time.Hour)) {
+		t.Fatalf("late plan should have 4 intervals of 150: %+v", late)
+	}
+	if late[0].MinOutput != nil {
+		t.Fatalf("min output set without a price: %s", late[0].MinOutput)
+	}
+}
+
+// newQuoteEngine returns an engine quoting against the mock Ethereum
+// adapter, whose gas price is 20 gwei, with a spot price for each pair
+func newQuoteEngine(t *testing.T, now time.Time, prices map[string]decimal.Decimal) *Engine {
+	t.Helper()
+	cfg := config.Config{
+		SupportedChains: []string{"ethereum"},
+		EthereumConfig:  config.EthereumConfig{GasLimit: 200000, ProtocolFeeBps: 25},
+	}
+	manager, err := adapters.NewManager(&cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache := &PriceCache{data: make(map[string][]*PricePoint)}
+	for pair, price := range prices {
+		cache.data[pair] = []*PricePoint{{Timestamp: now.Add(-time.Minute), Price: price}}
+	}
+	return &Engine{
+		config:         cfg,
+		adapterManager: manager,
+		logger:         zap.NewNop(),
+		circuitBreaker: NewCircuitBreaker(testBreakerConfig, zap.NewNop()),
+		priceCache:     cache,
+	}
+}
+
+func TestQuoteNetsGasInTargetBaseUnits(t *testing.T) {
+	now := time.Now()
+	spot := decimal.NewFromInt(2500)
+	engine := newQuoteEngine(t, now, map[string]decimal.Decimal{"WETH_DAI": spot, "ETH_DAI": spot})
+
+	order := &database.Order{
+		SourceChain:        "ethereum",
+		SourceToken:        "WETH",
+		TargetChain:        "ethereum",
+		TargetToken:        "DAI",
+		CreatedAt:          now,
+		SourceAmount:       decimal.RequireFromString("0.6").Shift(18),
+		WindowMinutes:      60,
+		ExecutionIntervals: 6,
+		MaxSlippage:        100,
+	}
+	quote := engine.QuoteOrder(order, false, now)
+	if quote.NetExpectedOutput == nil {
+		t.Fatalf("no net expected output: %v", quote.Warnings)
+	}
+
+	// 0.6 WETH is 1500 DAI, less 3.75 DAI of protocol fee. At the mock's
+	// 20 gwei, creating the order costs 0.016 ETH and the 6 intervals
+	// 0.004 ETH each, 100 DAI in all: enough to matter.
+	dai := func(amount string) decimal.Decimal { return decimal.RequireFromString(amount).Shift(18) }
+	if !quote.ExpectedOutput.Equal(dai("1500")) {
+		t.Fatalf("expected output = %s, want 1500 DAI in base units", quote.ExpectedOutput)
+	}
+	var gas decimal.Decimal
+	for _, estimate := range quote.Gas {
+		if estimate.CostQuote == nil {
+			t.Fatalf("gas for %s not priced", estimate.Purpose)
+		}
+		gas = gas.Add(*estimate.CostQuote)
+	}
+	if !gas.Equal(dai("100")) {
+		t.Fatalf("gas = %s, want 100 DAI in base units", gas)
+	}
+	if want := dai("1396.25"); !quote.NetExpectedOutput.Equal(want) {
+		t.Fatalf("net expected output = %s, want %s", quote.NetExpectedOutput, want)
+	}
+}
+
+func TestQuoteAcrossDecimals(t *testing.T) {
+	now := time.Now()
+	spot := decimal.NewFromInt(2500)
+	engine := newQuoteEngine(t, now, map[string]decimal.Decimal{"WETH_USDC": spot, "ETH_USDC": spot})
+	usdc := func(amount string) decimal.Decimal { return decimal.RequireFromString(amount).Shift(6) }
+
+	// WETH has 18 decimals and USDC 6
+	order := &database.Order{
+		SourceChain:        "ethereum",
+		SourceToken:        "WETH",
+		TargetChain:        "ethereum",
+		TargetToken:        "USDC",
+		CreatedAt:          now,
+		SourceAmount:       decimal.RequireFromString("0.6").Shift(18),
+		MinReceived:        usdc("1440"),
+		WindowMinutes:      60,
+		ExecutionIntervals: 6,
+		MaxSlippage:        100,
+	}
+	quote := engine.QuoteOrder(order, false, now)
+
+	if quote.ExpectedOutput == nil || !quote.ExpectedOutput.Equal(usdc("1500")) {
+		t.Fatalf("expected output = %v, want 1500 USDC in base units", quote.ExpectedOutput)
+	}
+	// Each 0.1 WETH must fetch 2500 less 1%, above its share of MinReceived
+	for _, interval := range quote.Intervals {
+		if interval.MinOutput == nil || !interval.MinOutput.Equal(usdc("247.5")) {
+			t.Fatalf("interval %d min output = %v, want 247.5 USDC in base units", interval.Number, interval.MinOutput)
+		}
+	}
+	if quote.WorstCaseOutput == nil || !quote.WorstCaseOutput.Equal(usdc("1485")) {
+		t.Fatalf("worst case output = %v, want 1485 USDC in base units", quote.WorstCaseOutput)
+	}
+	for _, warning := range quote.Warnings {
+		if strings.Contains(warning, "minimum output") || strings.Contains(warning, "min received") {
+			t.Fatalf("outputs compared in different units: %s", warning)
+		}
+	}
+}
@@ -0,0 +1,468 @@
+package twap
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+// Shortfalls in transaction cost analysis are in basis points of the
+// benchmark price, positive when the order received less than the
+// benchmark would have paid. Prices are target token per source token.
+
+// IntervalTCA measures one executed interval against the market
+type IntervalTCA struct {
+	IntervalNumber  int              `json:"interval_number"`
+	Timestamp       time.Time        `json:"timestamp"`
+	Amount          decimal.Decimal  `json:"amount"`
+	Price           decimal.Decimal  `json:"price"`
+	MarketPrice     *decimal.Decimal `json:"market_price,omitempty"`      // latest observation at the fill
+	ShortfallBps    *decimal.Decimal `json:"shortfall_bps,omitempty"`     // against the arrival price
+	MarketImpactBps *decimal.Decimal `json:"market_impact_bps,omitempty"` // against the market price
+	SlippageBps     *int             `json:"slippage_bps,omitempty"`
+	GasCostQuote    *decimal.Decimal `json:"gas_cost_quote,omitempty"`
+}
+
+// OrderTCA is the transaction cost analysis of one order. Benchmarks are
+// unset when the price history does not cover them. Once raw price points
+// have been pruned they come from the finest candles still retained.
+type OrderTCA struct {
+	OrderID     string        `json:"order_id"`
+	TokenPair   string        `json:"token_pair"`
+	Status      string        `json:"status"`
+	WindowStart time.Time     `json:"window_start"`
+	WindowEnd   time.Time     `json:"window_end"`
+	Intervals   []IntervalTCA `json:"intervals"`
+
+	ExecutedAmount decimal.Decimal  `json:"executed_amount"`
+	ReceivedAmount decimal.Decimal  `json:"received_amount"`
+	AveragePrice   *decimal.Decimal `json:"average_price,omitempty"`
+
+	// Implementation shortfall against the price when the order was created
+	ArrivalPrice               *decimal.Decimal `json:"arrival_price,omitempty"`
+	ImplementationShortfallBps *decimal.Decimal `json:"implementation_shortfall_bps,omitempty"`
+	ShortfallQuote             *decimal.Decimal `json:"shortfall_quote,omitempty"` // in target token
+
+	// The market over the order's window
+	MarketTWAP       *decimal.Decimal `json:"market_twap,omitempty"`
+	MarketVWAP       *decimal.Decimal `json:"market_vwap,omitempty"`
+	TWAPShortfallBps *decimal.Decimal `json:"twap_shortfall_bps,omitempty"`
+	VWAPShortfallBps *decimal.Decimal `json:"vwap_shortfall_bps,omitempty"`
+
+	// Slippage the engine recorded against the order's MaxSlippage
+	MaxSlippageBps      int              `json:"max_slippage_bps"`
+	AverageSlippageBps  *decimal.Decimal `json:"average_slippage_bps,omitempty"` // weighted by amount
+	WorstSlippageBps    *int             `json:"worst_slippage_bps,omitempty"`
+	SlippageBreaches    int              `json:"slippage_breaches"`
+	SlippageUtilization *decimal.Decimal `json:"slippage_utilization,omitempty"` // average over max
+
+	// Gas paid, in the target chain's native token and the target token
+	GasCostNative decimal.Decimal  `json:"gas_cost_native"`
+	GasCostQuote  decimal.Decimal  `json:"gas_cost_quote"`
+	GasDragBps    *decimal.Decimal `json:"gas_drag_bps,omitempty"` // priced gas over received
+	NetPrice      *decimal.Decimal `json:"net_price,omitempty"`    // average price after priced gas
+}
+
+// AnalyzeExecution computes an order's TCA from its execution history and
+// the pair's price points around its window. A market price is only used
+// where an observation is at most maxGap old.
+func AnalyzeExecution(order *database.Order, history []*database.ExecutionRecord, points []*PricePoint, now time.Time, maxGap time.Duration) *OrderTCA {
+	sorted := sortedPoints(points)
+	start, end := tcaWindow(order, history, now)
+
+	tca := &OrderTCA{
+		OrderID:        order.ID,
+		TokenPair:      orderTokenPair(order),
+		Status:         order.Status,
+		WindowStart:    start,
+		WindowEnd:      end,
+		Intervals:      make([]IntervalTCA, 0, len(history)),
+		ExecutedAmount: decimal.Zero,
+		ReceivedAmount: decimal.Zero,
+		MaxSlippageBps: order.MaxSlippage,
+		GasCostNative:  decimal.Zero,
+		GasCostQuote:   decimal.Zero,
+	}
+	if arrival, ok := priceAt(sorted, order.CreatedAt, maxGap); ok {
+		tca.ArrivalPrice = &arrival
+	}
+
+	var slipped, slippageSum decimal.Decimal
+	for _, record := range history {
+		interval := IntervalTCA{
+			IntervalNumber: record.IntervalNumber,
+			Timestamp:      record.Timestamp,
+			Amount:         record.Amount,
+			Price:          record.Price,
+			SlippageBps:    record.Slippage,
+			GasCostQuote:   record.GasCostQuote,
+		}
+		if market, ok := priceAt(sorted, record.Timestamp, maxGap); ok {
+			interval.MarketPrice = &market
+			interval.MarketImpactBps = shortfallBps(market, record.Price)
+		}
+		if tca.ArrivalPrice != nil {
+			interval.ShortfallBps = shortfallBps(*tca.ArrivalPrice, record.Price)
+		}
+		tca.Intervals = append(tca.Intervals, interval)
+
+		tca.ExecutedAmount = tca.ExecutedAmount.Add(record.Amount)
+		tca.ReceivedAmount = tca.ReceivedAmount.Add(record.Amount.Mul(record.Price))
+
+		if record.Slippage != nil {
+			slipped = slipped.Add(record.Amount)
+			slippageSum = slippageSum.Add(record.Amount.Mul(decimal.NewFromInt(int64(*record.Slippage))))
+			if tca.WorstSlippageBps == nil || *record.Slippage > *tca.WorstSlippageBps {
+				worst := *record.Slippage
+				tca.WorstSlippageBps = &worst
+			}
+			if *record.Slippage > order.MaxSlippage {
+				tca.SlippageBreaches++
+			}
+		}
+
+		if record.GasCostNative != nil {
+			tca.GasCostNative = tca.GasCostNative.Add(*record.GasCostNative)
+		}
+		if record.GasCostQuote != nil {
+			tca.GasCostQuote = tca.GasCostQuote.Add(*record.GasCostQuote)
+		}
+	}
+
+	if slipped.IsPositive() {
+		average := slippageSum.Div(slipped).Round(2)
+		tca.AverageSlippageBps = &average
+		if order.MaxSlippage > 0 {
+			utilization := average.Div(decimal.NewFromInt(int64(order.MaxSlippage))).Round(4)
+			tca.SlippageUtilization = &utilization
+		}
+	}
+
+	if !tca.ExecutedAmount.IsPositive() {
+		return tca
+	}
+
+	achieved := tca.ReceivedAmount.Div(tca.ExecutedAmount)
+	tca.AveragePrice = &achieved
+
+	if tca.ArrivalPrice != nil {
+		tca.ImplementationShortfallBps = shortfallBps(*tca.ArrivalPrice, achieved)
+		cost := tca.ExecutedAmount.Mul(*tca.ArrivalPrice).Sub(tca.ReceivedAmount)
+		tca.ShortfallQuote = &cost
+	}
+
+	if twapPrice, err := TimeWeightedAverage(sorted, start, end); err == nil {
+		tca.MarketTWAP = &twapPrice
+		tca.TWAPShortfallBps = shortfallBps(twapPrice, achieved)
+	}
+	if vwap, err := VolumeWeightedAverage(sorted, start, end); err == nil {
+		tca.MarketVWAP = &vwap
+		tca.VWAPShortfallBps = shortfallBps(vwap, achieved)
+	}
+
+	if tca.ReceivedAmount.IsPositive() {
+		drag := tca.GasCostQuote.Div(tca.ReceivedAmount).Mul(decimal.NewFromInt(10000)).Round(2)
+		tca.GasDragBps = &drag
+		net := tca.ReceivedAmount.Sub(tca.GasCostQuote).Div(tca.ExecutedAmount)
+		tca.NetPrice = &net
+	}
+
+	return tca
+}
+
+// PairTCA aggregates the TCA of a pair's orders. Averages are weighted by
+// executed amount.
+type PairTCA struct {
+	TokenPair                  string           `json:"token_pair"`
+	Orders                     int              `json:"orders"`
+	ExecutedAmount             decimal.Decimal  `json:"executed_amount"`
+	ReceivedAmount             decimal.Decimal  `json:"received_amount"`
+	GasCostQuote               decimal.Decimal  `json:"gas_cost_quote"`
+	AveragePrice               *decimal.Decimal `json:"average_price,omitempty"`
+	ImplementationShortfallBps *decimal.Decimal `json:"implementation_shortfall_bps,omitempty"`
+	TWAPShortfallBps           *decimal.Decimal `json:"twap_shortfall_bps,omitempty"`
+	VWAPShortfallBps           *decimal.Decimal `json:"vwap_shortfall_bps,omitempty"`
+	AverageSlippageBps         *decimal.Decimal `json:"average_slippage_bps,omitempty"`
+	SlippageBreaches           int              `json:"slippage_breaches"`
+	GasDragBps                 *decimal.Decimal `json:"gas_drag_bps,omitempty"`
+}
+
+// TCASummary aggregates the TCA of several orders. Orders in different
+// pairs cannot be weighted by amount, so the overall averages count each
+// executed order once; Pairs breaks them down by amount.
+type TCASummary struct {
+	Orders                     int              `json:"orders"`
+	ExecutedOrders             int              `json:"executed_orders"`
+	Intervals                  int              `json:"intervals"`
+	ImplementationShortfallBps *decimal.Decimal `json:"implementation_shortfall_bps,omitempty"`
+	TWAPShortfallBps           *decimal.Decimal `json:"twap_shortfall_bps,omitempty"`
+	VWAPShortfallBps           *decimal.Decimal `json:"vwap_shortfall_bps,omitempty"`
+	AverageSlippageBps         *decimal.Decimal `json:"average_slippage_bps,omitempty"`
+	SlippageBreaches           int              `json:"slippage_breaches"`
+	GasDragBps                 *decimal.Decimal `json:"gas_drag_bps,omitempty"`
+	Pairs                      []*PairTCA       `json:"pairs"`
+}
+
+// SummarizeTCA aggregates order TCAs overall and per pair
+func SummarizeTCA(reports []*OrderTCA) *TCASummary {
+	summary := &TCASummary{Orders: len(reports), Pairs: []*PairTCA{}}
+
+	var shortfall, twapShortfall, vwapShortfall, slippage, gasDrag mean
+	pairs := make(map[string]*pairAccumulator)
+	for _, tca := range reports {
+		summary.Intervals += len(tca.Intervals)
+		summary.SlippageBreaches += tca.SlippageBreaches
+
+		acc, ok := pairs[tca.TokenPair]
+		if !ok {
+			acc = &pairAccumulator{PairTCA: PairTCA{
+				TokenPair:      tca.TokenPair,
+				ExecutedAmount: decimal.Zero,
+				ReceivedAmount: decimal.Zero,
+				GasCostQuote:   decimal.Zero,
+			}}
+			pairs[tca.TokenPair] = acc
+		}
+		acc.Orders++
+		acc.SlippageBreaches += tca.SlippageBreaches
+
+		if !tca.ExecutedAmount.IsPositive() {
+			continue
+		}
+		summary.ExecutedOrders++
+		shortfall.add(tca.ImplementationShortfallBps, decimal.NewFromInt(1))
+		twapShortfall.add(tca.TWAPShortfallBps, decimal.NewFromInt(1))
+		vwapShortfall.add(tca.VWAPShortfallBps, decimal.NewFromInt(1))
+		slippage.add(tca.AverageSlippageBps, decimal.NewFromInt(1))
+		gasDrag.add(tca.GasDragBps, decimal.NewFromInt(1))
+		acc.add(tca)
+	}
+
+	summary.ImplementationShortfallBps = shortfall.value()
+	summary.TWAPShortfallBps = twapShortfall.value()
+	summary.VWAPShortfallBps = vwapShortfall.value()
+	summary.AverageSlippageBps = slippage.value()
+	summary.GasDragBps = gasDrag.value()
+
+	for _, acc := range pairs {
+		summary.Pairs = append(summary.Pairs, acc.result())
+	}
+	sort.Slice(summary.Pairs, func(i, j int) bool {
+		return summary.Pairs[i].TokenPair < summary.Pairs[j].TokenPair
+	})
+
+	return summary
+}
+
+// pairAccumulator sums a pair's orders, weighting by executed amount
+type pairAccumulator struct {
+	PairTCA
+	shortfall, twapShortfall, vwapShortfall, slippage mean
+}
+
+func (a *pairAccumulator) add(tca *OrderTCA) {
+	a.ExecutedAmount = a.ExecutedAmount.Add(tca.ExecutedAmount)
+	a.ReceivedAmount = a.ReceivedAmount.Add(tca.ReceivedAmount)
+	a.GasCostQuote = a.GasCostQuote.Add(tca.GasCostQuote)
+	a.shortfall.add(tca.ImplementationShortfallBps, tca.ExecutedAmount)
+	a.twapShortfall.add(tca.TWAPShortfallBps, tca.ExecutedAmount)
+	a.vwapShortfall.add(tca.VWAPShortfallBps, tca.ExecutedAmount)
+	a.slippage.add(tca.AverageSlippageBps, tca.ExecutedAmount)
+}
+
+func (a *pairAccumulator) result() *PairTCA {
+	pair := a.PairTCA
+	pair.ImplementationShortfallBps = a.shortfall.value()
+	pair.TWAPShortfallBps = a.twapShortfall.value()
+	pair.VWAPShortfallBps = a.vwapShortfall.value()
+	pair.AverageSlippageBps = a.slippage.value()
+	if pair.ExecutedAmount.IsPositive() {
+		average := pair.ReceivedAmount.Div(pair.ExecutedAmount)
+		pair.AveragePrice = &average
+	}
+	if pair.ReceivedAmount.IsPositive() {
+		drag := pair.GasCostQuote.Div(pair.ReceivedAmount).Mul(decimal.NewFromInt(10000)).Round(2)
+		pair.GasDragBps = &drag
+	}
+	return &pair
+}
+
+// mean is a weighted average of the values that are set
+type mean struct {
+	sum, weight decimal.Decimal
+}
+
+func (m *mean) add(value *decimal.Decimal, weight decimal.Decimal) {
+	if value == nil {
+		return
+	}
+	m.sum = m.sum.Add(value.Mul(weight))
+	m.weight = m.weight.Add(weight)
+}
+
+func (m *mean) value() *decimal.Decimal {
+	if !m.weight.IsPositive() {
+		return nil
+	}
+	v := m.sum.Div(m.weight).Round(2)
+	return &v
+}
+
+// OrderTCA loads an order's execution history and the prices around its
+// window and analyzes them
+func (e *Engine) OrderTCA(order *database.Order) (*OrderTCA, error) {
+	history, err := e.db.GetExecutionHistory(order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load execution history: %w", err)
+	}
+
+	now := time.Now()
+	_, end := tcaWindow(order, history, now)
+
+	// The arrival price is the latest observation before the order was
+	// created, so the prices start one gap earlier
+	points, maxGap, err := e.tcaPrices(orderTokenPair(order), order.CreatedAt.Add(-e.config.TWAPConfig.MaxPriceGap), end, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return AnalyzeExecution(order, history, points, now, maxGap), nil
+}
+
+// tcaPrices loads a pair's prices over [from, to] and the gap allowed
+// between them: raw price points while they are retained, otherwise the
+// closes of the finest candles that reach back to from. A candle's close
+// is only known when its bucket ends, so it is observed then and the gap
+// widens by the bucket width.
+func (e *Engine) tcaPrices(tokenPair string, from, to, now time.Time) ([]*PricePoint, time.Duration, error) {
+	maxGap := e.config.TWAPConfig.MaxPriceGap
+
+	resolution, ok := tcaResolution(e.config.PriceRetention, from, now)
+	if !ok {
+		dbPoints, err := e.db.GetPricePointsBetween(tokenPair, from, to)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to load price points: %w", err)
+		}
+
+		points := make([]*PricePoint, 0, len(dbPoints))
+		for _, p := range dbPoints {
+			volume := decimal.Zero
+			if p.Volume != nil {
+				volume = *p.Volume
+			}
+			points = append(points, &PricePoint{Timestamp: p.Timestamp, Price: p.Price, Volume: volume, Source: p.Source})
+		}
+		return points, maxGap, nil
+	}
+
+	width := resolution.Duration()
+	candles, err := e.db.GetCandles(tokenPair, resolution, from.Add(-width))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load %s candles: %w", resolution, err)
+	}
+	return candlePrices(candles, to), maxGap + width, nil
+}
+
+// tcaResolution returns the candle resolution to analyze prices from, or
+// false while raw price points reaching back to from are still retained.
+// Past every retention raw points are used, and benchmarks are unset for
+// lack of them.
+func tcaResolution(retention config.PriceRetentionConfig, from, now time.Time) (database.CandleResolution, bool) {
+	kept := func(keep time.Duration) bool {
+		return keep <= 0 || !from.Before(now.Add(-keep))
+	}
+	if kept(retention.RawPoints) {
+		return "", false
+	}
+
+	for _, candles := range []struct {
+		resolution database.CandleResolution
+		keep       time.Duration
+	}{
+		{database.CandleResolution1m, retention.Candles1m},
+		{database.CandleResolution5m, retention.Candles5m},
+		{database.CandleResolution1h, retention.Candles1h},
+	} {
+		if kept(candles.keep) {
+			return candles.resolution, true
+		}
+	}
+	return "", false
+}
+
+// candlePrices observes each candle's close when its bucket ends, dropping
+// buckets that end after to
+func candlePrices(candles []*database.Candle, to time.Time) []*PricePoint {
+	points := make([]*PricePoint, 0, len(candles))
+	for _, c := range candles {
+		closed := c.BucketStart.Add(c.Resolution.Duration())
+		if closed.After(to) {
+			continue
+		}
+		points = append(points, &PricePoint{Timestamp: closed, Price: c.Close, Volume: c.Volume})
+	}
+	return points
+}
+
+// tcaWindow returns the period the order was working: from activation to
+// the end of its window, or to its last fill once it has finished, and
+// never past now
+func tcaWindow(order *database.Order, history []*database.ExecutionRecord, now time.Time) (time.Time, time.Time) {
+	start := order.ActivatedAt()
+	end := order.WindowEnd()
+	if !isWorking(order) && len(history) > 0 {
+		last := history[0].Timestamp
+		for _, record := range history[1:] {
+			if record.Timestamp.After(last) {
+				last = record.Timestamp
+			}
+		}
+		if last.Before(end) {
+			end = last
+		}
+	}
+	if end.After(now) {
+		end = now
+	}
+	if end.Before(start) {
+		end = start
+	}
+	return start, end
+}
+
+// isWorking checks if the order may still fill intervals
+func isWorking(order *database.Order) bool {
+	switch database.OrderStatus(order.Status) {
+	case database.OrderStatusPending, database.OrderStatusExecuting, database.OrderStatusPaused:
+		return true
+	}
+	return false
+}
+
+// priceAt returns the latest observation at or before t, if it is at most
+// maxGap old. points must be sorted.
+func priceAt(points []*PricePoint, t time.Time, maxGap time.Duration) (decimal.Decimal, bool) {
+	i := sort.Search(len(points), func(i int) bool { return points[i].Timestamp.After(t) })
+	if i == 0 {
+		return decimal.Zero, false
+	}
+	latest := points[i-1]
+	if maxGap > 0 && t.Sub(latest.Timestamp) > maxGap {
+		return decimal.Zero, false
+	}
+	return latest.Price, true
+}
+
+// shortfallBps is how far achieved fell short of benchmark, in basis points
+func shortfallBps(benchmark, achieved decimal.Decimal) *decimal.Decimal {
+	if !benchmark.IsPositive() {
+		return nil
+	}
+	bps := benchmark.Sub(achieved).Div(benchmark).Mul(decimal.NewFromInt(10000)).Round(2)
+	return &bps
+}
@@ -0,0 +1,169 @@
+package twap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"flowfusion/bridge-orchestrator/internal/config"
+	"flowfusion/bridge-orchestrator/internal/database"
+)
+
+func TestAnalyzeExecution(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return created.Add(time.Duration(minutes) * time.Minute) }
+	d := func(v string) decimal.Decimal { return decimal.RequireFromString(v) }
+	ptr := func(v decimal.Decimal) *decimal.Decimal { return &v }
+	bps := func(v int) *int { return &v }
+
+	order := &database.Order{
+		ID:                 "ord_tca",
+		SourceToken:        "WETH",
+		TargetToken:        "USDC",
+		Status:             string(database.OrderStatusExecuting),
+		CreatedAt:          created,
+		SourceAmount:       decimal.NewFromInt(400),
+		WindowMinutes:      60,
+		ExecutionIntervals: 4,
+		MaxSlippage:        50,
+	}
+	points := []*PricePoint{
+		{Timestamp: at(-1), Price: d("2.0"), Volume: decimal.NewFromInt(10)},
+		{Timestamp: at(30), Price: d("1.8"), Volume: decimal.NewFromInt(30)},
+	}
+	history := []*database.ExecutionRecord{
+		{IntervalNumber: 0, Timestamp: at(0), Amount: decimal.NewFromInt(100), Price: d("1.98"), Slippage: bps(10), GasCostQuote: ptr(decimal.NewFromInt(1))},
+		{IntervalNumber: 1, Timestamp: at(30), Amount: decimal.NewFromInt(100), Price: d("1.78"), Slippage: bps(60), GasCostQuote: ptr(decimal.NewFromInt(1))},
+	}
+
+	tca := AnalyzeExecution(order, history, points, at(45), 5*time.Minute)
+
+	check := func(name string, got *decimal.Decimal, want string) {
+		t.Helper()
+		if got == nil || !got.Equal(d(want)) {
+			t.Fatalf("%s = %v, want %s", name, got, want)
+		}
+	}
+	if !tca.WindowEnd.Equal(at(45)) {
+		t.Fatalf("a working order's window should end now: %s", tca.WindowEnd)
+	}
+	check("arrival price", tca.ArrivalPrice, "2")
+	check("average price", tca.AveragePrice, "1.88")
+	check("implementation shortfall", tca.ImplementationShortfallBps, "600")
+	check("shortfall in target token", tca.ShortfallQuote, "24")
+	check("interval 0 market impact", tca.Intervals[0].MarketImpactBps, "100")
+	check("interval 1 market impact", tca.Intervals[1].MarketImpactBps, "111.11")
+	check("interval 1 shortfall", tca.Intervals[1].ShortfallBps, "1100")
+	// 2.0 for 30 minutes then 1.8 for 15
+	check("TWAP shortfall", tca.TWAPShortfallBps, "275.86")
+	// Only the 1.8 observation is inside the window
+	check("VWAP shortfall", tca.VWAPShortfallBps, "-444.44")
+	check("average slippage", tca.AverageSlippageBps, "35")
+	check("slippage utilization", tca.SlippageUtilization, "0.7")
+	check("gas drag", tca.GasDragBps, "53.19")
+	if tca.SlippageBreaches != 1 || tca.WorstSlippageBps == nil || *tca.WorstSlippageBps != 60 {
+		t.Fatalf("one interval slipped past the 50 bps tolerance: %+v", tca)
+	}
+
+	// A finished order is measured up to its last fill, and the arrival
+	// price needs an observation recent enough at creation
+	order.Status = string(database.OrderStatusPartiallyFilled)
+	finished := AnalyzeExecution(order, history, points[1:], at(120), 5*time.Minute)
+	if !finished.WindowEnd.Equal(at(30)) {
+		t.Fatalf("a finished order's window should end at its last fill: %s", finished.WindowEnd)
+	}
+	if finished.ArrivalPrice != nil || finished.ImplementationShortfallBps != nil {
+		t.Fatalf("arrival price set without an observation: %v", finished.ArrivalPrice)
+	}
+
+	pending := &database.Order{ID: "ord_pending", SourceToken: "WETH", TargetToken: "USDC", CreatedAt: created,
+		Status: string(database.OrderStatusPending), WindowMinutes: 60, ExecutionIntervals: 4}
+	summary := SummarizeTCA([]*OrderTCA{tca, AnalyzeExecution(pending, nil, points, at(45), 5*time.Minute)})
+	if summary.Orders != 2 || summary.ExecutedOrders != 1 || summary.Intervals != 2 || len(summary.Pairs) != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	check("summary shortfall", summary.ImplementationShortfallBps, "600")
+	pair := summary.Pairs[0]
+	if pair.TokenPair != "WETH_USDC" || pair.Orders != 2 || !pair.ReceivedAmount.Equal(decimal.NewFromInt(376)) {
+		t.Fatalf("unexpected pair summary: %+v", pair)
+	}
+	check("pair gas drag", pair.GasDragBps, "53.19")
+}
+
+func TestTCAFallsBackToCandles(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	retention := config.PriceRetentionConfig{RawPoints: 2 * day, Candles1m: 7 * day, Candles5m: 30 * day, Candles1h: 365 * day}
+
+	for _, tc := range []struct {
+		age  time.Duration
+		want database.CandleResolution
+	}{
+		{time.Hour, ""},
+		{3 * day, database.CandleResolution1m},
+		{10 * day, database.CandleResolution5m},
+		{60 * day, database.CandleResolution1h},
+		{400 * day, ""},
+	} {
+		resolution, ok := tcaResolution(retention, now.Add(-tc.age), now)
+		if resolution != tc.want || ok != (tc.want != "") {
+			t.Fatalf("prices from %s ago read from %q, want %q", tc.age, resolution, tc.want)
+		}
+	}
+	if _, ok := tcaResolution(config.PriceRetentionConfig{}, now.Add(-400*day), now); ok {
+		t.Fatalf("raw points kept forever should not fall back to candles")
+	}
+
+	// The order of TestAnalyzeExecution, 10 days on, with its prices only
+	// in 5m candles: 2.0 until 12:30, then 1.8
+	created := now.Add(-10 * day).Add(12 * time.Hour)
+	at := func(minutes int) time.Time { return created.Add(time.Duration(minutes) * time.Minute) }
+	d := func(v string) decimal.Decimal { return decimal.RequireFromString(v) }
+
+	var candles []*database.Candle
+	for minute := -10; minute <= 45; minute += 5 {
+		price := d("2.0")
+		if minute >= 30 {
+			price = d("1.8")
+		}
+		candles = append(candles, &database.Candle{TokenPair: "WETH_USDC", Resolution: database.CandleResolution5m,
+			BucketStart: at(minute), Close: price, Volume: decimal.NewFromInt(10)})
+	}
+	points := candlePrices(candles, at(45))
+	if len(points) != len(candles)-1 || !points[0].Timestamp.Equal(at(-5)) {
+		t.Fatalf("candles should be observed when their bucket ends, up to 12:45: %d points from %s",
+			len(points), points[0].Timestamp)
+	}
+
+	order := &database.Order{
+		ID:                 "ord_tca",
+		SourceToken:        "WETH",
+		TargetToken:        "USDC",
+		Status:             string(database.OrderStatusExecuting),
+		CreatedAt:          created,
+		SourceAmount:       decimal.NewFromInt(400),
+		WindowMinutes:      60,
+		ExecutionIntervals: 4,
+		MaxSlippage:        50,
+	}
+	history := []*database.ExecutionRecord{
+		{IntervalNumber: 0, Timestamp: at(0), Amount: decimal.NewFromInt(100), Price: d("1.98")},
+		{IntervalNumber: 1, Timestamp: at(32), Amount: decimal.NewFromInt(100), Price: d("1.78")},
+	}
+
+	tca := AnalyzeExecution(order, history, points, at(45), 5*time.Minute+database.CandleResolution5m.Duration())
+	if tca.ArrivalPrice == nil || !tca.ArrivalPrice.Equal(d("2")) {
+		t.Fatalf("arrival price = %v, want 2 from the candle closing at creation", tca.ArrivalPrice)
+	}
+	if tca.ImplementationShortfallBps == nil || !tca.ImplementationShortfallBps.Equal(d("600")) {
+		t.Fatalf("implementation shortfall = %v, want 600", tca.ImplementationShortfallBps)
+	}
+	// 12:32 is priced at the close of the 12:25 bucket
+	if market := tca.Intervals[1].MarketPrice; market == nil || !market.Equal(d("2")) {
+		t.Fatalf("interval 1 market price = %v, want 2", market)
+	}
+	if tca.MarketTWAP == nil || tca.MarketVWAP == nil {
+		t.Fatalf("window benchmarks unset with candles covering it")
+	}
+}